@@ -2,16 +2,37 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
 
 	"github.com/yourusername/webscrape-to-wikijs/internal/auth"
+	"github.com/yourusername/webscrape-to-wikijs/internal/config"
 	"github.com/yourusername/webscrape-to-wikijs/internal/conversion"
 	csvpkg "github.com/yourusername/webscrape-to-wikijs/internal/csv"
 	"github.com/yourusername/webscrape-to-wikijs/internal/discovery"
+	"github.com/yourusername/webscrape-to-wikijs/internal/feed"
+	"github.com/yourusername/webscrape-to-wikijs/internal/logger"
+	"github.com/yourusername/webscrape-to-wikijs/internal/progress"
+	"github.com/yourusername/webscrape-to-wikijs/internal/search"
+	"github.com/yourusername/webscrape-to-wikijs/internal/sitemap"
 	"github.com/yourusername/webscrape-to-wikijs/internal/sync"
+	"github.com/yourusername/webscrape-to-wikijs/internal/tags"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+	"github.com/yourusername/webscrape-to-wikijs/internal/validation"
+	"github.com/yourusername/webscrape-to-wikijs/internal/wikijs"
 )
 
 const (
@@ -24,46 +45,458 @@ Commands:
   discover   Discover files in Google Drive folders and output CSV
   convert    Convert Google Drive documents to markdown
   sync       Sync existing markdown files with Google Drive updates
+  validate-links
+             Check relative links in converted markdown and suggest fixes
+  validate   Check that converted markdown has the required frontmatter fields and content hash
+  check-credentials
+             Verify the credentials can access the Drive API before a long run
+  revoke-token
+             Revoke and delete the saved OAuth2 token to re-authenticate or switch accounts
+  suggest-tags
+             Suggest tags for converted markdown using TF-IDF keyword scores
+  dedup-check
+             Find near-duplicate converted documents using SimHash fingerprints
+  inventory-links
+             List external links in converted markdown and optionally check their status
+  wikijs     Create or update Wiki.js pages from converted markdown
+  wikijs-tree
+             Create the Wiki.js pages for the fragment directories of a conversion CSV
 
 Discover Flags:
   -input string
         Input CSV file with Google Drive URLs (required)
   -output string
-        Output CSV file path (required)
+        Output CSV or JSON file path (required)
   -credentials string
         Google API credentials JSON file (required)
+  -auth-flow string
+        How to get an OAuth2 token when none is saved: browser or device (default: browser)
+  -token-path string
+        Saved OAuth2 token file (default: ~/.credentials/gdrive-crawler-token.json)
+  -max-requests-per-second float
+        Cap on Drive API requests per second (default: 0, unlimited)
+  -quota-report string
+        JSON file that counts Drive API calls per day across runs; the summary is logged at the end
+  -quota-limit int
+        Daily Drive API call limit: warn at 80%, stop sending requests at 100% (default: 1000000000)
   -depth int
-        Maximum depth for recursive link discovery (default: 5)
+        Maximum depth for recursive link discovery, unless the input CSV has a depth for the URL (default: 5)
+  -discover-workers int
+        Number of input URLs discovered in parallel (default: 3)
+  -deduplicate
+        Drop records whose link points to a file already listed, keeping the longer link (default: true)
   -verbose
         Enable verbose logging
+  -exclude-folders string
+        Comma-separated glob patterns of folder names to skip (case-insensitive)
+  -exclude-folder-ids string
+        Comma-separated Drive folder IDs to skip
+  -mime-filter string
+        Comma-separated MIME type globs of the files discovered, e.g. application/vnd.google-apps.* (default: all)
+  -name-pattern string
+        Glob that file names must match to be listed, e.g. "RFC-*"; other files are still searched for links
+  -shared-drive-id string
+        Shared Drive ID to list folder contents from
+  -include-shared-with-me
+        Also discover files in "Shared with me", merged with the input folders
+  -shared-with-me-limit int
+        Maximum number of "Shared with me" files listed (default: 100)
+  -page-size int
+        Files requested per Drive API list call, up to 1000 (default: 100)
+  -include-trashed
+        Also discover files in the trash
+  -follow-shortcuts
+        Discover the files that Drive shortcuts point to instead of the shortcuts (default: true)
+  -owner-email value
+        Only discover files owned by this email; repeatable or comma-separated
+  -not-owner-email value
+        Skip files owned by this email; repeatable or comma-separated
+  -csv-delimiter string
+        Input CSV field delimiter, a single character or \t (default: ,)
+  -output-csv-delimiter string
+        Output CSV field delimiter; \t writes a .tsv when -output has no extension (default: ,)
+  -output-format string
+        Output file format: csv or json (default: csv)
+  -max-retries int
+        Retries for rate-limited or failing API calls (default: 5)
+  -base-delay duration
+        Delay before the first retry, doubled for each retry (default: 1s)
+  -max-delay duration
+        Upper bound on a single retry delay (default: 1m0s)
+  -jitter float
+        Randomize retry delays by up to this fraction, 0-1 (default: 0.25)
+  -retry-budget int
+        Retries allowed across all API calls of the run (default: 100; 0 = unlimited)
 
 Convert Flags:
   -input string
         Input CSV file with link, title, tags, frag1-5 columns (required)
+  -csv-delimiter string
+        Input CSV field delimiter, a single character or \t (default: ,)
+  -max-fragment-depth int
+        Number of fragment columns read, frag1 to fragN, up to 10 (default: 5)
   -output string
         Output directory path (default: ./output)
   -credentials string
         Google API credentials JSON file (required)
+  -auth-flow string
+        How to get an OAuth2 token when none is saved: browser or device (default: browser)
+  -token-path string
+        Saved OAuth2 token file (default: ~/.credentials/gdrive-crawler-token.json)
+  -max-requests-per-second float
+        Cap on Drive API requests per second (default: 0, unlimited)
+  -quota-report string
+        JSON file that counts Drive API calls per day across runs; the summary is logged at the end
+  -quota-limit int
+        Daily Drive API call limit: warn at 80%, stop sending requests at 100% (default: 1000000000)
   -workers int
         Number of concurrent workers (default: 5)
   -verbose
         Enable verbose logging
   -dry-run
         Preview actions without writing files
+  -tag-separator string
+        Force the tag separator (default: semicolon if present, otherwise comma)
+  -preserve-tag-case
+        Keep the case of CSV tags instead of lowercasing them
+  -routing-rules string
+        YAML file of tag to output directory routing rules
+  -routing-strategy string
+        Routing strategy: first-match or all-matching (default: first-match)
+  -tag-hierarchy string
+        YAML file of tag parents used to expand frontmatter tags
+  -auto-tag-from-frags
+        Add a tag for each non-empty fragment (lowercased, spaces as hyphens)
+  -auto-tag-prefix string
+        Prefix for tags generated from fragments (e.g. "category:")
+  -temp-folder-id string
+        Drive folder ID for temporary PDF conversion copies (default: Drive root)
+  -temp-file-prefix string
+        Name prefix for temporary PDF conversion copies (default: gdrive_tmp_)
+  -use-acl-for-published
+        Set published: true only for files shared with anyone as reader
+  -frontmatter-format string
+        Frontmatter syntax: yaml, toml or json (default: yaml)
+  -frontmatter-extra key=value
+        Extra frontmatter field added to every document; may be repeated
+  -frontmatter-defaults string
+        JSON file of extra frontmatter fields; -frontmatter-extra overrides them
+  -no-frontmatter
+        Write only the markdown body without frontmatter
+  -description-source string
+        Source of the description field: first-paragraph or title (default: first-paragraph)
+  -title-prefix string
+        Text prepended to the frontmatter title
+  -title-suffix string
+        Text appended to the frontmatter title
+  -prefix-in-filename
+        Also include the title prefix and suffix in output filenames
+  -append-source-link
+        Put a link to the Google Drive document first in the content
+  -source-link-template string
+        Go template for the source link, with {{.Link}} and {{.Title}}
+  -strip-comments
+        Remove HTML comments from exported content (code blocks are kept)
+  -embed-images
+        Download images referenced by URL to an assets directory and link them relatively
+  -max-errors int
+        Abort after this many failed documents (default: 0 = unlimited)
+  -failed-output string
+        Write failed and unprocessed records to this CSV for retrying
+  -slides-preview
+        Embed a PNG preview of the first slide in Google Slides stubs
+  -sheets-to-table
+        Write Google Sheets as markdown tables read with the Sheets API instead of stubs
+  -extract-pdf-images
+        Save an image of each page of PDFs converted locally with MuPDF and reference it before the page's text
+  -pdf-workers int
+        Parallel workers for converting the pages of one PDF (default: 0 = min(4, pages/10))
+  -io-workers int
+        Workers writing output files, separate from -workers (default: 0 = number of CPUs)
+  -manifest string
+        File listing each written file as it is written (action, path, link, title, timestamp)
+  -manifest-format string
+        Manifest syntax: ndjson or json-array (default: ndjson)
+  -checkpoint string
+        File of converted record links; records listed in it are skipped, to resume an interrupted run
+  -reset-checkpoint
+        Delete the -checkpoint file before starting, to convert every record again
+  -progress
+        Write a JSON line to stderr after each record completes (file, status, elapsed_ms, total, done)
+  -progress-file string
+        Write the progress lines to this file instead of stderr (implies -progress)
+  -export-backend string
+        How Google Docs are converted: google or pandoc (default: google)
+  -pandoc-path string
+        Pandoc binary used by the pandoc backend (default: pandoc from PATH)
+  -export-format string
+        Format Google Docs are exported in: markdown or html (default: markdown)
+  -html-to-markdown
+        Convert documents exported with -export-format html to markdown instead of writing .html files
+  -validate-links
+        After converting, check that every relative link resolves to an output file; exit 1 if any do not
+  -broken-links-report string
+        Write broken links found by -validate-links to this file instead of stdout
+  -hash-algorithm string
+        Hash for the hash-content field: sha256, sha512, sha1 or md5 (default: sha256)
+  -split-by-frag1
+        Write each frag1 to its own directory; records without frag1 go to _uncategorized
+  -split-by-frag2
+        Also split by frag2 within each frag1 directory (implies -split-by-frag1)
+  -output-format string
+        Output format of written documents (default: markdown)
+  -pipeline-debug
+        Log the content length after each post-processing stage
+  -detect-language
+        Write the detected body language to a language frontmatter field
+  -language-confidence float
+        Confidence below which the language is und (default: 0.8)
+  -split-by-language
+        Write documents under a directory named after their language (requires -detect-language)
+  -generate-sitemap
+        Write sitemap.xml for the published documents in the output directory
+  -base-url string
+        Base URL of the wiki used for sitemap and feed links (required with -generate-sitemap or -generate-feed)
+  -sitemap-max-urls int
+        Split the sitemap with a sitemap_index.xml above this many URLs (default: 50000)
+  -generate-feed
+        Write a feed of the most recently modified documents to the output directory
+  -feed-format string
+        Feed format: atom (atom.xml) or rss2 (rss.xml) (default: atom)
+  -feed-max-items int
+        Number of documents in the feed (default: 20)
+  -feed-title string
+        Feed title (default: Documentation updates)
+  -feed-author string
+        Atom feed author (default: the feed title)
+  -max-retries int
+        Retries for rate-limited or failing API calls (default: 5)
+  -base-delay duration
+        Delay before the first retry, doubled for each retry (default: 1s)
+  -max-delay duration
+        Upper bound on a single retry delay (default: 1m0s)
+  -jitter float
+        Randomize retry delays by up to this fraction, 0-1 (default: 0.25)
+  -retry-budget int
+        Retries allowed across all API calls of the run (default: 100; 0 = unlimited)
 
 Sync Flags:
   -input string
         Input CSV file with link, title, tags, frag1-5 columns (required)
+  -csv-delimiter string
+        Input CSV field delimiter, a single character or \t (default: ,)
+  -max-fragment-depth int
+        Number of fragment columns read, frag1 to fragN, up to 10 (default: 5)
   -output string
         Output directory path containing existing markdown files (default: ./output)
+  -file string
+        Sync only this markdown file instead of every file in the output directory
   -credentials string
         Google API credentials JSON file (required)
+  -auth-flow string
+        How to get an OAuth2 token when none is saved: browser or device (default: browser)
+  -token-path string
+        Saved OAuth2 token file (default: ~/.credentials/gdrive-crawler-token.json)
+  -max-requests-per-second float
+        Cap on Drive API requests per second (default: 0, unlimited)
+  -quota-report string
+        JSON file that counts Drive API calls per day across runs; the summary is logged at the end
+  -quota-limit int
+        Daily Drive API call limit: warn at 80%, stop sending requests at 100% (default: 1000000000)
   -workers int
         Number of concurrent workers (default: 5)
   -verbose
         Enable verbose logging
   -dry-run
         Preview actions without writing files
+  -no-frontmatter
+        Files were converted with -no-frontmatter; compare Drive modifiedTime to file mtime
+  -title-prefix string
+        Title prefix used by convert
+  -title-suffix string
+        Title suffix used by convert
+  -prefix-in-filename
+        Convert included the title prefix and suffix in filenames
+  -append-source-link
+        Put a link to the Google Drive document first in the content
+  -source-link-template string
+        Go template for the source link, with {{.Link}} and {{.Title}}
+  -strip-comments
+        Remove HTML comments from exported content (code blocks are kept)
+  -embed-images
+        Convert ran with -embed-images; download images to the assets directory again
+  -description-source string
+        Source of the description of updated files: first-paragraph or title (default: first-paragraph)
+  -detect-language
+        Convert ran with -detect-language; update the language field of updated files
+  -language-confidence float
+        Confidence below which the language is und (default: 0.8)
+  -hash-algorithm string
+        Hash for the hash-content field: sha256, sha512, sha1 or md5 (default: sha256)
+  -split-by-frag1
+        Convert ran with -split-by-frag1
+  -split-by-frag2
+        Convert ran with -split-by-frag2
+  -incremental-sync
+        Merge the changes made in Drive into existing files, keeping local edits to other lines
+  -min-change-ratio float
+        With -incremental-sync, rewrite files when more than this share of lines changed (default: 0.5)
+  -detect-orphans
+        Report files whose gdrive-link is not in the input CSV as orphans instead of syncing them
+  -delete-orphans
+        Delete orphaned files (requires -detect-orphans; respects -dry-run)
+  -diff
+        Print a unified diff of the body of each updated file
+  -diff-output string
+        Write the -diff output to this file instead of stdout
+  -delete-local
+        Delete local files whose Google Drive file was deleted (respects -dry-run)
+  -deletion-report string
+        JSON report file listing the files whose Google Drive file was deleted
+  -report string
+        JSON report file with the result of each synced file
+  -generate-sitemap
+        Write sitemap.xml for the published documents in the output directory
+  -base-url string
+        Base URL of the wiki used for sitemap and feed links (required with -generate-sitemap or -generate-feed)
+  -sitemap-max-urls int
+        Split the sitemap with a sitemap_index.xml above this many URLs (default: 50000)
+  -generate-feed
+        Write a feed of the most recently modified documents to the output directory
+  -feed-format string
+        Feed format: atom (atom.xml) or rss2 (rss.xml) (default: atom)
+  -feed-max-items int
+        Number of documents in the feed (default: 20)
+  -feed-title string
+        Feed title (default: Documentation updates)
+  -feed-author string
+        Atom feed author (default: the feed title)
+
+Validate-Links Flags:
+  -output string
+        Output directory path containing converted markdown files (default: ./output)
+  -report string
+        Write broken links and suggestions to a JSON report file
+  -auto-fix
+        Rewrite broken links to the closest match when within edit distance 3
+  -verbose
+        Enable verbose logging
+
+Validate Flags:
+  -output string
+        Output directory path containing converted markdown files (default: ./output)
+  -required-fields string
+        Comma-separated frontmatter fields that must be present and non-empty (default: title,hash-gdrive,editor)
+  -hash-algorithm string
+        Hash the hash-content field was written with: sha256, sha512, sha1 or md5 (default: sha256)
+  -report string
+        Write the frontmatter problems to a JSON report file
+  -verbose
+        Enable verbose logging
+
+Check-Credentials Flags:
+  -credentials string
+        Google API credentials JSON file (default: credentials.json)
+  -auth-flow string
+        How to get an OAuth2 token when none is saved: browser or device (default: browser)
+  -token-path string
+        Saved OAuth2 token file (default: ~/.credentials/gdrive-crawler-token.json)
+  -max-requests-per-second float
+        Cap on Drive API requests per second (default: 0, unlimited)
+  -shared-drive-id string
+        Also verify access to this Shared Drive
+
+Revoke-Token Flags:
+  -token-path string
+        Saved OAuth2 token file (default: ~/.credentials/gdrive-crawler-token.json)
+  -yes
+        Revoke without asking for confirmation
+
+Suggest-Tags Flags:
+  -output string
+        Output directory path containing converted markdown files (default: ./output)
+  -csv string
+        CSV file for suggested tags (default: suggested-tags.csv)
+  -top-n int
+        Number of tags suggested per document (default: 5)
+  -stopwords-file string
+        File of words never suggested, one per line (default: built-in English list)
+  -verbose
+        Enable verbose logging
+
+Dedup-Check Flags:
+  -output string
+        Output directory path containing converted markdown files (default: ./output)
+  -csv string
+        CSV file for duplicate pairs (default: duplicates.csv)
+  -threshold int
+        Report documents whose fingerprints differ in fewer bits than this (default: 3)
+  -merge-action string
+        Action for duplicate groups: none or canonical (default: none)
+  -verbose
+        Enable verbose logging
+
+Inventory-Links Flags:
+  -output string
+        Output directory path containing converted markdown files (default: ./output)
+  -csv string
+        CSV file for external links (default: external-links.csv)
+  -check-external
+        Check each URL with an HTTP HEAD request
+  -workers int
+        Number of concurrent URL checks (default: 10)
+  -timeout duration
+        Timeout for each URL check (default: 10s)
+  -cache-ttl duration
+        Reuse cached check results younger than this (default: 24h0m0s)
+  -verbose
+        Enable verbose logging
+
+Wikijs Flags:
+  -output string
+        Output directory path containing converted markdown files (default: ./output)
+  -wikijs-url string
+        Wiki.js base URL, e.g. https://wiki.example.com (required)
+  -wikijs-token string
+        Wiki.js API key (required)
+  -locale string
+        Locale of the created and updated pages (default: en)
+  -dry-run
+        Log the pages that would be created or updated without changing the wiki
+  -verbose
+        Enable verbose logging
+
+Wikijs-tree Flags:
+  -input string
+        Input CSV file with link, title, tags, frag1-5 columns (required)
+  -csv-delimiter string
+        Input CSV field delimiter, a single character or \t (default: ,)
+  -max-fragment-depth int
+        Number of fragment columns read, frag1 to fragN, up to 10 (default: 5)
+  -wikijs-url string
+        Wiki.js base URL, e.g. https://wiki.example.com (required)
+  -wikijs-token string
+        Wiki.js API key (required)
+  -locale string
+        Locale of the created pages (default: en)
+  -dry-run
+        Log the pages that would be created without changing the wiki
+  -verbose
+        Enable verbose logging
+
+Log Flags (all commands):
+  -log-file string
+        Append log output to this file instead of stderr
+  -log-stderr
+        Also write log output to stderr (default: true without -log-file, false with it)
+  -log-max-size-mb int
+        Rotate the log file when it would grow past this size (default: 100; 0 = never)
+  -log-max-backups int
+        Number of rotated log files kept as <file>.1, <file>.2, ... (default: 3)
+
+Config Flags (discover and convert):
+  -config string
+        YAML file of flag values keyed by flag name, e.g. "workers: 10"; flags on the command line override it
 
 Examples:
   # Discover files
@@ -72,9 +505,32 @@ Examples:
   # Convert documents
   gdrive-crawler convert -input enhanced-links.csv -output ./docs -credentials creds.json -workers 10
 
+  # Convert with settings from a config file, overriding its worker count
+  gdrive-crawler convert -config convert.yaml -workers 4
+
   # Sync existing documents with Google Drive
   gdrive-crawler sync -input enhanced-links.csv -output ./docs -credentials creds.json -workers 10
+
+  # Verify credentials before a long run
+  gdrive-crawler check-credentials -credentials creds.json
+
+  # Check converted documents for broken links
+  gdrive-crawler validate-links -output ./docs -report broken-links.json
+
+  # Check the frontmatter of converted documents before uploading them
+  gdrive-crawler validate -output ./docs -report frontmatter.json
+
+  # Suggest tags for converted documents
+  gdrive-crawler suggest-tags -output ./docs -csv suggested-tags.csv -top-n 5
+
+  # Find duplicated documents and point copies at the original
+  gdrive-crawler dedup-check -output ./docs -csv duplicates.csv -merge-action canonical
+
+  # List external links and check which are dead
+  gdrive-crawler inventory-links -output ./docs -csv external-links.csv -check-external
 `
+
+	noFrontmatterWarning = "Warning: -no-frontmatter disables hash tracking; sync and incremental conversion will be less accurate"
 )
 
 func main() {
@@ -92,6 +548,24 @@ func main() {
 		runConvert()
 	case "sync":
 		runSync()
+	case "validate-links":
+		runValidateLinks()
+	case "validate":
+		runValidate()
+	case "check-credentials":
+		runCheckCredentials()
+	case "revoke-token":
+		runRevokeToken()
+	case "suggest-tags":
+		runSuggestTags()
+	case "dedup-check":
+		runDedupCheck()
+	case "inventory-links":
+		runInventoryLinks()
+	case "wikijs":
+		runWikiJS()
+	case "wikijs-tree":
+		runWikiJSTree()
 	case "help", "-h", "--help":
 		fmt.Print(usageMessage)
 	default:
@@ -104,12 +578,37 @@ func main() {
 func runDiscover() {
 	fs := flag.NewFlagSet("discover", flag.ExitOnError)
 	input := fs.String("input", "", "Input CSV file with Google Drive URLs (required)")
-	output := fs.String("output", "", "Output CSV file path (required)")
+	output := fs.String("output", "", "Output CSV or JSON file path (required)")
 	credentials := fs.String("credentials", "credentials.json", "Google API credentials JSON file (required)")
-	depth := fs.Int("depth", 5, "Maximum depth for recursive link discovery (default: 5)")
+	authOpts := addAuthFlags(fs)
+	quotaOpts := addQuotaFlags(fs)
+	depth := fs.Int("depth", 5, "Maximum depth for recursive link discovery, unless the input CSV has a depth for the URL (default: 5)")
+	discoverWorkers := fs.Int("discover-workers", discovery.DefaultWorkers, "Number of input URLs discovered in parallel")
+	deduplicate := fs.Bool("deduplicate", true, "Drop records whose link points to a file already listed, keeping the longer link")
 	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+	excludeFolders := fs.String("exclude-folders", "", "Comma-separated glob patterns of folder names to skip (case-insensitive)")
+	excludeFolderIDs := fs.String("exclude-folder-ids", "", "Comma-separated Drive folder IDs to skip")
+	mimeFilter := fs.String("mime-filter", "", "Comma-separated MIME type globs of the files discovered, e.g. application/vnd.google-apps.*")
+	namePattern := fs.String("name-pattern", "", "Glob that file names must match to be listed, e.g. \"RFC-*\"; other files are still searched for links")
+	sharedDriveID := fs.String("shared-drive-id", "", "Shared Drive ID to list folder contents from")
+	includeSharedWithMe := fs.Bool("include-shared-with-me", false, "Also discover files in \"Shared with me\", merged with the input folders")
+	sharedWithMeLimit := fs.Int("shared-with-me-limit", discovery.DefaultSharedWithMeLimit, "Maximum number of \"Shared with me\" files listed")
+	pageSize := fs.Int("page-size", discovery.DefaultPageSize, "Files requested per Drive API list call, up to 1000")
+	includeTrashed := fs.Bool("include-trashed", false, "Also discover files in the trash")
+	followShortcuts := fs.Bool("follow-shortcuts", true, "Discover the files that Drive shortcuts point to instead of the shortcuts")
+	var ownerEmails, notOwnerEmails listFlag
+	fs.Var(&ownerEmails, "owner-email", "Only discover files owned by this email (repeatable or comma-separated)")
+	fs.Var(&notOwnerEmails, "not-owner-email", "Skip files owned by this email (repeatable or comma-separated)")
+	csvDelimiter := fs.String("csv-delimiter", ",", "Input CSV field delimiter (single character or \\t)")
+	outputCSVDelimiter := fs.String("output-csv-delimiter", ",", "Output CSV field delimiter (single character or \\t)")
+	outputFormat := fs.String("output-format", "csv", "Output file format: csv or json")
+	retry := addRetryFlags(fs)
 
+	applyConfig := addConfigFlag(fs)
+	logOpts := addLogFlags(fs)
 	fs.Parse(os.Args[2:])
+	applyConfig()
+	logOpts.setup()
 
 	// Validate required flags
 	if *input == "" || *output == "" || *credentials == "" {
@@ -118,6 +617,59 @@ func runDiscover() {
 		os.Exit(1)
 	}
 
+	excludePatterns := splitList(*excludeFolders)
+	for _, pattern := range excludePatterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			log.Fatalf("Invalid -exclude-folders pattern %q: %v", pattern, err)
+		}
+	}
+
+	mimePatterns := splitList(*mimeFilter)
+	for _, pattern := range mimePatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			log.Fatalf("Invalid -mime-filter pattern %q: %v", pattern, err)
+		}
+	}
+
+	if _, err := filepath.Match(*namePattern, ""); err != nil {
+		log.Fatalf("Invalid -name-pattern %q: %v", *namePattern, err)
+	}
+
+	if err := retry.Validate(); err != nil {
+		log.Fatalf("Invalid retry flags: %v", err)
+	}
+
+	if *discoverWorkers < 1 {
+		log.Fatalf("Invalid -discover-workers: must be positive, got %d", *discoverWorkers)
+	}
+
+	if *sharedWithMeLimit < 1 {
+		log.Fatalf("Invalid -shared-with-me-limit: must be positive, got %d", *sharedWithMeLimit)
+	}
+
+	if *pageSize < 1 || *pageSize > discovery.MaxPageSize {
+		log.Fatalf("Invalid -page-size: must be between 1 and %d, got %d", discovery.MaxPageSize, *pageSize)
+	}
+
+	inputDelimiter, err := csvpkg.ParseDelimiter(*csvDelimiter)
+	if err != nil {
+		log.Fatalf("Invalid -csv-delimiter: %v", err)
+	}
+	outputDelimiter, err := csvpkg.ParseDelimiter(*outputCSVDelimiter)
+	if err != nil {
+		log.Fatalf("Invalid -output-csv-delimiter: %v", err)
+	}
+
+	if *outputFormat != "csv" && *outputFormat != "json" {
+		log.Fatalf("Invalid -output-format: unknown format %q (want %q or %q)", *outputFormat, "csv", "json")
+	}
+
+	// Tab-delimited output defaults to a .tsv file
+	outputPath := *output
+	if *outputFormat == "csv" && outputDelimiter == '\t' && filepath.Ext(outputPath) == "" {
+		outputPath += ".tsv"
+	}
+
 	// Create context
 	ctx := context.Background()
 
@@ -125,7 +677,10 @@ func runDiscover() {
 	if *verbose {
 		log.Println("Authenticating with Google Drive API...")
 	}
-	driveService, err := auth.NewDriveService(ctx, *credentials)
+	quota := quotaOpts.tracker()
+	serviceOpts := authOpts()
+	serviceOpts.Quota = quota
+	driveService, err := auth.NewDriveServiceWithOptions(ctx, *credentials, serviceOpts)
 	if err != nil {
 		log.Fatalf("Failed to authenticate: %v", err)
 	}
@@ -134,53 +689,138 @@ func runDiscover() {
 	if *verbose {
 		log.Printf("Reading input from %s...", *input)
 	}
-	inputRecords, err := csvpkg.ParseInputCSV(*input)
+	inputRecords, err := csvpkg.ParseInputCSVWithDelimiter(*input, inputDelimiter)
 	if err != nil {
 		log.Fatalf("Failed to parse input CSV: %v", err)
 	}
 
-	// Extract URLs
-	var urls []string
-	for _, record := range inputRecords {
-		urls = append(urls, record.URL)
-	}
-
 	if *verbose {
-		log.Printf("Found %d URLs to process", len(urls))
+		log.Printf("Found %d URLs to process", len(inputRecords))
 	}
 
 	// Discover files
-	discoverer := discovery.NewDiscoverer(driveService.Service, *verbose, *depth)
-	records, err := discoverer.DiscoverFromURLs(urls)
-	if err != nil {
+	opts := discovery.Options{
+		ExcludeFolderPatterns: excludePatterns,
+		ExcludeFolderIDs:      splitList(*excludeFolderIDs),
+		MimeFilter:            mimePatterns,
+		NamePattern:           *namePattern,
+		SharedDriveID:         *sharedDriveID,
+		OwnerEmails:           ownerEmails,
+		NotOwnerEmails:        notOwnerEmails,
+		IncludeSharedWithMe:   *includeSharedWithMe,
+		SharedWithMeLimit:     *sharedWithMeLimit,
+		Workers:               *discoverWorkers,
+		PageSize:              *pageSize,
+		IncludeTrashed:        *includeTrashed,
+		FollowShortcuts:       *followShortcuts,
+		Retry:                 *retry,
+	}
+	discoverer := discovery.NewDiscoverer(driveService.Service, *verbose, *depth, opts)
+	records, err := discoverer.DiscoverFromRecords(interruptContext(), inputRecords)
+	quotaOpts.finish(quota, retry.Budget)
+	if errors.Is(err, context.Canceled) {
+		log.Printf("Warning: %v; writing the %d files discovered so far", err, len(records))
+	} else if err != nil {
 		log.Fatalf("Discovery failed: %v", err)
 	}
 
+	if *deduplicate {
+		records = discovery.DeduplicateRecords(records)
+	}
+
 	if *verbose {
 		log.Printf("Discovered %d files", len(records))
 	}
 
-	// Write output CSV
+	// Write output file
 	if *verbose {
-		log.Printf("Writing output to %s...", *output)
+		log.Printf("Writing output to %s...", outputPath)
 	}
-	if err := csvpkg.WriteDiscoveryCSV(*output, records); err != nil {
+	if *outputFormat == "json" {
+		if err := csvpkg.WriteDiscoveryJSON(outputPath, records); err != nil {
+			log.Fatalf("Failed to write output JSON: %v", err)
+		}
+	} else if err := csvpkg.WriteDiscoveryCSVWithDelimiter(outputPath, records, outputDelimiter); err != nil {
 		log.Fatalf("Failed to write output CSV: %v", err)
 	}
 
-	log.Printf("Successfully discovered %d files. Output written to %s", len(records), *output)
+	log.Printf("Successfully discovered %d files. Output written to %s", len(records), outputPath)
 }
 
 func runConvert() {
 	fs := flag.NewFlagSet("convert", flag.ExitOnError)
 	input := fs.String("input", "", "Input CSV file (required)")
 	output := fs.String("output", "./output", "Output directory path")
+	csvDelimiter := fs.String("csv-delimiter", ",", "Input CSV field delimiter (single character or \\t)")
+	maxFragmentDepth := fs.Int("max-fragment-depth", csvpkg.DefaultFragmentDepth, "Number of fragment columns read, frag1 to fragN")
 	credentials := fs.String("credentials", "credentials.json", "Google API credentials JSON file (required)")
+	authOpts := addAuthFlags(fs)
+	quotaOpts := addQuotaFlags(fs)
 	workers := fs.Int("workers", 1, "Number of concurrent workers")
 	verbose := fs.Bool("verbose", false, "Enable verbose logging")
 	dryRun := fs.Bool("dry-run", false, "Preview actions without writing files")
+	tagSeparator := fs.String("tag-separator", "", "Force the tag separator (default: semicolon if present, otherwise comma)")
+	preserveTagCase := fs.Bool("preserve-tag-case", false, "Keep the case of CSV tags instead of lowercasing them")
+	routingRules := fs.String("routing-rules", "", "YAML file of tag to output directory routing rules")
+	routingStrategy := fs.String("routing-strategy", conversion.RoutingFirstMatch, "Routing strategy: first-match or all-matching")
+	tagHierarchy := fs.String("tag-hierarchy", "", "YAML file of tag parents used to expand frontmatter tags")
+	autoTagFromFrags := fs.Bool("auto-tag-from-frags", false, "Add a tag for each non-empty fragment (lowercased, spaces as hyphens)")
+	autoTagPrefix := fs.String("auto-tag-prefix", "", "Prefix for tags generated from fragments (e.g. \"category:\")")
+	tempFolderID := fs.String("temp-folder-id", "", "Drive folder ID for temporary PDF conversion copies (default: Drive root)")
+	tempFilePrefix := fs.String("temp-file-prefix", conversion.DefaultTempFilePrefix, "Name prefix for temporary PDF conversion copies")
+	useACLForPublished := fs.Bool("use-acl-for-published", false, "Set published: true only for files shared with anyone as reader")
+	frontmatterFormat := fs.String("frontmatter-format", string(conversion.FrontmatterYAML), "Frontmatter syntax: yaml, toml or json")
+	var frontmatterExtraPairs []string
+	fs.Func("frontmatter-extra", "Extra frontmatter `key=value` field added to every document; may be repeated", func(value string) error {
+		frontmatterExtraPairs = append(frontmatterExtraPairs, value)
+		return nil
+	})
+	frontmatterDefaults := fs.String("frontmatter-defaults", "", "JSON file of extra frontmatter fields; -frontmatter-extra overrides them")
+	noFrontmatter := fs.Bool("no-frontmatter", false, "Write only the markdown body without frontmatter")
+	descriptionSource := fs.String("description-source", string(conversion.DescriptionFromFirstParagraph), "Source of the description field: first-paragraph or title")
+	titlePrefix := fs.String("title-prefix", "", "Text prepended to the frontmatter title")
+	titleSuffix := fs.String("title-suffix", "", "Text appended to the frontmatter title")
+	prefixInFilename := fs.Bool("prefix-in-filename", false, "Also include the title prefix and suffix in output filenames")
+	appendSourceLink := fs.Bool("append-source-link", false, "Put a link to the Google Drive document first in the content")
+	sourceLinkTemplate := fs.String("source-link-template", conversion.DefaultSourceLinkTemplate, "Go template for the source link, with {{.Link}} and {{.Title}}")
+	stripComments := fs.Bool("strip-comments", false, "Remove HTML comments from exported content")
+	embedImages := fs.Bool("embed-images", false, "Download images referenced by URL to an assets directory and link them relatively")
+	maxErrors := fs.Int("max-errors", 0, "Abort after this many failed documents (0 = unlimited)")
+	failedOutput := fs.String("failed-output", "", "CSV file for failed and unprocessed records")
+	slidesPreview := fs.Bool("slides-preview", false, "Embed a PNG preview of the first slide in Google Slides stubs")
+	sheetsToTable := fs.Bool("sheets-to-table", false, "Write Google Sheets as markdown tables read with the Sheets API instead of stubs")
+	extractPDFImages := fs.Bool("extract-pdf-images", false, "Save an image of each page of PDFs converted locally with MuPDF and reference it before the page's text")
+	pdfWorkers := fs.Int("pdf-workers", 0, "Parallel workers for converting the pages of one PDF (0 = min(4, pages/10))")
+	ioWorkers := fs.Int("io-workers", 0, "Workers writing output files, separate from -workers (0 = number of CPUs)")
+	manifestPath := fs.String("manifest", "", "File listing each written file as it is written (action, path, link, title, timestamp)")
+	manifestFormat := fs.String("manifest-format", string(conversion.ManifestNDJSON), "Manifest syntax: ndjson or json-array")
+	checkpoint := fs.String("checkpoint", "", "File of converted record links; records listed in it are skipped, to resume an interrupted run")
+	resetCheckpoint := fs.Bool("reset-checkpoint", false, "Delete the -checkpoint file before starting, to convert every record again")
+	progressFlag := fs.Bool("progress", false, "Write a JSON line to stderr after each record completes (file, status, elapsed_ms, total, done)")
+	progressFile := fs.String("progress-file", "", "Write the progress lines to this file instead of stderr (implies -progress)")
+	exportBackend := fs.String("export-backend", string(conversion.ExportBackendGoogle), "How Google Docs are converted: google or pandoc")
+	pandocPath := fs.String("pandoc-path", conversion.DefaultPandocPath, "Pandoc binary used by the pandoc backend")
+	exportFormat := fs.String("export-format", string(conversion.ExportFormatMarkdown), "Format Google Docs are exported in: markdown or html")
+	htmlToMarkdown := fs.Bool("html-to-markdown", false, "Convert documents exported with -export-format html to markdown instead of writing .html files")
+	validateLinks := fs.Bool("validate-links", false, "After converting, check that every relative link resolves to an output file; exit 1 if any do not")
+	brokenLinksReport := fs.String("broken-links-report", "", "Write broken links found by -validate-links to this file instead of stdout")
+	hashAlgorithm := fs.String("hash-algorithm", utils.HashSHA256, "Hash for the hash-content field: sha256, sha512, sha1 or md5")
+	splitByFrag1 := fs.Bool("split-by-frag1", false, "Write each frag1 to its own directory; records without frag1 go to _uncategorized")
+	splitByFrag2 := fs.Bool("split-by-frag2", false, "Also split by frag2 within each frag1 directory (implies -split-by-frag1)")
+	pipelineDebug := fs.Bool("pipeline-debug", false, "Log the content length after each post-processing stage")
+	detectLanguage := fs.Bool("detect-language", false, "Write the detected body language to a language frontmatter field")
+	languageConfidence := fs.Float64("language-confidence", conversion.DefaultLanguageConfidence, "Confidence below which the language is und")
+	splitByLanguage := fs.Bool("split-by-language", false, "Write documents under a directory named after their language (requires -detect-language)")
+	outputFormat := fs.String("output-format", conversion.DefaultOutputFormat, "Output format of written documents: "+strings.Join(conversion.Transformers.Names(), ", "))
+	retry := addRetryFlags(fs)
+	sitemapOpts := addSitemapFlags(fs)
+	feedOpts := addFeedFlags(fs, sitemapOpts.baseURL)
 
+	applyConfig := addConfigFlag(fs)
+	logOpts := addLogFlags(fs)
 	fs.Parse(os.Args[2:])
+	applyConfig()
+	logOpts.setup()
 
 	// Validate required flags
 	if *input == "" || *credentials == "" {
@@ -189,6 +829,127 @@ func runConvert() {
 		os.Exit(1)
 	}
 
+	delimiter, err := csvpkg.ParseDelimiter(*csvDelimiter)
+	if err != nil {
+		log.Fatalf("Invalid -csv-delimiter: %v", err)
+	}
+
+	if *maxFragmentDepth < 1 || *maxFragmentDepth > csvpkg.MaxFragmentDepth {
+		log.Fatalf("Invalid -max-fragment-depth: must be between 1 and %d, got %d", csvpkg.MaxFragmentDepth, *maxFragmentDepth)
+	}
+
+	if err := conversion.ValidateRoutingStrategy(*routingStrategy); err != nil {
+		log.Fatalf("Invalid -routing-strategy: %v", err)
+	}
+
+	if err := conversion.ValidateFrontmatterFormat(*frontmatterFormat); err != nil {
+		log.Fatalf("Invalid -frontmatter-format: %v", err)
+	}
+
+	if err := conversion.ValidateDescriptionSource(*descriptionSource); err != nil {
+		log.Fatalf("Invalid -description-source: %v", err)
+	}
+
+	var frontmatterDefaultFields map[string]string
+	if *frontmatterDefaults != "" {
+		if frontmatterDefaultFields, err = conversion.LoadFrontmatterDefaults(*frontmatterDefaults); err != nil {
+			log.Fatalf("Invalid -frontmatter-defaults: %v", err)
+		}
+	}
+	frontmatterExtra, err := conversion.ParseFrontmatterExtra(frontmatterExtraPairs, frontmatterDefaultFields)
+	if err != nil {
+		log.Fatalf("Invalid -frontmatter-extra: %v", err)
+	}
+
+	if err := conversion.ValidateExportBackend(*exportBackend); err != nil {
+		log.Fatalf("Invalid -export-backend: %v", err)
+	}
+
+	if err := conversion.ValidateExportFormat(*exportFormat); err != nil {
+		log.Fatalf("Invalid -export-format: %v", err)
+	}
+	if *exportFormat == string(conversion.ExportFormatHTML) {
+		if *exportBackend == string(conversion.ExportBackendPandoc) {
+			log.Fatalf("Invalid -export-format: html cannot be combined with -export-backend pandoc")
+		}
+		if !*htmlToMarkdown && *outputFormat != conversion.DefaultOutputFormat {
+			log.Fatalf("Invalid -export-format: html writes .html files and requires -output-format %s, unless -html-to-markdown is set", conversion.DefaultOutputFormat)
+		}
+	} else if *htmlToMarkdown {
+		log.Fatalf("Invalid -html-to-markdown: requires -export-format html")
+	}
+
+	if err := conversion.ValidateManifestFormat(*manifestFormat); err != nil {
+		log.Fatalf("Invalid -manifest-format: %v", err)
+	}
+
+	if *resetCheckpoint && *checkpoint == "" {
+		log.Fatalf("Invalid -reset-checkpoint: requires -checkpoint")
+	}
+
+	hashFunc, err := utils.ParseHashAlgorithm(*hashAlgorithm)
+	if err != nil {
+		log.Fatalf("Invalid -hash-algorithm: %v", err)
+	}
+
+	transformer, err := conversion.NewTransformer(*outputFormat)
+	if err != nil {
+		log.Fatalf("Invalid -output-format: %v", err)
+	}
+
+	if *languageConfidence <= 0 || *languageConfidence > 1 {
+		log.Fatalf("Invalid -language-confidence: must be in (0, 1], got %v", *languageConfidence)
+	}
+	if *splitByLanguage && !*detectLanguage {
+		log.Fatalf("Invalid -split-by-language: requires -detect-language")
+	}
+
+	sitemapOpts.validate()
+	feedOpts.validate()
+
+	if err := retry.Validate(); err != nil {
+		log.Fatalf("Invalid retry flags: %v", err)
+	}
+
+	if *maxErrors < 0 {
+		log.Fatalf("Invalid -max-errors: must not be negative, got %d", *maxErrors)
+	}
+
+	if *pdfWorkers < 0 {
+		log.Fatalf("Invalid -pdf-workers: must not be negative, got %d", *pdfWorkers)
+	}
+
+	if *ioWorkers < 0 {
+		log.Fatalf("Invalid -io-workers: must not be negative, got %d", *ioWorkers)
+	}
+
+	if *noFrontmatter {
+		log.Println(noFrontmatterWarning)
+	}
+
+	var rules []conversion.RoutingRule
+	if *routingRules != "" {
+		var err error
+		rules, err = conversion.LoadRoutingRules(*routingRules)
+		if err != nil {
+			log.Fatalf("Failed to load routing rules: %v", err)
+		}
+	}
+
+	var hierarchy map[string][]string
+	if *tagHierarchy != "" {
+		var err error
+		hierarchy, err = tags.LoadHierarchy(*tagHierarchy)
+		if err != nil {
+			log.Fatalf("Failed to load tag hierarchy: %v", err)
+		}
+	}
+
+	sourceLink, err := loadSourceLinkTemplate(*appendSourceLink, *sourceLinkTemplate)
+	if err != nil {
+		log.Fatalf("Invalid -source-link-template: %v", err)
+	}
+
 	// Create context
 	ctx := context.Background()
 
@@ -196,7 +957,10 @@ func runConvert() {
 	if *verbose {
 		log.Println("Authenticating with Google Drive API...")
 	}
-	driveService, err := auth.NewDriveService(ctx, *credentials)
+	quota := quotaOpts.tracker()
+	serviceOpts := authOpts()
+	serviceOpts.Quota = quota
+	driveService, err := auth.NewDriveServiceWithOptions(ctx, *credentials, serviceOpts)
 	if err != nil {
 		log.Fatalf("Failed to authenticate: %v", err)
 	}
@@ -205,7 +969,7 @@ func runConvert() {
 	if *verbose {
 		log.Printf("Reading input from %s...", *input)
 	}
-	records, err := csvpkg.ParseConversionCSV(*input)
+	records, err := csvpkg.ParseConversionCSVWithDepth(*input, delimiter, *maxFragmentDepth)
 	if err != nil {
 		log.Fatalf("Failed to parse input CSV: %v", err)
 	}
@@ -214,13 +978,91 @@ func runConvert() {
 		log.Printf("Found %d records to convert", len(records))
 	}
 
+	var progressReporter *progress.ProgressReporter
+	if *progressFile != "" {
+		file, err := os.Create(*progressFile)
+		if err != nil {
+			log.Fatalf("Failed to create progress file: %v", err)
+		}
+		defer file.Close()
+		progressReporter = progress.NewProgressReporter(file)
+	} else if *progressFlag {
+		progressReporter = progress.NewProgressReporter(os.Stderr)
+	}
+
+	opts := conversion.Options{
+		TagSeparator:       *tagSeparator,
+		PreserveTagCase:    *preserveTagCase,
+		RoutingRules:       rules,
+		RoutingStrategy:    *routingStrategy,
+		TagHierarchy:       hierarchy,
+		AutoTagFromFrags:   *autoTagFromFrags,
+		AutoTagPrefix:      *autoTagPrefix,
+		TempFolderID:       *tempFolderID,
+		TempFilePrefix:     *tempFilePrefix,
+		UseACLForPublished: *useACLForPublished,
+		FrontmatterFormat:  conversion.FrontmatterFormat(*frontmatterFormat),
+		DescriptionSource:  conversion.DescriptionSource(*descriptionSource),
+		FrontmatterExtra:   frontmatterExtra,
+		NoFrontmatter:      *noFrontmatter,
+		TitlePrefix:        *titlePrefix,
+		TitleSuffix:        *titleSuffix,
+		PrefixInFilename:   *prefixInFilename,
+		SourceLinkTemplate: sourceLink,
+		StripComments:      *stripComments,
+		EmbedImages:        *embedImages,
+		Retry:              *retry,
+		MaxErrors:          *maxErrors,
+		FailedOutput:       *failedOutput,
+		SlidesPreview:      *slidesPreview,
+		SheetsToTable:      *sheetsToTable,
+		Sheets:             driveService.Sheets,
+		ExtractPDFImages:   *extractPDFImages,
+		PDFWorkers:         *pdfWorkers,
+		IOWorkers:          *ioWorkers,
+		ManifestPath:       *manifestPath,
+		ManifestFormat:     conversion.ManifestFormat(*manifestFormat),
+		CheckpointPath:     *checkpoint,
+		Progress:           progressReporter,
+		ExportBackend:      conversion.ExportBackend(*exportBackend),
+		PandocPath:         *pandocPath,
+		ExportFormat:       conversion.ExportFormat(*exportFormat),
+		HTMLToMarkdown:     *htmlToMarkdown,
+		HashFunc:           hashFunc,
+		SplitByFrag1:       *splitByFrag1,
+		SplitByFrag2:       *splitByFrag2,
+		Transformer:        transformer,
+		PipelineDebug:      *pipelineDebug,
+		DetectLanguage:     *detectLanguage,
+		LanguageConfidence: *languageConfidence,
+		SplitByLanguage:    *splitByLanguage,
+	}
+
+	if *resetCheckpoint && !*dryRun {
+		if err := os.Remove(*checkpoint); err != nil && !os.IsNotExist(err) {
+			log.Fatalf("Failed to reset checkpoint: %v", err)
+		}
+	}
+
 	// Convert documents
-	converter := conversion.NewConverter(driveService.Service, *output, *verbose, *dryRun)
-	if err := converter.Convert(records, *workers); err != nil {
+	converter := conversion.NewConverter(driveService.Service, *output, *verbose, *dryRun, opts)
+	err = converter.Convert(interruptContext(), records, *workers)
+	quotaOpts.finish(quota, retry.Budget)
+	if *verbose {
+		log.Printf("Post-processing profile:\n%s", conversion.FormatProfile(converter.PipelineProfile()))
+	}
+	if err != nil {
 		log.Printf("Conversion completed with errors: %v", err)
 		os.Exit(1)
 	}
 
+	sitemapOpts.generate(*output, *dryRun)
+	feedOpts.generate(*output, *dryRun)
+
+	if *validateLinks && !*dryRun {
+		validateConvertedLinks(*output, *brokenLinksReport)
+	}
+
 	if *dryRun {
 		log.Println("Dry run completed successfully")
 	} else {
@@ -228,16 +1070,84 @@ func runConvert() {
 	}
 }
 
+// validateConvertedLinks checks the relative links in the converted documents under output and
+// reports broken ones to reportPath, or stdout when it is empty. Exits with status 1 if any
+// link is broken.
+func validateConvertedLinks(output, reportPath string) {
+	broken, err := validation.ValidateLinks(output)
+	if err != nil {
+		log.Fatalf("Link validation failed: %v", err)
+	}
+
+	if err := writeBrokenLinksReport(reportPath, broken); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if len(broken) > 0 {
+		log.Printf("Link validation found %d broken links", len(broken))
+		os.Exit(1)
+	}
+}
+
+// writeBrokenLinksReport writes the broken links report to path, or to stdout when path is empty
+func writeBrokenLinksReport(path string, broken []validation.BrokenLink) error {
+	if path == "" {
+		return validation.WriteBrokenLinksReport(os.Stdout, broken)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create broken links report: %w", err)
+	}
+	if err := validation.WriteBrokenLinksReport(file, broken); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 func runSync() {
 	fs := flag.NewFlagSet("sync", flag.ExitOnError)
 	input := fs.String("input", "", "Input CSV file (required)")
 	output := fs.String("output", "./output", "Output directory path")
+	file := fs.String("file", "", "Sync only this markdown file instead of every file in the output directory")
+	csvDelimiter := fs.String("csv-delimiter", ",", "Input CSV field delimiter (single character or \\t)")
+	maxFragmentDepth := fs.Int("max-fragment-depth", csvpkg.DefaultFragmentDepth, "Number of fragment columns read, frag1 to fragN")
 	credentials := fs.String("credentials", "credentials.json", "Google API credentials JSON file (required)")
+	authOpts := addAuthFlags(fs)
+	quotaOpts := addQuotaFlags(fs)
 	workers := fs.Int("workers", 1, "Number of concurrent workers")
 	verbose := fs.Bool("verbose", false, "Enable verbose logging")
 	dryRun := fs.Bool("dry-run", false, "Preview actions without writing files")
+	noFrontmatter := fs.Bool("no-frontmatter", false, "Files have no frontmatter; compare Drive modifiedTime to file mtime")
+	titlePrefix := fs.String("title-prefix", "", "Title prefix used by convert")
+	titleSuffix := fs.String("title-suffix", "", "Title suffix used by convert")
+	prefixInFilename := fs.Bool("prefix-in-filename", false, "Convert included the title prefix and suffix in filenames")
+	appendSourceLink := fs.Bool("append-source-link", false, "Convert put a link to the Google Drive document first in the content")
+	sourceLinkTemplate := fs.String("source-link-template", conversion.DefaultSourceLinkTemplate, "Go template for the source link, with {{.Link}} and {{.Title}}")
+	stripComments := fs.Bool("strip-comments", false, "Remove HTML comments from exported content")
+	embedImages := fs.Bool("embed-images", false, "Convert ran with -embed-images; download images to the assets directory again")
+	descriptionSource := fs.String("description-source", string(conversion.DescriptionFromFirstParagraph), "Source of the description of updated files: first-paragraph or title")
+	detectLanguage := fs.Bool("detect-language", false, "Convert ran with -detect-language; update the language field of updated files")
+	languageConfidence := fs.Float64("language-confidence", conversion.DefaultLanguageConfidence, "Confidence below which the language is und")
+	hashAlgorithm := fs.String("hash-algorithm", utils.HashSHA256, "Hash for the hash-content field: sha256, sha512, sha1 or md5")
+	splitByFrag1 := fs.Bool("split-by-frag1", false, "Convert ran with -split-by-frag1")
+	splitByFrag2 := fs.Bool("split-by-frag2", false, "Convert ran with -split-by-frag2")
+	incrementalSync := fs.Bool("incremental-sync", false, "Merge the changes made in Drive into existing files, keeping local edits to other lines")
+	minChangeRatio := fs.Float64("min-change-ratio", sync.DefaultMinChangeRatio, "With -incremental-sync, rewrite files when more than this share of lines changed")
+	detectOrphans := fs.Bool("detect-orphans", false, "Report files whose gdrive-link is not in the input CSV as orphans instead of syncing them")
+	deleteOrphans := fs.Bool("delete-orphans", false, "Delete orphaned files (requires -detect-orphans; respects -dry-run)")
+	showDiff := fs.Bool("diff", false, "Print a unified diff of the body of each updated file")
+	diffOutput := fs.String("diff-output", "", "Write the -diff output to this file instead of stdout")
+	deleteLocal := fs.Bool("delete-local", false, "Delete local files whose Google Drive file was deleted (respects -dry-run)")
+	deletionReport := fs.String("deletion-report", "", "JSON report file listing the files whose Google Drive file was deleted")
+	report := fs.String("report", "", "JSON report file with the result of each synced file")
+	sitemapOpts := addSitemapFlags(fs)
+	feedOpts := addFeedFlags(fs, sitemapOpts.baseURL)
 
+	logOpts := addLogFlags(fs)
 	fs.Parse(os.Args[2:])
+	logOpts.setup()
 
 	// Validate required flags
 	if *input == "" || *credentials == "" {
@@ -246,6 +1156,48 @@ func runSync() {
 		os.Exit(1)
 	}
 
+	delimiter, err := csvpkg.ParseDelimiter(*csvDelimiter)
+	if err != nil {
+		log.Fatalf("Invalid -csv-delimiter: %v", err)
+	}
+
+	if *maxFragmentDepth < 1 || *maxFragmentDepth > csvpkg.MaxFragmentDepth {
+		log.Fatalf("Invalid -max-fragment-depth: must be between 1 and %d, got %d", csvpkg.MaxFragmentDepth, *maxFragmentDepth)
+	}
+
+	hashFunc, err := utils.ParseHashAlgorithm(*hashAlgorithm)
+	if err != nil {
+		log.Fatalf("Invalid -hash-algorithm: %v", err)
+	}
+
+	if *minChangeRatio <= 0 || *minChangeRatio > 1 {
+		log.Fatalf("Invalid -min-change-ratio: must be in (0, 1], got %v", *minChangeRatio)
+	}
+
+	if err := conversion.ValidateDescriptionSource(*descriptionSource); err != nil {
+		log.Fatalf("Invalid -description-source: %v", err)
+	}
+
+	if *languageConfidence <= 0 || *languageConfidence > 1 {
+		log.Fatalf("Invalid -language-confidence: must be in (0, 1], got %v", *languageConfidence)
+	}
+
+	if *deleteOrphans && !*detectOrphans {
+		log.Fatalf("Invalid -delete-orphans: requires -detect-orphans")
+	}
+
+	sitemapOpts.validate()
+	feedOpts.validate()
+
+	if *noFrontmatter {
+		log.Println(noFrontmatterWarning)
+	}
+
+	sourceLink, err := loadSourceLinkTemplate(*appendSourceLink, *sourceLinkTemplate)
+	if err != nil {
+		log.Fatalf("Invalid -source-link-template: %v", err)
+	}
+
 	// Create context
 	ctx := context.Background()
 
@@ -253,7 +1205,10 @@ func runSync() {
 	if *verbose {
 		log.Println("Authenticating with Google Drive API...")
 	}
-	driveService, err := auth.NewDriveService(ctx, *credentials)
+	quota := quotaOpts.tracker()
+	serviceOpts := authOpts()
+	serviceOpts.Quota = quota
+	driveService, err := auth.NewDriveServiceWithOptions(ctx, *credentials, serviceOpts)
 	if err != nil {
 		log.Fatalf("Failed to authenticate: %v", err)
 	}
@@ -262,7 +1217,7 @@ func runSync() {
 	if *verbose {
 		log.Printf("Reading input from %s...", *input)
 	}
-	records, err := csvpkg.ParseConversionCSV(*input)
+	records, err := csvpkg.ParseConversionCSVWithDepth(*input, delimiter, *maxFragmentDepth)
 	if err != nil {
 		log.Fatalf("Failed to parse input CSV: %v", err)
 	}
@@ -272,42 +1227,884 @@ func runSync() {
 	}
 
 	// Sync documents
-	syncer := sync.NewSyncer(driveService.Service, *output, *verbose, *dryRun)
-	results, err := syncer.Sync(records, *workers)
+	opts := sync.Options{
+		NoFrontmatter:      *noFrontmatter,
+		SourceLinkTemplate: sourceLink,
+		StripComments:      *stripComments,
+		EmbedImages:        *embedImages,
+		DescriptionSource:  conversion.DescriptionSource(*descriptionSource),
+		DetectLanguage:     *detectLanguage,
+		LanguageConfidence: *languageConfidence,
+		HashFunc:           hashFunc,
+		SplitByFrag1:       *splitByFrag1,
+		SplitByFrag2:       *splitByFrag2,
+		IncrementalSync:    *incrementalSync,
+		MinChangeRatio:     *minChangeRatio,
+		DetectOrphans:      *detectOrphans,
+		DeleteOrphans:      *deleteOrphans,
+		Diff:               *showDiff,
+		DeleteLocal:        *deleteLocal,
+	}
+	if *prefixInFilename {
+		opts.FilenamePrefix = *titlePrefix
+		opts.FilenameSuffix = *titleSuffix
+	}
+	syncer := sync.NewSyncerWithSharedService(driveService, *output, *verbose, *dryRun, opts)
+	var results []sync.SyncResult
+	if *file != "" {
+		var result sync.SyncResult
+		result, err = syncer.SyncSingle(interruptContext(), records, *file)
+		results = []sync.SyncResult{result}
+	} else {
+		results, err = syncer.Sync(interruptContext(), records, *workers)
+	}
+	quotaOpts.finish(quota, nil)
 	if err != nil {
 		log.Printf("Sync completed with errors: %v", err)
 		os.Exit(1)
 	}
 
 	// Report results
-	updated := 0
-	unchanged := 0
-	errors := 0
-	skipped := 0
-
+	summary := sync.Summarize(results)
+	deleted := []sync.SyncResult{}
 	for _, result := range results {
 		switch result.Status {
-		case "updated":
-			updated++
-		case "unchanged":
-			unchanged++
 		case "error":
-			errors++
 			if *verbose {
 				log.Printf("Error syncing %s: %v", result.FilePath, result.Error)
 			}
-		case "skipped":
-			skipped++
+		case "deleted":
+			deleted = append(deleted, result)
+		}
+	}
+
+	sitemapOpts.generate(*output, *dryRun)
+	feedOpts.generate(*output, *dryRun)
+
+	if *deletionReport != "" {
+		data, err := json.MarshalIndent(deleted, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode deletion report: %v", err)
+		}
+		if err := os.WriteFile(*deletionReport, data, 0644); err != nil {
+			log.Fatalf("Failed to write deletion report: %v", err)
+		}
+		if *verbose {
+			log.Printf("Deletion report written to %s", *deletionReport)
+		}
+	}
+
+	if *showDiff {
+		if err := writeSyncDiffs(*diffOutput, results); err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+
+	if *report != "" {
+		data, err := json.MarshalIndent(struct {
+			Summary sync.SyncSummary  `json:"summary"`
+			Results []sync.SyncResult `json:"results"`
+		}{summary, results}, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		if err := os.WriteFile(*report, data, 0644); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		if *verbose {
+			log.Printf("Report written to %s", *report)
 		}
 	}
 
 	if *dryRun {
-		log.Printf("Dry run completed: %d would be updated, %d unchanged, %d skipped, %d errors", updated, unchanged, skipped, errors)
+		log.Printf("Dry run completed: %s (updates and deletions not applied)", summary)
 	} else {
-		log.Printf("Sync completed: %d updated, %d unchanged, %d skipped, %d errors", updated, unchanged, skipped, errors)
+		log.Printf("Synced %d files: %s", len(results), summary)
 	}
 
-	if errors > 0 {
+	if summary.Errors > 0 {
 		os.Exit(1)
 	}
 }
+
+// writeSyncDiffs writes the diffs of the updated files to path, or to stdout when path is empty
+func writeSyncDiffs(path string, results []sync.SyncResult) error {
+	var sb strings.Builder
+	for _, result := range results {
+		sb.WriteString(result.Diff)
+	}
+
+	if path == "" {
+		_, err := os.Stdout.WriteString(sb.String())
+		return err
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write diff output: %w", err)
+	}
+	return nil
+}
+
+func runValidateLinks() {
+	fs := flag.NewFlagSet("validate-links", flag.ExitOnError)
+	output := fs.String("output", "./output", "Output directory path containing converted markdown files")
+	report := fs.String("report", "", "JSON report file for broken links")
+	autoFix := fs.Bool("auto-fix", false, "Rewrite broken links to the closest match within edit distance 3")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	if *verbose {
+		log.Printf("Validating links in %s...", *output)
+	}
+	broken, err := validation.ValidateLinks(*output)
+	if err != nil {
+		log.Fatalf("Link validation failed: %v", err)
+	}
+
+	for _, link := range broken {
+		fmt.Printf("%s: broken link [%s](%s) -> %s\n", link.SourceFile, link.LinkText, link.Target, link.ResolvedPath)
+		if suggestions := validation.FormatSuggestions(link.Suggestions); suggestions != "" {
+			fmt.Printf("    %s\n", suggestions)
+		}
+	}
+
+	if *report != "" {
+		data, err := json.MarshalIndent(broken, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		if err := os.WriteFile(*report, data, 0644); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		if *verbose {
+			log.Printf("Report written to %s", *report)
+		}
+	}
+
+	remaining := len(broken)
+	if *autoFix {
+		fixed, err := validation.AutoFix(*output, broken)
+		for _, link := range fixed {
+			log.Printf("Fixed %s: %s -> %s", link.SourceFile, link.Target, link.Suggestions[0].Path)
+		}
+		if err != nil {
+			log.Fatalf("Auto-fix failed: %v", err)
+		}
+		remaining -= len(fixed)
+	}
+
+	log.Printf("Link validation completed: %d broken, %d remaining", len(broken), remaining)
+
+	if remaining > 0 {
+		os.Exit(1)
+	}
+}
+
+func runValidate() {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	output := fs.String("output", "./output", "Output directory path containing converted markdown files")
+	requiredFields := fs.String("required-fields", strings.Join(validation.DefaultRequiredFields, ","), "Comma-separated frontmatter fields that must be present and non-empty")
+	hashAlgorithm := fs.String("hash-algorithm", utils.HashSHA256, "Hash the hash-content field was written with: sha256, sha512, sha1 or md5")
+	report := fs.String("report", "", "JSON report file for frontmatter problems")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	fields := splitList(*requiredFields)
+	if len(fields) == 0 {
+		log.Fatalf("Invalid -required-fields: at least one field is required")
+	}
+	hashFunc, err := utils.ParseHashAlgorithm(*hashAlgorithm)
+	if err != nil {
+		log.Fatalf("Invalid -hash-algorithm: %v", err)
+	}
+
+	if *verbose {
+		log.Printf("Validating frontmatter in %s...", *output)
+	}
+	issues, err := validation.ValidateFrontmatter(*output, validation.FrontmatterOptions{RequiredFields: fields, HashFunc: hashFunc})
+	if err != nil {
+		log.Fatalf("Frontmatter validation failed: %v", err)
+	}
+
+	for _, issue := range issues {
+		fmt.Printf("%s: %s\n", issue.SourceFile, issue.Message)
+	}
+
+	if *report != "" {
+		data, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		if err := os.WriteFile(*report, data, 0644); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		if *verbose {
+			log.Printf("Report written to %s", *report)
+		}
+	}
+
+	log.Printf("Frontmatter validation completed: %d problems", len(issues))
+
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runCheckCredentials() {
+	fs := flag.NewFlagSet("check-credentials", flag.ExitOnError)
+	credentials := fs.String("credentials", "credentials.json", "Google API credentials JSON file")
+	authOpts := addAuthFlags(fs)
+	sharedDriveID := fs.String("shared-drive-id", "", "Also verify access to this Shared Drive")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	ctx := context.Background()
+
+	driveService, err := auth.NewDriveServiceWithOptions(ctx, *credentials, authOpts())
+	if err != nil {
+		log.Fatalf("Failed to authenticate: %v", err)
+	}
+
+	info, err := auth.CheckAccess(ctx, driveService.Service, *sharedDriveID)
+	if err != nil {
+		log.Fatalf("Credential check failed: %v", err)
+	}
+
+	log.Printf("Authenticated as %s (%s)", info.Email, info.DisplayName)
+	if info.QuotaLimit > 0 {
+		log.Printf("Storage quota: %d of %d bytes used", info.QuotaUsage, info.QuotaLimit)
+	} else {
+		log.Printf("Storage quota: %d bytes used (unlimited)", info.QuotaUsage)
+	}
+	if *sharedDriveID != "" {
+		log.Printf("Shared Drive access OK: %s", info.SharedDriveName)
+	}
+	log.Println("Credential check passed")
+}
+
+func runRevokeToken() {
+	fs := flag.NewFlagSet("revoke-token", flag.ExitOnError)
+	tokenPathFlag := fs.String("token-path", "", "Saved OAuth2 token file (default: ~/.credentials/gdrive-crawler-token.json)")
+	yes := fs.Bool("yes", false, "Revoke without asking for confirmation")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	tokenPath, err := auth.TokenPath(*tokenPathFlag)
+	if err != nil {
+		log.Fatalf("Failed to locate token: %v", err)
+	}
+
+	if !*yes {
+		fmt.Printf("Revoke and delete the saved token at %s? [y/N]: ", tokenPath)
+		var answer string
+		fmt.Scanln(&answer)
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			log.Println("Aborted")
+			return
+		}
+	}
+
+	revoked, err := auth.RevokeSavedToken(context.Background(), tokenPath)
+	if err != nil {
+		log.Fatalf("Failed to revoke token: %v", err)
+	}
+	if !revoked {
+		log.Printf("No saved token at %s", tokenPath)
+		return
+	}
+
+	log.Printf("Token revoked and deleted: %s", tokenPath)
+}
+
+func runSuggestTags() {
+	fs := flag.NewFlagSet("suggest-tags", flag.ExitOnError)
+	output := fs.String("output", "./output", "Output directory path containing converted markdown files")
+	csvFile := fs.String("csv", "suggested-tags.csv", "CSV file for suggested tags")
+	topN := fs.Int("top-n", search.DefaultTopN, "Number of tags suggested per document")
+	stopwordsFile := fs.String("stopwords-file", "", "File of words never suggested, one per line (default: built-in English list)")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	if *topN < 1 {
+		log.Fatalf("Invalid -top-n: must be at least 1, got %d", *topN)
+	}
+
+	stopwords := search.DefaultStopwords()
+	if *stopwordsFile != "" {
+		var err error
+		stopwords, err = search.LoadStopwords(*stopwordsFile)
+		if err != nil {
+			log.Fatalf("Invalid -stopwords-file: %v", err)
+		}
+	}
+
+	if *verbose {
+		log.Printf("Scoring terms in %s...", *output)
+	}
+	suggestions, err := search.SuggestTags(*output, *topN, stopwords)
+	if err != nil {
+		log.Fatalf("Tag suggestion failed: %v", err)
+	}
+
+	if err := search.WriteSuggestionsCSV(*csvFile, suggestions); err != nil {
+		log.Fatalf("Failed to write suggestions: %v", err)
+	}
+
+	log.Printf("Tag suggestion completed: %d documents, written to %s", len(suggestions), *csvFile)
+}
+
+func runDedupCheck() {
+	fs := flag.NewFlagSet("dedup-check", flag.ExitOnError)
+	output := fs.String("output", "./output", "Output directory path containing converted markdown files")
+	csvFile := fs.String("csv", "duplicates.csv", "CSV file for duplicate pairs")
+	threshold := fs.Int("threshold", search.DefaultDuplicateThreshold, "Report documents whose fingerprints differ in fewer bits than this")
+	mergeAction := fs.String("merge-action", "none", "Action for duplicate groups: none or canonical")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	if *threshold < 1 || *threshold > search.SimHashBits {
+		log.Fatalf("Invalid -threshold: must be between 1 and %d, got %d", search.SimHashBits, *threshold)
+	}
+	if *mergeAction != "none" && *mergeAction != "canonical" {
+		log.Fatalf("Invalid -merge-action: want none or canonical, got %q", *mergeAction)
+	}
+
+	if *verbose {
+		log.Printf("Fingerprinting documents in %s...", *output)
+	}
+	groups, err := search.FindDuplicates(*output, *threshold)
+	if err != nil {
+		log.Fatalf("Duplicate check failed: %v", err)
+	}
+
+	pairs := 0
+	for _, group := range groups {
+		for _, p := range group.Pairs {
+			marker := ""
+			if p.Identical() {
+				marker = " [IDENTICAL]"
+			}
+			fmt.Printf("Group %d: %s <-> %s (similarity %.3f)%s\n", p.GroupID, p.File1, p.File2, search.Similarity(p.Distance), marker)
+			pairs++
+		}
+	}
+
+	if err := search.WriteDuplicatesCSV(*csvFile, groups); err != nil {
+		log.Fatalf("Failed to write duplicates: %v", err)
+	}
+
+	if *mergeAction == "canonical" {
+		updated, err := search.SetCanonicalLinks(groups)
+		for _, file := range updated {
+			log.Printf("Set %s in %s", search.CanonicalLinkKey, file)
+		}
+		if err != nil {
+			log.Fatalf("Merge action failed: %v", err)
+		}
+	}
+
+	log.Printf("Duplicate check completed: %d groups, %d pairs, written to %s", len(groups), pairs, *csvFile)
+}
+
+func runInventoryLinks() {
+	fs := flag.NewFlagSet("inventory-links", flag.ExitOnError)
+	output := fs.String("output", "./output", "Output directory path containing converted markdown files")
+	csvFile := fs.String("csv", "external-links.csv", "CSV file for external links")
+	checkExternal := fs.Bool("check-external", false, "Check each URL with an HTTP HEAD request")
+	workers := fs.Int("workers", 10, "Number of concurrent URL checks")
+	timeout := fs.Duration("timeout", validation.DefaultLinkCheckTimeout, "Timeout for each URL check")
+	cacheTTL := fs.Duration("cache-ttl", validation.DefaultLinkCacheTTL, "Reuse cached check results younger than this")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	if *workers < 1 {
+		log.Fatalf("Invalid -workers: must be at least 1, got %d", *workers)
+	}
+	if *timeout <= 0 {
+		log.Fatalf("Invalid -timeout: must be positive, got %v", *timeout)
+	}
+
+	links, err := validation.FindExternalLinks(*output)
+	if err != nil {
+		log.Fatalf("Link inventory failed: %v", err)
+	}
+	if *verbose {
+		log.Printf("Found %d external links in %s", len(links), *output)
+	}
+
+	dead := 0
+	if *checkExternal {
+		cachePath := filepath.Join(*output, validation.ExternalLinkCacheFile)
+		cache, err := validation.LoadLinkCache(cachePath)
+		if err != nil {
+			log.Fatalf("Failed to load link cache: %v", err)
+		}
+
+		checker := validation.NewLinkChecker(*workers, *timeout, cache)
+		checker.CacheTTL = *cacheTTL
+		checker.Check(context.Background(), links)
+
+		if err := cache.Save(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+
+		for _, link := range links {
+			if !link.Dead() {
+				continue
+			}
+			dead++
+			reason := strconv.Itoa(link.StatusCode)
+			if link.Error != "" {
+				reason = link.Error
+			}
+			fmt.Printf("DEAD %s: [%s](%s) -> %s\n", link.SourceFile, link.LinkText, link.URL, reason)
+		}
+	}
+
+	if err := validation.WriteExternalLinksCSV(*csvFile, links); err != nil {
+		log.Fatalf("Failed to write link inventory: %v", err)
+	}
+
+	if *checkExternal {
+		log.Printf("Link inventory completed: %d external links, %d dead, written to %s", len(links), dead, *csvFile)
+	} else {
+		log.Printf("Link inventory completed: %d external links, written to %s", len(links), *csvFile)
+	}
+}
+
+func runWikiJS() {
+	fs := flag.NewFlagSet("wikijs", flag.ExitOnError)
+	output := fs.String("output", "./output", "Output directory path containing converted markdown files")
+	wikiOpts := addWikiJSFlags(fs)
+	locale := fs.String("locale", wikijs.DefaultLocale, "Locale of the created and updated pages")
+	dryRun := fs.Bool("dry-run", false, "Log the pages that would be created or updated without changing the wiki")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	client := wikiOpts()
+	if *verbose {
+		log.Printf("Uploading %s to %s...", *output, client.BaseURL)
+	}
+	opts := wikijs.UploadOptions{Locale: *locale, DryRun: *dryRun, Verbose: *verbose}
+	result, err := wikijs.Upload(interruptContext(), client, *output, opts)
+	if err != nil {
+		log.Fatalf("Wiki.js upload failed: %v", err)
+	}
+
+	log.Printf("Wiki.js upload completed: %d created, %d updated, %d failed", result.Created, result.Updated, len(result.Failed))
+
+	if len(result.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runWikiJSTree() {
+	fs := flag.NewFlagSet("wikijs-tree", flag.ExitOnError)
+	input := fs.String("input", "", "Input CSV file (required)")
+	csvDelimiter := fs.String("csv-delimiter", ",", "Input CSV field delimiter (single character or \\t)")
+	maxFragmentDepth := fs.Int("max-fragment-depth", csvpkg.DefaultFragmentDepth, "Number of fragment columns read, frag1 to fragN")
+	wikiOpts := addWikiJSFlags(fs)
+	locale := fs.String("locale", wikijs.DefaultLocale, "Locale of the created pages")
+	dryRun := fs.Bool("dry-run", false, "Log the pages that would be created without changing the wiki")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+
+	logOpts := addLogFlags(fs)
+	fs.Parse(os.Args[2:])
+	logOpts.setup()
+
+	if *input == "" {
+		fmt.Println("Error: -input is required")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	delimiter, err := csvpkg.ParseDelimiter(*csvDelimiter)
+	if err != nil {
+		log.Fatalf("Invalid -csv-delimiter: %v", err)
+	}
+	if *maxFragmentDepth < 1 || *maxFragmentDepth > csvpkg.MaxFragmentDepth {
+		log.Fatalf("Invalid -max-fragment-depth: must be between 1 and %d, got %d", csvpkg.MaxFragmentDepth, *maxFragmentDepth)
+	}
+	client := wikiOpts()
+
+	records, err := csvpkg.ParseConversionCSVWithDepth(*input, delimiter, *maxFragmentDepth)
+	if err != nil {
+		log.Fatalf("Failed to parse input CSV: %v", err)
+	}
+
+	// Sorting the fragment lists visits the tree depth-first, each parent before its children
+	var hierarchies [][]string
+	for i := range records {
+		if fragments := records[i].GetFragments(); len(fragments) > 0 {
+			hierarchies = append(hierarchies, fragments)
+		}
+	}
+	slices.SortFunc(hierarchies, slices.Compare)
+
+	if *verbose {
+		log.Printf("Creating the page tree of %s in %s...", *input, client.BaseURL)
+	}
+	ctx := interruptContext()
+	builder := wikijs.NewTreeBuilder(client, wikijs.TreeOptions{Locale: *locale, DryRun: *dryRun, Verbose: *verbose})
+	failed := 0
+	for _, fragments := range hierarchies {
+		if err := builder.EnsurePageTree(ctx, fragments); err != nil {
+			if ctx.Err() != nil {
+				log.Fatalf("Wiki.js page tree cancelled: %v", ctx.Err())
+			}
+			log.Printf("Warning: failed to create the page tree of %s: %v", strings.Join(fragments, "/"), err)
+			failed++
+		}
+	}
+
+	log.Printf("Wiki.js page tree completed: %d created, %d existing, %d failed", builder.Result.Created, builder.Result.Existing, failed)
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// addWikiJSFlags registers the -wikijs-url and -wikijs-token flags on fs. The returned function
+// validates the flags after parsing, exiting on a missing or invalid value, and returns a client.
+func addWikiJSFlags(fs *flag.FlagSet) func() *wikijs.Client {
+	wikiURL := fs.String("wikijs-url", "", "Wiki.js base URL, e.g. https://wiki.example.com (required)")
+	token := fs.String("wikijs-token", "", "Wiki.js API key (required)")
+	return func() *wikijs.Client {
+		if *wikiURL == "" || *token == "" {
+			fmt.Println("Error: -wikijs-url and -wikijs-token are required")
+			fs.PrintDefaults()
+			os.Exit(1)
+		}
+		if parsed, err := url.Parse(*wikiURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			log.Fatalf("Invalid -wikijs-url: want an http or https URL, got %q", *wikiURL)
+		}
+		return wikijs.NewClient(*wikiURL, *token)
+	}
+}
+
+// addAuthFlags registers the -auth-flow, -token-path and -max-requests-per-second flags on fs.
+// The returned function validates the flags after parsing and exits on an invalid value.
+func addAuthFlags(fs *flag.FlagSet) func() auth.Options {
+	flow := fs.String("auth-flow", string(auth.AuthFlowBrowser), "How to get an OAuth2 token when none is saved: browser or device")
+	tokenPath := fs.String("token-path", "", "Saved OAuth2 token file (default: ~/.credentials/gdrive-crawler-token.json)")
+	requestsPerSecond := fs.Float64("max-requests-per-second", 0, "Cap on Drive API requests per second (0 = unlimited)")
+	return func() auth.Options {
+		if err := auth.ValidateAuthFlow(*flow); err != nil {
+			log.Fatalf("Invalid -auth-flow: %v", err)
+		}
+		if *requestsPerSecond < 0 {
+			log.Fatalf("Invalid -max-requests-per-second: must not be negative, got %v", *requestsPerSecond)
+		}
+		return auth.Options{AuthFlow: auth.AuthFlow(*flow), TokenPath: *tokenPath, RequestsPerSecond: *requestsPerSecond}
+	}
+}
+
+// addConfigFlag registers the -config flag on fs. The returned function, called after parsing,
+// sets the flags not given on the command line from the config file and exits on an invalid file.
+func addConfigFlag(fs *flag.FlagSet) func() {
+	path := fs.String("config", "", "YAML file of flag values; flags on the command line override it")
+	return func() {
+		if *path == "" {
+			return
+		}
+		cfg, err := config.Load(*path)
+		if err != nil {
+			log.Fatalf("Invalid -config: %v", err)
+		}
+		if err := cfg.Apply(fs); err != nil {
+			log.Fatalf("Invalid -config: %v", err)
+		}
+	}
+}
+
+// addRetryFlags registers the retry backoff flags on fs
+func addRetryFlags(fs *flag.FlagSet) *utils.RetryConfig {
+	defaults := utils.DefaultRetryConfig()
+	retry := &utils.RetryConfig{}
+	fs.IntVar(&retry.MaxRetries, "max-retries", defaults.MaxRetries, "Retries for rate-limited or failing API calls")
+	fs.DurationVar(&retry.BaseDelay, "base-delay", defaults.BaseDelay, "Delay before the first retry, doubled for each retry")
+	fs.DurationVar(&retry.MaxDelay, "max-delay", defaults.MaxDelay, "Upper bound on a single retry delay")
+	fs.Float64Var(&retry.JitterFraction, "jitter", defaults.JitterFraction, "Randomize retry delays by up to this fraction (0-1)")
+
+	// Each subcommand gets its own budget, so it is never carried over between runs
+	retry.Budget = utils.NewRetryBudget(utils.DefaultRetryBudget)
+	fs.Func("retry-budget", "Retries allowed across all API calls of the run (default: 100; 0 = unlimited)", func(value string) error {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		retry.Budget = utils.NewRetryBudget(limit)
+		return nil
+	})
+	return retry
+}
+
+// interruptContext returns a context cancelled by the first interrupt or SIGTERM, so a run stops
+// starting new items and reports the ones left. A second interrupt exits at once.
+func interruptContext() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+		log.Println("Interrupted, stopping; press Ctrl-C again to quit at once")
+	}()
+	return ctx
+}
+
+// quotaFlags holds the flags that count Drive API calls against a daily quota
+type quotaFlags struct {
+	report *string
+	limit  *int64
+}
+
+// addQuotaFlags registers the -quota-report and -quota-limit flags on fs
+func addQuotaFlags(fs *flag.FlagSet) *quotaFlags {
+	return &quotaFlags{
+		report: fs.String("quota-report", "", "JSON file that counts Drive API calls per day across runs"),
+		limit:  fs.Int64("quota-limit", auth.DefaultDailyQuota, "Daily Drive API call limit for -quota-report (0 = unlimited)"),
+	}
+}
+
+// tracker loads the day's call counts from the quota report, or returns nil when no report is
+// requested. It exits on an invalid limit or an unreadable report.
+func (q *quotaFlags) tracker() *auth.QuotaTracker {
+	if *q.limit < 0 {
+		log.Fatalf("Invalid -quota-limit: must not be negative, got %d", *q.limit)
+	}
+	if *q.report == "" {
+		return nil
+	}
+
+	tracker, err := auth.LoadQuotaTracker(*q.report, *q.limit)
+	if err != nil {
+		log.Fatalf("Failed to load quota report: %v", err)
+	}
+	return tracker
+}
+
+// finish adds the retries taken from budget (nil for none) to the day's counts, logs the call
+// summary and saves the counts to the quota report
+func (q *quotaFlags) finish(tracker *auth.QuotaTracker, budget *utils.RetryBudget) {
+	if tracker == nil {
+		return
+	}
+
+	tracker.AddRetries(budget.Used())
+	log.Print(tracker.Summary())
+	if err := tracker.Save(*q.report); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// logFlags holds the flags that send log output to a rotating file
+type logFlags struct {
+	fs         *flag.FlagSet
+	file       *string
+	stderr     *bool
+	maxSizeMB  *int
+	maxBackups *int
+}
+
+// addLogFlags registers the -log-file, -log-stderr, -log-max-size-mb and -log-max-backups flags on fs
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	return &logFlags{
+		fs:         fs,
+		file:       fs.String("log-file", "", "Append log output to this file instead of stderr"),
+		stderr:     fs.Bool("log-stderr", true, "Also write log output to stderr (default: true without -log-file, false with it)"),
+		maxSizeMB:  fs.Int("log-max-size-mb", logger.DefaultMaxSizeMB, "Rotate the log file when it would grow past this size (0 = never)"),
+		maxBackups: fs.Int("log-max-backups", logger.DefaultMaxBackups, "Number of rotated log files kept"),
+	}
+}
+
+// setup sends log output to the log file, if any. Without an explicit -log-stderr, stderr is
+// only written when there is no log file. It exits on invalid flags or an unopenable file.
+func (l *logFlags) setup() {
+	if *l.maxSizeMB < 0 {
+		log.Fatalf("Invalid -log-max-size-mb: must not be negative, got %d", *l.maxSizeMB)
+	}
+	if *l.maxBackups < 0 {
+		log.Fatalf("Invalid -log-max-backups: must not be negative, got %d", *l.maxBackups)
+	}
+
+	stderrSet := false
+	l.fs.Visit(func(f *flag.Flag) {
+		if f.Name == "log-stderr" {
+			stderrSet = true
+		}
+	})
+	stderr := *l.stderr
+	if !stderrSet {
+		stderr = *l.file == ""
+	}
+
+	if _, err := logger.Setup(*l.file, stderr, *l.maxSizeMB, *l.maxBackups); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+}
+
+// sitemapFlags holds the flags that write a sitemap after documents are written
+type sitemapFlags struct {
+	enabled *bool
+	baseURL *string
+	maxURLs *int
+}
+
+// addSitemapFlags registers the sitemap flags on fs
+func addSitemapFlags(fs *flag.FlagSet) *sitemapFlags {
+	return &sitemapFlags{
+		enabled: fs.Bool("generate-sitemap", false, "Write sitemap.xml for the published documents in the output directory"),
+		baseURL: fs.String("base-url", "", "Base URL of the wiki used for sitemap and feed links (required with -generate-sitemap or -generate-feed)"),
+		maxURLs: fs.Int("sitemap-max-urls", sitemap.DefaultMaxURLs, "Split the sitemap with a sitemap_index.xml above this many URLs"),
+	}
+}
+
+// validate exits on invalid sitemap flags
+func (f *sitemapFlags) validate() {
+	if !*f.enabled {
+		return
+	}
+	validateBaseURL(*f.baseURL, "-generate-sitemap")
+	if *f.maxURLs < 1 || *f.maxURLs > sitemap.DefaultMaxURLs {
+		log.Fatalf("Invalid -sitemap-max-urls: must be between 1 and %d, got %d", sitemap.DefaultMaxURLs, *f.maxURLs)
+	}
+}
+
+// generate writes the sitemap for outputDir when -generate-sitemap is set. Failures are logged
+// as warnings since the documents themselves were written.
+func (f *sitemapFlags) generate(outputDir string, dryRun bool) {
+	if !*f.enabled {
+		return
+	}
+	if dryRun {
+		log.Printf("Would write: sitemap for %s", outputDir)
+		return
+	}
+
+	written, err := sitemap.Generate(outputDir, *f.baseURL, *f.maxURLs)
+	if err != nil {
+		log.Printf("Warning: failed to generate sitemap: %v", err)
+		return
+	}
+	log.Printf("Sitemap written: %s", strings.Join(written, ", "))
+}
+
+// feedFlags holds the flags that write a feed after documents are written
+type feedFlags struct {
+	enabled  *bool
+	baseURL  *string
+	format   *string
+	maxItems *int
+	title    *string
+	author   *string
+}
+
+// addFeedFlags registers the feed flags on fs. The base URL is shared with the sitemap flags.
+func addFeedFlags(fs *flag.FlagSet, baseURL *string) *feedFlags {
+	return &feedFlags{
+		enabled:  fs.Bool("generate-feed", false, "Write a feed of the most recently modified documents to the output directory"),
+		baseURL:  baseURL,
+		format:   fs.String("feed-format", string(feed.FormatAtom), "Feed format: atom (atom.xml) or rss2 (rss.xml)"),
+		maxItems: fs.Int("feed-max-items", feed.DefaultMaxItems, "Number of documents in the feed"),
+		title:    fs.String("feed-title", feed.DefaultTitle, "Feed title"),
+		author:   fs.String("feed-author", "", "Atom feed author (default: the feed title)"),
+	}
+}
+
+// validate exits on invalid feed flags
+func (f *feedFlags) validate() {
+	if !*f.enabled {
+		return
+	}
+	validateBaseURL(*f.baseURL, "-generate-feed")
+	if err := feed.ValidateFormat(*f.format); err != nil {
+		log.Fatalf("Invalid -feed-format: %v", err)
+	}
+	if *f.maxItems < 1 {
+		log.Fatalf("Invalid -feed-max-items: must be at least 1, got %d", *f.maxItems)
+	}
+}
+
+// generate writes the feed for outputDir when -generate-feed is set. Failures are logged as
+// warnings since the documents themselves were written.
+func (f *feedFlags) generate(outputDir string, dryRun bool) {
+	if !*f.enabled {
+		return
+	}
+	if dryRun {
+		log.Printf("Would write: feed for %s", outputDir)
+		return
+	}
+
+	name, err := feed.Generate(outputDir, feed.Options{
+		BaseURL:  *f.baseURL,
+		Title:    *f.title,
+		Author:   *f.author,
+		Format:   feed.Format(*f.format),
+		MaxItems: *f.maxItems,
+	})
+	if err != nil {
+		log.Printf("Warning: failed to generate feed: %v", err)
+		return
+	}
+	log.Printf("Feed written: %s", name)
+}
+
+// validateBaseURL exits unless baseURL is an http or https URL, which the flag named by
+// requiredBy needs
+func validateBaseURL(baseURL, requiredBy string) {
+	if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		log.Fatalf("Invalid -base-url: an http or https URL is required with %s, got %q", requiredBy, baseURL)
+	}
+}
+
+// loadSourceLinkTemplate parses the source link template when -append-source-link is set
+func loadSourceLinkTemplate(enabled bool, text string) (*template.Template, error) {
+	if !enabled {
+		return nil, nil
+	}
+	return conversion.ParseSourceLinkTemplate(text)
+}
+
+// splitList splits a comma-separated flag value, trimming whitespace and dropping empty entries
+// listFlag is a flag that may be repeated; each value may also be a comma-separated list
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, splitList(value)...)
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 
@@ -11,16 +12,60 @@ import (
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
 )
 
-// DriveService wraps the Google Drive API service
+// DriveService wraps the Google Drive API service. It implements SharedDriveService: all of
+// its requests are paced by one rate limiter, however many syncers and converters use it.
 type DriveService struct {
 	Service *drive.Service
+	Sheets  *sheets.Service // Sheets API service sharing the Drive client, rate limit and quota
 	ctx     context.Context
+	limiter RateLimiter // nil = unlimited
+}
+
+// AuthFlow selects how a new OAuth2 token is obtained
+type AuthFlow string
+
+const (
+	AuthFlowBrowser AuthFlow = "browser" // Open a URL in a browser and paste the authorization code
+	AuthFlowDevice  AuthFlow = "device"  // OAuth2 device authorization grant (RFC 8628) for headless machines
+)
+
+// ValidateAuthFlow returns an error if flow is not a supported auth flow
+func ValidateAuthFlow(flow string) error {
+	switch AuthFlow(flow) {
+	case AuthFlowBrowser, AuthFlowDevice:
+		return nil
+	default:
+		return fmt.Errorf("unknown auth flow %q (want %q or %q)", flow, AuthFlowBrowser, AuthFlowDevice)
+	}
+}
+
+// Options holds optional authentication settings
+type Options struct {
+	AuthFlow  AuthFlow // How to obtain a token when none is saved (empty = AuthFlowBrowser)
+	TokenPath string   // Saved token file (empty = ~/.credentials/gdrive-crawler-token.json)
+
+	// RequestsPerSecond caps the Drive API requests sent through the service (0 = unlimited)
+	RequestsPerSecond float64
+
+	// Quota counts the Drive API requests sent through the service (nil = not counted)
+	Quota *QuotaTracker
 }
 
 // NewDriveService creates a new Drive service from credentials file
 func NewDriveService(ctx context.Context, credentialsPath string) (*DriveService, error) {
+	return NewDriveServiceWithOptions(ctx, credentialsPath, Options{})
+}
+
+// NewDriveServiceWithOptions creates a new Drive service from credentials file. The token
+// options only apply to OAuth2 credentials; service accounts do not use a saved token.
+func NewDriveServiceWithOptions(ctx context.Context, credentialsPath string, opts Options) (*DriveService, error) {
+	if opts.RequestsPerSecond < 0 {
+		return nil, fmt.Errorf("requests per second must not be negative, got %v", opts.RequestsPerSecond)
+	}
+
 	// Read credentials file
 	credBytes, err := os.ReadFile(credentialsPath)
 	if err != nil {
@@ -32,12 +77,7 @@ func NewDriveService(ctx context.Context, credentialsPath string) (*DriveService
 	config, err := google.JWTConfigFromJSON(credBytes, drive.DriveScope)
 	if err == nil {
 		// Service account authentication
-		client := config.Client(ctx)
-		srv, err := drive.NewService(ctx, option.WithHTTPClient(client))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create Drive service: %w", err)
-		}
-		return &DriveService{Service: srv, ctx: ctx}, nil
+		return newDriveService(ctx, config.Client(ctx), opts)
 	}
 
 	// Try OAuth2 credentials
@@ -81,26 +121,56 @@ func NewDriveService(ctx context.Context, credentialsPath string) (*DriveService
 	}
 
 	// Try to load saved token
-	token, err := loadToken()
+	tokenPath, err := getTokenPath(opts.TokenPath)
+	if err != nil {
+		return nil, err
+	}
+	token, err := loadToken(tokenPath)
 	if err != nil || token == nil {
 		// No saved token, get new token from user
-		token, err = getTokenFromWeb(ctx, oauthConfig)
+		if opts.AuthFlow == AuthFlowDevice {
+			token, err = getTokenFromDevice(ctx, oauthConfig)
+		} else {
+			token, err = getTokenFromWeb(ctx, oauthConfig)
+		}
 		if err != nil {
 			return nil, err
 		}
 		// Save token for future use
-		if err := saveToken(token); err != nil {
+		if err := saveToken(token, tokenPath); err != nil {
 			fmt.Printf("Warning: Failed to save token: %v\n", err)
 		}
 	}
 
-	client := oauthConfig.Client(ctx, token)
+	return newDriveService(ctx, oauthConfig.Client(ctx, token), opts)
+}
+
+// newDriveService creates the Drive service for an authenticated client, pacing its requests
+// when opts.RequestsPerSecond is set and counting them when opts.Quota is set
+func newDriveService(ctx context.Context, client *http.Client, opts Options) (*DriveService, error) {
+	ds := &DriveService{ctx: ctx}
+	if opts.RequestsPerSecond > 0 {
+		limiter, err := NewIntervalLimiter(opts.RequestsPerSecond)
+		if err != nil {
+			return nil, err
+		}
+		ds.limiter = limiter
+	}
+
+	// Quota is checked first, so requests over the quota fail without waiting for the limiter
+	client = withQuota(withRateLimit(client, ds.limiter), opts.Quota)
 	srv, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Drive service: %w", err)
 	}
+	ds.Service = srv
 
-	return &DriveService{Service: srv, ctx: ctx}, nil
+	// The Drive scope also grants read access to the Sheets API
+	ds.Sheets, err = sheets.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sheets service: %w", err)
+	}
+	return ds, nil
 }
 
 // Context returns the context associated with this service
@@ -108,6 +178,20 @@ func (ds *DriveService) Context() context.Context {
 	return ds.ctx
 }
 
+// Drive returns the Drive API service
+func (ds *DriveService) Drive() *drive.Service {
+	return ds.Service
+}
+
+// Wait blocks until the service's rate limiter allows another request. Requests made through
+// the service already wait on their own; Wait is for pacing work outside it.
+func (ds *DriveService) Wait(ctx context.Context) error {
+	if ds.limiter == nil {
+		return nil
+	}
+	return ds.limiter.Wait(ctx)
+}
+
 // getTokenFromWeb uses OAuth2 to retrieve a token from the web
 func getTokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
 	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
@@ -127,8 +211,38 @@ func getTokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token,
 	return token, nil
 }
 
-// getTokenPath returns the path to the token file
-func getTokenPath() (string, error) {
+// getTokenFromDevice uses the OAuth2 device authorization grant to retrieve a token. The
+// token endpoint is polled at the interval given by the device authorization response.
+func getTokenFromDevice(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	response, err := config.DeviceAuth(ctx, oauth2.AccessTypeOffline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start device authorization: %w", err)
+	}
+
+	fmt.Printf("Please visit: %s and enter code: %s\n", response.VerificationURI, response.UserCode)
+
+	token, err := config.DeviceAccessToken(ctx, response, oauth2.AccessTypeOffline)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+
+	return token, nil
+}
+
+// getTokenPath returns the path to the token file. A non-empty override is used instead of
+// the default, resolved relative to the working directory.
+func getTokenPath(override string) (string, error) {
+	if override != "" {
+		tokenPath, err := filepath.Abs(override)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve token path: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+			return "", fmt.Errorf("failed to create token directory: %w", err)
+		}
+		return tokenPath, nil
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get user home directory: %w", err)
@@ -143,12 +257,7 @@ func getTokenPath() (string, error) {
 }
 
 // saveToken saves a token to a file
-func saveToken(token *oauth2.Token) error {
-	tokenPath, err := getTokenPath()
-	if err != nil {
-		return err
-	}
-
+func saveToken(token *oauth2.Token, tokenPath string) error {
 	fmt.Printf("Saving credentials to: %s\n", tokenPath)
 	f, err := os.OpenFile(tokenPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
@@ -164,12 +273,7 @@ func saveToken(token *oauth2.Token) error {
 }
 
 // loadToken loads a token from a file
-func loadToken() (*oauth2.Token, error) {
-	tokenPath, err := getTokenPath()
-	if err != nil {
-		return nil, err
-	}
-
+func loadToken(tokenPath string) (*oauth2.Token, error) {
 	f, err := os.Open(tokenPath)
 	if err != nil {
 		if os.IsNotExist(err) {
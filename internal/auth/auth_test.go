@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestValidateAuthFlow(t *testing.T) {
+	tests := []struct {
+		flow    string
+		wantErr bool
+	}{
+		{flow: "browser"},
+		{flow: "device"},
+		{flow: "web", wantErr: true},
+		{flow: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateAuthFlow(tt.flow); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateAuthFlow(%q) error = %v, wantErr %v", tt.flow, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetTokenFromDevice(t *testing.T) {
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device/code", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"device_code":"dev-123","user_code":"ABCD-EFGH","verification_url":"https://www.google.com/device","expires_in":60,"interval":1}`))
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		r.ParseForm()
+		if got := r.Form.Get("device_code"); got != "dev-123" {
+			t.Errorf("device_code = %q, want dev-123", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config := &oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{
+			DeviceAuthURL: server.URL + "/device/code",
+			TokenURL:      server.URL + "/token",
+		},
+	}
+
+	token, err := getTokenFromDevice(context.Background(), config)
+	if err != nil {
+		t.Fatalf("getTokenFromDevice() error = %v", err)
+	}
+	if token.AccessToken != "access" || token.RefreshToken != "refresh" {
+		t.Errorf("getTokenFromDevice() = %+v, want access and refresh tokens", token)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token endpoint polled %d times, want 1", tokenRequests)
+	}
+}
+
+func TestGetTokenPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(t.TempDir())
+	workDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		override string
+		want     string
+	}{
+		{name: "default", want: filepath.Join(home, ".credentials", "gdrive-crawler-token.json")},
+		{name: "absolute", override: filepath.Join(home, "tokens", "work.json"), want: filepath.Join(home, "tokens", "work.json")},
+		{name: "relative", override: filepath.Join("state", "token.json"), want: filepath.Join(workDir, "state", "token.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getTokenPath(tt.override)
+			if err != nil {
+				t.Fatalf("getTokenPath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("getTokenPath() = %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(filepath.Dir(got)); err != nil {
+				t.Errorf("token directory not created: %v", err)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoadToken(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token.json")
+
+	if token, err := loadToken(tokenPath); err != nil || token != nil {
+		t.Fatalf("loadToken() = %v, %v, want nil token for missing file", token, err)
+	}
+
+	if err := saveToken(&oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}, tokenPath); err != nil {
+		t.Fatalf("saveToken() error = %v", err)
+	}
+
+	token, err := loadToken(tokenPath)
+	if err != nil {
+		t.Fatalf("loadToken() error = %v", err)
+	}
+	if token.AccessToken != "access" || token.RefreshToken != "refresh" {
+		t.Errorf("loadToken() = %+v, want the saved token", token)
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// CheckTimeout bounds the total time spent by CheckAccess
+const CheckTimeout = 5 * time.Second
+
+// AccessInfo describes the account a Drive service is authenticated as
+type AccessInfo struct {
+	Email           string
+	DisplayName     string
+	QuotaUsage      int64  // Bytes used
+	QuotaLimit      int64  // Bytes available (0 = unlimited)
+	SharedDriveName string // Name of the checked Shared Drive (empty if none was checked)
+}
+
+// CheckAccess verifies that srv can reach the Drive API and read files. It fetches the
+// authenticated user, lists a single file and, when sharedDriveID is set, checks access to
+// that Shared Drive. The checks stop after CheckTimeout.
+func CheckAccess(ctx context.Context, srv *drive.Service, sharedDriveID string) (*AccessInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	about, err := srv.About.Get().Fields("user, storageQuota").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	info := &AccessInfo{}
+	if about.User != nil {
+		info.Email = about.User.EmailAddress
+		info.DisplayName = about.User.DisplayName
+	}
+	if about.StorageQuota != nil {
+		info.QuotaUsage = about.StorageQuota.Usage
+		info.QuotaLimit = about.StorageQuota.Limit
+	}
+
+	list := srv.Files.List().PageSize(1).Fields("files(id)").
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true)
+	if sharedDriveID != "" {
+		drv, err := srv.Drives.Get(sharedDriveID).Fields("id, name").Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to access Shared Drive %s: %w", sharedDriveID, err)
+		}
+		info.SharedDriveName = drv.Name
+		list = list.Corpora("drive").DriveId(sharedDriveID)
+	}
+
+	if _, err := list.Context(ctx).Do(); err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return info, nil
+}
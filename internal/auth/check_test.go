@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// newTestService returns a Drive service backed by handler
+func newTestService(t *testing.T, handler http.HandlerFunc) *drive.Service {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	srv, err := drive.NewService(context.Background(),
+		option.WithHTTPClient(server.Client()),
+		option.WithEndpoint(server.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create Drive service: %v", err)
+	}
+	return srv
+}
+
+func TestCheckAccess(t *testing.T) {
+	tests := []struct {
+		name          string
+		sharedDriveID string
+		failPath      string
+		wantErr       string
+		wantDrive     string
+	}{
+		{name: "success"},
+		{name: "shared drive", sharedDriveID: "drive1", wantDrive: "Engineering"},
+		{name: "user info fails", failPath: "/about", wantErr: "failed to get user info"},
+		{name: "list fails", failPath: "/files", wantErr: "failed to list files"},
+		{name: "shared drive fails", sharedDriveID: "drive1", failPath: "/drives/drive1", wantErr: "failed to access Shared Drive drive1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listQuery string
+			srv := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == tt.failPath {
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"error":{"code":403,"message":"insufficient permissions"}}`))
+					return
+				}
+
+				switch r.URL.Path {
+				case "/about":
+					w.Write([]byte(`{"user":{"emailAddress":"bot@example.com","displayName":"Bot"},"storageQuota":{"usage":"1024","limit":"4096"}}`))
+				case "/files":
+					listQuery = r.URL.RawQuery
+					w.Write([]byte(`{"files":[]}`))
+				case "/drives/drive1":
+					w.Write([]byte(`{"id":"drive1","name":"Engineering"}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			info, err := CheckAccess(context.Background(), srv, tt.sharedDriveID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CheckAccess() error = %v, want %q", err, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), "insufficient permissions") {
+					t.Errorf("CheckAccess() error = %v, want the API error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckAccess() error = %v", err)
+			}
+
+			if info.Email != "bot@example.com" || info.QuotaUsage != 1024 || info.QuotaLimit != 4096 {
+				t.Errorf("CheckAccess() = %+v, want user and quota from About", info)
+			}
+			if info.SharedDriveName != tt.wantDrive {
+				t.Errorf("SharedDriveName = %q, want %q", info.SharedDriveName, tt.wantDrive)
+			}
+			if tt.sharedDriveID != "" && !strings.Contains(listQuery, "driveId="+tt.sharedDriveID) {
+				t.Errorf("list query = %q, want it scoped to the Shared Drive", listQuery)
+			}
+		})
+	}
+}
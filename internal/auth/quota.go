@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDailyQuota is the default number of Drive API queries a project may make per day
+	DefaultDailyQuota = 1_000_000_000
+
+	// quotaWarningRatio is the share of the daily quota after which a warning is logged
+	quotaWarningRatio = 0.8
+)
+
+// ErrQuotaExhausted is returned for requests made after the daily quota is used up
+var ErrQuotaExhausted = errors.New("daily Drive API quota exhausted")
+
+// summaryMethods are the API methods always listed in the quota summary, in order
+var summaryMethods = []string{"Files.Get", "Files.Export", "Files.List", "Files.Copy", "Files.Delete"}
+
+// QuotaTracker counts Drive API calls by method against a daily quota. It is safe for
+// concurrent use.
+type QuotaTracker struct {
+	limit   int64
+	mu      sync.Mutex
+	date    string           // UTC day the counts belong to (YYYY-MM-DD)
+	calls   map[string]int64 // Calls per API method, such as "Files.Get"
+	retries int64            // Calls that were retries of failed calls
+	warned  bool
+}
+
+// quotaUsage is the saved form of a QuotaTracker
+type quotaUsage struct {
+	Date    string           `json:"date"`
+	Total   int64            `json:"total"`
+	Calls   map[string]int64 `json:"calls"`
+	Retries int64            `json:"retries"`
+}
+
+// NewQuotaTracker creates a tracker with no calls counted. A limit of 0 means unlimited.
+func NewQuotaTracker(limit int64) *QuotaTracker {
+	return &QuotaTracker{
+		limit: limit,
+		date:  today(),
+		calls: make(map[string]int64),
+	}
+}
+
+// LoadQuotaTracker creates a tracker that continues the counts saved at path, so the daily
+// quota covers every run of the day. Counts saved on an earlier day and a missing file give a
+// tracker with no calls counted.
+func LoadQuotaTracker(path string, limit int64) (*QuotaTracker, error) {
+	q := NewQuotaTracker(limit)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota report: %w", err)
+	}
+
+	var usage quotaUsage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("failed to parse quota report %s: %w", path, err)
+	}
+	if usage.Date == q.date {
+		for method, n := range usage.Calls {
+			q.calls[method] = n
+		}
+		q.retries = usage.Retries
+	}
+
+	return q, nil
+}
+
+// Save writes the day's counts to path as JSON
+func (q *QuotaTracker) Save(path string) error {
+	q.mu.Lock()
+	usage := quotaUsage{Date: q.date, Total: q.total(), Calls: q.calls, Retries: q.retries}
+	data, err := json.MarshalIndent(usage, "", "  ")
+	q.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode quota report: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write quota report: %w", err)
+	}
+	return nil
+}
+
+// record counts a call to method. It logs a warning once 80% of the quota is used and returns
+// ErrQuotaExhausted, without counting the call, once all of it is.
+func (q *QuotaTracker) record(method string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Quotas reset at midnight
+	if day := today(); day != q.date {
+		q.date = day
+		q.calls = make(map[string]int64)
+		q.retries = 0
+		q.warned = false
+	}
+
+	total := q.total()
+	if q.limit > 0 && total >= q.limit {
+		return ErrQuotaExhausted
+	}
+	q.calls[method]++
+	total++
+
+	if q.limit > 0 && !q.warned && float64(total) >= quotaWarningRatio*float64(q.limit) {
+		q.warned = true
+		log.Printf("Warning: %d of the daily Drive API quota of %d calls used", total, q.limit)
+	}
+	return nil
+}
+
+// AddRetries counts n calls that were retries, such as the retries taken from a run's retry
+// budget. They are already counted as calls; the retry count shows how many were spent on them.
+func (q *QuotaTracker) AddRetries(n int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.retries += n
+}
+
+// Retries returns the number of retries counted today
+func (q *QuotaTracker) Retries() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.retries
+}
+
+// Total returns the number of calls counted today
+func (q *QuotaTracker) Total() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.total()
+}
+
+func (q *QuotaTracker) total() int64 {
+	var total int64
+	for _, n := range q.calls {
+		total += n
+	}
+	return total
+}
+
+// Summary returns the calls counted today, such as
+// "API calls: 847 (Files.Get: 120, Files.Export: 500, Files.List: 200, Files.Copy: 15, Files.Delete: 12)".
+// Methods other than the file reads, copies and deletes are listed after them when called, and
+// retries are appended when there were any, such as ", retries: 12".
+func (q *QuotaTracker) Summary() string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var parts []string
+	listed := make(map[string]bool)
+	for _, method := range summaryMethods {
+		parts = append(parts, fmt.Sprintf("%s: %d", method, q.calls[method]))
+		listed[method] = true
+	}
+
+	var others []string
+	for method := range q.calls {
+		if !listed[method] {
+			others = append(others, method)
+		}
+	}
+	sort.Strings(others)
+	for _, method := range others {
+		parts = append(parts, fmt.Sprintf("%s: %d", method, q.calls[method]))
+	}
+
+	summary := fmt.Sprintf("API calls: %d (%s)", q.total(), strings.Join(parts, ", "))
+	if q.retries > 0 {
+		summary += fmt.Sprintf(", retries: %d", q.retries)
+	}
+	return summary
+}
+
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// quotaTransport counts each request against the tracker before sending it
+type quotaTransport struct {
+	base  http.RoundTripper
+	quota *QuotaTracker
+}
+
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.quota.record(apiMethod(req)); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withQuota returns client with its requests counted by quota. A nil tracker returns the
+// client unchanged.
+func withQuota(client *http.Client, quota *QuotaTracker) *http.Client {
+	if quota == nil {
+		return client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	counted := *client
+	counted.Transport = &quotaTransport{base: base, quota: quota}
+	return &counted
+}
+
+// apiMethod returns the Drive API method a request calls, such as "Files.Export", from its
+// HTTP method and path
+func apiMethod(req *http.Request) string {
+	path := strings.Trim(req.URL.Path, "/")
+	path = strings.TrimPrefix(path, "upload/")
+	path = strings.TrimPrefix(path, "drive/v3/")
+	segments := strings.Split(path, "/")
+
+	switch {
+	case segments[0] == "files" && len(segments) == 1:
+		if req.Method == http.MethodPost {
+			return "Files.Create"
+		}
+		return "Files.List"
+	case segments[0] == "files" && len(segments) == 2:
+		switch req.Method {
+		case http.MethodDelete:
+			return "Files.Delete"
+		case http.MethodPatch:
+			return "Files.Update"
+		default:
+			return "Files.Get"
+		}
+	case segments[0] == "files" && len(segments) >= 3:
+		switch segments[2] {
+		case "export":
+			return "Files.Export"
+		case "copy":
+			return "Files.Copy"
+		case "permissions":
+			return "Permissions.List"
+		}
+	case segments[0] == "about":
+		return "About.Get"
+	case segments[0] == "drives":
+		return "Drives.Get"
+	}
+	return "Other"
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+func TestAPIMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: "GET", path: "/drive/v3/files", want: "Files.List"},
+		{method: "GET", path: "/drive/v3/files/abc", want: "Files.Get"},
+		{method: "GET", path: "/drive/v3/files/abc/export", want: "Files.Export"},
+		{method: "POST", path: "/drive/v3/files/abc/copy", want: "Files.Copy"},
+		{method: "DELETE", path: "/drive/v3/files/abc", want: "Files.Delete"},
+		{method: "PATCH", path: "/drive/v3/files/abc", want: "Files.Update"},
+		{method: "POST", path: "/upload/drive/v3/files", want: "Files.Create"},
+		{method: "GET", path: "/drive/v3/files/abc/permissions", want: "Permissions.List"},
+		{method: "GET", path: "/drive/v3/about", want: "About.Get"},
+		{method: "GET", path: "/drive/v3/drives/xyz", want: "Drives.Get"},
+		{method: "GET", path: "/files/abc/export", want: "Files.Export"},
+		{method: "GET", path: "/drive/v3/changes", want: "Other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "https://www.googleapis.com"+tt.path, nil)
+			if got := apiMethod(req); got != tt.want {
+				t.Errorf("apiMethod() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotaTrackerLimit(t *testing.T) {
+	q := NewQuotaTracker(3)
+	for i := 0; i < 3; i++ {
+		if err := q.record("Files.Get"); err != nil {
+			t.Fatalf("record() call %d error = %v", i+1, err)
+		}
+	}
+	if !q.warned {
+		t.Errorf("no warning after using the whole quota")
+	}
+
+	if err := q.record("Files.Get"); !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("record() over the limit error = %v, want %v", err, ErrQuotaExhausted)
+	}
+	if got := q.Total(); got != 3 {
+		t.Errorf("Total() = %d, want 3 (rejected calls are not counted)", got)
+	}
+}
+
+func TestQuotaTrackerWarning(t *testing.T) {
+	q := NewQuotaTracker(10)
+	for i := 0; i < 7; i++ {
+		q.record("Files.List")
+	}
+	if q.warned {
+		t.Errorf("warned after 70%% of the quota")
+	}
+	q.record("Files.List")
+	if !q.warned {
+		t.Errorf("no warning after 80%% of the quota")
+	}
+}
+
+func TestQuotaTrackerUnlimited(t *testing.T) {
+	q := NewQuotaTracker(0)
+	for i := 0; i < 100; i++ {
+		if err := q.record("Files.Get"); err != nil {
+			t.Fatalf("record() error = %v, want no limit", err)
+		}
+	}
+	if q.warned {
+		t.Errorf("warned without a limit")
+	}
+}
+
+func TestQuotaTrackerSummary(t *testing.T) {
+	q := NewQuotaTracker(0)
+	counts := map[string]int{"Files.Get": 3, "Files.Export": 2, "Files.Delete": 1, "Permissions.List": 4, "About.Get": 1}
+	for method, n := range counts {
+		for i := 0; i < n; i++ {
+			q.record(method)
+		}
+	}
+
+	want := "API calls: 11 (Files.Get: 3, Files.Export: 2, Files.List: 0, Files.Copy: 0, Files.Delete: 1, About.Get: 1, Permissions.List: 4)"
+	if got := q.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	q.AddRetries(2)
+	want += ", retries: 2"
+	if got := q.Summary(); got != want {
+		t.Errorf("Summary() with retries = %q, want %q", got, want)
+	}
+}
+
+func TestQuotaTrackerSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+
+	q, err := LoadQuotaTracker(path, 0)
+	if err != nil {
+		t.Fatalf("LoadQuotaTracker() missing file error = %v", err)
+	}
+	q.record("Files.Get")
+	q.record("Files.Export")
+	q.AddRetries(1)
+	if err := q.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A later run on the same day continues the counts
+	next, err := LoadQuotaTracker(path, 0)
+	if err != nil {
+		t.Fatalf("LoadQuotaTracker() error = %v", err)
+	}
+	next.record("Files.Get")
+	if got := next.Total(); got != 3 {
+		t.Errorf("Total() = %d, want 3", got)
+	}
+	if got := next.Retries(); got != 1 {
+		t.Errorf("Retries() = %d, want 1", got)
+	}
+
+	// Counts from an earlier day are not carried over
+	stale := `{"date": "2000-01-01", "total": 5, "calls": {"Files.Get": 5}}`
+	if err := os.WriteFile(path, []byte(stale), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	fresh, err := LoadQuotaTracker(path, 0)
+	if err != nil {
+		t.Fatalf("LoadQuotaTracker() error = %v", err)
+	}
+	if got := fresh.Total(); got != 0 {
+		t.Errorf("Total() = %d, want 0 for a report from an earlier day", got)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadQuotaTracker(path, 0); err == nil {
+		t.Errorf("LoadQuotaTracker() invalid file error = nil, want error")
+	}
+}
+
+func TestWithQuotaCountsDriveCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	q := NewQuotaTracker(0)
+	srv, err := drive.NewService(context.Background(),
+		option.WithHTTPClient(withQuota(server.Client(), q)),
+		option.WithEndpoint(server.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create Drive service: %v", err)
+	}
+
+	srv.Files.List().Do()
+	srv.Files.Get("doc1").Do()
+	srv.Files.Copy("doc1", &drive.File{}).Do()
+	srv.Files.Delete("doc1").Do()
+	if resp, err := srv.Files.Export("doc1", "text/markdown").Download(); err == nil {
+		resp.Body.Close()
+	}
+
+	want := "API calls: 5 (Files.Get: 1, Files.Export: 1, Files.List: 1, Files.Copy: 1, Files.Delete: 1)"
+	if got := q.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
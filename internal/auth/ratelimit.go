@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// RateLimiter paces Drive API requests
+type RateLimiter interface {
+	// Wait blocks until the next request may be sent or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// SharedDriveService is a Drive service whose requests are paced by one rate limiter, so every
+// Syncer and Converter created from it draws on the same request budget
+type SharedDriveService interface {
+	RateLimiter
+	Drive() *drive.Service
+}
+
+// IntervalLimiter allows one request per fixed interval. It is safe for concurrent use.
+type IntervalLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time // Earliest time the next request may be sent
+}
+
+// NewIntervalLimiter creates a limiter that allows requestsPerSecond requests per second
+func NewIntervalLimiter(requestsPerSecond float64) (*IntervalLimiter, error) {
+	if requestsPerSecond <= 0 {
+		return nil, fmt.Errorf("requests per second must be positive, got %v", requestsPerSecond)
+	}
+	return &IntervalLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}, nil
+}
+
+// Wait reserves the next free slot and blocks until it is reached. A cancelled wait keeps its
+// slot, so cancellation never lets other requests through early.
+func (l *IntervalLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimitedTransport waits for the limiter before sending each request
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter RateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withRateLimit returns client with its requests paced by limiter. A nil limiter returns the
+// client unchanged.
+func withRateLimit(client *http.Client, limiter RateLimiter) *http.Client {
+	if limiter == nil {
+		return client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	limited := *client
+	limited.Transport = &rateLimitedTransport{base: base, limiter: limiter}
+	return &limited
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewIntervalLimiter(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestsPerSecond float64
+		wantInterval      time.Duration
+		wantErr           bool
+	}{
+		{name: "ten per second", requestsPerSecond: 10, wantInterval: 100 * time.Millisecond},
+		{name: "fractional", requestsPerSecond: 0.5, wantInterval: 2 * time.Second},
+		{name: "zero", requestsPerSecond: 0, wantErr: true},
+		{name: "negative", requestsPerSecond: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := NewIntervalLimiter(tt.requestsPerSecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIntervalLimiter(%v) error = %v, wantErr %v", tt.requestsPerSecond, err, tt.wantErr)
+			}
+			if err == nil && limiter.interval != tt.wantInterval {
+				t.Errorf("interval = %v, want %v", limiter.interval, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestIntervalLimiterPacesConcurrentWaiters(t *testing.T) {
+	limiter, err := NewIntervalLimiter(100) // One request per 10ms
+	if err != nil {
+		t.Fatalf("NewIntervalLimiter() error = %v", err)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first request goes immediately, the other four wait one interval each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("5 requests took %v, want at least 40ms", elapsed)
+	}
+}
+
+func TestIntervalLimiterWaitCancelled(t *testing.T) {
+	limiter, err := NewIntervalLimiter(0.1) // One request per 10s
+	if err != nil {
+		t.Fatalf("NewIntervalLimiter() error = %v", err)
+	}
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// countingLimiter records how often Wait is called
+type countingLimiter struct {
+	mu    sync.Mutex
+	waits int
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.waits++
+	return nil
+}
+
+func TestWithRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if client := withRateLimit(server.Client(), nil); client != server.Client() {
+		t.Errorf("withRateLimit() with a nil limiter returned a different client")
+	}
+
+	// Two services built on one limiter share it
+	limiter := &countingLimiter{}
+	first := withRateLimit(server.Client(), limiter)
+	second := withRateLimit(server.Client(), limiter)
+	for _, client := range []*http.Client{first, second, first} {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if limiter.waits != 3 {
+		t.Errorf("limiter waited %d times, want 3", limiter.waits)
+	}
+}
+
+func TestWithRateLimitAcrossWorkers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("takes 4.5s")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	limiter, err := NewIntervalLimiter(2)
+	if err != nil {
+		t.Fatalf("NewIntervalLimiter() error = %v", err)
+	}
+
+	// Ten workers with their own clients, like converter workers sharing one DriveService
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := withRateLimit(server.Client(), limiter).Get(server.URL)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	// The first request goes immediately, the other nine wait half a second each
+	if elapsed := time.Since(start); elapsed < 4*time.Second {
+		t.Errorf("10 requests at 2 per second took %v, want at least 4s", elapsed)
+	}
+}
+
+func TestDriveServiceWaitUnlimited(t *testing.T) {
+	ds := &DriveService{ctx: context.Background()}
+	if err := ds.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() error = %v, want nil without a limiter", err)
+	}
+	var _ SharedDriveService = ds
+}
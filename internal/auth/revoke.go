@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// revokeEndpoint is Google's OAuth2 token revocation endpoint
+var revokeEndpoint = "https://oauth2.googleapis.com/revoke"
+
+// TokenPath returns the path of the saved OAuth2 token file, using override when it is set
+func TokenPath(override string) (string, error) {
+	return getTokenPath(override)
+}
+
+// RevokeSavedToken revokes the OAuth2 token saved at tokenPath and deletes the token file.
+// A failed revocation (already revoked, network error) is reported as a warning and the file
+// is still deleted. It returns false if there is no saved token.
+func RevokeSavedToken(ctx context.Context, tokenPath string) (bool, error) {
+	token, err := loadToken(tokenPath)
+	if err != nil {
+		return false, err
+	}
+	if token == nil {
+		return false, nil
+	}
+
+	// Revoking the refresh token also invalidates the access tokens issued from it
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+	if err := revokeToken(ctx, value); err != nil {
+		fmt.Printf("Warning: Failed to revoke token, deleting it locally anyway: %v\n", err)
+	}
+
+	if err := os.Remove(tokenPath); err != nil {
+		return false, fmt.Errorf("failed to delete token file: %w", err)
+	}
+
+	return true, nil
+}
+
+// revokeToken invalidates token at Google's revocation endpoint
+func revokeToken(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeEndpoint+"?token="+url.QueryEscape(token), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create revoke request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revoke request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revoke request returned %s", resp.Status)
+	}
+
+	return nil
+}
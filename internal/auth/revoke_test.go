@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestRevokeSavedToken(t *testing.T) {
+	tests := []struct {
+		name         string
+		token        *oauth2.Token
+		revokeStatus int
+		wantRevoked  bool
+		wantToken    string
+	}{
+		{
+			name:         "refresh token revoked",
+			token:        &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"},
+			revokeStatus: http.StatusOK,
+			wantRevoked:  true,
+			wantToken:    "refresh",
+		},
+		{
+			name:         "access token only",
+			token:        &oauth2.Token{AccessToken: "access"},
+			revokeStatus: http.StatusOK,
+			wantRevoked:  true,
+			wantToken:    "access",
+		},
+		{
+			name:         "already revoked still deletes",
+			token:        &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"},
+			revokeStatus: http.StatusBadRequest,
+			wantRevoked:  true,
+			wantToken:    "refresh",
+		},
+		{
+			name: "no saved token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+
+			var gotToken string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("revoke method = %s, want POST", r.Method)
+				}
+				gotToken = r.URL.Query().Get("token")
+				w.WriteHeader(tt.revokeStatus)
+			}))
+			t.Cleanup(server.Close)
+
+			oldEndpoint := revokeEndpoint
+			revokeEndpoint = server.URL
+			t.Cleanup(func() { revokeEndpoint = oldEndpoint })
+
+			tokenPath, err := TokenPath("")
+			if err != nil {
+				t.Fatalf("TokenPath() error = %v", err)
+			}
+			if tt.token != nil {
+				if err := saveToken(tt.token, tokenPath); err != nil {
+					t.Fatalf("saveToken() error = %v", err)
+				}
+			}
+
+			revoked, err := RevokeSavedToken(context.Background(), tokenPath)
+			if err != nil {
+				t.Fatalf("RevokeSavedToken() error = %v", err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("RevokeSavedToken() = %v, want %v", revoked, tt.wantRevoked)
+			}
+			if gotToken != tt.wantToken {
+				t.Errorf("revoked token = %q, want %q", gotToken, tt.wantToken)
+			}
+
+			if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+				t.Errorf("token file still exists: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds flag values read from a YAML configuration file. Keys are flag names without the
+// leading dash, such as "workers: 10" or "dry-run: true", so every flag of a command can be set
+// from the file. A list sets a repeatable flag once per item.
+type Config struct {
+	Path   string
+	values []value
+}
+
+// value is one key of the configuration file
+type value struct {
+	key   string
+	items []string
+	line  int
+}
+
+// Load reads a YAML configuration file of flag names and values
+func Load(filePath string) (*Config, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	config := &Config{Path: filePath}
+	if len(doc.Content) == 0 {
+		return config, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d: config must be a mapping of flag names to values", filePath, root.Line)
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+		if keyNode.Kind != yaml.ScalarNode || keyNode.Value == "" {
+			return nil, fmt.Errorf("%s:%d: config keys must be flag names", filePath, keyNode.Line)
+		}
+		if seen[keyNode.Value] {
+			return nil, fmt.Errorf("%s:%d: duplicate key %q", filePath, keyNode.Line, keyNode.Value)
+		}
+		seen[keyNode.Value] = true
+
+		items, err := scalarValues(valueNode)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %w", filePath, valueNode.Line, keyNode.Value, err)
+		}
+		config.values = append(config.values, value{key: keyNode.Value, items: items, line: keyNode.Line})
+	}
+
+	return config, nil
+}
+
+// scalarValues returns the value of a scalar node, or the items of a list of scalars
+func scalarValues(node *yaml.Node) ([]string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil, fmt.Errorf("value must not be empty")
+		}
+		return []string{node.Value}, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode || item.Tag == "!!null" {
+				return nil, fmt.Errorf("list items must be plain values")
+			}
+			items = append(items, item.Value)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("value must be a plain value or a list")
+	}
+}
+
+// Apply sets the flags of fs from the configuration file. Flags already given on the command
+// line are left alone, so they override the file. Keys that are not flags of fs are an error.
+func (c *Config) Apply(fs *flag.FlagSet) error {
+	setOnCommandLine := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+
+	for _, v := range c.values {
+		if v.key == "config" || fs.Lookup(v.key) == nil {
+			return fmt.Errorf("%s:%d: unknown flag %q for %s", c.Path, v.line, v.key, fs.Name())
+		}
+		if setOnCommandLine[v.key] {
+			continue
+		}
+		for _, item := range v.items {
+			if err := fs.Set(v.key, item); err != nil {
+				return fmt.Errorf("%s:%d: invalid value %q for %s: %w", c.Path, v.line, item, v.key, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listValue is a repeatable flag collecting every value it is set to
+type listValue []string
+
+func (l *listValue) String() string     { return strings.Join(*l, ",") }
+func (l *listValue) Set(v string) error { *l = append(*l, v); return nil }
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestApplyOverridesDefaults(t *testing.T) {
+	path := writeConfig(t, `# convert settings
+input: records.csv
+workers: 10
+dry-run: true
+base-delay: 2s
+owner-email:
+  - a@example.com
+  - b@example.com
+verbose: true
+`)
+
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	input := fs.String("input", "", "")
+	output := fs.String("output", "./output", "")
+	workers := fs.Int("workers", 1, "")
+	dryRun := fs.Bool("dry-run", false, "")
+	baseDelay := fs.Duration("base-delay", time.Second, "")
+	verbose := fs.Bool("verbose", false, "")
+	var owners listValue
+	fs.Var(&owners, "owner-email", "")
+
+	// Flags on the command line win over the file
+	if err := fs.Parse([]string{"-workers", "3", "-verbose=false"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	config, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := config.Apply(fs); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if *input != "records.csv" {
+		t.Errorf("input = %q, want %q", *input, "records.csv")
+	}
+	if *output != "./output" {
+		t.Errorf("output = %q, want the default %q", *output, "./output")
+	}
+	if *workers != 3 {
+		t.Errorf("workers = %d, want the command line value 3", *workers)
+	}
+	if !*dryRun {
+		t.Error("dry-run = false, want true")
+	}
+	if *baseDelay != 2*time.Second {
+		t.Errorf("base-delay = %v, want 2s", *baseDelay)
+	}
+	if *verbose {
+		t.Error("verbose = true, want the command line value false")
+	}
+	if got := owners.String(); got != "a@example.com,b@example.com" {
+		t.Errorf("owner-email = %q, want both list items", got)
+	}
+}
+
+func TestLoadEmpty(t *testing.T) {
+	config, err := Load(writeConfig(t, "# nothing yet\n"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	fs := flag.NewFlagSet("discover", flag.ContinueOnError)
+	if err := config.Apply(fs); err != nil {
+		t.Errorf("Apply() error = %v", err)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not a mapping", content: "- workers\n", wantErr: "must be a mapping"},
+		{name: "duplicate key", content: "workers: 1\nworkers: 2\n", wantErr: `duplicate key "workers"`},
+		{name: "empty value", content: "workers:\n", wantErr: "must not be empty"},
+		{name: "nested value", content: "workers:\n  count: 2\n", wantErr: "plain value or a list"},
+		{name: "unknown flag", content: "wokers: 2\n", wantErr: `unknown flag "wokers" for convert`},
+		{name: "config key", content: "config: other.yaml\n", wantErr: `unknown flag "config"`},
+		{name: "invalid value", content: "workers: many\n", wantErr: `invalid value "many" for workers`},
+		{name: "invalid YAML", content: "workers: [1\n", wantErr: "failed to parse config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+			fs.Int("workers", 1, "")
+			fs.String("config", "", "")
+
+			config, err := Load(writeConfig(t, tt.content))
+			if err == nil {
+				err = config.Apply(fs)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
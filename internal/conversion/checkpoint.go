@@ -0,0 +1,130 @@
+package conversion
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// Checkpoint lists the records converted so far, one link per line followed by the paths of
+// the files written for it, separated by tabs, so an interrupted run can skip them when it is
+// started again. It is safe for concurrent use.
+type Checkpoint struct {
+	mu    sync.Mutex
+	file  *os.File
+	done  map[string]bool // Keyed by LinkKey
+	paths []string        // Files written for the listed records
+}
+
+// OpenCheckpoint reads the links listed in the checkpoint file at path, if it exists, and opens
+// it for appending the records converted from now on
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	cp := &Checkpoint{file: file, done: make(map[string]bool)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines written before paths were listed hold only the link
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), "\t")
+		if fields[0] == "" {
+			continue
+		}
+		cp.done[LinkKey(fields[0])] = true
+		cp.paths = append(cp.paths, fields[1:]...)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	return cp, nil
+}
+
+// Done reports whether the record with link is listed in the checkpoint
+func (cp *Checkpoint) Done(link string) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.done[LinkKey(link)]
+}
+
+// Paths returns the paths of the files written for the records listed when the checkpoint was
+// opened
+func (cp *Checkpoint) Paths() []string {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return slices.Clone(cp.paths)
+}
+
+// Add lists the record with link in the checkpoint file, with the paths of its files
+func (cp *Checkpoint) Add(link string, paths []string) error {
+	key := LinkKey(link)
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.done[key] {
+		return nil
+	}
+	// The file is unbuffered, so a listed record survives the process being killed
+	line := strings.Join(append([]string{key}, paths...), "\t")
+	if _, err := cp.file.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	cp.done[key] = true
+	return nil
+}
+
+// Close closes the checkpoint file
+func (cp *Checkpoint) Close() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.file.Close()
+}
+
+// recordConverted lists a record whose files were all written in the run's checkpoint, if any
+func (c *Converter) recordConverted(record *csv.ConversionRecord, jobs []WriteJob) {
+	if c.checkpoint == nil {
+		return
+	}
+	paths := make([]string, len(jobs))
+	for i, job := range jobs {
+		paths[i] = job.path
+	}
+	if err := c.checkpoint.Add(record.Link, paths); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// pendingRecords returns the records not listed in the run's checkpoint, or all records
+// without one. The files written for the listed records are reserved, so records sharing a
+// title with them are numbered instead of overwriting them.
+func (c *Converter) pendingRecords(records []csv.ConversionRecord) []*csv.ConversionRecord {
+	if c.checkpoint != nil {
+		c.mu.Lock()
+		for _, path := range c.checkpoint.Paths() {
+			c.existingPaths[path] = true
+		}
+		c.mu.Unlock()
+	}
+
+	pending := make([]*csv.ConversionRecord, 0, len(records))
+	for i := range records {
+		if c.checkpoint != nil && c.checkpoint.Done(records[i].Link) {
+			if c.verbose {
+				log.Printf("Skipping %s: converted in an earlier run", records[i].Title)
+			}
+			continue
+		}
+		pending = append(pending, &records[i])
+	}
+	return pending
+}
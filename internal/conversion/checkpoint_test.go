@@ -0,0 +1,156 @@
+package conversion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestCheckpointAddAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.txt")
+	if err := os.WriteFile(path, []byte("https://docs.google.com/document/d/doc1/edit\n\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cp, err := OpenCheckpoint(path)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	if !cp.Done("https://docs.google.com/document/d/doc1/edit?usp=sharing") {
+		t.Error("Done(doc1) = false, want true for a listed link with sharing parameters")
+	}
+
+	// Workers add records concurrently
+	var wg sync.WaitGroup
+	for _, id := range []string{"doc2", "doc3", "doc2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cp.Add("https://docs.google.com/document/d/"+id+"/edit", []string{filepath.Join("out", id+".md")}); err != nil {
+				t.Errorf("Add(%s) error = %v", id, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := cp.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != 4 {
+		t.Errorf("checkpoint has %d lines, want 4 (one per record plus the blank line):\n%s", lines, data)
+	}
+
+	reopened, err := OpenCheckpoint(path)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	defer reopened.Close()
+	for _, id := range []string{"doc1", "doc2", "doc3"} {
+		if !reopened.Done("https://docs.google.com/document/d/" + id + "/edit") {
+			t.Errorf("Done(%s) = false after reopening, want true", id)
+		}
+	}
+	if reopened.Done("https://docs.google.com/document/d/doc4/edit") {
+		t.Error("Done(doc4) = true, want false")
+	}
+	paths := reopened.Paths()
+	sort.Strings(paths)
+	if want := []string{filepath.Join("out", "doc2.md"), filepath.Join("out", "doc3.md")}; !slices.Equal(paths, want) {
+		t.Errorf("Paths() = %q, want %q", paths, want)
+	}
+}
+
+func TestConvertResumesFromCheckpoint(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/forms/d/form1/viewform", Title: "Form 1"},
+		{Link: "https://docs.google.com/forms/d/form2/viewform", Title: "Form 2", Fragments: []string{"Guides"}},
+		{Link: "https://docs.google.com/forms/d/form3/viewform", Title: "Form 3"},
+	}
+	outputDir := t.TempDir()
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.txt")
+
+	// A run interrupted after the first two records
+	c := newTestConverter(t, newFakeDrive(), outputDir, Options{CheckpointPath: checkpointPath})
+	if err := c.Convert(context.Background(), records[:2], 2); err != nil {
+		t.Fatalf("first Convert() error = %v", err)
+	}
+
+	// Files of the first run must not be written again
+	firstRun := filepath.Join(outputDir, "form-1.md")
+	if err := os.WriteFile(firstRun, []byte("edited after the first run"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "manifest.ndjson")
+	c = newTestConverter(t, newFakeDrive(), outputDir, Options{CheckpointPath: checkpointPath, ManifestPath: manifestPath})
+	if err := c.Convert(context.Background(), records, 2); err != nil {
+		t.Fatalf("resumed Convert() error = %v", err)
+	}
+
+	written := readNDJSONManifest(t, manifestPath)
+	if len(written) != 1 || written[0].Path != "form-3.md" {
+		t.Errorf("resumed run wrote %+v, want only form-3.md", written)
+	}
+	if data, _ := os.ReadFile(firstRun); string(data) != "edited after the first run" {
+		t.Errorf("form-1.md was written again: %q", data)
+	}
+
+	data, err := os.ReadFile(checkpointPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		link, _, _ := strings.Cut(line, "\t")
+		lines = append(lines, link)
+	}
+	sort.Strings(lines)
+	want := []string{LinkKey(records[0].Link), LinkKey(records[1].Link), LinkKey(records[2].Link)}
+	sort.Strings(want)
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("checkpoint = %q, want each record once", lines)
+	}
+}
+
+func TestConvertResumeKeepsFilesOfSameTitle(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/forms/d/form1/viewform", Title: "Intro"},
+		{Link: "https://docs.google.com/forms/d/form2/viewform", Title: "Intro"},
+	}
+	outputDir := t.TempDir()
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.txt")
+
+	// A run interrupted before the second record
+	c := newTestConverter(t, newFakeDrive(), outputDir, Options{CheckpointPath: checkpointPath})
+	if err := c.Convert(context.Background(), records[:1], 1); err != nil {
+		t.Fatalf("first Convert() error = %v", err)
+	}
+	first, err := os.ReadFile(filepath.Join(outputDir, "intro.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	c = newTestConverter(t, newFakeDrive(), outputDir, Options{CheckpointPath: checkpointPath})
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("resumed Convert() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(outputDir, "intro.md")); string(data) != string(first) {
+		t.Errorf("intro.md was overwritten by the resumed run:\n%s", data)
+	}
+	second, err := os.ReadFile(filepath.Join(outputDir, "intro_1.md"))
+	if err != nil {
+		t.Fatalf("resumed run did not number the second record: %v", err)
+	}
+	if !strings.Contains(string(second), "form2") {
+		t.Errorf("intro_1.md = %q, want the second record", second)
+	}
+}
@@ -0,0 +1,72 @@
+package conversion
+
+import (
+	"regexp"
+	"strings"
+)
+
+// commentOrCodeSpan matches an inline code span or an HTML comment. Code spans are matched
+// so that comment syntax inside them is left alone.
+var commentOrCodeSpan = regexp.MustCompile("`[^`\n]+`|<!--[\\s\\S]*?-->")
+
+// StripHTMLComments removes HTML comments (<!-- ... -->), including multiline ones, from
+// markdown. Comments inside fenced code blocks and inline code spans are preserved.
+func StripHTMLComments(content string) string {
+	var out, text strings.Builder
+	var fence string
+
+	flushText := func() {
+		out.WriteString(commentOrCodeSpan.ReplaceAllStringFunc(text.String(), func(match string) string {
+			if strings.HasPrefix(match, "`") {
+				return match
+			}
+			return ""
+		}))
+		text.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		marker := fenceMarker(line)
+
+		switch {
+		case fence == "" && marker != "":
+			// Opening fence: strip the text before it, then copy the block verbatim
+			flushText()
+			fence = marker
+			out.WriteString(line)
+		case fence != "":
+			out.WriteString(line)
+			if marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) {
+				fence = ""
+			}
+		default:
+			text.WriteString(line)
+		}
+	}
+	flushText()
+
+	return out.String()
+}
+
+// fenceMarker returns the run of backticks or tildes that opens or closes a fenced code
+// block on line, or "" if line is not a fence
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return ""
+	}
+
+	char := trimmed[0]
+	if char != '`' && char != '~' {
+		return ""
+	}
+
+	n := 0
+	for n < len(trimmed) && trimmed[n] == char {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return trimmed[:n]
+}
@@ -0,0 +1,104 @@
+package conversion
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+func TestStripHTMLComments(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "single line comment",
+			content: "Before <!-- suggestion: reword --> after\n",
+			want:    "Before  after\n",
+		},
+		{
+			name:    "multiline comment",
+			content: "Intro\n<!--\nreviewer note\nspanning lines\n-->\nBody\n",
+			want:    "Intro\n\nBody\n",
+		},
+		{
+			name:    "multiple comments are stripped separately",
+			content: "a<!-- 1 -->b<!-- 2 -->c",
+			want:    "abc",
+		},
+		{
+			name:    "comment in fenced code block preserved",
+			content: "Example:\n\n```html\n<!-- this is an HTML comment -->\n<p>hi</p>\n```\n<!-- real comment -->Done\n",
+			want:    "Example:\n\n```html\n<!-- this is an HTML comment -->\n<p>hi</p>\n```\nDone\n",
+		},
+		{
+			name:    "comment in tilde fence preserved",
+			content: "~~~\n<!-- kept -->\n~~~\n",
+			want:    "~~~\n<!-- kept -->\n~~~\n",
+		},
+		{
+			name:    "shorter fence does not close block",
+			content: "````\n```\n<!-- kept -->\n````\n<!-- removed -->",
+			want:    "````\n```\n<!-- kept -->\n````\n",
+		},
+		{
+			name:    "comment in inline code preserved",
+			content: "Use `<!-- note -->` to hide text<!-- hidden -->.",
+			want:    "Use `<!-- note -->` to hide text.",
+		},
+		{
+			name:    "unterminated comment left alone",
+			content: "Text <!-- never closed",
+			want:    "Text <!-- never closed",
+		},
+		{
+			name:    "no comments",
+			content: "# Title\n\nPlain content",
+			want:    "# Title\n\nPlain content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripHTMLComments(tt.content); got != tt.want {
+				t.Errorf("StripHTMLComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRecordStripComments(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc123", jsonHandler(`{"id":"doc123","mimeType":"application/vnd.google-apps.document","modifiedTime":"2024-01-15T10:30:00.000Z"}`))
+	fake.handle("GET", "/files/doc123/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Text<!-- reviewer: fix this -->\n\n```\n<!-- example -->\n```\n"))
+	})
+
+	outputDir := t.TempDir()
+	c := newTestConverter(t, fake, outputDir, Options{StripComments: true})
+
+	record := &csv.ConversionRecord{Title: "Doc", Link: "https://docs.google.com/document/d/doc123/edit"}
+	if err := c.convertRecord(record); err != nil {
+		t.Fatalf("convertRecord() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "doc.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	wantBody := "> Link: " + record.Link + "\n\nText\n\n```\n<!-- example -->\n```\n"
+	_, body, _ := strings.Cut(string(content), "---\n\n")
+	if body != wantBody {
+		t.Errorf("body = %q, want %q", body, wantBody)
+	}
+	if !strings.Contains(string(content), "hash-content: "+utils.CalculateStringHash(wantBody)+"\n") {
+		t.Errorf("hash-content not calculated on stripped content:\n%s", content)
+	}
+}
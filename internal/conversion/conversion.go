@@ -1,21 +1,29 @@
 package conversion
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
-	"time"
+	"text/template"
 
 	"github.com/ledongthuc/pdf"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
 
 	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/progress"
+	"github.com/yourusername/webscrape-to-wikijs/internal/tags"
 	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
 )
 
@@ -25,77 +33,291 @@ type Converter struct {
 	outputDir     string
 	verbose       bool
 	dryRun        bool
-	linkMap       map[string]*csv.ConversionRecord // Maps file ID to record
-	existingPaths map[string]bool
+	opts          Options
+	linkMap       map[string]*csv.ConversionRecord            // Maps file ID to record
+	existingPaths map[string]bool                             // Output paths taken by this run or, when resuming, earlier runs
+	metadata      *FileMetadataCache                          // File metadata fetched during this run
+	pipeline      *ContentPipeline                            // Post-processing applied to exported content
+	pendingWrites map[*csv.ConversionRecord][]WriteJob        // Files queued for the I/O workers during Convert (nil = write directly)
+	manifest      *Manifest                                   // Lists the files written during Convert (nil = none)
+	checkpoint    *Checkpoint                                 // Lists the records converted during Convert and earlier runs (nil = none)
+	images        map[string]*imageDownload                   // Embedded image downloads, keyed by assets directory and URL
+	languageDocs  map[*csv.ConversionRecord]*languageDocument // Documents written below a language directory, with SplitByLanguage
+	ctx           context.Context                             // Context of the running Convert (nil = context.Background())
 	mu            sync.Mutex
 }
 
+// Options holds optional conversion settings
+type Options struct {
+	TagSeparator       string              // Separator used to split tags (empty = auto-detect)
+	PreserveTagCase    bool                // Keep the case of CSV tags instead of lowercasing them
+	RoutingRules       []RoutingRule       // Per-tag output directory routing rules
+	RoutingStrategy    string              // "first-match" (default) or "all-matching"
+	TagHierarchy       map[string][]string // Maps a tag to parent tags added to frontmatter
+	AutoTagFromFrags   bool                // Add a tag for each non-empty fragment
+	AutoTagPrefix      string              // Prepended to tags generated from fragments
+	TempFolderID       string              // Parent folder for temporary conversion copies (empty = Drive root)
+	TempFilePrefix     string              // Name prefix for temporary conversion copies
+	UseACLForPublished bool                // Set published only for files shared with anyone as reader
+	FrontmatterFormat  FrontmatterFormat   // Frontmatter syntax (empty = YAML)
+	FrontmatterExtra   map[string]string   // Extra fields written after the standard ones; cannot replace them
+	NoFrontmatter      bool                // Write only the content body without frontmatter
+	DescriptionSource  DescriptionSource   // What the description field is taken from (empty = DescriptionFromFirstParagraph)
+	TitlePrefix        string              // Prepended to the frontmatter title
+	TitleSuffix        string              // Appended to the frontmatter title
+	PrefixInFilename   bool                // Also apply TitlePrefix and TitleSuffix to output filenames
+	SourceLinkTemplate *template.Template  // Renders a source link line placed first in the content (nil = none)
+	StripComments      bool                // Remove HTML comments from exported content
+	Retry              utils.RetryConfig   // Backoff for rate-limited and failing API calls (zero value = utils.DefaultRetryConfig)
+	MaxErrors          int                 // Abort after this many failed records (0 = unlimited)
+	FailedOutput       string              // CSV file for failed and unprocessed records (empty = none)
+	SlidesPreview      bool                // Embed a PNG preview of the first slide in presentation stubs
+	SheetsToTable      bool                // Write Google Sheets as markdown tables read with the Sheets API instead of stubs
+	Sheets             *sheets.Service     // Sheets API service used by SheetsToTable
+	ExtractPDFImages   bool                // Save an image of each page of PDFs converted locally and reference it before the page's text
+	PDFWorkers         int                 // Parallel workers per PDF for MuPDF conversion (0 = min(4, pages/10))
+	ExportBackend      ExportBackend       // How Google Docs are converted to markdown (empty = google)
+	PandocPath         string              // Pandoc binary for the pandoc backend (empty = DefaultPandocPath)
+	ExportFormat       ExportFormat        // Format Google Docs are exported in (empty = markdown)
+	HTMLToMarkdown     bool                // With ExportFormatHTML, convert the HTML to markdown instead of writing .html files
+	HashFunc           utils.HashFunc      // Hash for the hash-content field (nil = utils.CalculateContentHash)
+	SplitByFrag1       bool                // Write every record under a frag1 directory (empty frag1 = utils.UncategorizedDir)
+	SplitByFrag2       bool                // Also split by frag2 within each frag1 directory (implies SplitByFrag1)
+	Transformer        Transformer         // Output format of written documents (nil = MarkdownTransformer)
+	PipelineDebug      bool                // Log the content length after each post-processing stage
+	DetectLanguage     bool                // Write the detected body language to a language frontmatter field
+	LanguageConfidence float64             // Confidence below which the language is "und" (0 = DefaultLanguageConfidence)
+	SplitByLanguage    bool                // Write documents under a directory named after their detected language
+	IOWorkers          int                 // Workers writing output files, separate from the API workers (0 = runtime.NumCPU())
+	ManifestPath       string              // File listing each file written by Convert as it is written (empty = none)
+	ManifestFormat     ManifestFormat      // Syntax of the manifest (empty = ManifestNDJSON)
+	CheckpointPath     string              // File of converted record links; listed records are skipped (empty = none)
+	EmbedImages        bool                // Download images referenced by URL to an assets directory and link them relatively
+	ImageClient        *http.Client        // Client downloading embedded images (nil = a client with DefaultImageTimeout)
+
+	// Progress reports each completed record (nil = silent)
+	Progress *progress.ProgressReporter
+}
+
+// DefaultTempFilePrefix is the name prefix used for temporary conversion copies
+const DefaultTempFilePrefix = "gdrive_tmp_"
+
 // NewConverter creates a new Converter
-func NewConverter(service *drive.Service, outputDir string, verbose, dryRun bool) *Converter {
-	return &Converter{
+func NewConverter(service *drive.Service, outputDir string, verbose, dryRun bool, opts Options) *Converter {
+	if opts.Retry == (utils.RetryConfig{}) {
+		opts.Retry = utils.DefaultRetryConfig()
+	}
+	if opts.HashFunc == nil {
+		opts.HashFunc = utils.CalculateContentHash
+	}
+	if opts.Transformer == nil {
+		opts.Transformer = MarkdownTransformer{}
+	}
+	if opts.LanguageConfidence == 0 {
+		opts.LanguageConfidence = DefaultLanguageConfidence
+	}
+	if opts.IOWorkers == 0 {
+		opts.IOWorkers = runtime.NumCPU()
+	}
+	if opts.ImageClient == nil {
+		opts.ImageClient = &http.Client{Timeout: DefaultImageTimeout}
+	}
+
+	c := &Converter{
 		service:       service,
 		outputDir:     outputDir,
 		verbose:       verbose,
 		dryRun:        dryRun,
+		opts:          opts,
 		linkMap:       make(map[string]*csv.ConversionRecord),
 		existingPaths: make(map[string]bool),
+		metadata:      NewFileMetadataCache(),
+		images:        make(map[string]*imageDownload),
+		languageDocs:  make(map[*csv.ConversionRecord]*languageDocument),
 	}
+	c.pipeline = c.buildPipeline()
+
+	return c
 }
 
-// Convert converts all records to markdown files
-func (c *Converter) Convert(records []csv.ConversionRecord, workers int) error {
-	// Build link map for O(1) lookup - index by both URL and file ID
-	for i := range records {
-		// Index by the exact URL from CSV
-		c.linkMap[records[i].Link] = &records[i]
+// Convert converts all records to markdown files. Once ctx is cancelled no further records are
+// started, requests in flight are cancelled, and the records left are reported as not processed.
+func (c *Converter) Convert(ctx context.Context, records []csv.ConversionRecord, workers int) error {
+	c.ctx = ctx
+	defer func() { c.ctx = nil }()
 
-		// Also index by file ID for cross-format matching
-		fileID, err := utils.ExtractFileID(records[i].Link)
+	c.IndexRecords(records)
+
+	if c.opts.ManifestPath != "" && !c.dryRun {
+		manifest, err := OpenManifest(c.opts.ManifestPath, c.opts.ManifestFormat)
 		if err != nil {
-			log.Printf("Warning: failed to extract file ID from %s: %v", records[i].Link, err)
-			continue
+			return err
 		}
-		c.linkMap[fileID] = &records[i]
+		c.manifest = manifest
+		defer func() {
+			if err := manifest.Close(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			c.manifest = nil
+		}()
+	}
+
+	if c.opts.CheckpointPath != "" && !c.dryRun {
+		checkpoint, err := OpenCheckpoint(c.opts.CheckpointPath)
+		if err != nil {
+			return err
+		}
+		c.checkpoint = checkpoint
+		defer func() {
+			if err := checkpoint.Close(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			c.checkpoint = nil
+		}()
+	}
+
+	// Records converted by an earlier run stay in the link map, so links to them are rewritten
+	pending := c.pendingRecords(records)
+	if skipped := len(records) - len(pending); skipped > 0 {
+		log.Printf("Skipping %d records listed in checkpoint %s", skipped, c.opts.CheckpointPath)
+	}
+
+	if c.opts.Progress != nil {
+		c.opts.Progress.Start(len(pending))
+		defer c.opts.Progress.Finish()
 	}
 
-	// Create worker pool
-	jobs := make(chan *csv.ConversionRecord, len(records))
-	results := make(chan error, len(records))
+	// Queue output files for the I/O workers, so API workers never wait on slow disks
+	c.pendingWrites = make(map[*csv.ConversionRecord][]WriteJob)
+	defer func() { c.pendingWrites = nil }()
 
-	// Start workers
+	// Create worker pools
+	jobs := make(chan *csv.ConversionRecord)
+	batches := make(chan writeBatch, c.opts.IOWorkers)
+	results := make(chan recordResult, workers)
+	abort := make(chan struct{})
+
+	// Start I/O workers; converted records are reported once their files are written
+	var ioWG sync.WaitGroup
+	for i := 0; i < c.opts.IOWorkers; i++ {
+		ioWG.Add(1)
+		go func() {
+			defer ioWG.Done()
+			c.writeBatches(batches, results)
+		}()
+	}
+
+	// Start conversion workers
 	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			for record := range jobs {
+				if ctx.Err() != nil {
+					results <- recordResult{record: record, cancelled: true}
+					continue
+				}
 				err := c.convertRecord(record)
+				writes := c.takeWrites(record)
+				if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+					results <- recordResult{record: record, cancelled: true}
+					continue
+				}
 				if err != nil {
 					log.Printf("Error: %s", err)
+					results <- recordResult{record: record, err: err}
+					continue
 				}
-				results <- err
+				// Blocks only while the I/O workers are busy and the queue is full
+				batches <- writeBatch{record: record, jobs: writes}
 			}
 		}()
 	}
 
-	// Send jobs
-	for i := range records {
-		jobs <- &records[i]
-	}
-	close(jobs)
+	// Send jobs until all are sent or the run is aborted; next is the first unsent record
+	next := 0
+	go func() {
+		defer close(jobs)
+		for ; next < len(pending); next++ {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case jobs <- pending[next]:
+			case <-abort:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
-	// Wait for completion
-	wg.Wait()
-	close(results)
+	// Close results once the sender and all workers are done
+	go func() {
+		wg.Wait()
+		close(batches)
+		ioWG.Wait()
+		close(results)
+	}()
 
-	// Check for errors
+	// Collect results, aborting once MaxErrors is reached
 	var errors []error
-	for err := range results {
-		if err != nil {
-			errors = append(errors, err)
+	var failed []csv.FailedRecord
+	var unprocessed []csv.FailedRecord
+	aborted := false
+	for result := range results {
+		// Records cut short by cancellation are not failures
+		if result.cancelled {
+			unprocessed = append(unprocessed, csv.FailedRecord{ConversionRecord: *result.record})
+			continue
+		}
+		c.reportProgress(result)
+		if result.err == nil {
+			continue
+		}
+		errors = append(errors, result.err)
+		failed = append(failed, csv.FailedRecord{ConversionRecord: *result.record, ErrorType: ErrorType(result.err)})
+
+		if c.opts.MaxErrors > 0 && len(errors) >= c.opts.MaxErrors && !aborted {
+			aborted = true
+			close(abort)
+		}
+	}
+
+	if c.opts.SplitByLanguage && !c.dryRun {
+		c.fixLanguageLinks()
+	}
+
+	// Safe to read next: the sender finished before jobs was closed and the workers exited
+	cancelled := ctx.Err() != nil
+	if aborted || cancelled {
+		for _, record := range pending[next:] {
+			unprocessed = append(unprocessed, csv.FailedRecord{ConversionRecord: *record})
+		}
+	}
+	if aborted {
+		log.Printf("Aborted after %d errors, %d records not processed", len(errors), len(unprocessed))
+	} else if cancelled {
+		log.Printf("Cancelled, %d records not processed", len(unprocessed))
+	}
+
+	if c.opts.FailedOutput != "" && len(failed)+len(unprocessed) > 0 {
+		if err := csv.WriteFailedCSV(c.opts.FailedOutput, append(failed, unprocessed...)); err != nil {
+			log.Printf("Warning: failed to write failed records to %s: %v", c.opts.FailedOutput, err)
+		} else {
+			log.Printf("Wrote %d failed and %d unprocessed records to %s", len(failed), len(unprocessed), c.opts.FailedOutput)
 		}
 	}
 
+	if aborted {
+		return fmt.Errorf("aborted after %d errors", len(errors))
+	}
+
+	if cancelled {
+		return fmt.Errorf("conversion cancelled: %w", ctx.Err())
+	}
+
 	if len(errors) > 0 {
 		log.Printf("Completed with %d errors", len(errors))
 		return fmt.Errorf("conversion had %d errors", len(errors))
@@ -104,10 +326,66 @@ func (c *Converter) Convert(records []csv.ConversionRecord, workers int) error {
 	return nil
 }
 
+// IndexRecords adds records to the link map used to rewrite links between documents, by URL
+// and by file ID. Convert indexes its records itself; ProcessContent only sees indexed records.
+func (c *Converter) IndexRecords(records []csv.ConversionRecord) {
+	for i := range records {
+		// Index by the URL from CSV without sharing parameters, so URL variants of one file match
+		c.linkMap[LinkKey(records[i].Link)] = &records[i]
+
+		// Also index by file ID for cross-format matching
+		fileID, err := utils.ExtractFileID(records[i].Link)
+		if err != nil {
+			log.Printf("Warning: failed to extract file ID from %s: %v", records[i].Link, err)
+			continue
+		}
+		c.linkMap[fileID] = &records[i]
+	}
+}
+
+// ProcessContent applies the post-processing Convert applies to exported content, from
+// stripping the byte order mark to prepending the source link, so other commands produce the
+// same body for the record
+func (c *Converter) ProcessContent(content string, record *csv.ConversionRecord) (string, error) {
+	return c.pipeline.Run(content, record)
+}
+
+// runContext returns the context of the running Convert, or context.Background() outside it
+func (c *Converter) runContext() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// reportProgress reports a completed record to the progress reporter, if any
+func (c *Converter) reportProgress(result recordResult) {
+	if c.opts.Progress == nil {
+		return
+	}
+	status := progress.StatusOK
+	if result.err != nil {
+		status = progress.StatusError
+	}
+	c.opts.Progress.RecordDone(result.record.Link, status, result.err)
+}
+
+// recordResult is the outcome of converting a single record
+type recordResult struct {
+	record    *csv.ConversionRecord
+	err       error
+	cancelled bool // Not converted because Convert was cancelled
+}
+
 // convertRecord converts a single record
 func (c *Converter) convertRecord(record *csv.ConversionRecord) error {
 	if c.verbose {
 		log.Printf("Converting: %s", record.Title)
+		if c.opts.AutoTagFromFrags {
+			if autoTags := c.autoTags(record); len(autoTags) > 0 {
+				log.Printf("Auto-tags for %s: %s", record.Title, strings.Join(autoTags, ", "))
+			}
+		}
 	}
 
 	// Extract file ID
@@ -116,81 +394,186 @@ func (c *Converter) convertRecord(record *csv.ConversionRecord) error {
 		return fmt.Errorf("failed to extract file ID from %s: %w", record.Link, err)
 	}
 
+	published := c.isPublished(fileID)
+
+	// Sheets become tables when SheetsToTable is set
+	if c.opts.SheetsToTable && isSpreadsheet(record, "") {
+		return c.convertSheet(record, fileID, published)
+	}
+
 	// Check if this is a Google Form or Sheet - handle as special case
 	if c.requiresStubConversion(record.Link) {
-		return c.convertStubDocument(record)
+		return c.convertStubDocument(record, published)
 	}
 
 	// Get file metadata
 	file, err := c.getFileMetadata(fileID)
 	if err != nil {
-		return fmt.Errorf("failed to get metadata for %s: %w", fileID, err)
+		err = fmt.Errorf("failed to get metadata for %s: %w", fileID, err)
+		return newRecordError(APIErrorKind(err, ErrExportFailed), record, err)
+	}
+	if record.MIMEOverride != "" {
+		if c.verbose {
+			log.Printf("Using MIME type %s instead of %s for %s", record.MIMEOverride, file.MimeType, record.Title)
+		}
+		overridden := *file
+		overridden.MimeType = record.MIMEOverride
+		file = &overridden
+	}
+
+	// Shortcuts to external URLs have no Drive content, so they become Wiki.js redirect pages
+	if isExternalShortcut(file) {
+		return c.convertRedirectStub(record, file.WebViewLink, published)
+	}
+
+	if c.opts.SheetsToTable && isSpreadsheet(record, file.MimeType) {
+		return c.convertSheet(record, fileID, published)
 	}
 
 	// Check if this is a video file or other unsupported media type - handle as stub
 	if c.isUnsupportedMediaType(file.MimeType) {
-		return c.convertStubDocumentWithMimeType(record, file.MimeType)
+		return c.convertStubDocumentWithMimeType(record, file.MimeType, published)
 	}
 
 	// Download content based on mime type
 	var content []byte
 	var revisionHash string
 
-	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
+	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") && c.opts.ExportFormat == ExportFormatHTML {
+		// Google Workspace document - export as HTML
+		content, revisionHash, err = c.exportAsHTML(fileID)
+		if err != nil {
+			err = fmt.Errorf("failed to export %s as HTML: %w", record.Title, err)
+			return newRecordError(APIErrorKind(err, ErrExportFailed), record, err)
+		}
+		if !c.opts.HTMLToMarkdown {
+			return c.writeHTMLDocument(record, revisionHash, string(content), published)
+		}
+		if content, err = ConvertHTMLToMarkdown(content); err != nil {
+			return newRecordError(ErrExportFailed, record, fmt.Errorf("failed to convert %s: %w", record.Title, err))
+		}
+	} else if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
 		// Google Workspace document - export as markdown
 		content, revisionHash, err = c.exportAsMarkdown(fileID)
 		if err != nil {
-			return fmt.Errorf("failed to export %s as markdown: %w", record.Title, err)
+			err = fmt.Errorf("failed to export %s as markdown: %w", record.Title, err)
+			return newRecordError(APIErrorKind(err, ErrExportFailed), record, err)
+		}
+	} else if file.MimeType == docxMimeType {
+		// Word document - convert locally, without a temporary Google Docs copy
+		content, revisionHash, err = c.convertDOCX(record, fileID, RevisionHash(file))
+		if err != nil {
+			err = fmt.Errorf("failed to convert Word document %s: %w", record.Title, err)
+			return newRecordError(APIErrorKind(err, ErrExportFailed), record, err)
 		}
-	} else if file.MimeType == "application/pdf" || file.MimeType == "application/vnd.openxmlformats-officedocument.wordprocessingml.document" {
+	} else if file.MimeType == "application/pdf" {
 		// PDF - convert to Google Docs format (like "Open with Google Docs" in UI)
-		content, revisionHash, err = c.convertPDFViaGoogleDocs(fileID, file.ModifiedTime)
+		content, revisionHash, err = c.convertPDFViaGoogleDocs(record, fileID, RevisionHash(file))
 		if err != nil {
-			return fmt.Errorf("failed to convert PDF %s: %w", record.Title, err)
+			err = fmt.Errorf("failed to convert PDF %s: %w", record.Title, err)
+			return newRecordError(APIErrorKind(err, ErrExportFailed), record, err)
 		}
 	} else {
-		return fmt.Errorf("unsupported file type %s for %s", file.MimeType, record.Title)
+		return newRecordError(ErrUnsupportedType, record, fmt.Errorf("unsupported file type %s for %s", file.MimeType, record.Title))
 	}
 
-	// Rewrite links in content
-	contentStr := string(content)
-	preamble := c.preamble(record)
-	contentStr = preamble + "\n\n" + c.rewriteLinks(contentStr, record)
-
-	// Generate frontmatter
-	frontmatter := c.generateFrontmatter(record, revisionHash, contentStr)
+	// Post-process content: link rewriting, preamble, etc.
+	contentStr, err := c.pipeline.Run(string(content), record)
+	if err != nil {
+		return newRecordError(ErrProcessingFailed, record, fmt.Errorf("failed to process %s: %w", record.Title, err))
+	}
 
-	// Combine frontmatter and content
-	finalContent := frontmatter + "\n" + contentStr
+	if c.opts.NoFrontmatter {
+		return c.writeDocument(record, "", contentStr, nil)
+	}
 
-	// Build output path with normalized filename
-	normalizedTitle := utils.NormalizeFilename(record.Title)
-	outputPath := utils.BuildOutputPath(c.outputDir, normalizedTitle, record.GetFragments())
+	// Generate frontmatter
+	frontmatter := c.generateFrontmatter(record, revisionHash, contentStr, published, c.opts.FrontmatterFormat)
 
-	// Ensure unique path
-	c.mu.Lock()
-	outputPath = utils.EnsureUniquePath(outputPath, c.existingPaths)
-	c.existingPaths[outputPath] = true
-	c.mu.Unlock()
+	return c.writeDocument(record, frontmatter, contentStr, nil)
+}
 
-	if c.dryRun {
-		log.Printf("Would write: %s", outputPath)
-		return nil
+// writeDocument runs the configured transformer over a document and writes the result,
+// along with any assets it references
+func (c *Converter) writeDocument(record *csv.ConversionRecord, frontmatter, content string, assets map[string][]byte) error {
+	body, fm, err := c.opts.Transformer.Transform(content, frontmatter, *record)
+	if err != nil {
+		return fmt.Errorf("failed to transform %s: %w", record.Title, err)
 	}
 
-	// Create directory structure
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	language := ""
+	if c.opts.SplitByLanguage {
+		language = c.detectLanguage(content)
+		c.trackLanguageContent(record, frontmatter, content)
 	}
 
-	// Write file
-	if err := os.WriteFile(outputPath, []byte(finalContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", outputPath, err)
+	if fm == "" {
+		return c.writeOutputWithAssets(record, body, assets, language)
 	}
 
-	if c.verbose {
-		log.Printf("Wrote: %s", outputPath)
+	// Combine frontmatter and content
+	return c.writeOutputWithAssets(record, fm+"\n"+body, assets, language)
+}
+
+// writeOutput writes the final content for a record to each output directory it is routed to
+func (c *Converter) writeOutput(record *csv.ConversionRecord, finalContent string) error {
+	return c.writeOutputWithAssets(record, finalContent, nil, "")
+}
+
+// writeOutputWithAssets writes the document like writeOutput and writes each asset next to it,
+// keyed by its slash-separated path relative to the document's directory. A non-empty language
+// adds a directory named after it below each output directory.
+func (c *Converter) writeOutputWithAssets(record *csv.ConversionRecord, finalContent string, assets map[string][]byte, language string) error {
+	return c.writeOutputWithExt(record, finalContent, assets, language, c.opts.Transformer.FileExtension())
+}
+
+// writeOutputWithExt writes the document like writeOutputWithAssets, with the file extension ext
+func (c *Converter) writeOutputWithExt(record *csv.ConversionRecord, finalContent string, assets map[string][]byte, language, ext string) error {
+	// Build output path with normalized filename
+	normalizedTitle := utils.NormalizeFilename(c.filenameTitle(record))
+
+	for _, outputDir := range c.outputDirsFor(c.recordTags(record)) {
+		if language != "" {
+			outputDir = filepath.Join(outputDir, language)
+		}
+		outputPath, err := utils.ValidateOutputPath(utils.BuildOutputPathWithExt(outputDir, normalizedTitle, c.outputFragments(record), ext))
+		if err != nil {
+			return newRecordError(ErrWriteFailed, record, err)
+		}
+
+		// Ensure unique path
+		c.mu.Lock()
+		if c.existingPaths[outputPath] && len(normalizedTitle)+len(ext) > utils.MaxComponentBytes {
+			// Another long title was truncated to the same name, and numbering it could
+			// exceed the limit again
+			outputPath = filepath.Join(filepath.Dir(outputPath), utils.ShortFilename(normalizedTitle, ext))
+		}
+		outputPath = utils.EnsureUniquePath(outputPath, c.existingPaths)
+		c.existingPaths[outputPath] = true
+		c.mu.Unlock()
+
+		if c.dryRun {
+			log.Printf("Would write: %s", outputPath)
+			for assetPath := range assets {
+				log.Printf("Would write: %s", filepath.Join(filepath.Dir(outputPath), filepath.FromSlash(assetPath)))
+			}
+			continue
+		}
+
+		if err := c.write(record, WriteJob{path: outputPath, content: []byte(finalContent), record: record}); err != nil {
+			return err
+		}
+		if language != "" {
+			c.trackLanguagePath(record, language, outputPath)
+		}
+
+		dir := filepath.Dir(outputPath)
+		for assetPath, data := range assets {
+			fullPath := filepath.Join(dir, filepath.FromSlash(assetPath))
+			if err := c.write(record, WriteJob{path: fullPath, content: data, record: record}); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -210,6 +593,7 @@ func (c *Converter) isUnsupportedMediaType(mimeType string) bool {
 		strings.HasPrefix(mimeType, "image/") ||
 		mimeType == "application/vnd.google-apps.presentation" ||
 		mimeType == "application/vnd.google-apps.spreadsheet" ||
+		mimeType == appsScriptMimeType ||
 		mimeType == "application/vnd.openxmlformats-officedocument.presentationml.presentation" ||
 		mimeType == "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 }
@@ -245,6 +629,9 @@ func (c *Converter) getDocumentTypeFromMimeType(mimeType string) string {
 	if mimeType == "application/vnd.google-apps.spreadsheet" {
 		return "Google Sheet"
 	}
+	if mimeType == appsScriptMimeType {
+		return "Google Apps Script project"
+	}
 	if mimeType == "application/vnd.openxmlformats-officedocument.presentationml.presentation" {
 		return "PowerPoint presentation"
 	}
@@ -255,7 +642,7 @@ func (c *Converter) getDocumentTypeFromMimeType(mimeType string) string {
 }
 
 // convertStubDocument creates a stub document for unsupported document types (Forms, Sheets, etc.)
-func (c *Converter) convertStubDocument(record *csv.ConversionRecord) error {
+func (c *Converter) convertStubDocument(record *csv.ConversionRecord, published bool) error {
 	docType := c.getDocumentType(record.Link)
 
 	if c.verbose {
@@ -266,11 +653,19 @@ func (c *Converter) convertStubDocument(record *csv.ConversionRecord) error {
 	preamble := c.preamble(record)
 	contentStr := fmt.Sprintf("%s\n\n*This is a %s. This document type cannot be exported to markdown format.*", preamble, docType)
 
-	return c.writeStubDocument(record, contentStr)
+	var assets map[string][]byte
+	if c.opts.SlidesPreview && strings.Contains(record.Link, "docs.google.com/presentation") {
+		var preview string
+		if preview, assets = c.slidesPreview(record); preview != "" {
+			contentStr += "\n\n" + preview
+		}
+	}
+
+	return c.writeStubDocument(record, contentStr, published, assets)
 }
 
 // convertStubDocumentWithMimeType creates a stub document for unsupported media types
-func (c *Converter) convertStubDocumentWithMimeType(record *csv.ConversionRecord, mimeType string) error {
+func (c *Converter) convertStubDocumentWithMimeType(record *csv.ConversionRecord, mimeType string, published bool) error {
 	docType := c.getDocumentTypeFromMimeType(mimeType)
 
 	if c.verbose {
@@ -280,49 +675,84 @@ func (c *Converter) convertStubDocumentWithMimeType(record *csv.ConversionRecord
 	// Create stub content with just the preamble
 	preamble := c.preamble(record)
 	contentStr := fmt.Sprintf("%s\n\n*This is a %s (%s). Media files cannot be exported to markdown format.*", preamble, docType, mimeType)
+	if mimeType == appsScriptMimeType {
+		contentStr = fmt.Sprintf("%s\n\n*This is a %s. Apps Script projects cannot be exported to markdown format.*\n\n%s", preamble, docType, appsScriptEditorLink(record))
+	}
 
-	return c.writeStubDocument(record, contentStr)
+	var assets map[string][]byte
+	if c.opts.SlidesPreview && mimeType == "application/vnd.google-apps.presentation" {
+		var preview string
+		if preview, assets = c.slidesPreview(record); preview != "" {
+			contentStr += "\n\n" + preview
+		}
+	}
+
+	return c.writeStubDocument(record, contentStr, published, assets)
 }
 
-// writeStubDocument writes a stub document to disk
-func (c *Converter) writeStubDocument(record *csv.ConversionRecord, contentStr string) error {
-	// Generate frontmatter with stub hash
-	frontmatter := c.generateFrontmatterStub(record, contentStr)
+// appsScriptMimeType is the MIME type of Google Apps Script projects, which Drive cannot export
+const appsScriptMimeType = "application/vnd.google-apps.script"
 
-	// Combine frontmatter and content
-	finalContent := frontmatter + "\n" + contentStr
+// appsScriptEditorLink returns a markdown link that opens a record's Apps Script project in the
+// script editor. The script ID is the Drive file ID.
+func appsScriptEditorLink(record *csv.ConversionRecord) string {
+	scriptID, _ := utils.ExtractFileID(record.Link)
+	return fmt.Sprintf("[Open %s in the Apps Script editor](https://script.google.com/d/%s/edit)", record.Title, scriptID)
+}
 
-	// Build output path with normalized filename
-	normalizedTitle := utils.NormalizeFilename(record.Title)
-	outputPath := utils.BuildOutputPath(c.outputDir, normalizedTitle, record.GetFragments())
+// shortcutMimeType is the MIME type of Google Drive shortcuts
+const shortcutMimeType = "application/vnd.google-apps.shortcut"
 
-	// Ensure unique path
-	c.mu.Lock()
-	outputPath = utils.EnsureUniquePath(outputPath, c.existingPaths)
-	c.existingPaths[outputPath] = true
-	c.mu.Unlock()
+// isExternalShortcut reports whether file is a shortcut to an external URL rather than to a
+// Drive file. Such shortcuts have no target ID.
+func isExternalShortcut(file *drive.File) bool {
+	return file.MimeType == shortcutMimeType && (file.ShortcutDetails == nil || file.ShortcutDetails.TargetId == "")
+}
 
-	if c.dryRun {
-		log.Printf("Would write: %s", outputPath)
-		return nil
+// convertRedirectStub creates a stub document whose frontmatter redirects to target, for
+// shortcuts to external URLs
+func (c *Converter) convertRedirectStub(record *csv.ConversionRecord, target string, published bool) error {
+	if target == "" {
+		target = record.Link
 	}
 
-	// Create directory structure
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	if c.verbose {
+		log.Printf("Creating redirect stub for %s: %s", record.Title, target)
 	}
 
-	// Write file
-	if err := os.WriteFile(outputPath, []byte(finalContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", outputPath, err)
+	preamble := c.preamble(record)
+	contentStr := fmt.Sprintf("%s\n\n*This is a shortcut to an external page: [%s](%s)*", preamble, record.Title, target)
+
+	contentStr, err := c.prependSourceLink(record, contentStr)
+	if err != nil {
+		return err
 	}
 
-	if c.verbose {
-		log.Printf("Wrote: %s", outputPath)
+	if c.opts.NoFrontmatter {
+		return c.writeDocument(record, "", contentStr, nil)
 	}
 
-	return nil
+	fm, tags := c.frontmatterFields(record, "stub", contentStr, published)
+	fm["description"] = record.Title
+	fm["redirect"] = target
+	return c.writeDocument(record, RenderFrontmatter(fm, tags, c.opts.FrontmatterFormat), contentStr, nil)
+}
+
+// writeStubDocument writes a stub document to disk, along with any assets it references
+func (c *Converter) writeStubDocument(record *csv.ConversionRecord, contentStr string, published bool, assets map[string][]byte) error {
+	contentStr, err := c.prependSourceLink(record, contentStr)
+	if err != nil {
+		return err
+	}
+
+	if c.opts.NoFrontmatter {
+		return c.writeDocument(record, "", contentStr, assets)
+	}
+
+	// Generate frontmatter with stub hash
+	frontmatter := c.generateFrontmatterStub(record, contentStr, published, c.opts.FrontmatterFormat)
+
+	return c.writeDocument(record, frontmatter, contentStr, assets)
 }
 
 // exportAsMarkdown exports a Google Workspace document as markdown
@@ -333,6 +763,14 @@ func (c *Converter) exportAsMarkdown(fileID string) ([]byte, string, error) {
 		return nil, "", err
 	}
 
+	if c.opts.ExportBackend == ExportBackendPandoc {
+		content, err := c.exportWithPandoc(fileID)
+		if err != nil {
+			return nil, "", err
+		}
+		return content, RevisionHash(file), nil
+	}
+
 	// Export as markdown
 	body, err := c.executeExportWithRetry(fileID, "text/markdown")
 	if err != nil {
@@ -345,37 +783,42 @@ func (c *Converter) exportAsMarkdown(fileID string) ([]byte, string, error) {
 		return nil, "", fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return content, file.ModifiedTime, nil
+	return content, RevisionHash(file), nil
 }
 
 // convertPDFViaGoogleDocs converts a PDF to markdown by creating a Google Docs copy
-func (c *Converter) convertPDFViaGoogleDocs(fileID string, modifiedTime string) ([]byte, string, error) {
+func (c *Converter) convertPDFViaGoogleDocs(record *csv.ConversionRecord, fileID string, revisionHash string) ([]byte, string, error) {
 	if c.verbose {
 		log.Printf("Converting PDF %s using Google Docs conversion...", fileID)
 	}
 
 	// Create a copy of the PDF as a Google Doc
 	// This mimics the "Open with Google Docs" behavior in the UI
+	prefix := c.opts.TempFilePrefix
+	if prefix == "" {
+		prefix = DefaultTempFilePrefix
+	}
 	copyFile := &drive.File{
-		Name:     "temp_conversion_" + fileID,
+		Name:     prefix + fileID,
 		MimeType: "application/vnd.google-apps.document",
 	}
+	if c.opts.TempFolderID != "" {
+		copyFile.Parents = []string{c.opts.TempFolderID}
+	}
 
-	copiedFile, err := c.service.Files.Copy(fileID, copyFile).SupportsAllDrives(true).Do()
+	copiedFile, err := c.service.Files.Copy(fileID, copyFile).SupportsAllDrives(true).Context(c.runContext()).Do()
 	if err != nil {
 		if c.verbose {
 			log.Printf("Warning: Failed to convert PDF %s using Google Docs, falling back to text extraction: %v", fileID, err)
 		}
 		// Fall back to direct PDF text extraction
-		return c.convertPDF(fileID)
+		return c.convertPDF(record, fileID)
 	}
 
 	// Delete the temporary converted file when done
 	defer func() {
-		if err := c.service.Files.Delete(copiedFile.Id).SupportsAllDrives(true).Do(); err != nil {
-			if c.verbose {
-				log.Printf("Warning: Failed to delete temporary file %s: %v", copiedFile.Id, err)
-			}
+		if err := c.deleteWithRetry(copiedFile.Id); err != nil {
+			log.Printf("Warning: Failed to delete temporary file %s: %v", copiedFile.Id, err)
 		}
 	}()
 
@@ -395,11 +838,11 @@ func (c *Converter) convertPDFViaGoogleDocs(fileID string, modifiedTime string)
 		log.Printf("Successfully converted PDF %s using Google Docs", fileID)
 	}
 
-	return content, modifiedTime, nil
+	return content, revisionHash, nil
 }
 
-// convertPDF downloads a PDF and converts it to markdown using direct text extraction (fallback)
-func (c *Converter) convertPDF(fileID string) ([]byte, string, error) {
+// convertPDF downloads a PDF and converts it to markdown locally (fallback)
+func (c *Converter) convertPDF(record *csv.ConversionRecord, fileID string) ([]byte, string, error) {
 	// Get revision hash
 	file, err := c.getFileMetadata(fileID)
 	if err != nil {
@@ -429,16 +872,28 @@ func (c *Converter) convertPDF(fileID string) ([]byte, string, error) {
 	// Close the file so we can read it
 	tempFile.Close()
 
-	// Convert PDF to markdown
-	content, err := convertPDFToMarkdown(tempFile.Name())
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to convert PDF to markdown: %w", err)
+	// Convert PDF to markdown, keeping structure with MuPDF when possible
+	var content []byte
+	pages, err := convertPDFPagesWithFitz(tempFile.Name(), c.opts.PDFWorkers)
+	if err == nil {
+		if c.opts.ExtractPDFImages {
+			pages = c.addPDFPageImages(record, fileID, tempFile.Name(), pages)
+		}
+		content = joinPDFPages(pages)
+	} else {
+		if c.verbose {
+			log.Printf("Warning: MuPDF conversion of %s failed, falling back to plain text extraction: %v", fileID, err)
+		}
+		content, err = convertPDFToMarkdown(tempFile.Name())
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to convert PDF to markdown: %w", err)
+		}
 	}
 
-	return content, file.ModifiedTime, nil
+	return content, RevisionHash(file), nil
 }
 
-// convertPDFToMarkdown converts a PDF file to markdown
+// convertPDFToMarkdown converts a PDF file to markdown using plain text extraction
 func convertPDFToMarkdown(pdfPath string) ([]byte, error) {
 	// Open PDF file
 	pdfFile, pdfReader, err := pdf.Open(pdfPath)
@@ -481,6 +936,15 @@ func (c *Converter) preamble(sourceRecord *csv.ConversionRecord) string {
 	return fmt.Sprintf("> Link: %s", sourceRecord.Link)
 }
 
+// LinkKey returns the link map key for a URL: the URL without sharing parameters and fragment,
+// or the URL itself when it cannot be parsed
+func LinkKey(link string) string {
+	if normalized, err := utils.NormalizeURL(link); err == nil {
+		return normalized
+	}
+	return link
+}
+
 // rewriteLinks rewrites Google Drive/Docs links to relative paths
 func (c *Converter) rewriteLinks(content string, sourceRecord *csv.ConversionRecord) string {
 	// Normalize content to fix URLs broken across multiple lines
@@ -499,8 +963,8 @@ func (c *Converter) rewriteLinks(content string, sourceRecord *csv.ConversionRec
 		linkText := matches[1]
 		linkURL := matches[2]
 
-		// Look up target in link map by exact URL first
-		targetRecord, exists := c.linkMap[linkURL]
+		// Look up target in link map by URL first
+		targetRecord, exists := c.linkMap[LinkKey(linkURL)]
 
 		// If not found by URL, try by file ID (for cross-format matching)
 		if !exists {
@@ -515,177 +979,285 @@ func (c *Converter) rewriteLinks(content string, sourceRecord *csv.ConversionRec
 			}
 		}
 
-		// Calculate relative path with normalized filename
-		normalizedTargetTitle := utils.NormalizeFilename(targetRecord.Title)
-		relPath := utils.CalculateRelativePath(
-			sourceRecord.GetFragments(),
-			targetRecord.GetFragments(),
-			normalizedTargetTitle,
-		)
-
+		relPath, ok := c.relativeLink(sourceRecord, targetRecord, "", "")
+		if !ok {
+			return match
+		}
+		if c.opts.SplitByLanguage {
+			c.trackLanguageLink(sourceRecord, targetRecord, linkURL, relPath)
+		}
 		return fmt.Sprintf("[%s](%s)", linkText, relPath)
 	})
 }
 
-// generateFrontmatter generates YAML frontmatter for the document
-func (c *Converter) generateFrontmatter(record *csv.ConversionRecord, revisionHash, content string) string {
-	var sb strings.Builder
-	sb.WriteString("---\n")
-	sb.WriteString(fmt.Sprintf("description: %s\n", escapeYAML(record.Title)))
-	sb.WriteString("editor: markdown\n")
-	sb.WriteString(fmt.Sprintf("gdrive-link: %s\n", escapeYAML(record.Link)))
-	sb.WriteString(fmt.Sprintf("hash-gdrive: %s\n", escapeYAML(revisionHash)))
-	sb.WriteString(fmt.Sprintf("hash-content: %s\n", utils.CalculateStringHash(content)))
-	sb.WriteString("published: true\n")
+// relativeLink returns the relative path from the source document to the target document, or
+// false when no single path reaches the target from every output directory the source is
+// written to. The same content is written to each of them. A non-empty sourceLanguage or
+// targetLanguage adds the language directory below each output directory of that document.
+func (c *Converter) relativeLink(sourceRecord, targetRecord *csv.ConversionRecord, sourceLanguage, targetLanguage string) (string, bool) {
+	ext := c.opts.Transformer.FileExtension()
+	normalizedTargetTitle := utils.NormalizeFilename(c.filenameTitle(targetRecord))
+	sourceDirs := languageDirs(c.outputDirsFor(c.recordTags(sourceRecord)), sourceLanguage)
+	targetDirs := languageDirs(c.outputDirsFor(c.recordTags(targetRecord)), targetLanguage)
+
+	// A target written next to every copy of the source is reached by the path between
+	// their fragments
+	shared := true
+	for _, dir := range sourceDirs {
+		shared = shared && slices.Contains(targetDirs, dir)
+	}
+	if shared {
+		return utils.CalculateRelativePathWithExt(
+			c.outputFragments(sourceRecord),
+			c.outputFragments(targetRecord),
+			normalizedTargetTitle,
+			ext,
+		), true
+	}
+
+	// Otherwise the target lives in another output directory, which a copy in each of
+	// several source directories would reach by a different path
+	if len(sourceDirs) > 1 {
+		return "", false
+	}
+	sourcePath := utils.BuildOutputPathWithExt(sourceDirs[0], utils.NormalizeFilename(c.filenameTitle(sourceRecord)), c.outputFragments(sourceRecord), ext)
+	targetPath := utils.BuildOutputPathWithExt(targetDirs[0], normalizedTargetTitle, c.outputFragments(targetRecord), ext)
+	relPath, err := filepath.Rel(filepath.Dir(sourcePath), targetPath)
+	if err != nil {
+		return "", false
+	}
+	return relPath, true
+}
 
-	tags := record.GetTagsList()
-	if len(tags) > 0 {
-		sb.WriteString(fmt.Sprintf("tags: %s\n", strings.Join(tags, ", ")))
+// languageDirs returns the language directory language below each of dirs, or dirs when
+// language is empty
+func languageDirs(dirs []string, language string) []string {
+	if language == "" {
+		return dirs
 	}
+	joined := make([]string, len(dirs))
+	for i, dir := range dirs {
+		joined[i] = filepath.Join(dir, language)
+	}
+	return joined
+}
 
-	sb.WriteString(fmt.Sprintf("title: %s\n", escapeYAML(record.Title)))
-	sb.WriteString("---\n")
+// SetContentFields sets the frontmatter fields derived from the content of a document: its
+// description and, with DetectLanguage, its language. Sync calls it when a body is replaced.
+func (c *Converter) SetContentFields(fm map[string]string, record *csv.ConversionRecord, content string) {
+	fm["description"] = c.description(record, content)
+	if c.opts.DetectLanguage {
+		fm["language"] = c.detectLanguage(content)
+	}
+}
 
-	return sb.String()
+// generateFrontmatter generates frontmatter for the document in the given format
+func (c *Converter) generateFrontmatter(record *csv.ConversionRecord, revisionHash, content string, published bool, format FrontmatterFormat) string {
+	fm, tags := c.frontmatterFields(record, revisionHash, content, published)
+	return RenderFrontmatter(fm, tags, format)
 }
 
-// generateFrontmatterStub generates YAML frontmatter for stub documents (like Google Forms)
-func (c *Converter) generateFrontmatterStub(record *csv.ConversionRecord, content string) string {
-	var sb strings.Builder
-	sb.WriteString("---\n")
-	sb.WriteString(fmt.Sprintf("description: %s\n", escapeYAML(record.Title)))
-	sb.WriteString("editor: markdown\n")
-	sb.WriteString(fmt.Sprintf("gdrive-link: %s\n", escapeYAML(record.Link)))
-	sb.WriteString("hash-gdrive: stub\n")
-	sb.WriteString(fmt.Sprintf("hash-content: %s\n", utils.CalculateStringHash(content)))
-	sb.WriteString("published: true\n")
+// frontmatterFields returns the frontmatter fields for a document, before rendering, and its
+// tags, or nil when it has none. Tags are kept as a list so a tag containing a comma stays one
+// tag.
+func (c *Converter) frontmatterFields(record *csv.ConversionRecord, revisionHash, content string, published bool) (map[string]string, []string) {
+	fm := map[string]string{
+		"editor":       "markdown",
+		"gdrive-link":  record.Link,
+		"hash-gdrive":  revisionHash,
+		"hash-content": c.opts.HashFunc([]byte(content)),
+		"published":    fmt.Sprintf("%t", published),
+		"title":        c.displayTitle(record),
+	}
+
+	c.SetContentFields(fm, record, content)
+
+	tags := c.frontmatterTags(record)
+	if len(tags) == 0 {
+		tags = nil
+	}
+
+	// Extra fields never replace the standard ones
+	for key, value := range c.opts.FrontmatterExtra {
+		if !slices.Contains(frontmatterKeys, key) {
+			fm[key] = value
+		}
+	}
+
+	return fm, tags
+}
+
+// generateFrontmatterStub generates frontmatter for stub documents (like Google Forms)
+func (c *Converter) generateFrontmatterStub(record *csv.ConversionRecord, content string, published bool, format FrontmatterFormat) string {
+	fm, tags := c.frontmatterFields(record, "stub", content, published)
+	// Stub content only explains why the file was not converted, so it does not describe it
+	fm["description"] = record.Title
+	return RenderFrontmatter(fm, tags, format)
+}
+
+// displayTitle returns the record's title with the configured prefix and suffix
+func (c *Converter) displayTitle(record *csv.ConversionRecord) string {
+	return c.opts.TitlePrefix + record.Title + c.opts.TitleSuffix
+}
 
-	tags := record.GetTagsList()
-	if len(tags) > 0 {
-		sb.WriteString(fmt.Sprintf("tags: %s\n", strings.Join(tags, ", ")))
+// filenameTitle returns the title the output filename is derived from
+func (c *Converter) filenameTitle(record *csv.ConversionRecord) string {
+	if c.opts.PrefixInFilename {
+		return c.displayTitle(record)
 	}
+	return record.Title
+}
+
+// recordTags returns the record's tags using the configured separator, merged with the
+// tags generated from its fragments when AutoTagFromFrags is set
+func (c *Converter) recordTags(record *csv.ConversionRecord) []string {
+	csvTags := record.GetTagsListWithOptions(csv.TagOptions{Separator: c.opts.TagSeparator, PreserveCase: c.opts.PreserveTagCase})
+	if !c.opts.AutoTagFromFrags {
+		return csvTags
+	}
+	return tags.Merge(csvTags, c.autoTags(record))
+}
+
+// autoTags returns the tags generated from the record's fragments
+func (c *Converter) autoTags(record *csv.ConversionRecord) []string {
+	return tags.FromFragments(record.GetFragments(), c.opts.AutoTagPrefix)
+}
 
-	sb.WriteString(fmt.Sprintf("title: %s\n", escapeYAML(record.Title)))
-	sb.WriteString("---\n")
+// frontmatterTags returns the record's tags expanded with their parents from the tag hierarchy
+func (c *Converter) frontmatterTags(record *csv.ConversionRecord) []string {
+	recordTags := c.recordTags(record)
+	if len(c.opts.TagHierarchy) == 0 {
+		return recordTags
+	}
 
-	return sb.String()
+	expanded, err := tags.ExpandTags(recordTags, c.opts.TagHierarchy)
+	if err != nil {
+		log.Printf("Warning: failed to expand tags for %s: %v", record.Title, err)
+		return recordTags
+	}
+	return expanded
 }
 
 // escapeYAML escapes special characters in YAML values
 func escapeYAML(s string) string {
 	// If string contains special characters, quote it
 	if strings.ContainsAny(s, ":#@&*!|>'\"%[]{}") || strings.HasPrefix(s, "-") {
-		// Escape quotes
+		// Escape backslashes and quotes
+		s = strings.ReplaceAll(s, "\\", "\\\\")
 		s = strings.ReplaceAll(s, "\"", "\\\"")
 		return fmt.Sprintf("\"%s\"", s)
 	}
 	return s
 }
 
-// getFileMetadata retrieves metadata for a file
-func (c *Converter) getFileMetadata(fileID string) (*drive.File, error) {
-	maxRetries := 5
-	baseDelay := time.Second
-
-	for i := 0; i < maxRetries; i++ {
-		file, err := c.service.Files.Get(fileID).
-			Fields("id, name, mimeType, modifiedTime").
-			SupportsAllDrives(true).
-			Do()
-
-		if err == nil {
-			return file, nil
-		}
-
-		// Check if it's a rate limit error
-		if apiErr, ok := err.(*googleapi.Error); ok {
-			if apiErr.Code == 403 || apiErr.Code == 429 {
-				delay := baseDelay * time.Duration(1<<uint(i))
-				if c.verbose {
-					log.Printf("Rate limited, retrying in %v...", delay)
-				}
-				time.Sleep(delay)
-				continue
-			}
-		}
-
-		return nil, err
+// isPublished reports whether a file should be published. Without UseACLForPublished every
+// file is published; otherwise only files shared with anyone as reader are.
+func (c *Converter) isPublished(fileID string) bool {
+	if !c.opts.UseACLForPublished {
+		return true
 	}
 
-	// Final attempt
-	return c.service.Files.Get(fileID).
-		Fields("id, name, mimeType, modifiedTime").
+	perms, err := c.service.Permissions.List(fileID).
+		Fields("permissions(role,type)").
 		SupportsAllDrives(true).
+		Context(c.runContext()).
 		Do()
-}
-
-// executeExportWithRetry exports a file with retry logic
-func (c *Converter) executeExportWithRetry(fileID, mimeType string) (io.ReadCloser, error) {
-	maxRetries := 5
-	baseDelay := time.Second
-
-	for i := 0; i < maxRetries; i++ {
-		resp, err := c.service.Files.Export(fileID, mimeType).Download()
+	if err != nil {
+		log.Printf("Warning: failed to list permissions for %s, marking unpublished: %v", fileID, err)
+		return false
+	}
 
-		if err == nil {
-			return resp.Body, nil
+	for _, perm := range perms.Permissions {
+		if perm.Type == "anyone" && perm.Role == "reader" {
+			return true
 		}
+	}
+	return false
+}
 
-		// Check if it's a rate limit error
-		if apiErr, ok := err.(*googleapi.Error); ok {
-			if apiErr.Code == 403 || apiErr.Code == 429 {
-				delay := baseDelay * time.Duration(1<<uint(i))
-				if c.verbose {
-					log.Printf("Rate limited, retrying in %v...", delay)
-				}
-				time.Sleep(delay)
-				continue
-			}
-		}
+// fileMetadataFields is the field mask for file metadata requests. Fields needed by optional
+// features belong here so they arrive in the same call rather than a separate request.
+const fileMetadataFields = "id, name, mimeType, modifiedTime, headRevisionId, webViewLink, shortcutDetails(targetId)"
 
-		return nil, err
+// RevisionHash returns the hash-gdrive value of a file: its head revision ID, which only
+// changes with the content, or its modifiedTime when it has none. Drive only reports head
+// revisions for files with binary content, so Google Docs, Sheets and Slides use modifiedTime.
+func RevisionHash(file *drive.File) string {
+	if file.HeadRevisionId != "" {
+		return file.HeadRevisionId
+	}
+	return file.ModifiedTime
+}
+
+// getFileMetadata retrieves metadata for a file, using the run's metadata cache
+func (c *Converter) getFileMetadata(fileID string) (*drive.File, error) {
+	if file, ok := c.metadata.Get(fileID); ok {
+		return file, nil
 	}
 
-	// Final attempt
-	resp, err := c.service.Files.Export(fileID, mimeType).Download()
+	file, err := c.fetchFileMetadata(fileID)
 	if err != nil {
 		return nil, err
 	}
-	return resp.Body, nil
-}
 
-// executeDownloadWithRetry downloads a file with retry logic
-func (c *Converter) executeDownloadWithRetry(fileID string) (io.ReadCloser, error) {
-	maxRetries := 5
-	baseDelay := time.Second
+	c.metadata.Put(fileID, file)
+	return file, nil
+}
 
-	for i := 0; i < maxRetries; i++ {
-		resp, err := c.service.Files.Get(fileID).SupportsAllDrives(true).Download()
+// fetchFileMetadata retrieves metadata for a file from the Drive API
+func (c *Converter) fetchFileMetadata(fileID string) (*drive.File, error) {
+	var file *drive.File
+	err := c.opts.Retry.Do(c.runContext(), c.verbose, func() error {
+		var err error
+		file, err = c.service.Files.Get(fileID).
+			Fields(fileMetadataFields).
+			SupportsAllDrives(true).
+			Context(c.runContext()).
+			Do()
+		return err
+	})
+	return file, err
+}
 
-		if err == nil {
-			return resp.Body, nil
+// executeExportWithRetry exports a file with retry logic
+func (c *Converter) executeExportWithRetry(fileID, mimeType string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := c.opts.Retry.Do(c.runContext(), c.verbose, func() error {
+		resp, err := c.service.Files.Export(fileID, mimeType).Context(c.runContext()).Download()
+		if err != nil {
+			return err
 		}
+		body = resp.Body
+		return nil
+	})
+	return body, err
+}
 
-		// Check if it's a rate limit error
-		if apiErr, ok := err.(*googleapi.Error); ok {
-			if apiErr.Code == 403 || apiErr.Code == 429 {
-				delay := baseDelay * time.Duration(1<<uint(i))
-				if c.verbose {
-					log.Printf("Rate limited, retrying in %v...", delay)
-				}
-				time.Sleep(delay)
-				continue
-			}
+// executeDownloadWithRetry downloads a file with retry logic
+func (c *Converter) executeDownloadWithRetry(fileID string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := c.opts.Retry.Do(c.runContext(), c.verbose, func() error {
+		resp, err := c.service.Files.Get(fileID).SupportsAllDrives(true).Context(c.runContext()).Download()
+		if err != nil {
+			return err
 		}
-
-		return nil, err
-	}
-
-	// Final attempt
-	resp, err := c.service.Files.Get(fileID).SupportsAllDrives(true).Download()
-	if err != nil {
-		return nil, err
-	}
-	return resp.Body, nil
+		body = resp.Body
+		return nil
+	})
+	return body, err
 }
 
+// deleteWithRetry deletes a file, retrying transient failures such as network errors. It is
+// used to clean up temporary copies, so it runs to completion even after Convert is cancelled.
+func (c *Converter) deleteWithRetry(fileID string) error {
+	ctx := context.WithoutCancel(c.runContext())
+	retryAll := func(error) bool { return true }
+	return c.opts.Retry.DoIf(ctx, c.verbose, retryAll, func() error {
+		err := c.service.Files.Delete(fileID).SupportsAllDrives(true).Context(ctx).Do()
+		// Already gone - nothing left to clean up
+		if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Code == 404 {
+			return nil
+		}
+		return err
+	})
+}
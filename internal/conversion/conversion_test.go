@@ -1,8 +1,21 @@
 package conversion
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/progress"
 	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
 )
 
@@ -181,6 +194,11 @@ func TestIsUnsupportedMediaType(t *testing.T) {
 			mimeType: "application/pdf",
 			want:     false,
 		},
+		{
+			name:     "Google Apps Script",
+			mimeType: "application/vnd.google-apps.script",
+			want:     true,
+		},
 		{
 			name:     "Word Document - should be supported",
 			mimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
@@ -269,6 +287,11 @@ func TestGetDocumentTypeFromMimeType(t *testing.T) {
 			mimeType: "application/vnd.google-apps.spreadsheet",
 			want:     "Google Sheet",
 		},
+		{
+			name:     "Google Apps Script",
+			mimeType: "application/vnd.google-apps.script",
+			want:     "Google Apps Script project",
+		},
 		{
 			name:     "PowerPoint",
 			mimeType: "application/vnd.openxmlformats-officedocument.presentationml.presentation",
@@ -295,3 +318,794 @@ func TestGetDocumentTypeFromMimeType(t *testing.T) {
 	}
 }
 
+func TestGenerateFrontmatterExpandsTagHierarchy(t *testing.T) {
+	c := NewConverter(nil, "/out", false, false, Options{
+		TagHierarchy: map[string][]string{"kubernetes": {"devops", "infrastructure"}},
+	})
+	record := &csv.ConversionRecord{
+		Link:  "https://docs.google.com/document/d/abc123/edit",
+		Title: "Cluster Runbook",
+		Tags:  "kubernetes;runbook",
+	}
+
+	fm := c.generateFrontmatter(record, "2024-01-15T10:30:00Z", "content", true, FrontmatterYAML)
+	if !strings.Contains(fm, "tags:\n  - kubernetes\n  - runbook\n  - devops\n  - infrastructure\n") {
+		t.Errorf("generateFrontmatter() tags not expanded:\n%s", fm)
+	}
+
+	// GetTagsList itself is unaffected by the hierarchy
+	if got := record.GetTagsList(); len(got) != 2 {
+		t.Errorf("GetTagsList() = %v, want 2 tags", got)
+	}
+}
+
+func TestGenerateFrontmatterTagWithComma(t *testing.T) {
+	c := NewConverter(nil, "/out", false, false, Options{TagSeparator: ";"})
+	record := &csv.ConversionRecord{
+		Link:  "https://docs.google.com/document/d/abc123/edit",
+		Title: "Release Notes",
+		Tags:  "tips, tricks;release",
+	}
+
+	tests := map[FrontmatterFormat]string{
+		FrontmatterYAML: "tags:\n  - tips, tricks\n  - release\n",
+		FrontmatterTOML: `tags = ["tips, tricks", "release"]`,
+		FrontmatterJSON: `"tags": ["tips, tricks","release"]`,
+	}
+	for format, want := range tests {
+		if fm := c.generateFrontmatter(record, "2024-01-15T10:30:00Z", "content", true, format); !strings.Contains(fm, want) {
+			t.Errorf("generateFrontmatter(%s) =\n%s\nwant it to contain %q", format, fm, want)
+		}
+	}
+}
+
+func TestGenerateFrontmatterAutoTagsFromFragments(t *testing.T) {
+	record := &csv.ConversionRecord{
+		Link:      "https://docs.google.com/document/d/abc123/edit",
+		Title:     "Cluster Runbook",
+		Tags:      "Engineering;runbook",
+		Fragments: []string{"Engineering", "Platform Team"},
+	}
+
+	c := NewConverter(nil, "/out", false, false, Options{AutoTagFromFrags: true})
+	fm := c.generateFrontmatter(record, "2024-01-15T10:30:00Z", "content", true, FrontmatterYAML)
+	if !strings.Contains(fm, "tags:\n  - engineering\n  - runbook\n  - platform-team\n") {
+		t.Errorf("generateFrontmatter() tags not merged with fragments:\n%s", fm)
+	}
+
+	c = NewConverter(nil, "/out", false, false, Options{AutoTagFromFrags: true, AutoTagPrefix: "category:"})
+	got := c.recordTags(record)
+	want := []string{"engineering", "runbook", "category:engineering", "category:platform-team"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recordTags() = %v, want %v", got, want)
+	}
+
+	c = NewConverter(nil, "/out", false, false, Options{PreserveTagCase: true})
+	got = c.recordTags(record)
+	want = []string{"Engineering", "runbook"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recordTags() with PreserveTagCase = %v, want %v", got, want)
+	}
+}
+
+func TestConvertPDFViaGoogleDocsTempFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		wantName    string
+		wantParents bool
+	}{
+		{
+			name:     "default prefix and no parent",
+			opts:     Options{},
+			wantName: `"name":"gdrive_tmp_pdf123"`,
+		},
+		{
+			name:        "custom prefix and temp folder",
+			opts:        Options{TempFolderID: "tempFolder1", TempFilePrefix: "conv_"},
+			wantName:    `"name":"conv_pdf123"`,
+			wantParents: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.handle("POST", "/files/pdf123/copy", jsonHandler(`{"id":"copy1"}`))
+			fake.handle("GET", "/files/copy1/export", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("# Converted"))
+			})
+			fake.handle("DELETE", "/files/copy1", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			c := newTestConverter(t, fake, t.TempDir(), tt.opts)
+			content, _, err := c.convertPDFViaGoogleDocs(&csv.ConversionRecord{Title: "PDF"}, "pdf123", "2024-01-15T10:30:00Z")
+			if err != nil {
+				t.Fatalf("convertPDFViaGoogleDocs() error = %v", err)
+			}
+			if string(content) != "# Converted" {
+				t.Errorf("content = %q, want %q", content, "# Converted")
+			}
+
+			copies := fake.requestsFor("POST", "/files/pdf123/copy")
+			if len(copies) != 1 {
+				t.Fatalf("Got %d copy requests, want 1", len(copies))
+			}
+			if !strings.Contains(copies[0].Body, tt.wantName) {
+				t.Errorf("copy body %s missing %s", copies[0].Body, tt.wantName)
+			}
+			if hasParents := strings.Contains(copies[0].Body, `"parents":["tempFolder1"]`); hasParents != tt.wantParents {
+				t.Errorf("copy body %s parents present = %v, want %v", copies[0].Body, hasParents, tt.wantParents)
+			}
+
+			if deletes := fake.requestsFor("DELETE", "/files/copy1"); len(deletes) != 1 {
+				t.Errorf("Got %d delete requests, want 1", len(deletes))
+			}
+		})
+	}
+}
+
+func TestDeleteWithRetryIgnoresMissingFile(t *testing.T) {
+	fake := newFakeDrive() // Unregistered routes respond with 404
+	c := newTestConverter(t, fake, t.TempDir(), Options{})
+
+	if err := c.deleteWithRetry("gone"); err != nil {
+		t.Errorf("deleteWithRetry() error = %v, want nil for missing file", err)
+	}
+	if deletes := fake.requestsFor("DELETE", "/files/gone"); len(deletes) != 1 {
+		t.Errorf("Got %d delete requests, want 1", len(deletes))
+	}
+}
+
+func TestDeleteWithRetryAttempts(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		fail       bool
+		wantDelete int
+	}{
+		{name: "no retries", maxRetries: 0, wantDelete: 1},
+		{name: "no retries failing", maxRetries: 0, fail: true, wantDelete: 1},
+		{name: "retries plus final attempt", maxRetries: 2, fail: true, wantDelete: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.handle("DELETE", "/files/copy1", func(w http.ResponseWriter, r *http.Request) {
+				if tt.fail {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(`{"error":{"code":500,"message":"backend error"}}`))
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			})
+			c := newTestConverter(t, fake, t.TempDir(), Options{Retry: utils.RetryConfig{MaxRetries: tt.maxRetries, BaseDelay: time.Millisecond}})
+
+			err := c.deleteWithRetry("copy1")
+			if (err != nil) != tt.fail {
+				t.Errorf("deleteWithRetry() error = %v, want error %v", err, tt.fail)
+			}
+			if deletes := fake.requestsFor("DELETE", "/files/copy1"); len(deletes) != tt.wantDelete {
+				t.Errorf("Got %d delete requests, want %d", len(deletes), tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestIsPublished(t *testing.T) {
+	tests := []struct {
+		name        string
+		useACL      bool
+		permissions string
+		want        bool
+	}{
+		{
+			name:   "ACL disabled always publishes",
+			useACL: false,
+			want:   true,
+		},
+		{
+			name:        "anyone with the link as reader",
+			useACL:      true,
+			permissions: `{"permissions":[{"type":"user","role":"owner"},{"type":"anyone","role":"reader"}]}`,
+			want:        true,
+		},
+		{
+			name:        "anyone with the link as writer",
+			useACL:      true,
+			permissions: `{"permissions":[{"type":"anyone","role":"writer"}]}`,
+			want:        false,
+		},
+		{
+			name:        "internally shared",
+			useACL:      true,
+			permissions: `{"permissions":[{"type":"domain","role":"reader"},{"type":"user","role":"owner"}]}`,
+			want:        false,
+		},
+		{
+			name:   "permissions lookup fails",
+			useACL: true,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			if tt.permissions != "" {
+				fake.handle("GET", "/files/file123/permissions", jsonHandler(tt.permissions))
+			}
+
+			c := newTestConverter(t, fake, t.TempDir(), Options{UseACLForPublished: tt.useACL})
+			if got := c.isPublished("file123"); got != tt.want {
+				t.Errorf("isPublished() = %v, want %v", got, tt.want)
+			}
+
+			if !tt.useACL {
+				if reqs := fake.requestsFor("GET", "/files/file123/permissions"); len(reqs) != 0 {
+					t.Errorf("Got %d permissions requests with ACL disabled, want 0", len(reqs))
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFrontmatterPublished(t *testing.T) {
+	c := NewConverter(nil, "/out", false, false, Options{})
+	record := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/abc123/edit", Title: "Doc"}
+
+	if fm := c.generateFrontmatter(record, "rev", "content", false, FrontmatterYAML); !strings.Contains(fm, "published: false\n") {
+		t.Errorf("generateFrontmatter() missing published: false:\n%s", fm)
+	}
+	if fm := c.generateFrontmatterStub(record, "content", true, FrontmatterYAML); !strings.Contains(fm, "published: true\n") || !strings.Contains(fm, "hash-gdrive: stub\n") {
+		t.Errorf("generateFrontmatterStub() unexpected output:\n%s", fm)
+	}
+}
+
+func TestGenerateFrontmatterGDriveLink(t *testing.T) {
+	c := NewConverter(nil, "/out", false, false, Options{})
+	record := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/abc123/edit", Title: "Doc"}
+	want := "gdrive-link: \"https://docs.google.com/document/d/abc123/edit\"\n"
+
+	if fm := c.generateFrontmatter(record, "rev", "content", true, FrontmatterYAML); !strings.Contains(fm, want) {
+		t.Errorf("generateFrontmatter() missing gdrive-link:\n%s", fm)
+	}
+	if fm := c.generateFrontmatterStub(record, "content", true, FrontmatterYAML); !strings.Contains(fm, want) {
+		t.Errorf("generateFrontmatterStub() missing gdrive-link:\n%s", fm)
+	}
+}
+
+func TestWriteStubDocumentNoFrontmatter(t *testing.T) {
+	outputDir := t.TempDir()
+	c := NewConverter(nil, outputDir, false, false, Options{NoFrontmatter: true})
+
+	record := &csv.ConversionRecord{Title: "Feedback Form", Link: "https://docs.google.com/forms/d/abc123/viewform"}
+	if err := c.convertStubDocument(record, true); err != nil {
+		t.Fatalf("convertStubDocument() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "feedback-form.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.HasPrefix(string(content), "> Link: "+record.Link+"\n\n") {
+		t.Errorf("expected content to start with the link preamble, got:\n%s", content)
+	}
+	if strings.Contains(string(content), "hash-gdrive") {
+		t.Errorf("expected no frontmatter, got:\n%s", content)
+	}
+}
+
+func TestRewriteLinksURLVariants(t *testing.T) {
+	target := &csv.ConversionRecord{Title: "Rate Limits", Link: "https://docs.google.com/document/d/target1/edit?usp=drive_link"}
+	source := &csv.ConversionRecord{Title: "Overview"}
+
+	c := NewConverter(nil, t.TempDir(), false, false, Options{})
+	// Index by URL only, so the match cannot come from the file ID fallback
+	c.linkMap[LinkKey(target.Link)] = target
+
+	content := "[limits](https://docs.google.com/document/d/target1/edit?usp=sharing&authuser=0#heading=h.1)"
+	if got, want := c.rewriteLinks(content, source), "[limits](rate-limits.md)"; got != want {
+		t.Errorf("rewriteLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestTitlePrefixAndSuffix(t *testing.T) {
+	target := &csv.ConversionRecord{Title: "Rate Limits", Link: "https://docs.google.com/document/d/target1/edit", Fragments: []string{"reference"}}
+	source := &csv.ConversionRecord{Title: "Overview", Link: "https://docs.google.com/document/d/source1/edit", Fragments: []string{"reference"}}
+	content := "[limits](https://docs.google.com/document/d/target1/edit)"
+
+	tests := []struct {
+		name     string
+		opts     Options
+		wantFile string
+		wantLink string
+	}{
+		{
+			name:     "prefix only in title",
+			opts:     Options{TitlePrefix: "API: ", TitleSuffix: " (v2)"},
+			wantFile: "overview.md",
+			wantLink: "[limits](rate-limits.md)",
+		},
+		{
+			name:     "prefix in filename",
+			opts:     Options{TitlePrefix: "API: ", TitleSuffix: " (v2)", PrefixInFilename: true},
+			wantFile: "api-overview-v2.md",
+			wantLink: "[limits](api-rate-limits-v2.md)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			// The description is the unprefixed title only when taken from the title
+			tt.opts.DescriptionSource = DescriptionFromTitle
+			c := NewConverter(nil, outputDir, false, false, tt.opts)
+			c.linkMap[target.Link] = target
+
+			fm := c.generateFrontmatter(source, "rev", content, true, FrontmatterYAML)
+			if !strings.Contains(fm, `title: "API: Overview (v2)"`) {
+				t.Errorf("generateFrontmatter() missing prefixed title:\n%s", fm)
+			}
+			if !strings.Contains(fm, "description: Overview\n") {
+				t.Errorf("generateFrontmatter() description should not be prefixed:\n%s", fm)
+			}
+
+			if got := c.rewriteLinks(content, source); got != tt.wantLink {
+				t.Errorf("rewriteLinks() = %q, want %q", got, tt.wantLink)
+			}
+
+			if err := c.writeOutput(source, fm); err != nil {
+				t.Fatalf("writeOutput() error = %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, "reference", tt.wantFile)); err != nil {
+				t.Errorf("expected %s to be written: %v", tt.wantFile, err)
+			}
+		})
+	}
+}
+
+func TestWriteStubDocumentSourceLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		wantFirst string
+	}{
+		{
+			name:      "default template",
+			template:  DefaultSourceLinkTemplate,
+			wantFirst: "> **Source:** [View in Google Drive](https://docs.google.com/forms/d/abc123/viewform)",
+		},
+		{
+			name:      "custom template",
+			template:  "> Edit [{{.Title}}]({{.Link}})",
+			wantFirst: "> Edit [Feedback Form](https://docs.google.com/forms/d/abc123/viewform)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseSourceLinkTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("ParseSourceLinkTemplate() error = %v", err)
+			}
+
+			outputDir := t.TempDir()
+			c := NewConverter(nil, outputDir, false, false, Options{SourceLinkTemplate: tmpl})
+			record := &csv.ConversionRecord{Title: "Feedback Form", Link: "https://docs.google.com/forms/d/abc123/viewform"}
+			if err := c.convertStubDocument(record, true); err != nil {
+				t.Fatalf("convertStubDocument() error = %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "feedback-form.md"))
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+
+			_, body, _ := strings.Cut(string(content), "---\n\n")
+			if first, _, _ := strings.Cut(body, "\n"); first != tt.wantFirst {
+				t.Errorf("first content line = %q, want %q", first, tt.wantFirst)
+			}
+			if !strings.Contains(string(content), "hash-content: "+utils.CalculateStringHash(body)+"\n") {
+				t.Errorf("hash-content does not cover the source link:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestParseSourceLinkTemplate(t *testing.T) {
+	if _, err := ParseSourceLinkTemplate("{{.Link"); err == nil {
+		t.Error("ParseSourceLinkTemplate() expected error for malformed template")
+	}
+
+	tmpl, err := ParseSourceLinkTemplate("{{.Missing}}")
+	if err != nil {
+		t.Fatalf("ParseSourceLinkTemplate() error = %v", err)
+	}
+	if _, err := RenderSourceLink(tmpl, &csv.ConversionRecord{}); err == nil {
+		t.Error("RenderSourceLink() expected error for unknown field")
+	}
+}
+
+func TestGetFileMetadataRetriesRateLimit(t *testing.T) {
+	calls := 0
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc123", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"code":429,"message":"rate limited"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"doc123","mimeType":"application/vnd.google-apps.document"}`))
+	})
+
+	c := newTestConverter(t, fake, t.TempDir(), Options{
+		Retry: utils.RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond},
+	})
+
+	file, err := c.getFileMetadata("doc123")
+	if err != nil {
+		t.Fatalf("getFileMetadata() error = %v", err)
+	}
+	if file.Id != "doc123" || calls != 3 {
+		t.Errorf("getFileMetadata() = %q after %d calls, want doc123 after 3", file.Id, calls)
+	}
+}
+
+func TestExportRetriesServiceUnavailable(t *testing.T) {
+	calls := 0
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc123/export", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":{"code":503,"message":"backend unavailable"}}`))
+	})
+
+	const maxRetries = 3
+	c := newTestConverter(t, fake, t.TempDir(), Options{
+		Retry: utils.RetryConfig{MaxRetries: maxRetries, BaseDelay: time.Millisecond},
+	})
+
+	_, err := c.executeExportWithRetry("doc123", "text/markdown")
+	if !utils.IsRetryableError(err) {
+		t.Fatalf("executeExportWithRetry() error = %v, want the 503 error", err)
+	}
+	if calls != maxRetries+1 {
+		t.Errorf("export requested %d times, want %d (%d retries)", calls, maxRetries+1, maxRetries)
+	}
+}
+
+func TestExportRetryBudgetExhausted(t *testing.T) {
+	calls := 0
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc123/export", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"code":429,"message":"rate limited"}}`))
+	})
+
+	budget := utils.NewRetryBudget(3)
+	c := newTestConverter(t, fake, t.TempDir(), Options{
+		Retry: utils.RetryConfig{MaxRetries: 5, BaseDelay: time.Millisecond, Budget: budget},
+	})
+
+	// The first export uses the whole budget, so the second fails without retrying
+	_, err := c.executeExportWithRetry("doc123", "text/markdown")
+	if !errors.Is(err, utils.ErrRetryBudgetExhausted) {
+		t.Fatalf("executeExportWithRetry() error = %v, want %v", err, utils.ErrRetryBudgetExhausted)
+	}
+	if calls != 4 {
+		t.Errorf("export requested %d times, want 4 (3 retries)", calls)
+	}
+
+	calls = 0
+	_, err = c.executeExportWithRetry("doc123", "text/markdown")
+	if !errors.Is(err, utils.ErrRetryBudgetExhausted) || !strings.Contains(err.Error(), "retry budget exhausted") {
+		t.Fatalf("executeExportWithRetry() error = %v, want %v", err, utils.ErrRetryBudgetExhausted)
+	}
+	if calls != 1 {
+		t.Errorf("export requested %d times, want 1 with the budget used up", calls)
+	}
+	if got := budget.Used(); got != 3 {
+		t.Errorf("budget.Used() = %d, want 3", got)
+	}
+}
+
+func TestConvertMaxErrors(t *testing.T) {
+	var records []csv.ConversionRecord
+	for i := 0; i < 10; i++ {
+		records = append(records, csv.ConversionRecord{
+			Link:  fmt.Sprintf("https://docs.google.com/document/d/missing%d/edit", i),
+			Title: fmt.Sprintf("Doc %d", i),
+		})
+	}
+
+	failedPath := filepath.Join(t.TempDir(), "failed.csv")
+	fake := newFakeDrive()
+	c := newTestConverter(t, fake, t.TempDir(), Options{MaxErrors: 2, FailedOutput: failedPath})
+
+	err := c.Convert(context.Background(), records, 1)
+	if err == nil || !strings.Contains(err.Error(), "aborted after") {
+		t.Fatalf("Convert() error = %v, want aborted", err)
+	}
+
+	// Every record either failed or was never attempted, so all of them must be retried
+	failed, err := csv.ParseConversionCSV(failedPath)
+	if err != nil {
+		t.Fatalf("ParseConversionCSV() error = %v", err)
+	}
+	if len(failed) != len(records) {
+		t.Errorf("failed output has %d records, want %d", len(failed), len(records))
+	}
+
+	// The record in flight when the limit is hit may still be attempted
+	attempted := 0
+	for i := range records {
+		attempted += len(fake.requestsFor("GET", fmt.Sprintf("/files/missing%d", i)))
+	}
+	if attempted < 2 || attempted > 3 {
+		t.Errorf("attempted %d records, want 2 or 3", attempted)
+	}
+}
+
+func TestConvertUnlimitedErrors(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/document/d/missing1/edit", Title: "Doc 1"},
+		{Link: "https://docs.google.com/document/d/missing2/edit", Title: "Doc 2"},
+		{Link: "https://docs.google.com/document/d/missing3/edit", Title: "Doc 3"},
+	}
+
+	fake := newFakeDrive()
+	c := newTestConverter(t, fake, t.TempDir(), Options{})
+
+	err := c.Convert(context.Background(), records, 2)
+	if err == nil || !strings.Contains(err.Error(), "conversion had 3 errors") {
+		t.Errorf("Convert() error = %v, want 3 errors", err)
+	}
+	if got := len(fake.requestsFor("GET", "/files/missing1")) + len(fake.requestsFor("GET", "/files/missing2")) + len(fake.requestsFor("GET", "/files/missing3")); got != 3 {
+		t.Errorf("made %d metadata requests, want 3", got)
+	}
+}
+
+func TestConvertAppsScriptStub(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/script1", jsonHandler(`{"id":"script1","name":"Deploy Hook","mimeType":"application/vnd.google-apps.script","modifiedTime":"2024-01-15T10:30:00.000Z"}`))
+
+	outputDir := t.TempDir()
+	c := newTestConverter(t, fake, outputDir, Options{})
+	records := []csv.ConversionRecord{{Link: "https://drive.google.com/file/d/script1/view", Title: "Deploy Hook"}}
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "deploy-hook.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		"hash-gdrive: stub",
+		"*This is a Google Apps Script project. Apps Script projects cannot be exported to markdown format.*",
+		"[Open Deploy Hook in the Apps Script editor](https://script.google.com/d/script1/edit)",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("stub does not contain %q:\n%s", want, data)
+		}
+	}
+	if reqs := fake.requestsFor("GET", "/files/script1/export"); len(reqs) != 0 {
+		t.Errorf("made %d export requests, want none", len(reqs))
+	}
+}
+
+func TestConvertExternalShortcutRedirect(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/short1", jsonHandler(`{"id":"short1","name":"Vendor Docs","mimeType":"application/vnd.google-apps.shortcut","webViewLink":"https://drive.google.com/file/d/short1/view","shortcutDetails":{}}`))
+
+	for _, tt := range []struct {
+		format FrontmatterFormat
+		want   string
+	}{
+		{format: FrontmatterYAML, want: `redirect: "https://drive.google.com/file/d/short1/view"`},
+		{format: FrontmatterJSON, want: `"redirect": "https://drive.google.com/file/d/short1/view"`},
+	} {
+		t.Run(string(tt.format), func(t *testing.T) {
+			outputDir := t.TempDir()
+			c := newTestConverter(t, fake, outputDir, Options{FrontmatterFormat: tt.format})
+			records := []csv.ConversionRecord{{Link: "https://drive.google.com/file/d/short1/view", Title: "Vendor Docs"}}
+			if err := c.Convert(context.Background(), records, 1); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "vendor-docs.md"))
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			for _, want := range []string{tt.want, "*This is a shortcut to an external page: [Vendor Docs](https://drive.google.com/file/d/short1/view)*"} {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("redirect stub does not contain %q:\n%s", want, data)
+				}
+			}
+		})
+	}
+	if reqs := fake.requestsFor("GET", "/files/short1/export"); len(reqs) != 0 {
+		t.Errorf("made %d export requests, want none", len(reqs))
+	}
+}
+
+func TestConvertReportsProgress(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/forms/d/form1/viewform", Title: "Form 1"},
+		{Link: "https://docs.google.com/document/d/missing/edit", Title: "Missing"},
+		{Link: "https://docs.google.com/forms/d/form2/viewform", Title: "Form 2"},
+	}
+
+	var buf bytes.Buffer
+	c := newTestConverter(t, newFakeDrive(), t.TempDir(), Options{Progress: progress.NewProgressReporter(&buf)})
+	if err := c.Convert(context.Background(), records, 2); err == nil {
+		t.Fatal("Convert() error = nil, want the missing document to fail")
+	}
+
+	var events []progress.Event
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event progress.Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %q is not a JSON event: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != len(records)+1 {
+		t.Fatalf("got %d events, want one per record and a final one:\n%s", len(events), buf.String())
+	}
+
+	// Workers finish in any order, but done counts up and elapsed time never goes back
+	statuses := make(map[string]string)
+	for i, event := range events[:len(records)] {
+		if event.Done != i+1 || event.Total != len(records) {
+			t.Errorf("event %d has done %d of %d, want %d of %d", i, event.Done, event.Total, i+1, len(records))
+		}
+		if i > 0 && event.ElapsedMS < events[i-1].ElapsedMS {
+			t.Errorf("event %d elapsed %dms is before the previous event", i, event.ElapsedMS)
+		}
+		statuses[event.File] = event.Status
+	}
+	wantStatuses := map[string]string{records[0].Link: "ok", records[1].Link: "error", records[2].Link: "ok"}
+	if !reflect.DeepEqual(statuses, wantStatuses) {
+		t.Errorf("statuses = %v, want %v", statuses, wantStatuses)
+	}
+
+	final := events[len(events)-1]
+	if final.Status != progress.StatusFinished || final.Done != len(records) || final.Errors != 1 {
+		t.Errorf("final event = %+v, want finished with %d done and 1 error", final, len(records))
+	}
+}
+
+func TestConvertMIMEOverride(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc1", jsonHandler(`{"id":"doc1","name":"Notes","mimeType":"application/octet-stream","modifiedTime":"2024-01-15T10:30:00.000Z"}`))
+	fake.handle("GET", "/files/doc1/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Notes\n"))
+	})
+
+	outputDir := t.TempDir()
+	c := newTestConverter(t, fake, outputDir, Options{})
+	records := []csv.ConversionRecord{{
+		Link:         "https://drive.google.com/file/d/doc1/view",
+		Title:        "Notes",
+		MIMEOverride: "application/vnd.google-apps.document",
+	}}
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	reqs := fake.requestsFor("GET", "/files/doc1/export")
+	if len(reqs) != 1 || !reflect.DeepEqual(reqs[0].Query["mimeType"], []string{"text/markdown"}) {
+		t.Fatalf("made %d export requests, want one markdown export", len(reqs))
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, "notes.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "# Notes\n") || !strings.Contains(string(data), `hash-gdrive: "2024-01-15T10:30:00.000Z"`) {
+		t.Errorf("output = %q, want the exported document", data)
+	}
+
+	// Without the override the Drive type is used, which is not supported
+	records[0].MIMEOverride = ""
+	if err := c.Convert(context.Background(), records, 1); err == nil {
+		t.Error("Convert() error = nil, want an unsupported type error for application/octet-stream")
+	}
+}
+
+func TestConvertHashGdriveRevision(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "minimal.docx"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/docx1", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("alt") == "media" {
+			w.Write(fixture)
+			return
+		}
+		jsonHandler(`{"id":"docx1","name":"Onboarding.docx","mimeType":"`+docxMimeType+`","modifiedTime":"2024-01-15T10:30:00.000Z","headRevisionId":"0B-rev42"}`)(w, r)
+	})
+	fake.handle("GET", "/files/doc1", jsonHandler(`{"id":"doc1","name":"Guide","mimeType":"application/vnd.google-apps.document","modifiedTime":"2024-01-15T10:30:00.000Z"}`))
+	fake.handle("GET", "/files/doc1/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Guide\n"))
+	})
+
+	outputDir := t.TempDir()
+	c := newTestConverter(t, fake, outputDir, Options{})
+	records := []csv.ConversionRecord{
+		{Link: "https://drive.google.com/file/d/docx1/view", Title: "Onboarding"},
+		{Link: "https://docs.google.com/document/d/doc1/edit", Title: "Guide"},
+	}
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"onboarding.md": "hash-gdrive: 0B-rev42\n",
+		"guide.md":      `hash-gdrive: "2024-01-15T10:30:00.000Z"` + "\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+
+	reqs := fake.requestsFor("GET", "/files/docx1")
+	if len(reqs) == 0 || !strings.Contains(strings.Join(reqs[0].Query["fields"], ","), "headRevisionId") {
+		t.Errorf("metadata requests = %+v, want headRevisionId requested", reqs)
+	}
+}
+
+func TestConvertFrontmatterFormats(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc1", jsonHandler(`{"id":"doc1","name":"Guide","mimeType":"application/vnd.google-apps.document","modifiedTime":"2024-01-15T10:30:00.000Z"}`))
+	fake.handle("GET", "/files/doc1/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Guide\n"))
+	})
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantPrefix string
+	}{
+		{name: "yaml", opts: Options{}, wantPrefix: "---\n"},
+		{name: "toml", opts: Options{FrontmatterFormat: FrontmatterTOML}, wantPrefix: "+++\n"},
+		{name: "no frontmatter", opts: Options{NoFrontmatter: true}, wantPrefix: "> Link: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			c := newTestConverter(t, fake, outputDir, tt.opts)
+			records := []csv.ConversionRecord{{Link: "https://docs.google.com/document/d/doc1/edit", Title: "Guide"}}
+			if err := c.Convert(context.Background(), records, 1); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(outputDir, "guide.md"))
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !strings.HasPrefix(string(data), tt.wantPrefix) {
+				t.Errorf("output = %q, want it to start with %q", data, tt.wantPrefix)
+			}
+			if !strings.Contains(string(data), "# Guide\n") {
+				t.Errorf("output = %q, want the exported document", data)
+			}
+		})
+	}
+}
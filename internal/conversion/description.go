@@ -0,0 +1,110 @@
+package conversion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// DescriptionSource selects what the description frontmatter field is taken from
+type DescriptionSource string
+
+const (
+	DescriptionFromFirstParagraph DescriptionSource = "first-paragraph" // First paragraph of the content (default)
+	DescriptionFromTitle          DescriptionSource = "title"           // Document title
+)
+
+// MaxDescriptionLength is the number of characters a description is truncated to, the length
+// search results show
+const MaxDescriptionLength = 160
+
+var (
+	// descriptionSkipPattern matches lines that start a block other than a paragraph: headings,
+	// blockquotes such as the link preamble, tables, HTML, list items and horizontal rules
+	descriptionSkipPattern = regexp.MustCompile(`^(#{1,6}(\s|$)|>|\||<|([-*+]|\d+[.)])\s|([-*_])(\s*([-*_])){2,}$)`)
+
+	// imageLinePattern matches a line holding only an image
+	imageLinePattern = regexp.MustCompile(`^!\[[^\]]*\]\([^)]*\)$`)
+
+	// inlineLinkPattern matches inline images and links, keeping the text of links
+	inlineLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+
+	// emphasisPattern matches bold, italic, strikethrough and code markers
+	emphasisPattern = regexp.MustCompile("\\*\\*|__|~~|\\*|`")
+
+	// markdownEscapePattern matches backslash escapes, which Google Docs adds to exported text
+	markdownEscapePattern = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]()#+\\-.!|>~])")
+)
+
+// ValidateDescriptionSource returns an error if source is not a supported description source
+func ValidateDescriptionSource(source string) error {
+	switch DescriptionSource(source) {
+	case DescriptionFromFirstParagraph, DescriptionFromTitle:
+		return nil
+	default:
+		return fmt.Errorf("unknown description source %q (want %q or %q)", source, DescriptionFromFirstParagraph, DescriptionFromTitle)
+	}
+}
+
+// FirstParagraph returns the first paragraph of markdown content as plain text, truncated to
+// maxLen characters. Headings, blockquotes, code blocks, tables, HTML, lists, images and
+// horizontal rules are skipped. Returns an empty string when the content has no paragraph.
+func FirstParagraph(content string, maxLen int) string {
+	var lines []string
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			if len(lines) > 0 {
+				break
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" || descriptionSkipPattern.MatchString(trimmed) || imageLinePattern.MatchString(trimmed) {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+
+	text := strings.Join(lines, " ")
+	text = inlineLinkPattern.ReplaceAllString(text, "$1")
+	text = emphasisPattern.ReplaceAllString(text, "")
+	text = markdownEscapePattern.ReplaceAllString(text, "$1")
+	return truncateText(strings.Join(strings.Fields(text), " "), maxLen)
+}
+
+// truncateText shortens text to at most maxLen characters, cutting at the last word that fits
+// and marking the cut with "..."
+func truncateText(text string, maxLen int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLen {
+		return text
+	}
+
+	cut := string(runes[:maxLen-len("...")])
+	// Cut at a word boundary unless that would drop most of the text
+	if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:.") + "..."
+}
+
+// description returns the description field for a document: its first paragraph, or its
+// title with DescriptionFromTitle or when the content has no paragraph
+func (c *Converter) description(record *csv.ConversionRecord, content string) string {
+	if c.opts.DescriptionSource != DescriptionFromTitle {
+		if paragraph := FirstParagraph(content, MaxDescriptionLength); paragraph != "" {
+			return paragraph
+		}
+	}
+	return record.Title
+}
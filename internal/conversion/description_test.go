@@ -0,0 +1,124 @@
+package conversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestFirstParagraph(t *testing.T) {
+	long := strings.Repeat("Deployments roll out in waves across regions. ", 5)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "empty", content: "", want: ""},
+		{name: "only headings", content: "# Setup\n\n## Tools\n\n### Editors\n", want: ""},
+		{
+			name:    "short paragraph after heading",
+			content: "# Setup\n\nInstall the tools.\n\nThen log in.\n",
+			want:    "Install the tools.",
+		},
+		{
+			name:    "starts with blockquote",
+			content: "> Link: https://docs.google.com/document/d/abc/edit\n\n> **Note:** draft\n> still open\n\nThe real introduction.\n",
+			want:    "The real introduction.",
+		},
+		{
+			name:    "lines of a paragraph are joined",
+			content: "First line\nsecond line\n\nNext paragraph\n",
+			want:    "First line second line",
+		},
+		{
+			name:    "inline markdown is stripped",
+			content: "See the **[runbook](https://example.com/runbook)** for `kubectl` *tips* in v1\\.2.\n",
+			want:    "See the runbook for kubectl tips in v1.2.",
+		},
+		{
+			name:    "skips code, lists, tables, images and rules",
+			content: "```\ncode line\n```\n\n- item\n1. step\n\n| a | b |\n|---|---|\n\n![diagram](assets/d.png)\n\n---\n\n<div>html</div>\n\nFinally prose.\n",
+			want:    "Finally prose.",
+		},
+		{
+			name:    "hashtag is not a heading",
+			content: "#oncall owns this page.\n",
+			want:    "#oncall owns this page.",
+		},
+		{
+			name:    "exactly the maximum length",
+			content: strings.Repeat("a", MaxDescriptionLength),
+			want:    strings.Repeat("a", MaxDescriptionLength),
+		},
+		{
+			name:    "long paragraph is cut at a word",
+			content: long,
+			want:    "Deployments roll out in waves across regions. Deployments roll out in waves across regions. Deployments roll out in waves across regions. Deployments roll...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FirstParagraph(tt.content, MaxDescriptionLength)
+			if got != tt.want {
+				t.Errorf("FirstParagraph() = %q, want %q", got, tt.want)
+			}
+			if n := len([]rune(got)); n > MaxDescriptionLength {
+				t.Errorf("FirstParagraph() has %d characters, want at most %d", n, MaxDescriptionLength)
+			}
+		})
+	}
+}
+
+func TestTruncateTextWithoutSpaces(t *testing.T) {
+	got := truncateText(strings.Repeat("é", 200), MaxDescriptionLength)
+	if want := strings.Repeat("é", MaxDescriptionLength-3) + "..."; got != want {
+		t.Errorf("truncateText() = %q, want %q", got, want)
+	}
+}
+
+func TestFrontmatterDescription(t *testing.T) {
+	record := &csv.ConversionRecord{Title: "Setup", Link: "https://docs.google.com/document/d/abc/edit"}
+	content := "> Link: https://docs.google.com/document/d/abc/edit\n\n# Setup\n\nInstall the tools.\n"
+
+	tests := []struct {
+		name    string
+		source  DescriptionSource
+		content string
+		want    string
+	}{
+		{name: "first paragraph by default", content: content, want: "Install the tools."},
+		{name: "title", source: DescriptionFromTitle, content: content, want: "Setup"},
+		{name: "no paragraph falls back to title", content: "# Setup\n", want: "Setup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConverter(nil, t.TempDir(), false, false, Options{DescriptionSource: tt.source})
+			fm, _ := c.frontmatterFields(record, "rev", tt.content, true)
+			if got := fm["description"]; got != tt.want {
+				t.Errorf("description = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Stubs keep the title, as their content only explains why the file was not converted
+	c := NewConverter(nil, t.TempDir(), false, false, Options{})
+	fm := c.generateFrontmatterStub(record, "*This is a Google Form. Forms cannot be exported.*", true, FrontmatterYAML)
+	if !strings.Contains(fm, "description: Setup\n") {
+		t.Errorf("generateFrontmatterStub() = %q, want the title as description", fm)
+	}
+}
+
+func TestValidateDescriptionSource(t *testing.T) {
+	for _, source := range []string{"first-paragraph", "title"} {
+		if err := ValidateDescriptionSource(source); err != nil {
+			t.Errorf("ValidateDescriptionSource(%q) error = %v", source, err)
+		}
+	}
+	if err := ValidateDescriptionSource("summary"); err == nil {
+		t.Error("ValidateDescriptionSource(\"summary\") error = nil, want an error")
+	}
+}
@@ -0,0 +1,182 @@
+package conversion
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// docxBodyPart is the zip entry of a .docx file holding the document body
+const docxBodyPart = "word/document.xml"
+
+// docxHeadingStyle matches the built-in heading paragraph styles, e.g. Heading1
+var docxHeadingStyle = regexp.MustCompile(`^(?i)heading\s*([1-6])$`)
+
+// docxParagraph collects the text of a w:p element while it is decoded
+type docxParagraph struct {
+	style  string
+	isList bool
+	text   strings.Builder
+}
+
+// markdown returns the paragraph as a markdown block, or an empty string for an empty paragraph
+func (p *docxParagraph) markdown() string {
+	text := strings.TrimSpace(p.text.String())
+	if text == "" {
+		return ""
+	}
+	if matches := docxHeadingStyle.FindStringSubmatch(p.style); matches != nil {
+		level, _ := strconv.Atoi(matches[1])
+		return strings.Repeat("#", level) + " " + text
+	}
+	if strings.EqualFold(p.style, "Title") {
+		return "# " + text
+	}
+	if p.isList {
+		return "- " + text
+	}
+	return text
+}
+
+// convertDOCXToMarkdown converts a Word document to markdown. Paragraphs with a heading or
+// title style become headings, numbered and bulleted paragraphs become list items and all
+// other paragraphs become plain text; formatting, tables and images are not kept.
+func convertDOCXToMarkdown(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docx: %w", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docx: %w", err)
+	}
+
+	var part *zip.File
+	for _, f := range archive.File {
+		if f.Name == docxBodyPart {
+			part = f
+			break
+		}
+	}
+	if part == nil {
+		return nil, fmt.Errorf("docx has no %s", docxBodyPart)
+	}
+	body, err := part.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", docxBodyPart, err)
+	}
+	defer body.Close()
+
+	var blocks []string
+	var para *docxParagraph
+	inText := false
+	decoder := xml.NewDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", docxBodyPart, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para = &docxParagraph{}
+			case "pStyle":
+				if para != nil {
+					para.style = docxAttr(t, "val")
+				}
+			case "numPr":
+				if para != nil {
+					para.isList = true
+				}
+			case "t":
+				inText = true
+			case "tab":
+				if para != nil {
+					para.text.WriteString("\t")
+				}
+			case "br", "cr":
+				if para != nil {
+					para.text.WriteString("  \n")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p":
+				if para != nil {
+					if block := para.markdown(); block != "" {
+						blocks = append(blocks, block)
+					}
+				}
+				para = nil
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText && para != nil {
+				para.text.Write(t)
+			}
+		}
+	}
+
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+	return []byte(joinDOCXBlocks(blocks) + "\n"), nil
+}
+
+// joinDOCXBlocks joins markdown blocks with blank lines, keeping consecutive list items together
+func joinDOCXBlocks(blocks []string) string {
+	var sb strings.Builder
+	for i, block := range blocks {
+		if i > 0 {
+			if strings.HasPrefix(block, "- ") && strings.HasPrefix(blocks[i-1], "- ") {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(block)
+	}
+	return sb.String()
+}
+
+// docxAttr returns the value of the attribute with the given local name, ignoring its namespace
+func docxAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// convertDOCX downloads a Word document and converts it to markdown locally. If the document
+// cannot be parsed it falls back to converting it via Google Docs.
+func (c *Converter) convertDOCX(record *csv.ConversionRecord, fileID, revisionHash string) ([]byte, string, error) {
+	body, err := c.executeDownloadWithRetry(fileID)
+	if err != nil {
+		return nil, "", err
+	}
+	defer body.Close()
+
+	content, err := convertDOCXToMarkdown(body)
+	if err == nil {
+		return content, revisionHash, nil
+	}
+
+	log.Printf("Warning: Failed to convert %s locally, falling back to Google Docs conversion: %v", record.Title, err)
+	return c.convertPDFViaGoogleDocs(record, fileID, revisionHash)
+}
@@ -0,0 +1,69 @@
+package conversion
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestConvertDOCXToMarkdown(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "minimal.docx"))
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer f.Close()
+
+	got, err := convertDOCXToMarkdown(f)
+	if err != nil {
+		t.Fatalf("convertDOCXToMarkdown() error = %v", err)
+	}
+	want := "# Onboarding\n\n## First day\n\nCollect your badge at reception.\n\n- Laptop\n- Accounts\n\nAsk your manager  \nfor the rest.\n"
+	if string(got) != want {
+		t.Errorf("convertDOCXToMarkdown() = %q, want %q", got, want)
+	}
+}
+
+func TestConvertDOCXToMarkdownInvalid(t *testing.T) {
+	if _, err := convertDOCXToMarkdown(strings.NewReader("not a zip")); err == nil {
+		t.Error("convertDOCXToMarkdown() error = nil, want an error for a file that is not a docx")
+	}
+}
+
+func TestConvertDOCXWithoutGoogleDocsCopy(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "minimal.docx"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/docx1", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("alt") == "media" {
+			w.Write(fixture)
+			return
+		}
+		jsonHandler(`{"id": "docx1", "name": "Onboarding.docx", "mimeType": "`+docxMimeType+`", "modifiedTime": "2024-01-01T00:00:00.000Z"}`)(w, r)
+	})
+
+	outDir := t.TempDir()
+	c := newTestConverter(t, fake, outDir, Options{})
+	records := []csv.ConversionRecord{{Link: "https://drive.google.com/file/d/docx1/view", Title: "Onboarding"}}
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "onboarding.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !strings.Contains(string(content), "## First day\n\nCollect your badge at reception.") {
+		t.Errorf("output = %q, want the converted document", content)
+	}
+	if len(fake.requestsFor("POST", "/files/docx1/copy")) != 0 {
+		t.Error("Google Docs copy created, want the docx converted locally")
+	}
+}
@@ -0,0 +1,83 @@
+package conversion
+
+import (
+	"errors"
+
+	"google.golang.org/api/googleapi"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// Kinds of record failures, matched with errors.Is
+var (
+	ErrFileNotFound     = errors.New("file not found")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrExportFailed     = errors.New("export failed") // The file could not be read from or exported by Drive
+	ErrWriteFailed      = errors.New("write failed")
+	ErrUnsupportedType  = errors.New("unsupported file type")
+	ErrProcessingFailed = errors.New("processing failed") // A content pipeline stage failed (see StageError)
+)
+
+// errorTypes names each kind of failure in reports
+var errorTypes = map[error]string{
+	ErrFileNotFound:     "file_not_found",
+	ErrPermissionDenied: "permission_denied",
+	ErrExportFailed:     "export_failed",
+	ErrWriteFailed:      "write_failed",
+	ErrUnsupportedType:  "unsupported_type",
+	ErrProcessingFailed: "processing_failed",
+}
+
+// RecordError is a failure to convert or sync one file. errors.Is matches both its kind and
+// the underlying error.
+type RecordError struct {
+	Kind   error  // One of ErrFileNotFound, ErrPermissionDenied, ErrExportFailed, ErrWriteFailed, ErrUnsupportedType or ErrProcessingFailed
+	FileID string // Drive file ID (empty if unknown)
+	Title  string // Record or Drive file title (empty if unknown)
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// ErrorType returns the report name of an error's kind, such as "export_failed". Errors that
+// are not RecordErrors are "other", and a nil error is "".
+func ErrorType(err error) string {
+	if err == nil {
+		return ""
+	}
+	var recordErr *RecordError
+	if errors.As(err, &recordErr) {
+		if name, ok := errorTypes[recordErr.Kind]; ok {
+			return name
+		}
+	}
+	return "other"
+}
+
+// APIErrorKind returns the kind of a failed Drive API call: ErrFileNotFound for 404,
+// ErrPermissionDenied for 403 and fallback for anything else
+func APIErrorKind(err error, fallback error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case 404:
+			return ErrFileNotFound
+		case 403:
+			return ErrPermissionDenied
+		}
+	}
+	return fallback
+}
+
+// newRecordError returns err as a RecordError of the given kind for record
+func newRecordError(kind error, record *csv.ConversionRecord, err error) *RecordError {
+	fileID, _ := utils.ExtractFileID(record.Link)
+	return &RecordError{Kind: kind, FileID: fileID, Title: record.Title, Err: err}
+}
@@ -0,0 +1,122 @@
+package conversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "untyped", err: errors.New("boom"), want: "other"},
+		{name: "not found", err: &RecordError{Kind: ErrFileNotFound, Err: errors.New("gone")}, want: "file_not_found"},
+		{name: "write failed", err: &RecordError{Kind: ErrWriteFailed, Err: errors.New("disk full")}, want: "write_failed"},
+		{name: "wrapped", err: fmt.Errorf("context: %w", &RecordError{Kind: ErrExportFailed, Err: errors.New("500")}), want: "export_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorType(tt.err); got != tt.want {
+				t.Errorf("ErrorType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordErrorMatchesKindAndCause(t *testing.T) {
+	cause := &googleapi.Error{Code: 404}
+	err := newRecordError(APIErrorKind(cause, ErrExportFailed), &csv.ConversionRecord{Link: "https://docs.google.com/document/d/doc1/edit", Title: "Doc"}, cause)
+
+	if !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("errors.Is(err, ErrFileNotFound) = false")
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		t.Errorf("errors.As(err, *googleapi.Error) = false")
+	}
+	if err.FileID != "doc1" || err.Title != "Doc" {
+		t.Errorf("FileID, Title = %q, %q, want doc1, Doc", err.FileID, err.Title)
+	}
+
+	if kind := APIErrorKind(&googleapi.Error{Code: 403}, ErrExportFailed); kind != ErrPermissionDenied {
+		t.Errorf("APIErrorKind(403) = %v, want %v", kind, ErrPermissionDenied)
+	}
+	if kind := APIErrorKind(errors.New("connection reset"), ErrExportFailed); kind != ErrExportFailed {
+		t.Errorf("APIErrorKind(network error) = %v, want %v", kind, ErrExportFailed)
+	}
+}
+
+func TestConvertRecordPipelineError(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc1", jsonHandler(`{"id":"doc1","name":"Guide","mimeType":"application/vnd.google-apps.document","modifiedTime":"2024-01-15T10:30:00.000Z"}`))
+	fake.handle("GET", "/files/doc1/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Guide\n"))
+	})
+
+	c := newTestConverter(t, fake, t.TempDir(), Options{})
+	cause := errors.New("boom")
+	c.pipeline.Add("fail", func(content string, record *csv.ConversionRecord) (string, error) {
+		return "", cause
+	})
+
+	err := c.convertRecord(&csv.ConversionRecord{Link: "https://docs.google.com/document/d/doc1/edit", Title: "Guide"})
+	var recordErr *RecordError
+	if !errors.As(err, &recordErr) || recordErr.Kind != ErrProcessingFailed || recordErr.FileID != "doc1" || recordErr.Title != "Guide" {
+		t.Fatalf("convertRecord() error = %#v, want a processing_failed RecordError for doc1", err)
+	}
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != "fail" {
+		t.Errorf("convertRecord() error = %v, want a StageError for stage fail", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(err, cause) = false")
+	}
+	if got := ErrorType(err); got != "processing_failed" {
+		t.Errorf("ErrorType() = %q, want processing_failed", got)
+	}
+}
+
+func TestConvertFailedOutputErrorTypes(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/document/d/missing1/edit", Title: "Missing"},
+	}
+
+	failedPath := filepath.Join(t.TempDir(), "failed.csv")
+	c := newTestConverter(t, newFakeDrive(), t.TempDir(), Options{FailedOutput: failedPath})
+	if err := c.Convert(context.Background(), records, 1); err == nil {
+		t.Fatalf("Convert() error = nil, want an error")
+	}
+
+	data, err := os.ReadFile(failedPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "link,title,tags,frag1,frag2,frag3,frag4,frag5,error_type\n" +
+		"https://docs.google.com/document/d/missing1/edit,Missing,,,,,,,file_not_found\n"
+	if string(data) != want {
+		t.Errorf("failed output = %q, want %q", data, want)
+	}
+
+	// The failed output can be converted again as is
+	parsed, err := csv.ParseConversionCSV(failedPath)
+	if err != nil {
+		t.Fatalf("ParseConversionCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("parsed = %+v, want %+v", parsed, records)
+	}
+}
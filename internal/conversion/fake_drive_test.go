@@ -0,0 +1,98 @@
+package conversion
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// recordedRequest captures the parts of a request that tests assert on
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  map[string][]string
+	Body   string
+}
+
+// fakeDrive is a minimal Drive API server that dispatches to per-route handlers
+type fakeDrive struct {
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc // Keyed by "METHOD /path"
+	requests []recordedRequest
+}
+
+func newFakeDrive() *fakeDrive {
+	return &fakeDrive{handlers: make(map[string]http.HandlerFunc)}
+}
+
+// handle registers a handler for the given method and path
+func (f *fakeDrive) handle(method, path string, handler http.HandlerFunc) {
+	f.handlers[method+" "+path] = handler
+}
+
+// requestsFor returns the recorded requests for the given method and path
+func (f *fakeDrive) requestsFor(method, path string) []recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var reqs []recordedRequest
+	for _, r := range f.requests {
+		if r.Method == method && r.Path == path {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+func (f *fakeDrive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.requests = append(f.requests, recordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Body:   string(body),
+	})
+	f.mu.Unlock()
+
+	handler, ok := f.handlers[r.Method+" "+r.URL.Path]
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+		return
+	}
+	handler(w, r)
+}
+
+// newTestConverter returns a Converter backed by the fake Drive server
+func newTestConverter(t *testing.T, fake *fakeDrive, outputDir string, opts Options) *Converter {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	service, err := drive.NewService(context.Background(),
+		option.WithHTTPClient(server.Client()),
+		option.WithEndpoint(server.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create Drive service: %v", err)
+	}
+
+	return NewConverter(service, outputDir, false, false, opts)
+}
+
+// jsonHandler returns a handler that responds with the given JSON body
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
@@ -0,0 +1,231 @@
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FrontmatterFormat selects the syntax used for generated frontmatter
+type FrontmatterFormat string
+
+const (
+	FrontmatterYAML FrontmatterFormat = "yaml" // --- delimited YAML (default)
+	FrontmatterTOML FrontmatterFormat = "toml" // +++ delimited TOML
+	FrontmatterJSON FrontmatterFormat = "json" // JSON object at the start of the file
+)
+
+// frontmatterKeys is the order in which frontmatter fields are written
+var frontmatterKeys = []string{"canonical_link", "description", "editor", "gdrive-link", "hash-gdrive", "hash-content", "language", "published", "redirect", "tags", "title"}
+
+// frontmatterKeyPattern matches the names allowed for extra frontmatter fields
+var frontmatterKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// ValidateFrontmatterFormat returns an error if format is not a supported frontmatter format
+func ValidateFrontmatterFormat(format string) error {
+	switch FrontmatterFormat(format) {
+	case FrontmatterYAML, FrontmatterTOML, FrontmatterJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown frontmatter format %q (want %q, %q or %q)", format, FrontmatterYAML, FrontmatterTOML, FrontmatterJSON)
+	}
+}
+
+// RenderFrontmatter renders frontmatter fields in the given format. The standard fields are
+// written in a fixed order, followed by any other fields sorted by key. The tags field is
+// written from tags, as a sequence in YAML and as an array in TOML and JSON, and left out when
+// tags is nil; a tags entry in fm is ignored. In TOML and JSON, published is written as a
+// boolean, and in TOML, hash-gdrive is written as a datetime when it is one.
+func RenderFrontmatter(fm map[string]string, tags []string, format FrontmatterFormat) string {
+	fields := make(map[string]string, len(fm)+1)
+	for key, value := range fm {
+		if key != "tags" {
+			fields[key] = value
+		}
+	}
+	if tags != nil {
+		// Only marks where the field goes; the renderers write tags itself
+		fields["tags"] = ""
+	}
+
+	switch format {
+	case FrontmatterTOML:
+		return renderTOMLFrontmatter(fields, tags)
+	case FrontmatterJSON:
+		return renderJSONFrontmatter(fields, tags)
+	default:
+		return renderYAMLFrontmatter(fields, tags)
+	}
+}
+
+func renderYAMLFrontmatter(fm map[string]string, tags []string) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, key := range renderedKeys(fm) {
+		if key == "tags" {
+			writeYAMLTags(&sb, tags)
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s\n", key, escapeYAML(fm[key])))
+	}
+	sb.WriteString("---\n")
+	return sb.String()
+}
+
+// writeYAMLTags writes tags as a block sequence, which Wiki.js reads more reliably than an
+// inline string
+func writeYAMLTags(sb *strings.Builder, tags []string) {
+	if len(tags) == 0 {
+		sb.WriteString("tags: []\n")
+		return
+	}
+	sb.WriteString("tags:\n")
+	for _, tag := range tags {
+		sb.WriteString(fmt.Sprintf("  - %s\n", escapeYAML(tag)))
+	}
+}
+
+func renderTOMLFrontmatter(fm map[string]string, tags []string) string {
+	var sb strings.Builder
+	sb.WriteString("+++\n")
+	for _, key := range renderedKeys(fm) {
+		value := fm[key]
+
+		var encoded string
+		switch {
+		case key == "tags":
+			quoted := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				quoted = append(quoted, quoteTOML(tag))
+			}
+			encoded = "[" + strings.Join(quoted, ", ") + "]"
+		case key == "published" && (value == "true" || value == "false"):
+			encoded = value
+		case key == "hash-gdrive" && isDateTime(value):
+			encoded = value
+		default:
+			encoded = quoteTOML(value)
+		}
+		sb.WriteString(fmt.Sprintf("%s = %s\n", key, encoded))
+	}
+	sb.WriteString("+++\n")
+	return sb.String()
+}
+
+func renderJSONFrontmatter(fm map[string]string, tags []string) string {
+	var fields []string
+	for _, key := range renderedKeys(fm) {
+		value := fm[key]
+
+		var encoded any = value
+		switch {
+		case key == "tags":
+			encoded = append([]string{}, tags...)
+		case key == "published" && (value == "true" || value == "false"):
+			encoded = value == "true"
+		}
+
+		data, _ := json.Marshal(encoded)
+		fields = append(fields, fmt.Sprintf("  %q: %s", key, data))
+	}
+	return "{\n" + strings.Join(fields, ",\n") + "\n}\n"
+}
+
+// renderedKeys returns the keys of fm in the order they are written: the standard fields in
+// their fixed order, then the other fields sorted
+func renderedKeys(fm map[string]string) []string {
+	keys := make([]string, 0, len(fm))
+	for _, key := range frontmatterKeys {
+		if _, exists := fm[key]; exists {
+			keys = append(keys, key)
+		}
+	}
+
+	var extra []string
+	for key := range fm {
+		if !slices.Contains(frontmatterKeys, key) {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+// ParseFrontmatterExtra parses key=value pairs into extra frontmatter fields, added to
+// defaults. A later pair replaces an earlier value or default for the same key. Standard
+// fields such as title cannot be set.
+func ParseFrontmatterExtra(pairs []string, defaults map[string]string) (map[string]string, error) {
+	extra := make(map[string]string, len(defaults)+len(pairs))
+	for key, value := range defaults {
+		extra[key] = value
+	}
+
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q (want key=value)", pair)
+		}
+		key = strings.TrimSpace(key)
+		if err := validateExtraKey(key); err != nil {
+			return nil, err
+		}
+		extra[key] = value
+	}
+	return extra, nil
+}
+
+// LoadFrontmatterDefaults reads extra frontmatter fields from a JSON object. Values must be
+// strings, numbers or booleans.
+func LoadFrontmatterDefaults(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frontmatter defaults: %w", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter defaults %s: %w", path, err)
+	}
+
+	defaults := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if err := validateExtraKey(key); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		switch value.(type) {
+		case string, float64, bool:
+			defaults[key] = fmt.Sprint(value)
+		default:
+			return nil, fmt.Errorf("%s: field %q must be a string, number or boolean", path, key)
+		}
+	}
+	return defaults, nil
+}
+
+// validateExtraKey returns an error if key cannot be used for an extra frontmatter field
+func validateExtraKey(key string) error {
+	if slices.Contains(frontmatterKeys, key) {
+		return fmt.Errorf("field %q is set by the converter and cannot be overridden", key)
+	}
+	if !frontmatterKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid field name %q", key)
+	}
+	return nil
+}
+
+// quoteTOML quotes s as a TOML basic string
+func quoteTOML(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// isDateTime reports whether s is an RFC 3339 timestamp, which TOML accepts as a datetime literal
+func isDateTime(s string) bool {
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
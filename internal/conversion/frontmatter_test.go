@@ -0,0 +1,194 @@
+package conversion
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestRenderFrontmatter(t *testing.T) {
+	tags := []string{"tutorial", "beginner"}
+	fm := map[string]string{
+		"description":  "Getting Started",
+		"editor":       "markdown",
+		"gdrive-link":  "https://docs.google.com/document/d/abc123/edit",
+		"hash-gdrive":  "2024-01-15T10:30:00.000Z",
+		"hash-content": "abc123",
+		"published":    "true",
+		"title":        `Say "hi"`,
+	}
+
+	tests := []struct {
+		name   string
+		fm     map[string]string
+		tags   []string
+		format FrontmatterFormat
+		want   string
+	}{
+		{
+			name:   "yaml",
+			fm:     fm,
+			tags:   tags,
+			format: FrontmatterYAML,
+			want: `---
+description: Getting Started
+editor: markdown
+gdrive-link: "https://docs.google.com/document/d/abc123/edit"
+hash-gdrive: "2024-01-15T10:30:00.000Z"
+hash-content: abc123
+published: true
+tags:
+  - tutorial
+  - beginner
+title: "Say \"hi\""
+---
+`,
+		},
+		{
+			name:   "toml",
+			fm:     fm,
+			tags:   tags,
+			format: FrontmatterTOML,
+			want: `+++
+description = "Getting Started"
+editor = "markdown"
+gdrive-link = "https://docs.google.com/document/d/abc123/edit"
+hash-gdrive = 2024-01-15T10:30:00.000Z
+hash-content = "abc123"
+published = true
+tags = ["tutorial", "beginner"]
+title = "Say \"hi\""
++++
+`,
+		},
+		{
+			name:   "yaml extra fields after the standard ones",
+			fm:     map[string]string{"title": "Guide", "reviewed-by": "Ops: on call", "department": "Platform"},
+			format: FrontmatterYAML,
+			want: `---
+title: Guide
+department: Platform
+reviewed-by: "Ops: on call"
+---
+`,
+		},
+		{
+			name:   "toml stub hash stays a string",
+			fm:     map[string]string{"hash-gdrive": "stub"},
+			tags:   []string{},
+			format: FrontmatterTOML,
+			want: `+++
+hash-gdrive = "stub"
+tags = []
++++
+`,
+		},
+		{
+			name:   "json",
+			fm:     fm,
+			tags:   tags,
+			format: FrontmatterJSON,
+			want: `{
+  "description": "Getting Started",
+  "editor": "markdown",
+  "gdrive-link": "https://docs.google.com/document/d/abc123/edit",
+  "hash-gdrive": "2024-01-15T10:30:00.000Z",
+  "hash-content": "abc123",
+  "published": true,
+  "tags": ["tutorial","beginner"],
+  "title": "Say \"hi\""
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderFrontmatter(tt.fm, tt.tags, tt.format); got != tt.want {
+				t.Errorf("RenderFrontmatter() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFrontmatterFormat(t *testing.T) {
+	for _, format := range []string{"yaml", "toml", "json"} {
+		if err := ValidateFrontmatterFormat(format); err != nil {
+			t.Errorf("ValidateFrontmatterFormat(%q) error = %v", format, err)
+		}
+	}
+	if err := ValidateFrontmatterFormat("xml"); err == nil {
+		t.Error("ValidateFrontmatterFormat(\"xml\") expected error")
+	}
+}
+
+func TestParseFrontmatterExtra(t *testing.T) {
+	defaults := map[string]string{"department": "Platform", "author": "Docs team"}
+
+	got, err := ParseFrontmatterExtra([]string{"author=Jo Doe", "reviewed-by=ops=oncall", " empty="}, defaults)
+	if err != nil {
+		t.Fatalf("ParseFrontmatterExtra() error = %v", err)
+	}
+	want := map[string]string{"department": "Platform", "author": "Jo Doe", "reviewed-by": "ops=oncall", "empty": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFrontmatterExtra() = %v, want %v", got, want)
+	}
+
+	for _, pair := range []string{"title=Overridden", "hash-content=0", "no-equals", "=value", "bad key=value"} {
+		if _, err := ParseFrontmatterExtra([]string{pair}, nil); err == nil {
+			t.Errorf("ParseFrontmatterExtra(%q) error = nil, want an error", pair)
+		}
+	}
+}
+
+func TestLoadFrontmatterDefaults(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+
+	got, err := LoadFrontmatterDefaults(write("defaults.json", `{"department": "Platform", "version": 2, "reviewed": true}`))
+	if err != nil {
+		t.Fatalf("LoadFrontmatterDefaults() error = %v", err)
+	}
+	want := map[string]string{"department": "Platform", "version": "2", "reviewed": "true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFrontmatterDefaults() = %v, want %v", got, want)
+	}
+
+	for name, content := range map[string]string{
+		"standard.json": `{"published": "false"}`,
+		"nested.json":   `{"owner": {"name": "Jo"}}`,
+		"invalid.json":  `department: Platform`,
+	} {
+		if _, err := LoadFrontmatterDefaults(write(name, content)); err == nil {
+			t.Errorf("LoadFrontmatterDefaults(%s) error = nil, want an error", name)
+		}
+	}
+}
+
+func TestFrontmatterFieldsExtra(t *testing.T) {
+	c := NewConverter(nil, t.TempDir(), false, true, Options{
+		// Set directly, bypassing ParseFrontmatterExtra, to check standard fields still win
+		FrontmatterExtra: map[string]string{"department": "Platform", "title": "Overridden", "published": "false"},
+	})
+	record := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/abc123/edit", Title: "Guide"}
+
+	got := c.generateFrontmatter(record, "2024-01-15T10:30:00.000Z", "Body", true, FrontmatterYAML)
+	for _, want := range []string{"published: true\n", "title: Guide\ndepartment: Platform\n---\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generateFrontmatter() =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Overridden") {
+		t.Errorf("generateFrontmatter() =\n%s\nextra field replaced a standard one", got)
+	}
+}
@@ -0,0 +1,87 @@
+package conversion
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// ExportFormat selects the format Google Workspace documents are exported in
+type ExportFormat string
+
+const (
+	ExportFormatMarkdown ExportFormat = "markdown" // Drive API markdown export (default)
+	ExportFormatHTML     ExportFormat = "html"     // Drive API HTML export, written as .html files
+)
+
+// htmlMimeType is the export MIME type used for ExportFormatHTML
+const htmlMimeType = "text/html"
+
+// ValidateExportFormat returns an error if format is not a supported export format
+func ValidateExportFormat(format string) error {
+	switch ExportFormat(format) {
+	case ExportFormatMarkdown, ExportFormatHTML:
+		return nil
+	default:
+		return fmt.Errorf("unknown export format %q (want %q or %q)", format, ExportFormatMarkdown, ExportFormatHTML)
+	}
+}
+
+// ConvertHTMLToMarkdown converts exported HTML to markdown. Google Docs exports style their
+// elements through classes defined in a <style> element; the styling is dropped, including
+// the bold and italic text it sets.
+func ConvertHTMLToMarkdown(html []byte) ([]byte, error) {
+	converter := md.NewConverter("", true, nil)
+	converter.Remove("style", "script")
+
+	markdown, err := converter.ConvertBytes(html)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert HTML to markdown: %w", err)
+	}
+	return markdown, nil
+}
+
+// exportAsHTML exports a Google Workspace document as HTML
+func (c *Converter) exportAsHTML(fileID string) ([]byte, string, error) {
+	file, err := c.getFileMetadata(fileID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	body, err := c.executeExportWithRetry(fileID, htmlMimeType)
+	if err != nil {
+		return nil, "", err
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return content, RevisionHash(file), nil
+}
+
+// writeHTMLDocument writes an exported HTML document as a .html file. The markdown
+// post-processing pipeline and the transformer do not apply to HTML, so the document is
+// written as exported, after its frontmatter in the HTML comment Wiki.js reads it from.
+func (c *Converter) writeHTMLDocument(record *csv.ConversionRecord, revisionHash, content string, published bool) error {
+	language := ""
+	if c.opts.SplitByLanguage {
+		language = c.detectLanguage(content)
+	}
+
+	if c.opts.NoFrontmatter {
+		return c.writeOutputWithExt(record, content, nil, language, ".html")
+	}
+
+	fm, tags := c.frontmatterFields(record, revisionHash, content, published)
+	fm["editor"] = "code"
+	fm["description"] = record.Title // Paragraphs are only found in markdown
+	yaml := strings.TrimSuffix(strings.TrimPrefix(RenderFrontmatter(fm, tags, FrontmatterYAML), "---\n"), "---\n")
+	return c.writeOutputWithExt(record, "<!--\n"+yaml+"-->\n\n"+content, nil, language, ".html")
+}
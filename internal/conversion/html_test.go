@@ -0,0 +1,133 @@
+package conversion
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// googleDocsHTML wraps body like a Google Docs HTML export, which styles its elements through
+// classes defined in a style element
+func googleDocsHTML(body string) string {
+	return `<html><head><meta content="text/html; charset=UTF-8" http-equiv="content-type">` +
+		`<style type="text/css">.c1{font-weight:700}.c2{color:#000000;font-size:11pt}</style></head>` +
+		`<body class="c2 doc-content">` + body + `</body></html>`
+}
+
+func TestValidateExportFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: "markdown"},
+		{format: "html"},
+		{format: "pdf", wantErr: true},
+		{format: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateExportFormat(tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateExportFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+func TestConvertHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "heading and paragraph",
+			html: `<h1 class="c3" id="h.abc"><span class="c1">Setup</span></h1><p class="c2"><span class="c1">Install</span><span> the tools.</span></p>`,
+			want: "# Setup\n\nInstall the tools.",
+		},
+		{
+			name: "list",
+			html: `<ul class="c4 lst-kix_list_1-0 start"><li class="c2 li-bullet-0"><span>First</span></li><li class="c2 li-bullet-0"><span>Second</span></li></ul>`,
+			want: "- First\n- Second",
+		},
+		{
+			name: "link",
+			html: `<p class="c2"><span class="c5"><a class="c6" href="https://docs.google.com/document/d/abc/edit">Guide</a></span></p>`,
+			want: "[Guide](https://docs.google.com/document/d/abc/edit)",
+		},
+		{
+			name: "table",
+			html: `<table class="c7"><tr class="c8"><td class="c9"><p class="c2"><span>Name</span></p></td><td class="c9"><p class="c2"><span>Value</span></p></td></tr>` +
+				`<tr class="c8"><td class="c9"><p class="c2"><span>a</span></p></td><td class="c9"><p class="c2"><span>1</span></p></td></tr></table>`,
+			want: "Name\n\nValue\n\na\n\n1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertHTMLToMarkdown([]byte(googleDocsHTML(tt.html)))
+			if err != nil {
+				t.Fatalf("ConvertHTMLToMarkdown() error = %v", err)
+			}
+			if strings.TrimSpace(string(got)) != tt.want {
+				t.Errorf("ConvertHTMLToMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertExportFormatHTML(t *testing.T) {
+	const exported = `<html><head><style>.c1{font-weight:700}</style></head><body><h1>Setup</h1><p><span class="c1">Install</span> the tools.</p></body></html>`
+
+	tests := []struct {
+		name           string
+		htmlToMarkdown bool
+		wantFile       string
+		wantContent    []string
+	}{
+		{
+			name:        "html",
+			wantFile:    "setup.html",
+			wantContent: []string{"<!--\ndescription: Setup\neditor: code\n", "\n-->\n\n" + exported},
+		},
+		{
+			name:           "html to markdown",
+			htmlToMarkdown: true,
+			wantFile:       "setup.md",
+			wantContent:    []string{"---\ndescription: Install the tools.\neditor: markdown\n", "# Setup\n\nInstall the tools."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.handle("GET", "/files/doc1", jsonHandler(`{"id": "doc1", "name": "Setup", "mimeType": "application/vnd.google-apps.document", "modifiedTime": "2024-01-01T00:00:00.000Z"}`))
+			fake.handle("GET", "/files/doc1/export", func(w http.ResponseWriter, r *http.Request) {
+				if got := r.URL.Query().Get("mimeType"); got != htmlMimeType {
+					t.Errorf("export mimeType = %q, want %q", got, htmlMimeType)
+				}
+				w.Write([]byte(exported))
+			})
+
+			outDir := t.TempDir()
+			c := newTestConverter(t, fake, outDir, Options{ExportFormat: ExportFormatHTML, HTMLToMarkdown: tt.htmlToMarkdown})
+			records := []csv.ConversionRecord{{Link: "https://docs.google.com/document/d/doc1/edit", Title: "Setup"}}
+			if err := c.Convert(context.Background(), records, 1); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outDir, tt.wantFile))
+			if err != nil {
+				t.Fatalf("Failed to read output: %v", err)
+			}
+			for _, want := range tt.wantContent {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("output = %q, want it to contain %q", content, want)
+				}
+			}
+		})
+	}
+}
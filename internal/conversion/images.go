@@ -0,0 +1,166 @@
+package conversion
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// imageAssetsDir is the directory below each output directory that embedded images and PDF
+// page images are saved to
+const imageAssetsDir = "assets"
+
+// DefaultImageTimeout bounds the download of a single embedded image
+const DefaultImageTimeout = 30 * time.Second
+
+// imagePattern matches markdown images with an http(s) URL and an optional title
+var imagePattern = regexp.MustCompile(`!\[([^\]]*)\]\((https?://[^\s)]+)(\s+"[^"]*")?\)`)
+
+// imageExtensions maps image content types to file extensions, for the types
+// mime.ExtensionsByType returns several extensions for
+var imageExtensions = map[string]string{
+	"image/png":     ".png",
+	"image/jpeg":    ".jpg",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+	"image/bmp":     ".bmp",
+	"image/tiff":    ".tiff",
+}
+
+// imageDownload is the download of one image URL to one assets directory, shared by every
+// document referencing it
+type imageDownload struct {
+	once    sync.Once
+	relPath string
+	err     error
+}
+
+// embedImages downloads the images content references by URL to the assets directory of each
+// of the record's output directories and points the references at the saved files. An image
+// that cannot be downloaded keeps its URL.
+func (c *Converter) embedImages(content string, record *csv.ConversionRecord) string {
+	return imagePattern.ReplaceAllStringFunc(content, func(match string) string {
+		matches := imagePattern.FindStringSubmatch(match)
+		alt, imageURL, title := matches[1], matches[2], matches[3]
+
+		if c.dryRun {
+			log.Printf("Would download image: %s", imageURL)
+			return match
+		}
+
+		var relPath string
+		var err error
+		for _, assetsDir := range c.assetsDirs(record) {
+			if relPath, err = c.downloadImage(imageURL, assetsDir); err != nil {
+				log.Printf("Warning: failed to embed image %s in %s: %v", imageURL, record.Title, err)
+				return match
+			}
+		}
+		return fmt.Sprintf("![%s](%s%s)", alt, c.assetLink(record, relPath), title)
+	})
+}
+
+// assetsDirs returns the assets directory of each output directory the record is written to
+func (c *Converter) assetsDirs(record *csv.ConversionRecord) []string {
+	var dirs []string
+	for _, outputDir := range c.outputDirsFor(c.recordTags(record)) {
+		dirs = append(dirs, filepath.Join(outputDir, imageAssetsDir))
+	}
+	return dirs
+}
+
+// assetLink returns the slash-separated path from the record's document to the file name in
+// its assets directory. The assets directory sits at the top of each output directory, so the
+// same path works in all of them.
+func (c *Converter) assetLink(record *csv.ConversionRecord, name string) string {
+	docDir := filepath.Dir(utils.BuildOutputPathWithExt(".", record.Title, c.outputFragments(record), ".md"))
+	if c.opts.SplitByLanguage {
+		// Images are embedded before the language is detected; its directory only adds a level
+		docDir = filepath.Join("language", docDir)
+	}
+	toRoot, err := filepath.Rel(docDir, ".")
+	if err != nil {
+		toRoot = "."
+	}
+	return path.Join(filepath.ToSlash(toRoot), imageAssetsDir, name)
+}
+
+// downloadImage saves the image at imageURL in assetsDir, named after the hash of its content
+// with an extension for its content type, and returns its path relative to assetsDir. Each
+// URL is downloaded to each assets directory once per run.
+func (c *Converter) downloadImage(imageURL, assetsDir string) (string, error) {
+	key := assetsDir + "\x00" + imageURL
+	c.mu.Lock()
+	download, ok := c.images[key]
+	if !ok {
+		download = &imageDownload{}
+		c.images[key] = download
+	}
+	c.mu.Unlock()
+
+	download.once.Do(func() {
+		download.relPath, download.err = c.fetchImage(imageURL, assetsDir)
+	})
+	return download.relPath, download.err
+}
+
+// fetchImage downloads the image at imageURL and writes it to assetsDir
+func (c *Converter) fetchImage(imageURL, assetsDir string) (string, error) {
+	req, err := http.NewRequestWithContext(c.runContext(), http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.opts.ImageClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed with HTTP %d", resp.StatusCode)
+	}
+	ext, err := imageExtension(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+
+	name := utils.CalculateContentHash(data) + ext
+	if err := c.writeFile(WriteJob{path: filepath.Join(assetsDir, name), content: data}); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// imageExtension returns the file extension, including the dot, for an image content type
+func imageExtension(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("content type %s is not an image", mediaType)
+	}
+	if ext, ok := imageExtensions[mediaType]; ok {
+		return ext, nil
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0], nil
+	}
+	return "", fmt.Errorf("no file extension known for %s", mediaType)
+}
@@ -0,0 +1,135 @@
+package conversion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// newImageServer serves a PNG at /diagram.png, HTML at /page and 404 elsewhere, counting the
+// requests for each path
+func newImageServer(t *testing.T) (*httptest.Server, map[string]*atomic.Int32) {
+	t.Helper()
+	counts := map[string]*atomic.Int32{"/diagram.png": {}, "/page": {}, "/missing.png": {}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count, ok := counts[r.URL.Path]; ok {
+			count.Add(1)
+		}
+		switch r.URL.Path {
+		case "/diagram.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png data"))
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, counts
+}
+
+func TestImageExtension(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+		wantErr     bool
+	}{
+		{contentType: "image/png", want: ".png"},
+		{contentType: "image/jpeg", want: ".jpg"},
+		{contentType: "image/svg+xml; charset=utf-8", want: ".svg"},
+		{contentType: "text/html", wantErr: true},
+		{contentType: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := imageExtension(tt.contentType)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("imageExtension(%q) = %q, %v, want %q, wantErr %v", tt.contentType, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDownloadImage(t *testing.T) {
+	server, counts := newImageServer(t)
+	c := newTestConverter(t, newFakeDrive(), t.TempDir(), Options{})
+	assetsDir := filepath.Join(t.TempDir(), "assets")
+
+	relPath, err := c.downloadImage(server.URL+"/diagram.png", assetsDir)
+	if err != nil {
+		t.Fatalf("downloadImage() error = %v", err)
+	}
+	if want := utils.CalculateContentHash([]byte("png data")) + ".png"; relPath != want {
+		t.Errorf("downloadImage() = %q, want %q", relPath, want)
+	}
+	data, err := os.ReadFile(filepath.Join(assetsDir, relPath))
+	if err != nil || string(data) != "png data" {
+		t.Errorf("asset = %q, %v, want the downloaded image", data, err)
+	}
+
+	// A second reference is served from the cache
+	if again, err := c.downloadImage(server.URL+"/diagram.png", assetsDir); err != nil || again != relPath {
+		t.Errorf("second downloadImage() = %q, %v, want %q", again, err, relPath)
+	}
+	if got := counts["/diagram.png"].Load(); got != 1 {
+		t.Errorf("image downloaded %d times, want 1", got)
+	}
+
+	if _, err := c.downloadImage(server.URL+"/page", assetsDir); err == nil || !strings.Contains(err.Error(), "not an image") {
+		t.Errorf("downloadImage(page) error = %v, want a not an image error", err)
+	}
+	if _, err := c.downloadImage(server.URL+"/missing.png", assetsDir); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("downloadImage(missing) error = %v, want an HTTP 404 error", err)
+	}
+}
+
+func TestConvertEmbedImages(t *testing.T) {
+	server, counts := newImageServer(t)
+	content := "# Diagrams\n\n![Flow](" + server.URL + "/diagram.png)\n\n" +
+		"![Again](" + server.URL + "/diagram.png \"Title\")\n\n" +
+		"![Broken](" + server.URL + "/missing.png)\n"
+
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc1", jsonHandler(`{"id": "doc1", "name": "Diagrams", "mimeType": "application/vnd.google-apps.document", "modifiedTime": "2024-01-01T00:00:00.000Z"}`))
+	fake.handle("GET", "/files/doc1/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	})
+
+	outDir := t.TempDir()
+	c := newTestConverter(t, fake, outDir, Options{EmbedImages: true})
+	records := []csv.ConversionRecord{{Link: "https://docs.google.com/document/d/doc1/edit", Title: "Diagrams", Fragments: []string{"guides", "design"}}}
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	name := utils.CalculateContentHash([]byte("png data")) + ".png"
+	if _, err := os.Stat(filepath.Join(outDir, "assets", name)); err != nil {
+		t.Errorf("asset not written: %v", err)
+	}
+	if got := counts["/diagram.png"].Load(); got != 1 {
+		t.Errorf("image downloaded %d times, want 1", got)
+	}
+
+	doc, err := os.ReadFile(filepath.Join(outDir, "guides", "design", "diagrams.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	for _, want := range []string{
+		"![Flow](../../assets/" + name + ")",
+		"![Again](../../assets/" + name + " \"Title\")",
+		"![Broken](" + server.URL + "/missing.png)",
+	} {
+		if !strings.Contains(string(doc), want) {
+			t.Errorf("output = %q, want it to contain %q", doc, want)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package conversion
+
+import (
+	"log"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+const (
+	// DefaultLanguageConfidence is the confidence below which the language is undetermined
+	DefaultLanguageConfidence = 0.8
+
+	// UndeterminedLanguage is the BCP-47 code written when detection is not confident enough
+	UndeterminedLanguage = "und"
+
+	// languageSampleSize is the number of characters of body content used for detection
+	languageSampleSize = 2000
+
+	// minLanguageHits is the number of stopwords needed before a Latin-script language is scored
+	minLanguageHits = 3
+)
+
+// languageStopwords are frequent words of each detected Latin-script language. Words listed for
+// more than one language are ignored, so every hit points at a single language.
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "it", "for", "with", "was", "on", "are", "this", "be", "as", "have", "not", "you", "from", "by", "at", "or", "which", "will", "can", "an"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "von", "den", "ein", "eine", "zu", "auf", "sich", "für", "dem", "auch", "es", "wird", "werden", "bei", "oder", "wir", "sie", "im", "sind"},
+	"es": {"el", "la", "de", "que", "y", "los", "las", "en", "es", "por", "con", "para", "una", "un", "del", "se", "al", "como", "más", "su", "pero", "está", "muy", "también", "son", "sus"},
+	"fr": {"le", "la", "les", "des", "et", "est", "une", "un", "du", "dans", "que", "pour", "qui", "pas", "sur", "au", "avec", "ce", "il", "sont", "aux", "cette", "nous", "vous", "être", "mais"},
+}
+
+// stopwordLanguage maps each stopword that belongs to exactly one language to that language
+var stopwordLanguage = buildStopwordLanguage()
+
+func buildStopwordLanguage() map[string]string {
+	index := make(map[string]string)
+	shared := make(map[string]bool)
+	for lang, words := range languageStopwords {
+		for _, word := range words {
+			if other, ok := index[word]; ok && other != lang {
+				shared[word] = true
+			}
+			index[word] = lang
+		}
+	}
+	for word := range shared {
+		delete(index, word)
+	}
+	return index
+}
+
+// DetectLanguage returns the BCP-47 code of the language text is written in and a confidence
+// between 0 and 1. Japanese, Chinese and Korean are recognized by script; English, German,
+// Spanish and French by their most frequent words. An empty code means no language was found.
+func DetectLanguage(text string) (string, float64) {
+	var letters, latin, kana, han, hangul int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	if letters == 0 {
+		return "", 0
+	}
+
+	if cjk := kana + han + hangul; cjk > latin {
+		switch {
+		case hangul >= kana+han:
+			return "ko", float64(hangul) / float64(letters)
+		case kana > 0:
+			return "ja", float64(kana+han) / float64(letters)
+		default:
+			return "zh", float64(han) / float64(letters)
+		}
+	}
+
+	hits := make(map[string]int)
+	total := 0
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if lang, ok := stopwordLanguage[word]; ok {
+			hits[lang]++
+			total++
+		}
+	}
+	if total < minLanguageHits {
+		return "", 0
+	}
+
+	best := ""
+	for lang, n := range hits {
+		if best == "" || n > hits[best] || (n == hits[best] && lang < best) {
+			best = lang
+		}
+	}
+	return best, float64(hits[best]) / float64(total)
+}
+
+// detectLanguage returns the BCP-47 code of the language of the first 2000 characters of body
+// content, or UndeterminedLanguage when the confidence is below the configured threshold
+func (c *Converter) detectLanguage(content string) string {
+	if sample := []rune(content); len(sample) > languageSampleSize {
+		content = string(sample[:languageSampleSize])
+	}
+
+	lang, confidence := DetectLanguage(content)
+	if lang == "" || confidence < c.opts.LanguageConfidence {
+		return UndeterminedLanguage
+	}
+	return lang
+}
+
+// languageDocument is a document written below a language directory during Convert
+type languageDocument struct {
+	language string
+	paths    []string       // Files the document was written to
+	links    []languageLink // Links to other documents, rewritten before their language was known
+
+	// The frontmatter and content the document was written from, kept while it has links
+	frontmatter string
+	content     string
+}
+
+// languageLink is a link rewritten as if the target were in the language of the source
+type languageLink struct {
+	target *csv.ConversionRecord
+	url    string // Drive URL the link had
+	path   string // Path it was rewritten to
+}
+
+// languageDocument returns the tracked language document of record, adding it if needed.
+// c.mu must be held.
+func (c *Converter) languageDocument(record *csv.ConversionRecord) *languageDocument {
+	doc, ok := c.languageDocs[record]
+	if !ok {
+		doc = &languageDocument{}
+		c.languageDocs[record] = doc
+	}
+	return doc
+}
+
+// trackLanguageLink remembers that the link from source to target was rewritten from url to
+// path, to correct it once both languages are known
+func (c *Converter) trackLanguageLink(source, target *csv.ConversionRecord, url, path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc := c.languageDocument(source)
+	doc.links = append(doc.links, languageLink{target: target, url: url, path: path})
+}
+
+// trackLanguageContent keeps the frontmatter and content of a document with links to other
+// documents, so it can be written again with corrected links
+func (c *Converter) trackLanguageContent(record *csv.ConversionRecord, frontmatter, content string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if doc, ok := c.languageDocs[record]; ok && len(doc.links) > 0 {
+		doc.frontmatter, doc.content = frontmatter, content
+	}
+}
+
+// trackLanguagePath records that the document of record was written to path below the
+// language directory language
+func (c *Converter) trackLanguagePath(record *csv.ConversionRecord, language, path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc := c.languageDocument(record)
+	doc.language = language
+	doc.paths = append(doc.paths, path)
+}
+
+// fixLanguageLinks writes the documents again whose links point at a document in another
+// language. Links are rewritten before the language of their target is known, so they assume
+// it is the language of the source. Run once all documents are written.
+func (c *Converter) fixLanguageLinks() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for record, doc := range c.languageDocs {
+		if doc.content == "" || doc.language == "" {
+			continue
+		}
+
+		var replacements []string
+		for _, link := range doc.links {
+			target, ok := c.languageDocs[link.target]
+			if !ok || target.language == "" || target.language == doc.language {
+				continue
+			}
+			fixed, ok := c.relativeLink(record, link.target, doc.language, target.language)
+			if !ok {
+				fixed = link.url
+			}
+			replacements = append(replacements, "]("+link.path+")", "]("+fixed+")")
+		}
+		if len(replacements) == 0 {
+			continue
+		}
+
+		content := strings.NewReplacer(replacements...).Replace(doc.content)
+		frontmatter := strings.Replace(doc.frontmatter, c.opts.HashFunc([]byte(doc.content)), c.opts.HashFunc([]byte(content)), 1)
+		body, fm, err := c.opts.Transformer.Transform(content, frontmatter, *record)
+		if err != nil {
+			log.Printf("Warning: failed to transform %s: %v", record.Title, err)
+			continue
+		}
+		if fm != "" {
+			body = fm + "\n" + body
+		}
+
+		for _, path := range doc.paths {
+			// Files whose first write failed are left to the error report
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+				log.Printf("Warning: failed to correct links between languages in %s: %v", path, err)
+			} else if c.verbose {
+				log.Printf("Corrected links between languages: %s", path)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package conversion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "english",
+			text: "Restart the service and check that the queue is drained before you deploy the new version.",
+			want: "en",
+		},
+		{
+			name: "german",
+			text: "Der Dienst wird neu gestartet und die Warteschlange ist leer, bevor wir die neue Version mit dem Skript installieren.",
+			want: "de",
+		},
+		{
+			name: "spanish",
+			text: "Reinicie el servicio y compruebe que la cola está vacía antes de instalar la nueva versión con los scripts del equipo.",
+			want: "es",
+		},
+		{
+			name: "french",
+			text: "Redémarrez le service et vérifiez que la file est vide avant de déployer la nouvelle version avec les scripts du projet.",
+			want: "fr",
+		},
+		{
+			name: "japanese",
+			text: "サービスを再起動して、新しいバージョンをデプロイする前にキューが空であることを確認してください。",
+			want: "ja",
+		},
+		{
+			name: "chinese",
+			text: "重新启动服务并在部署新版本之前确认队列为空。",
+			want: "zh",
+		},
+		{
+			name: "korean",
+			text: "새 버전을 배포하기 전에 서비스를 다시 시작하고 대기열이 비어 있는지 확인하십시오.",
+			want: "ko",
+		},
+		{
+			name: "no letters",
+			text: "12345 -- 67890",
+			want: "",
+		},
+		{
+			name: "too few stopwords",
+			text: "Kubernetes Terraform Grafana",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, confidence := DetectLanguage(tt.text)
+			if got != tt.want {
+				t.Errorf("DetectLanguage() = %q (%.2f), want %q", got, confidence, tt.want)
+			}
+			if tt.want != "" && confidence < DefaultLanguageConfidence {
+				t.Errorf("DetectLanguage() confidence = %.2f, want at least %.2f", confidence, DefaultLanguageConfidence)
+			}
+		})
+	}
+}
+
+func TestDetectLanguageConfidence(t *testing.T) {
+	// Equal numbers of English and German stopwords give a confidence of 0.5
+	mixed := "the and of to der und ist nicht"
+
+	c := NewConverter(nil, "/out", false, false, Options{})
+	if got := c.detectLanguage(mixed); got != UndeterminedLanguage {
+		t.Errorf("detectLanguage() = %q, want %q", got, UndeterminedLanguage)
+	}
+
+	c = NewConverter(nil, "/out", false, false, Options{LanguageConfidence: 0.5})
+	if got := c.detectLanguage(mixed); got == UndeterminedLanguage {
+		t.Errorf("detectLanguage() = %q with a 0.5 threshold, want a language", got)
+	}
+
+	// Only the first 2000 characters are used
+	long := strings.Repeat("x", languageSampleSize) + " the and of to is that"
+	if got := c.detectLanguage(long); got != UndeterminedLanguage {
+		t.Errorf("detectLanguage() = %q, want text after the sample to be ignored", got)
+	}
+}
+
+func TestLanguageFrontmatterAndSplit(t *testing.T) {
+	dir := t.TempDir()
+	c := NewConverter(nil, dir, false, false, Options{DetectLanguage: true, SplitByLanguage: true})
+	record := &csv.ConversionRecord{
+		Link:      "https://docs.google.com/document/d/abc123/edit",
+		Title:     "Runbook",
+		Fragments: []string{"Ops"},
+	}
+	content := "Der Dienst wird neu gestartet und die Warteschlange ist leer, bevor wir die neue Version installieren."
+
+	fm := c.generateFrontmatter(record, "2024-01-15T10:30:00Z", content, true, FrontmatterYAML)
+	if !strings.Contains(fm, "language: de\n") {
+		t.Errorf("generateFrontmatter() missing language:\n%s", fm)
+	}
+
+	if err := c.writeDocument(record, fm, content, nil); err != nil {
+		t.Fatalf("writeDocument() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "de", "Ops", "runbook.md")); err != nil {
+		t.Errorf("document not written under the language directory: %v", err)
+	}
+}
+
+func TestSplitByLanguageLinks(t *testing.T) {
+	dir := t.TempDir()
+	c := NewConverter(nil, dir, false, false, Options{DetectLanguage: true, SplitByLanguage: true})
+	source := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/source1/edit", Title: "Overview"}
+	german := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/german1/edit", Title: "Runbook"}
+	english := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/english1/edit", Title: "Rate Limits"}
+	for _, record := range []*csv.ConversionRecord{source, german, english} {
+		c.linkMap[LinkKey(record.Link)] = record
+	}
+
+	write := func(record *csv.ConversionRecord, content string) {
+		t.Helper()
+		content, err := c.pipeline.Run(content, record)
+		if err != nil {
+			t.Fatalf("pipeline.Run() error = %v", err)
+		}
+		fm := c.generateFrontmatter(record, "rev", content, true, FrontmatterYAML)
+		if err := c.writeDocument(record, fm, content, nil); err != nil {
+			t.Fatalf("writeDocument() error = %v", err)
+		}
+	}
+	// The source is written first, before the language of the German target is known
+	write(source, "Read the [runbook](https://docs.google.com/document/d/german1/edit) and the [limits](https://docs.google.com/document/d/english1/edit) before you deploy, as this is the way to check that it is safe.")
+	write(german, "Der Dienst wird neu gestartet und die Warteschlange ist leer, bevor wir die neue Version installieren.")
+	write(english, "Restart the service and check that the queue is drained before you deploy the new version.")
+	c.fixLanguageLinks()
+
+	content, err := os.ReadFile(filepath.Join(dir, "en", "overview.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{
+		"[runbook](" + filepath.FromSlash("../de/runbook.md") + ")",
+		"[limits](rate-limits.md)",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("document =\n%s\nwant it to contain %q", content, want)
+		}
+	}
+
+	// The corrected body still matches its hash-content
+	_, body, _ := strings.Cut(strings.TrimPrefix(string(content), "---\n"), "---\n\n")
+	if want := "hash-content: " + c.opts.HashFunc([]byte(body)) + "\n"; !strings.Contains(string(content), want) {
+		t.Errorf("document =\n%s\nwant it to contain %q", content, want)
+	}
+}
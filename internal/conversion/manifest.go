@@ -0,0 +1,137 @@
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ManifestFormat selects how manifest entries are written
+type ManifestFormat string
+
+const (
+	ManifestNDJSON    ManifestFormat = "ndjson"     // One JSON object per line (default)
+	ManifestJSONArray ManifestFormat = "json-array" // A JSON array with one entry per line
+)
+
+// ValidateManifestFormat returns an error if format is not a supported manifest format
+func ValidateManifestFormat(format string) error {
+	switch ManifestFormat(format) {
+	case ManifestNDJSON, ManifestJSONArray:
+		return nil
+	default:
+		return fmt.Errorf("unknown manifest format %q (want %q or %q)", format, ManifestNDJSON, ManifestJSONArray)
+	}
+}
+
+// ManifestEntry is one file written during conversion
+type ManifestEntry struct {
+	Action    string `json:"action"` // "created" or "updated"
+	Path      string `json:"path"`   // Relative to the output directory when inside it
+	Link      string `json:"link"`
+	Title     string `json:"title"`
+	Timestamp string `json:"timestamp"` // RFC3339 time the file was written
+}
+
+// Manifest writes an entry for each written file as soon as it is written, so downstream tools
+// can follow a running conversion. It is safe for concurrent use.
+type Manifest struct {
+	format ManifestFormat
+	mu     sync.Mutex
+	file   *os.File
+	count  int
+}
+
+// OpenManifest creates the manifest file at path, replacing an existing one
+func OpenManifest(path string, format ManifestFormat) (*Manifest, error) {
+	if format == "" {
+		format = ManifestNDJSON
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manifest: %w", err)
+	}
+
+	m := &Manifest{format: format, file: file}
+	if format == ManifestJSONArray {
+		if _, err := file.WriteString("["); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// Add writes an entry to the manifest file
+func (m *Manifest) Add(entry ManifestEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest entry: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var line []byte
+	switch {
+	case m.format == ManifestJSONArray && m.count > 0:
+		line = append([]byte(",\n"), data...)
+	case m.format == ManifestJSONArray:
+		line = append([]byte("\n"), data...)
+	default:
+		line = append(data, '\n')
+	}
+
+	// The file is unbuffered, so each entry is visible to readers once written
+	if _, err := m.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	m.count++
+	return nil
+}
+
+// Close completes and closes the manifest file
+func (m *Manifest) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.format == ManifestJSONArray {
+		if _, err := m.file.WriteString("\n]\n"); err != nil {
+			m.file.Close()
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+	return m.file.Close()
+}
+
+// recordWrite adds the written file to the run's manifest, if any
+func (c *Converter) recordWrite(job WriteJob, existed bool) {
+	if c.manifest == nil || job.record == nil {
+		return
+	}
+
+	action := "created"
+	if existed {
+		action = "updated"
+	}
+	path := job.path
+	if rel, err := filepath.Rel(c.outputDir, job.path); err == nil && filepath.IsLocal(rel) {
+		path = filepath.ToSlash(rel)
+	}
+
+	err := c.manifest.Add(ManifestEntry{
+		Action:    action,
+		Path:      path,
+		Link:      job.record.Link,
+		Title:     job.record.Title,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package conversion
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestValidateManifestFormat(t *testing.T) {
+	for _, format := range []string{"ndjson", "json-array"} {
+		if err := ValidateManifestFormat(format); err != nil {
+			t.Errorf("ValidateManifestFormat(%q) error = %v", format, err)
+		}
+	}
+	for _, format := range []string{"", "json", "csv"} {
+		if err := ValidateManifestFormat(format); err == nil {
+			t.Errorf("ValidateManifestFormat(%q) error = nil, want error", format)
+		}
+	}
+}
+
+func TestManifestFormats(t *testing.T) {
+	entries := []ManifestEntry{
+		{Action: "created", Path: "guides/intro.md", Link: "https://example.com/1", Title: "Intro", Timestamp: "2024-05-01T12:00:00Z"},
+		{Action: "updated", Path: "faq.md", Link: "https://example.com/2", Title: "FAQ", Timestamp: "2024-05-01T12:00:01Z"},
+	}
+
+	tests := []struct {
+		name   string
+		format ManifestFormat
+		decode func(t *testing.T, path string) []ManifestEntry
+	}{
+		{name: "ndjson", format: ManifestNDJSON, decode: readNDJSONManifest},
+		{name: "json array", format: ManifestJSONArray, decode: func(t *testing.T, path string) []ManifestEntry {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			var got []ManifestEntry
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("manifest is not a JSON array: %v\n%s", err, data)
+			}
+			return got
+		}},
+		{name: "empty json array", format: ManifestJSONArray},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest")
+			m, err := OpenManifest(path, tt.format)
+			if err != nil {
+				t.Fatalf("OpenManifest() error = %v", err)
+			}
+
+			want := entries
+			if tt.decode == nil {
+				want = nil
+			}
+			for _, entry := range want {
+				if err := m.Add(entry); err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+			}
+			if err := m.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			if tt.decode == nil {
+				data, _ := os.ReadFile(path)
+				var got []ManifestEntry
+				if err := json.Unmarshal(data, &got); err != nil || len(got) != 0 {
+					t.Errorf("empty manifest = %q, want an empty JSON array", data)
+				}
+				return
+			}
+			got := tt.decode(t, path)
+			if len(got) != len(want) {
+				t.Fatalf("manifest has %d entries, want %d", len(got), len(want))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWritesManifest(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/forms/d/form1/viewform", Title: "Form 1", Fragments: []string{"Guides"}},
+		{Link: "https://docs.google.com/forms/d/form2/viewform", Title: "Form 2"},
+	}
+
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "form-2.md"), []byte("old"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "manifest.ndjson")
+	c := newTestConverter(t, newFakeDrive(), outputDir, Options{ManifestPath: manifestPath})
+	if err := c.Convert(context.Background(), records, 2); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	got := readNDJSONManifest(t, manifestPath)
+	sort.Slice(got, func(i, j int) bool { return got[i].Path < got[j].Path })
+	want := []ManifestEntry{
+		{Action: "created", Path: "Guides/form-1.md", Link: records[0].Link, Title: "Form 1"},
+		{Action: "updated", Path: "form-2.md", Link: records[1].Link, Title: "Form 2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("manifest has %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if _, err := time.Parse(time.RFC3339, got[i].Timestamp); err != nil {
+			t.Errorf("entry %d timestamp %q is not RFC3339", i, got[i].Timestamp)
+		}
+		got[i].Timestamp = ""
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConvertDryRunSkipsManifest(t *testing.T) {
+	records := []csv.ConversionRecord{{Link: "https://docs.google.com/forms/d/form1/viewform", Title: "Form 1"}}
+
+	manifestPath := filepath.Join(t.TempDir(), "manifest.ndjson")
+	c := newTestConverter(t, newFakeDrive(), t.TempDir(), Options{ManifestPath: manifestPath})
+	c.dryRun = true
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Errorf("manifest written in dry run (stat error = %v)", err)
+	}
+}
+
+func readNDJSONManifest(t *testing.T, path string) []ManifestEntry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer file.Close()
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("manifest line %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,36 @@
+package conversion
+
+import (
+	"sync"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// FileMetadataCache stores Drive file metadata by file ID so a file's metadata is fetched
+// at most once per conversion run. It is safe for concurrent use.
+type FileMetadataCache struct {
+	mu    sync.Mutex
+	files map[string]*drive.File
+}
+
+// NewFileMetadataCache creates an empty FileMetadataCache
+func NewFileMetadataCache() *FileMetadataCache {
+	return &FileMetadataCache{files: make(map[string]*drive.File)}
+}
+
+// Get returns the cached metadata for fileID, if any
+func (fc *FileMetadataCache) Get(fileID string) (*drive.File, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	file, ok := fc.files[fileID]
+	return file, ok
+}
+
+// Put stores the metadata for fileID
+func (fc *FileMetadataCache) Put(fileID string, file *drive.File) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.files[fileID] = file
+}
@@ -0,0 +1,62 @@
+package conversion
+
+import (
+	"net/http"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestFileMetadataCache(t *testing.T) {
+	cache := NewFileMetadataCache()
+
+	if _, ok := cache.Get("doc123"); ok {
+		t.Fatal("Get() on empty cache returned a file")
+	}
+
+	cache.Put("doc123", &drive.File{Id: "doc123", MimeType: "application/pdf"})
+
+	file, ok := cache.Get("doc123")
+	if !ok || file.MimeType != "application/pdf" {
+		t.Errorf("Get() = %+v, %v, want cached PDF metadata", file, ok)
+	}
+}
+
+func TestConvertRecordFetchesMetadataOnce(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc123", jsonHandler(`{"id":"doc123","mimeType":"application/vnd.google-apps.document","modifiedTime":"2024-01-15T10:30:00.000Z"}`))
+	fake.handle("GET", "/files/doc123/export", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Content"))
+	})
+
+	c := newTestConverter(t, fake, t.TempDir(), Options{})
+	record := &csv.ConversionRecord{Title: "Doc", Link: "https://docs.google.com/document/d/doc123/edit"}
+	if err := c.convertRecord(record); err != nil {
+		t.Fatalf("convertRecord() error = %v", err)
+	}
+
+	reqs := fake.requestsFor("GET", "/files/doc123")
+	if len(reqs) != 1 {
+		t.Fatalf("made %d metadata requests, want 1", len(reqs))
+	}
+	if fields := reqs[0].Query["fields"]; len(fields) != 1 || fields[0] != fileMetadataFields {
+		t.Errorf("fields = %v, want %q", fields, fileMetadataFields)
+	}
+}
+
+func TestGetFileMetadataDoesNotCacheErrors(t *testing.T) {
+	fake := newFakeDrive()
+	c := newTestConverter(t, fake, t.TempDir(), Options{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.getFileMetadata("missing"); err == nil {
+			t.Fatal("getFileMetadata() expected error for missing file")
+		}
+	}
+
+	if reqs := fake.requestsFor("GET", "/files/missing"); len(reqs) != 2 {
+		t.Errorf("made %d requests, want 2 (errors must not be cached)", len(reqs))
+	}
+}
@@ -0,0 +1,78 @@
+package conversion
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ExportBackend selects how Google Docs are turned into markdown
+type ExportBackend string
+
+const (
+	ExportBackendGoogle ExportBackend = "google" // Drive API markdown export (default)
+	ExportBackendPandoc ExportBackend = "pandoc" // Export as docx and convert with Pandoc
+)
+
+// DefaultPandocPath is the Pandoc binary used when none is configured, looked up in PATH
+const DefaultPandocPath = "pandoc"
+
+// docxMimeType is the export MIME type used for the Pandoc backend
+const docxMimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// ValidateExportBackend returns an error if backend is not a supported export backend
+func ValidateExportBackend(backend string) error {
+	switch ExportBackend(backend) {
+	case ExportBackendGoogle, ExportBackendPandoc:
+		return nil
+	default:
+		return fmt.Errorf("unknown export backend %q (want %q or %q)", backend, ExportBackendGoogle, ExportBackendPandoc)
+	}
+}
+
+// exportWithPandoc exports a Google Doc as docx and converts it to markdown with Pandoc
+func (c *Converter) exportWithPandoc(fileID string) ([]byte, error) {
+	body, err := c.executeExportWithRetry(fileID, docxMimeType)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	tempFile, err := os.CreateTemp("", "gdrive-*.docx")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := io.Copy(tempFile, body); err != nil {
+		tempFile.Close()
+		return nil, fmt.Errorf("failed to save docx: %w", err)
+	}
+	tempFile.Close()
+
+	return runPandoc(c.opts.PandocPath, tempFile.Name())
+}
+
+// runPandoc converts a docx file to markdown with the Pandoc binary at pandocPath
+func runPandoc(pandocPath, docxPath string) ([]byte, error) {
+	if pandocPath == "" {
+		pandocPath = DefaultPandocPath
+	}
+
+	cmd := exec.Command(pandocPath, docxPath, "-f", "docx", "-t", "markdown", "--wrap=none", "--atx-headers")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("pandoc failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("pandoc failed: %w", err)
+	}
+
+	return out, nil
+}
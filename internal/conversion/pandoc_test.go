@@ -0,0 +1,83 @@
+package conversion
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFakePandoc writes a shell script standing in for pandoc. It prints its arguments
+// followed by the content of the input file.
+func writeFakePandoc(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "pandoc")
+	script := "#!/bin/sh\necho \"args: $*\"\ncat \"$1\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestValidateExportBackend(t *testing.T) {
+	tests := []struct {
+		backend string
+		wantErr bool
+	}{
+		{backend: "google"},
+		{backend: "pandoc"},
+		{backend: "docx", wantErr: true},
+		{backend: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateExportBackend(tt.backend); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateExportBackend(%q) error = %v, wantErr %v", tt.backend, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExportAsMarkdownPandoc(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/doc1", jsonHandler(`{"id": "doc1", "name": "Doc", "mimeType": "application/vnd.google-apps.document", "modifiedTime": "2024-01-01T00:00:00.000Z"}`))
+	fake.handle("GET", "/files/doc1/export", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("mimeType"); got != docxMimeType {
+			t.Errorf("export mimeType = %q, want %q", got, docxMimeType)
+		}
+		w.Write([]byte("docx bytes"))
+	})
+
+	c := newTestConverter(t, fake, t.TempDir(), Options{ExportBackend: ExportBackendPandoc, PandocPath: writeFakePandoc(t)})
+
+	content, rev, err := c.exportAsMarkdown("doc1")
+	if err != nil {
+		t.Fatalf("exportAsMarkdown() error = %v", err)
+	}
+	if rev != "2024-01-01T00:00:00.000Z" {
+		t.Errorf("revision = %q, want modifiedTime", rev)
+	}
+	if !strings.Contains(string(content), "docx bytes") {
+		t.Errorf("content = %q, want pandoc output of the exported docx", content)
+	}
+	if !strings.Contains(string(content), "--wrap=none --atx-headers") {
+		t.Errorf("content = %q, want pandoc called with --wrap=none --atx-headers", content)
+	}
+}
+
+func TestRunPandocFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pandoc")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho 'unknown option' >&2\nexit 2\n"), 0755); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, err := runPandoc(path, "input.docx")
+	if err == nil || !strings.Contains(err.Error(), "unknown option") {
+		t.Errorf("runPandoc() error = %v, want it to include pandoc's stderr", err)
+	}
+
+	if _, err := runPandoc(filepath.Join(t.TempDir(), "missing"), "input.docx"); err == nil {
+		t.Error("runPandoc() expected error for missing binary")
+	}
+}
@@ -0,0 +1,196 @@
+package conversion
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/gen2brain/go-fitz"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// maxDefaultPDFWorkers caps the default number of workers used for a single PDF
+const maxDefaultPDFWorkers = 4
+
+// convertPDFWithFitz converts a PDF file to markdown by rendering each page to HTML with
+// MuPDF and converting the HTML to markdown. This keeps headings, lists and emphasis that
+// plain text extraction loses.
+//
+// Pages are split into contiguous ranges across workers. A fitz document is not safe for
+// concurrent use, so each worker opens its own instance of the file. workers <= 0 uses
+// defaultPDFWorkers.
+func convertPDFWithFitz(pdfPath string, workers int) ([]byte, error) {
+	pages, err := convertPDFPagesWithFitz(pdfPath, workers)
+	if err != nil {
+		return nil, err
+	}
+	return joinPDFPages(pages), nil
+}
+
+// convertPDFPagesWithFitz converts a PDF file like convertPDFWithFitz and returns the markdown
+// of each page
+func convertPDFPagesWithFitz(pdfPath string, workers int) ([]string, error) {
+	doc, err := fitz.New(pdfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+	numPages := doc.NumPage()
+
+	if workers <= 0 {
+		workers = defaultPDFWorkers(numPages)
+	}
+	if workers > numPages {
+		workers = numPages
+	}
+
+	pages := make([]string, numPages)
+	if workers <= 1 {
+		convertFitzPages(doc, 0, numPages, pages)
+		doc.Close()
+	} else {
+		doc.Close()
+
+		var wg sync.WaitGroup
+		errs := make([]error, workers)
+		chunk := (numPages + workers - 1) / workers
+		for w := 0; w < workers; w++ {
+			start, end := w*chunk, min((w+1)*chunk, numPages)
+			if start >= end {
+				break
+			}
+
+			wg.Add(1)
+			go func(w, start, end int) {
+				defer wg.Done()
+
+				workerDoc, err := fitz.New(pdfPath)
+				if err != nil {
+					errs[w] = fmt.Errorf("failed to open PDF: %w", err)
+					return
+				}
+				defer workerDoc.Close()
+
+				convertFitzPages(workerDoc, start, end, pages)
+			}(w, start, end)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pages, nil
+}
+
+// joinPDFPages joins the markdown of the pages of a PDF, skipping empty pages
+func joinPDFPages(pages []string) []byte {
+	var sb strings.Builder
+	for _, markdown := range pages {
+		if markdown == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n---\n\n") // Page separator
+		}
+		sb.WriteString(markdown)
+	}
+	return []byte(sb.String())
+}
+
+// extractPDFImages renders each page of doc to a PNG saved in assetsDir as
+// <fileID>-page-<N>.png, with N counting from 1, and returns the file names in page order
+func extractPDFImages(doc *fitz.Document, fileID, assetsDir string) ([]string, error) {
+	numPages := doc.NumPage()
+	if numPages == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(assetsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", assetsDir, err)
+	}
+
+	names := make([]string, numPages)
+	for n := 0; n < numPages; n++ {
+		img, err := doc.Image(n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render page %d: %w", n+1, err)
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("failed to encode page %d: %w", n+1, err)
+		}
+
+		names[n] = fmt.Sprintf("%s-page-%d.png", fileID, n+1)
+		if err := os.WriteFile(filepath.Join(assetsDir, names[n]), buf.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write page %d: %w", n+1, err)
+		}
+	}
+	return names, nil
+}
+
+// addPDFPageImages saves an image of each page of the PDF at pdfPath in the record's assets
+// directories and places a reference to it before the page's text. On failure a warning is
+// logged and pages are returned unchanged.
+func (c *Converter) addPDFPageImages(record *csv.ConversionRecord, fileID, pdfPath string, pages []string) []string {
+	if c.dryRun {
+		log.Printf("Would extract page images of %s", record.Title)
+		return pages
+	}
+
+	doc, err := fitz.New(pdfPath)
+	if err != nil {
+		log.Printf("Warning: failed to extract page images of %s: %v", record.Title, err)
+		return pages
+	}
+	defer doc.Close()
+
+	var names []string
+	for _, assetsDir := range c.assetsDirs(record) {
+		if names, err = extractPDFImages(doc, fileID, assetsDir); err != nil {
+			log.Printf("Warning: failed to extract page images of %s: %v", record.Title, err)
+			return pages
+		}
+	}
+
+	withImages := make([]string, len(pages))
+	for n, markdown := range pages {
+		withImages[n] = strings.TrimSpace(fmt.Sprintf("![Page %d](%s)\n\n%s", n+1, c.assetLink(record, names[n]), markdown))
+	}
+	return withImages
+}
+
+// convertFitzPages converts pages [start, end) of doc to markdown, storing each in pages[n].
+// Pages that fail to convert are logged and left empty.
+func convertFitzPages(doc *fitz.Document, start, end int, pages []string) {
+	converter := md.NewConverter("", true, nil)
+
+	for n := start; n < end; n++ {
+		html, err := doc.HTML(n, false)
+		if err != nil {
+			log.Printf("Warning: failed to extract HTML from page %d: %v", n+1, err)
+			continue
+		}
+
+		markdown, err := converter.ConvertString(html)
+		if err != nil {
+			log.Printf("Warning: failed to convert page %d to markdown: %v", n+1, err)
+			continue
+		}
+
+		pages[n] = strings.TrimSpace(markdown)
+	}
+}
+
+// defaultPDFWorkers returns min(4, numPages/10), and at least 1
+func defaultPDFWorkers(numPages int) int {
+	return max(1, min(maxDefaultPDFWorkers, numPages/10))
+}
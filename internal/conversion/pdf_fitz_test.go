@@ -0,0 +1,195 @@
+package conversion
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gen2brain/go-fitz"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// writeTestPDF writes a PDF with one page per text, each showing that text, and returns its path
+func writeTestPDF(t *testing.T, texts ...string) string {
+	t.Helper()
+
+	// Objects 1 and 2 are the catalog and page tree, 3 is the font, then a page and its
+	// content stream for each text
+	var kids []string
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", "", "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"}
+	for _, text := range texts {
+		pageObj := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+		stream := fmt.Sprintf("BT /F1 24 Tf 72 720 Td (%s) Tj ET", text)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>", pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(texts))
+
+	var sb strings.Builder
+	sb.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = sb.Len()
+		sb.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", i+1, obj))
+	}
+
+	xref := sb.Len()
+	sb.WriteString(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(objects)+1))
+	for _, offset := range offsets {
+		sb.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	sb.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref))
+
+	path := filepath.Join(t.TempDir(), "test.pdf")
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestConvertPDFWithFitz(t *testing.T) {
+	content, err := convertPDFWithFitz(writeTestPDF(t, "Hello from MuPDF"), 0)
+	if err != nil {
+		t.Fatalf("convertPDFWithFitz() error = %v", err)
+	}
+
+	if !strings.Contains(string(content), "Hello from MuPDF") {
+		t.Errorf("convertPDFWithFitz() = %q, want it to contain the page text", content)
+	}
+	if strings.Contains(string(content), "<p") || strings.Contains(string(content), "<div") {
+		t.Errorf("convertPDFWithFitz() left HTML in the output: %q", content)
+	}
+}
+
+func TestConvertPDFWithFitzInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	if err := os.WriteFile(path, []byte("not a pdf"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := convertPDFWithFitz(path, 0); err == nil {
+		t.Error("convertPDFWithFitz() expected error for invalid PDF")
+	}
+}
+
+func TestConvertPDFWithFitzWorkers(t *testing.T) {
+	var texts []string
+	for i := 1; i <= 12; i++ {
+		texts = append(texts, fmt.Sprintf("Page %02d", i))
+	}
+	path := writeTestPDF(t, texts...)
+
+	for _, workers := range []int{0, 1, 3, 4, 50} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			content, err := convertPDFWithFitz(path, workers)
+			if err != nil {
+				t.Fatalf("convertPDFWithFitz() error = %v", err)
+			}
+
+			pages := strings.Split(string(content), "\n\n---\n\n")
+			if len(pages) != len(texts) {
+				t.Fatalf("got %d pages, want %d: %q", len(pages), len(texts), content)
+			}
+			for i, page := range pages {
+				if !strings.Contains(page, texts[i]) {
+					t.Errorf("page %d = %q, want it to contain %q", i+1, page, texts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractPDFImages(t *testing.T) {
+	doc, err := fitz.New(writeTestPDF(t, "First", "Second"))
+	if err != nil {
+		t.Fatalf("fitz.New() error = %v", err)
+	}
+	defer doc.Close()
+
+	assetsDir := filepath.Join(t.TempDir(), "assets")
+	names, err := extractPDFImages(doc, "pdf1", assetsDir)
+	if err != nil {
+		t.Fatalf("extractPDFImages() error = %v", err)
+	}
+
+	want := []string{"pdf1-page-1.png", "pdf1-page-2.png"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("extractPDFImages() = %v, want %v", names, want)
+	}
+	for _, name := range want {
+		data, err := os.ReadFile(filepath.Join(assetsDir, name))
+		if err != nil {
+			t.Errorf("Failed to read %s: %v", name, err)
+			continue
+		}
+		if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+			t.Errorf("%s is not a PNG: %v", name, err)
+		}
+	}
+}
+
+func TestExtractPDFImagesEmptyPDF(t *testing.T) {
+	doc, err := fitz.New(writeTestPDF(t))
+	if err != nil {
+		t.Fatalf("fitz.New() error = %v", err)
+	}
+	defer doc.Close()
+
+	assetsDir := filepath.Join(t.TempDir(), "assets")
+	names, err := extractPDFImages(doc, "empty", assetsDir)
+	if err != nil {
+		t.Fatalf("extractPDFImages() error = %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("extractPDFImages() = %v, want no images", names)
+	}
+	if _, err := os.Stat(assetsDir); !os.IsNotExist(err) {
+		t.Errorf("assets directory created for an empty PDF: %v", err)
+	}
+}
+
+func TestAddPDFPageImages(t *testing.T) {
+	outDir := t.TempDir()
+	c := newTestConverter(t, newFakeDrive(), outDir, Options{ExtractPDFImages: true})
+	record := &csv.ConversionRecord{Title: "Scan", Fragments: []string{"reports"}}
+
+	pages := c.addPDFPageImages(record, "pdf1", writeTestPDF(t, "First", ""), []string{"First", ""})
+
+	want := []string{"![Page 1](../assets/pdf1-page-1.png)\n\nFirst", "![Page 2](../assets/pdf1-page-2.png)"}
+	if !reflect.DeepEqual(pages, want) {
+		t.Errorf("addPDFPageImages() = %q, want %q", pages, want)
+	}
+	for _, name := range []string{"pdf1-page-1.png", "pdf1-page-2.png"} {
+		if _, err := os.Stat(filepath.Join(outDir, "assets", name)); err != nil {
+			t.Errorf("page image not written: %v", err)
+		}
+	}
+}
+
+func TestDefaultPDFWorkers(t *testing.T) {
+	tests := []struct {
+		pages int
+		want  int
+	}{
+		{pages: 1, want: 1},
+		{pages: 19, want: 1},
+		{pages: 20, want: 2},
+		{pages: 40, want: 4},
+		{pages: 500, want: 4},
+	}
+
+	for _, tt := range tests {
+		if got := defaultPDFWorkers(tt.pages); got != tt.want {
+			t.Errorf("defaultPDFWorkers(%d) = %d, want %d", tt.pages, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package conversion
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// ContentProcessor is a post-processing step applied to exported content
+type ContentProcessor func(content string, record *csv.ConversionRecord) (string, error)
+
+// pipelineStage is a named step of a ContentPipeline
+type pipelineStage struct {
+	name    string
+	process ContentProcessor
+}
+
+// ContentPipeline applies named ContentProcessors to content in order and records how long
+// each stage takes. It is safe for concurrent use.
+type ContentPipeline struct {
+	stages  []pipelineStage
+	debug   bool // Log the content length after each stage
+	verbose bool // Log each document's stage timings
+
+	mu        sync.Mutex
+	totals    map[string]time.Duration // Time spent in each stage across all documents
+	documents int                      // Documents that went through the pipeline
+}
+
+// StageError is the failure of one pipeline stage, returned by ContentPipeline.Run
+type StageError struct {
+	Stage string // Name of the failing stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return e.Stage + ": " + e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// StageProfile is the time spent in one pipeline stage across all documents
+type StageProfile struct {
+	Name    string
+	Total   time.Duration
+	Average time.Duration // Per document
+	Percent float64       // Share of total post-processing time
+}
+
+// NewContentPipeline creates an empty pipeline. With debug, the content length is logged
+// after each stage; with verbose, each document's stage timings are logged.
+func NewContentPipeline(debug, verbose bool) *ContentPipeline {
+	return &ContentPipeline{debug: debug, verbose: verbose, totals: make(map[string]time.Duration)}
+}
+
+// Add appends a processor to the pipeline under name
+func (p *ContentPipeline) Add(name string, processor ContentProcessor) {
+	p.stages = append(p.stages, pipelineStage{name: name, process: processor})
+}
+
+// Names returns the stage names in the order they run
+func (p *ContentPipeline) Names() []string {
+	names := make([]string, len(p.stages))
+	for i, stage := range p.stages {
+		names[i] = stage.name
+	}
+	return names
+}
+
+// Run applies every stage to content. An error is returned as a StageError naming the failing
+// stage.
+func (p *ContentPipeline) Run(content string, record *csv.ConversionRecord) (string, error) {
+	if p.debug {
+		log.Printf("Pipeline %s: input: %d bytes", record.Title, len(content))
+	}
+
+	timings := make([]time.Duration, 0, len(p.stages))
+	for _, stage := range p.stages {
+		start := time.Now()
+		var err error
+		content, err = stage.process(content, record)
+		timings = append(timings, time.Since(start))
+		if err != nil {
+			return "", &StageError{Stage: stage.name, Err: err}
+		}
+
+		if p.debug {
+			log.Printf("Pipeline %s: %s: %d bytes", record.Title, stage.name, len(content))
+		}
+	}
+
+	p.mu.Lock()
+	for i, elapsed := range timings {
+		p.totals[p.stages[i].name] += elapsed
+	}
+	p.documents++
+	p.mu.Unlock()
+
+	if p.verbose {
+		parts := make([]string, len(timings))
+		for i, elapsed := range timings {
+			parts[i] = fmt.Sprintf("%s=%v", p.stages[i].name, elapsed)
+		}
+		log.Printf("Pipeline timings for %s: %s", record.Title, strings.Join(parts, " "))
+	}
+
+	return content, nil
+}
+
+// Profile returns the time spent in each stage, in stage order, for documents that went
+// through the whole pipeline
+func (p *ContentPipeline) Profile() []StageProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var total time.Duration
+	for _, elapsed := range p.totals {
+		total += elapsed
+	}
+
+	profile := make([]StageProfile, len(p.stages))
+	for i, stage := range p.stages {
+		sp := StageProfile{Name: stage.name, Total: p.totals[stage.name]}
+		if p.documents > 0 {
+			sp.Average = sp.Total / time.Duration(p.documents)
+		}
+		if total > 0 {
+			sp.Percent = 100 * float64(sp.Total) / float64(total)
+		}
+		profile[i] = sp
+	}
+	return profile
+}
+
+// FormatProfile renders a stage profile as a text table
+func FormatProfile(profile []StageProfile) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-16s %12s %12s %7s\n", "Stage", "Total", "Average", "%"))
+	for _, sp := range profile {
+		sb.WriteString(fmt.Sprintf("%-16s %12v %12v %6.1f%%\n", sp.Name, sp.Total.Round(time.Microsecond), sp.Average.Round(time.Microsecond), sp.Percent))
+	}
+	return sb.String()
+}
+
+// buildPipeline builds the post-processing pipeline for exported content from the options.
+// BOM stripping always runs first.
+func (c *Converter) buildPipeline() *ContentPipeline {
+	p := NewContentPipeline(c.opts.PipelineDebug, c.verbose)
+
+	p.Add("strip-bom", func(content string, record *csv.ConversionRecord) (string, error) {
+		return strings.TrimPrefix(content, "\uFEFF"), nil
+	})
+	if c.opts.StripComments {
+		p.Add("strip-comments", func(content string, record *csv.ConversionRecord) (string, error) {
+			return StripHTMLComments(content), nil
+		})
+	}
+	p.Add("rewrite-links", func(content string, record *csv.ConversionRecord) (string, error) {
+		return c.rewriteLinks(content, record), nil
+	})
+	if c.opts.EmbedImages {
+		p.Add("embed-images", func(content string, record *csv.ConversionRecord) (string, error) {
+			return c.embedImages(content, record), nil
+		})
+	}
+	p.Add("preamble", func(content string, record *csv.ConversionRecord) (string, error) {
+		return c.preamble(record) + "\n\n" + content, nil
+	})
+	p.Add("source-link", func(content string, record *csv.ConversionRecord) (string, error) {
+		return c.prependSourceLink(record, content)
+	})
+
+	return p
+}
+
+// PipelineProfile returns the time spent in each post-processing stage during this run
+func (c *Converter) PipelineProfile() []StageProfile {
+	return c.pipeline.Profile()
+}
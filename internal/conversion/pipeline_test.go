@@ -0,0 +1,129 @@
+package conversion
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestContentPipelineRun(t *testing.T) {
+	p := NewContentPipeline(false, false)
+	p.Add("upper", func(content string, record *csv.ConversionRecord) (string, error) {
+		return strings.ToUpper(content), nil
+	})
+	p.Add("title", func(content string, record *csv.ConversionRecord) (string, error) {
+		return record.Title + ": " + content, nil
+	})
+
+	got, err := p.Run("hello", &csv.ConversionRecord{Title: "Doc"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got != "Doc: HELLO" {
+		t.Errorf("Run() = %q, want stages applied in order", got)
+	}
+}
+
+func TestContentPipelineError(t *testing.T) {
+	ran := false
+	p := NewContentPipeline(false, false)
+	p.Add("fail", func(content string, record *csv.ConversionRecord) (string, error) {
+		return "", errors.New("boom")
+	})
+	p.Add("after", func(content string, record *csv.ConversionRecord) (string, error) {
+		ran = true
+		return content, nil
+	})
+
+	_, err := p.Run("hello", &csv.ConversionRecord{})
+	if err == nil || err.Error() != "fail: boom" {
+		t.Errorf("Run() error = %v, want error attributed to the failing stage", err)
+	}
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) || stageErr.Stage != "fail" {
+		t.Errorf("Run() error = %#v, want a StageError for stage fail", err)
+	}
+	if ran {
+		t.Error("stages after a failure must not run")
+	}
+}
+
+func TestBuildPipeline(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"strip-bom", "rewrite-links", "preamble", "source-link"},
+		},
+		{
+			name: "strip comments",
+			opts: Options{StripComments: true},
+			want: []string{"strip-bom", "strip-comments", "rewrite-links", "preamble", "source-link"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConverter(nil, t.TempDir(), false, false, tt.opts)
+			if got := c.pipeline.Names(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pipeline stages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPipelineOutput(t *testing.T) {
+	c := NewConverter(nil, t.TempDir(), false, false, Options{StripComments: true})
+	record := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/doc1/edit", Title: "Doc"}
+
+	got, err := c.pipeline.Run("\uFEFFText<!-- note -->", record)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := "> Link: " + record.Link + "\n\nText"; got != want {
+		t.Errorf("Run() = %q, want %q", got, want)
+	}
+}
+
+func TestContentPipelineProfile(t *testing.T) {
+	p := NewContentPipeline(false, false)
+	p.Add("slow", func(content string, record *csv.ConversionRecord) (string, error) {
+		time.Sleep(4 * time.Millisecond)
+		return content, nil
+	})
+	p.Add("fast", func(content string, record *csv.ConversionRecord) (string, error) {
+		return content, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Run("content", &csv.ConversionRecord{}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	}
+
+	profile := p.Profile()
+	if len(profile) != 2 || profile[0].Name != "slow" || profile[1].Name != "fast" {
+		t.Fatalf("Profile() = %+v, want stages in order", profile)
+	}
+	if profile[0].Total < 8*time.Millisecond {
+		t.Errorf("slow total = %v, want at least 8ms", profile[0].Total)
+	}
+	if profile[0].Average != profile[0].Total/2 {
+		t.Errorf("slow average = %v, want total / 2 documents", profile[0].Average)
+	}
+	if profile[0].Percent < 50 || profile[0].Percent+profile[1].Percent < 99.9 {
+		t.Errorf("percentages = %.1f, %.1f, want slow to dominate and sum to 100", profile[0].Percent, profile[1].Percent)
+	}
+
+	table := FormatProfile(profile)
+	if !strings.Contains(table, "slow") || !strings.Contains(table, "Average") {
+		t.Errorf("FormatProfile() = %q, want a row per stage", table)
+	}
+}
@@ -0,0 +1,79 @@
+package conversion
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Routing strategies for records matching multiple rules
+const (
+	RoutingFirstMatch  = "first-match"
+	RoutingAllMatching = "all-matching"
+)
+
+// RoutingRule routes records carrying Tag to OutputDir
+type RoutingRule struct {
+	Tag       string `yaml:"tag"`
+	OutputDir string `yaml:"output_dir"`
+}
+
+// LoadRoutingRules reads routing rules from a YAML file
+func LoadRoutingRules(filePath string) ([]RoutingRule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing rules: %w", err)
+	}
+
+	var rules []RoutingRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse routing rules: %w", err)
+	}
+
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Tag) == "" || strings.TrimSpace(rule.OutputDir) == "" {
+			return nil, fmt.Errorf("routing rule %d must have both tag and output_dir", i+1)
+		}
+	}
+
+	return rules, nil
+}
+
+// ValidateRoutingStrategy checks that strategy is a known routing strategy
+func ValidateRoutingStrategy(strategy string) error {
+	switch strategy {
+	case RoutingFirstMatch, RoutingAllMatching:
+		return nil
+	default:
+		return fmt.Errorf("invalid routing strategy %q: expected %s or %s", strategy, RoutingFirstMatch, RoutingAllMatching)
+	}
+}
+
+// outputDirsFor returns the output directories a record with the given tags is routed to.
+// Rules are checked in order; unmatched records use the default output directory.
+func (c *Converter) outputDirsFor(tags []string) []string {
+	tagSet := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tagSet[strings.ToLower(tag)] = true
+	}
+
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, rule := range c.opts.RoutingRules {
+		if !tagSet[strings.ToLower(rule.Tag)] || seen[rule.OutputDir] {
+			continue
+		}
+		seen[rule.OutputDir] = true
+		dirs = append(dirs, rule.OutputDir)
+		if c.opts.RoutingStrategy != RoutingAllMatching {
+			break
+		}
+	}
+
+	if len(dirs) == 0 {
+		return []string{c.outputDir}
+	}
+	return dirs
+}
@@ -0,0 +1,189 @@
+package conversion
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestLoadRoutingRules(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		want        []RoutingRule
+		expectError bool
+	}{
+		{
+			name: "valid rules",
+			content: `- tag: hr
+  output_dir: /wiki/hr
+- {tag: "eng", output_dir: "/wiki/eng"}`,
+			want: []RoutingRule{
+				{Tag: "hr", OutputDir: "/wiki/hr"},
+				{Tag: "eng", OutputDir: "/wiki/eng"},
+			},
+		},
+		{
+			name:        "missing output_dir",
+			content:     `- tag: hr`,
+			expectError: true,
+		},
+		{
+			name:        "malformed YAML",
+			content:     `tag: [hr`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "rules.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write rules file: %v", err)
+			}
+
+			rules, err := LoadRoutingRules(path)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rules, tt.want) {
+				t.Errorf("LoadRoutingRules() = %v, want %v", rules, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputDirsFor(t *testing.T) {
+	rules := []RoutingRule{
+		{Tag: "hr", OutputDir: "/wiki/hr"},
+		{Tag: "eng", OutputDir: "/wiki/eng"},
+		{Tag: "Onboarding", OutputDir: "/wiki/hr"},
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		tags     []string
+		want     []string
+	}{
+		{
+			name:     "no matching tag uses default",
+			strategy: RoutingFirstMatch,
+			tags:     []string{"misc"},
+			want:     []string{"/out"},
+		},
+		{
+			name:     "first match follows rule order",
+			strategy: RoutingFirstMatch,
+			tags:     []string{"eng", "hr"},
+			want:     []string{"/wiki/hr"},
+		},
+		{
+			name:     "all matching dedupes directories",
+			strategy: RoutingAllMatching,
+			tags:     []string{"onboarding", "eng", "hr"},
+			want:     []string{"/wiki/hr", "/wiki/eng"},
+		},
+		{
+			name:     "empty strategy defaults to first match",
+			strategy: "",
+			tags:     []string{"eng", "hr"},
+			want:     []string{"/wiki/hr"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConverter(nil, "/out", false, false, Options{RoutingRules: rules, RoutingStrategy: tt.strategy})
+			if got := c.outputDirsFor(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outputDirsFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteOutputRouting(t *testing.T) {
+	tempDir := t.TempDir()
+	defaultDir := filepath.Join(tempDir, "default")
+	hrDir := filepath.Join(tempDir, "hr")
+	engDir := filepath.Join(tempDir, "eng")
+
+	c := NewConverter(nil, defaultDir, false, false, Options{
+		RoutingRules: []RoutingRule{
+			{Tag: "hr", OutputDir: hrDir},
+			{Tag: "eng", OutputDir: engDir},
+		},
+		RoutingStrategy: RoutingAllMatching,
+	})
+
+	record := &csv.ConversionRecord{Title: "Leave Policy", Tags: "hr;eng"}
+	if err := c.writeOutput(record, "content"); err != nil {
+		t.Fatalf("writeOutput() error = %v", err)
+	}
+	// Same path in a different directory must not be treated as a collision
+	if err := c.writeOutput(&csv.ConversionRecord{Title: "Leave Policy"}, "content"); err != nil {
+		t.Fatalf("writeOutput() error = %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(hrDir, "leave-policy.md"),
+		filepath.Join(engDir, "leave-policy.md"),
+		filepath.Join(defaultDir, "leave-policy.md"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be written: %v", path, err)
+		}
+	}
+}
+
+func TestRewriteLinksAcrossRoutes(t *testing.T) {
+	const targetLink = "https://docs.google.com/document/d/target1/edit"
+	content := "[limits](" + targetLink + ")"
+
+	tests := []struct {
+		name       string
+		sourceTags string
+		targetTags string
+		want       string
+	}{
+		{name: "same route", sourceTags: "hr", targetTags: "hr", want: "rate-limits.md"},
+		{name: "other route", sourceTags: "hr", targetTags: "eng", want: "../eng/rate-limits.md"},
+		{name: "default to route", sourceTags: "", targetTags: "eng", want: "../eng/rate-limits.md"},
+		{name: "target in every source route", sourceTags: "hr;eng", targetTags: "eng;hr", want: "rate-limits.md"},
+		{name: "target in one of several source routes", sourceTags: "hr;eng", targetTags: "eng", want: targetLink},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConverter(nil, "/wiki/default", false, false, Options{
+				RoutingRules: []RoutingRule{
+					{Tag: "hr", OutputDir: "/wiki/hr"},
+					{Tag: "eng", OutputDir: "/wiki/eng"},
+				},
+				RoutingStrategy: RoutingAllMatching,
+			})
+			target := &csv.ConversionRecord{Title: "Rate Limits", Link: targetLink, Tags: tt.targetTags}
+			source := &csv.ConversionRecord{Title: "Overview", Tags: tt.sourceTags}
+			c.linkMap[LinkKey(target.Link)] = target
+
+			want := "[limits](" + filepath.FromSlash(tt.want) + ")"
+			if tt.want == targetLink {
+				want = content
+			}
+			if got := c.rewriteLinks(content, source); got != want {
+				t.Errorf("rewriteLinks() = %q, want %q", got, want)
+			}
+		})
+	}
+}
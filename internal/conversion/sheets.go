@@ -0,0 +1,154 @@
+package conversion
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// spreadsheetMimeType is the MIME type of Google Sheets
+const spreadsheetMimeType = "application/vnd.google-apps.spreadsheet"
+
+// SheetsToMarkdown formats the values of a sheet as a GitHub-flavored markdown table, using the
+// first row as the header. Short rows are padded to the widest row. Returns an empty string
+// for a sheet without values.
+func SheetsToMarkdown(data [][]interface{}) string {
+	width := 0
+	for _, row := range data {
+		width = max(width, len(row))
+	}
+	if width == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeRow := func(row []interface{}) {
+		sb.WriteString("|")
+		for i := 0; i < width; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = formatSheetCell(row[i])
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(data[0])
+	sb.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+	for _, row := range data[1:] {
+		writeRow(row)
+	}
+	return sb.String()
+}
+
+// formatSheetCell formats a cell value for a markdown table cell, escaping pipes and turning
+// line breaks into <br>
+func formatSheetCell(value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\n", "<br>")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// isSpreadsheet reports whether a record links to a Google Sheet, by its URL or MIME type
+func isSpreadsheet(record *csv.ConversionRecord, mimeType string) bool {
+	return mimeType == spreadsheetMimeType || strings.Contains(record.Link, "docs.google.com/spreadsheets")
+}
+
+// convertSheet writes a Google Sheet as a document with a markdown table per sheet, read with
+// the Sheets API. If the spreadsheet cannot be read, a warning is logged and the usual stub is
+// written instead.
+func (c *Converter) convertSheet(record *csv.ConversionRecord, fileID string, published bool) error {
+	file, err := c.getFileMetadata(fileID)
+	if err != nil {
+		err = fmt.Errorf("failed to get metadata for %s: %w", fileID, err)
+		return newRecordError(APIErrorKind(err, ErrExportFailed), record, err)
+	}
+
+	content, err := c.sheetTables(fileID)
+	if err != nil && c.runContext().Err() != nil {
+		return err
+	}
+	if err != nil {
+		log.Printf("Warning: Failed to read sheet values for %s, writing a stub: %v", record.Title, err)
+		if c.requiresStubConversion(record.Link) {
+			return c.convertStubDocument(record, published)
+		}
+		return c.convertStubDocumentWithMimeType(record, file.MimeType, published)
+	}
+
+	contentStr, err := c.pipeline.Run(content, record)
+	if err != nil {
+		return newRecordError(ErrProcessingFailed, record, fmt.Errorf("failed to process %s: %w", record.Title, err))
+	}
+
+	if c.opts.NoFrontmatter {
+		return c.writeDocument(record, "", contentStr, nil)
+	}
+	frontmatter := c.generateFrontmatter(record, RevisionHash(file), contentStr, published, c.opts.FrontmatterFormat)
+	return c.writeDocument(record, frontmatter, contentStr, nil)
+}
+
+// sheetTables reads the values of every sheet of a spreadsheet and formats them as a heading
+// and a markdown table per sheet
+func (c *Converter) sheetTables(spreadsheetID string) (string, error) {
+	if c.opts.Sheets == nil {
+		return "", fmt.Errorf("no Sheets API service configured")
+	}
+
+	var spreadsheet *sheets.Spreadsheet
+	err := c.opts.Retry.Do(c.runContext(), c.verbose, func() error {
+		var err error
+		spreadsheet, err = c.opts.Sheets.Spreadsheets.Get(spreadsheetID).Fields("sheets.properties.title").Context(c.runContext()).Do()
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get spreadsheet: %w", err)
+	}
+
+	var sections []string
+	for _, sheet := range spreadsheet.Sheets {
+		title := sheet.Properties.Title
+
+		var values *sheets.ValueRange
+		err := c.opts.Retry.Do(c.runContext(), c.verbose, func() error {
+			var err error
+			values, err = c.opts.Sheets.Spreadsheets.Values.Get(spreadsheetID, sheetRange(title)).Context(c.runContext()).Do()
+			return err
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to get values of sheet %s: %w", title, err)
+		}
+
+		table := SheetsToMarkdown(values.Values)
+		if table == "" {
+			table = "*This sheet is empty.*\n"
+		}
+		sections = append(sections, "## "+title+"\n\n"+table)
+	}
+	return strings.Join(sections, "\n"), nil
+}
+
+// sheetRange returns the A1 notation range covering the whole sheet named title
+func sheetRange(title string) string {
+	return "'" + strings.ReplaceAll(title, "'", "''") + "'"
+}
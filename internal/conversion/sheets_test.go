@@ -0,0 +1,130 @@
+package conversion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// newTestSheetsService returns a Sheets service backed by the fake server
+func newTestSheetsService(t *testing.T, fake *fakeDrive) *sheets.Service {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	service, err := sheets.NewService(context.Background(),
+		option.WithHTTPClient(server.Client()),
+		option.WithEndpoint(server.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create Sheets service: %v", err)
+	}
+	return service
+}
+
+func TestSheetsToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		data [][]interface{}
+		want string
+	}{
+		{name: "nil", data: nil, want: ""},
+		{name: "empty rows", data: [][]interface{}{{}, {}}, want: ""},
+		{
+			name: "header only",
+			data: [][]interface{}{{"Name", "Owner"}},
+			want: "| Name | Owner |\n| --- | --- |\n",
+		},
+		{
+			name: "mixed types and short rows",
+			data: [][]interface{}{
+				{"Item", "Count", "Price", "In stock"},
+				{"Bolt", float64(120), 0.25, true},
+				{"Nut", nil, float64(1000000)},
+				{},
+			},
+			want: "| Item | Count | Price | In stock |\n| --- | --- | --- | --- |\n" +
+				"| Bolt | 120 | 0.25 | true |\n" +
+				"| Nut |  | 1000000 |  |\n" +
+				"|  |  |  |  |\n",
+		},
+		{
+			name: "pipes and line breaks",
+			data: [][]interface{}{{"Command"}, {"grep a | sort"}, {"line one\nline two"}},
+			want: "| Command |\n| --- |\n| grep a \\| sort |\n| line one<br>line two |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SheetsToMarkdown(tt.data); got != tt.want {
+				t.Errorf("SheetsToMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertSheetsToTable(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/sheet1", jsonHandler(`{"id": "sheet1", "name": "Inventory", "mimeType": "application/vnd.google-apps.spreadsheet", "modifiedTime": "2024-01-01T00:00:00.000Z"}`))
+	fake.handle("GET", "/v4/spreadsheets/sheet1", jsonHandler(`{"sheets": [{"properties": {"title": "Parts"}}, {"properties": {"title": "Tom's notes"}}]}`))
+	fake.handle("GET", "/v4/spreadsheets/sheet1/values/'Parts'", jsonHandler(`{"values": [["Part", "Count"], ["Bolt", "120"]]}`))
+	fake.handle("GET", "/v4/spreadsheets/sheet1/values/'Tom''s notes'", jsonHandler(`{}`))
+
+	outDir := t.TempDir()
+	c := newTestConverter(t, fake, outDir, Options{SheetsToTable: true, Sheets: newTestSheetsService(t, fake)})
+	records := []csv.ConversionRecord{{Link: "https://docs.google.com/spreadsheets/d/sheet1/edit", Title: "Inventory"}}
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "inventory.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	want := "## Parts\n\n| Part | Count |\n| --- | --- |\n| Bolt | 120 |\n\n## Tom's notes\n\n*This sheet is empty.*\n"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("output = %q, want it to contain %q", content, want)
+	}
+	if !strings.Contains(string(content), `hash-gdrive: "2024-01-01T00:00:00.000Z"`) {
+		t.Errorf("output = %q, want the sheet's modified time as hash-gdrive", content)
+	}
+	if len(fake.requestsFor("GET", "/files/sheet1/export")) != 0 {
+		t.Error("Sheet exported through Drive, want it read with the Sheets API")
+	}
+}
+
+func TestConvertSheetsToTableFallsBackToStub(t *testing.T) {
+	fake := newFakeDrive()
+	fake.handle("GET", "/files/sheet1", jsonHandler(`{"id": "sheet1", "name": "Inventory", "mimeType": "application/vnd.google-apps.spreadsheet"}`))
+	fake.handle("GET", "/v4/spreadsheets/sheet1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found."}}`))
+	})
+
+	outDir := t.TempDir()
+	c := newTestConverter(t, fake, outDir, Options{SheetsToTable: true, Sheets: newTestSheetsService(t, fake)})
+	records := []csv.ConversionRecord{{Link: "https://docs.google.com/spreadsheets/d/sheet1/edit", Title: "Inventory"}}
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outDir, "inventory.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !strings.Contains(string(content), "This is a Google Sheet") {
+		t.Errorf("output = %q, want the stub", content)
+	}
+}
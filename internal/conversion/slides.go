@@ -0,0 +1,43 @@
+package conversion
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"path"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// slidesAssetsDir is the directory, relative to the stub, that slide previews are written to
+const slidesAssetsDir = "_assets"
+
+// slidesPreview exports a PNG preview of a presentation and returns the markdown that embeds
+// it together with the image itself, keyed by its path relative to the stub. A failed export
+// is logged and returns an empty result so the caller can fall back to a text-only stub.
+func (c *Converter) slidesPreview(record *csv.ConversionRecord) (string, map[string][]byte) {
+	fileID, err := utils.ExtractFileID(record.Link)
+	if err != nil {
+		log.Printf("Warning: Failed to export slide preview for %s: %v", record.Title, err)
+		return "", nil
+	}
+
+	body, err := c.executeExportWithRetry(fileID, "image/png")
+	if err != nil {
+		log.Printf("Warning: Failed to export slide preview for %s: %v", record.Title, err)
+		return "", nil
+	}
+	defer body.Close()
+
+	image, err := io.ReadAll(body)
+	if err != nil {
+		log.Printf("Warning: Failed to read slide preview for %s: %v", record.Title, err)
+		return "", nil
+	}
+
+	assetPath := path.Join(slidesAssetsDir, utils.NormalizeFilename(c.filenameTitle(record))+"-preview.png")
+	markdown := fmt.Sprintf("![Slide Preview](%s)\n\n[Open presentation](%s)", assetPath, record.Link)
+
+	return markdown, map[string][]byte{assetPath: image}
+}
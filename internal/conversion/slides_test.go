@@ -0,0 +1,82 @@
+package conversion
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestSlidesPreview(t *testing.T) {
+	png := []byte("\x89PNG fake image")
+	record := &csv.ConversionRecord{
+		Link:      "https://docs.google.com/presentation/d/deck1/edit",
+		Title:     "Quarterly Review",
+		Fragments: []string{"Reports"},
+	}
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		exportFails bool
+		wantPreview bool
+	}{
+		{name: "enabled", enabled: true, wantPreview: true},
+		{name: "disabled", enabled: false, wantPreview: false},
+		{name: "export fails", enabled: true, exportFails: true, wantPreview: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			if !tt.exportFails {
+				fake.handle("GET", "/files/deck1/export", func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "image/png")
+					w.Write(png)
+				})
+			}
+
+			outputDir := t.TempDir()
+			c := newTestConverter(t, fake, outputDir, Options{SlidesPreview: tt.enabled, NoFrontmatter: true})
+
+			if err := c.convertStubDocument(record, false); err != nil {
+				t.Fatalf("convertStubDocument() error = %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "Reports", "quarterly-review.md"))
+			if err != nil {
+				t.Fatalf("Failed to read stub: %v", err)
+			}
+			if !strings.Contains(string(content), "This is a Google Presentation") {
+				t.Errorf("stub missing text-only notice:\n%s", content)
+			}
+
+			hasPreview := strings.Contains(string(content), "![Slide Preview](_assets/quarterly-review-preview.png)")
+			if hasPreview != tt.wantPreview {
+				t.Errorf("stub has preview = %v, want %v:\n%s", hasPreview, tt.wantPreview, content)
+			}
+			if tt.wantPreview && !strings.Contains(string(content), "[Open presentation]("+record.Link+")") {
+				t.Errorf("stub missing presentation link:\n%s", content)
+			}
+
+			image, err := os.ReadFile(filepath.Join(outputDir, "Reports", "_assets", "quarterly-review-preview.png"))
+			if tt.wantPreview {
+				if err != nil {
+					t.Fatalf("Failed to read preview: %v", err)
+				}
+				if string(image) != string(png) {
+					t.Errorf("preview = %q, want %q", image, png)
+				}
+			} else if !os.IsNotExist(err) {
+				t.Errorf("preview should not be written, got err = %v", err)
+			}
+
+			if !tt.enabled && len(fake.requestsFor("GET", "/files/deck1/export")) != 0 {
+				t.Error("preview exported while disabled")
+			}
+		})
+	}
+}
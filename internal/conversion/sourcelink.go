@@ -0,0 +1,44 @@
+package conversion
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// DefaultSourceLinkTemplate is the source link line used by -append-source-link
+const DefaultSourceLinkTemplate = "> **Source:** [View in Google Drive]({{.Link}})"
+
+// ParseSourceLinkTemplate parses a source link template. The template is executed with
+// the document's ConversionRecord, so {{.Link}} and {{.Title}} are available.
+func ParseSourceLinkTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("source-link").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source link template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderSourceLink executes a source link template for a record
+func RenderSourceLink(tmpl *template.Template, record *csv.ConversionRecord) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, record); err != nil {
+		return "", fmt.Errorf("failed to render source link for %s: %w", record.Title, err)
+	}
+	return sb.String(), nil
+}
+
+// prependSourceLink puts the rendered source link before content when a template is configured
+func (c *Converter) prependSourceLink(record *csv.ConversionRecord, content string) (string, error) {
+	if c.opts.SourceLinkTemplate == nil {
+		return content, nil
+	}
+
+	sourceLink, err := RenderSourceLink(c.opts.SourceLinkTemplate, record)
+	if err != nil {
+		return "", err
+	}
+	return sourceLink + "\n\n" + content, nil
+}
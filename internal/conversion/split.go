@@ -0,0 +1,36 @@
+package conversion
+
+import (
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// SplitFragments returns the fragments used to build a record's output path. With byFrag1,
+// every record gets a frag1 directory and an empty frag1 becomes utils.UncategorizedDir; byFrag2
+// does the same for frag2 within each frag1 directory and implies byFrag1.
+func SplitFragments(fragments []string, byFrag1, byFrag2 bool) []string {
+	split := append([]string(nil), fragments...)
+	if byFrag1 || byFrag2 {
+		split = uncategorized(split, 0)
+	}
+	if byFrag2 {
+		split = uncategorized(split, 1)
+	}
+	return split
+}
+
+// uncategorized sets an empty or missing fragments[i] to utils.UncategorizedDir
+func uncategorized(fragments []string, i int) []string {
+	for len(fragments) <= i {
+		fragments = append(fragments, "")
+	}
+	if fragments[i] == "" {
+		fragments[i] = utils.UncategorizedDir
+	}
+	return fragments
+}
+
+// outputFragments returns the fragments a record's output path and relative links are built from
+func (c *Converter) outputFragments(record *csv.ConversionRecord) []string {
+	return SplitFragments(record.GetFragments(), c.opts.SplitByFrag1, c.opts.SplitByFrag2)
+}
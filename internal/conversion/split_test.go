@@ -0,0 +1,97 @@
+package conversion
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+func TestSplitFragments(t *testing.T) {
+	tests := []struct {
+		name      string
+		fragments []string
+		byFrag1   bool
+		byFrag2   bool
+		want      []string
+	}{
+		{
+			name:      "no split",
+			fragments: []string{"", "b", "", "", ""},
+			want:      []string{"", "b", "", "", ""},
+		},
+		{
+			name:      "frag1 present",
+			fragments: []string{"a", "", "", "", ""},
+			byFrag1:   true,
+			want:      []string{"a", "", "", "", ""},
+		},
+		{
+			name:      "frag1 missing",
+			fragments: []string{"", "b", "", "", ""},
+			byFrag1:   true,
+			want:      []string{utils.UncategorizedDir, "b", "", "", ""},
+		},
+		{
+			name:      "frag2 missing",
+			fragments: []string{"a", "", "", "", ""},
+			byFrag2:   true,
+			want:      []string{"a", utils.UncategorizedDir, "", "", ""},
+		},
+		{
+			name:      "no fragments",
+			fragments: nil,
+			byFrag2:   true,
+			want:      []string{utils.UncategorizedDir, utils.UncategorizedDir},
+		},
+		{
+			name:      "frag2 implies frag1",
+			fragments: []string{"", "", "", "", ""},
+			byFrag2:   true,
+			want:      []string{utils.UncategorizedDir, utils.UncategorizedDir, "", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitFragments(tt.fragments, tt.byFrag1, tt.byFrag2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitFragments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitByFrag1(t *testing.T) {
+	outputDir := t.TempDir()
+	c := NewConverter(nil, outputDir, false, false, Options{SplitByFrag1: true})
+
+	hr := csv.ConversionRecord{Link: "https://docs.google.com/document/d/hr1/edit", Title: "Leave Policy", Fragments: []string{"HR"}}
+	misc := csv.ConversionRecord{Link: "https://docs.google.com/document/d/misc1/edit", Title: "Notes"}
+	c.linkMap[hr.Link] = &hr
+	c.linkMap[misc.Link] = &misc
+
+	for _, record := range []*csv.ConversionRecord{&hr, &misc} {
+		if err := c.writeOutput(record, "content"); err != nil {
+			t.Fatalf("writeOutput() error = %v", err)
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join(outputDir, "HR", "leave-policy.md"),
+		filepath.Join(outputDir, utils.UncategorizedDir, "notes.md"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be written: %v", path, err)
+		}
+	}
+
+	// Links across sections must resolve between the split directories
+	got := c.rewriteLinks("[policy]("+hr.Link+")", &misc)
+	if !strings.Contains(got, "(../HR/leave-policy.md)") {
+		t.Errorf("rewriteLinks() = %q, want link to ../HR/leave-policy.md", got)
+	}
+}
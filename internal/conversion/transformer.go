@@ -0,0 +1,95 @@
+package conversion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// DefaultOutputFormat is the output format used when none is selected
+const DefaultOutputFormat = "markdown"
+
+// Transformer turns a converted document into an output format. Transform receives the
+// markdown content with rewritten links and the rendered frontmatter (empty with
+// NoFrontmatter) and returns the body and frontmatter to write. A non-empty frontmatter is
+// written before the body, separated by a blank line.
+type Transformer interface {
+	Transform(content, frontmatter string, record csv.ConversionRecord) (body, fm string, err error)
+	FileExtension() string // Extension of output files, including the dot
+}
+
+// TransformerRegistry maps output format names to Transformer constructors
+type TransformerRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]func() Transformer
+}
+
+// NewTransformerRegistry creates an empty registry
+func NewTransformerRegistry() *TransformerRegistry {
+	return &TransformerRegistry{factories: make(map[string]func() Transformer)}
+}
+
+// Register makes a Transformer available under name, replacing any previous registration
+func (r *TransformerRegistry) Register(name string, factory func() Transformer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[strings.ToLower(name)] = factory
+}
+
+// New returns a new Transformer for the output format name
+func (r *TransformerRegistry) New(name string) (Transformer, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[strings.ToLower(name)]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (available: %s)", name, strings.Join(r.Names(), ", "))
+	}
+	return factory(), nil
+}
+
+// Names returns the registered output format names in sorted order
+func (r *TransformerRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Transformers is the registry used by NewTransformer. Additional formats, e.g. in files
+// behind build tags, register themselves from an init function with RegisterTransformer.
+var Transformers = NewTransformerRegistry()
+
+func init() {
+	Transformers.Register(DefaultOutputFormat, func() Transformer { return MarkdownTransformer{} })
+}
+
+// RegisterTransformer registers a Transformer in the default registry
+func RegisterTransformer(name string, factory func() Transformer) {
+	Transformers.Register(name, factory)
+}
+
+// NewTransformer returns the Transformer registered for the output format formatName
+func NewTransformer(formatName string) (Transformer, error) {
+	return Transformers.New(formatName)
+}
+
+// MarkdownTransformer writes markdown files unchanged
+type MarkdownTransformer struct{}
+
+// Transform returns the content and frontmatter as they are
+func (MarkdownTransformer) Transform(content, frontmatter string, record csv.ConversionRecord) (string, string, error) {
+	return content, frontmatter, nil
+}
+
+// FileExtension returns ".md"
+func (MarkdownTransformer) FileExtension() string {
+	return ".md"
+}
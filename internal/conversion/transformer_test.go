@@ -0,0 +1,96 @@
+package conversion
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// upperTransformer is a test Transformer writing upper-cased .txt files
+type upperTransformer struct {
+	err error
+}
+
+func (u upperTransformer) Transform(content, frontmatter string, record csv.ConversionRecord) (string, string, error) {
+	if u.err != nil {
+		return "", "", u.err
+	}
+	return strings.ToUpper(content), "", nil
+}
+
+func (upperTransformer) FileExtension() string {
+	return ".txt"
+}
+
+func TestTransformerRegistry(t *testing.T) {
+	registry := NewTransformerRegistry()
+	registry.Register("Upper", func() Transformer { return upperTransformer{} })
+	registry.Register("markdown", func() Transformer { return MarkdownTransformer{} })
+
+	if got, want := registry.Names(), []string{"markdown", "upper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	transformer, err := registry.New("UPPER")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if transformer.FileExtension() != ".txt" {
+		t.Errorf("New() returned %T, want upperTransformer", transformer)
+	}
+
+	if _, err := registry.New("asciidoc"); err == nil || !strings.Contains(err.Error(), "markdown, upper") {
+		t.Errorf("New() error = %v, want unknown format listing available formats", err)
+	}
+}
+
+func TestNewTransformerDefault(t *testing.T) {
+	transformer, err := NewTransformer(DefaultOutputFormat)
+	if err != nil {
+		t.Fatalf("NewTransformer() error = %v", err)
+	}
+
+	body, fm, err := transformer.Transform("content", "---\ntitle: x\n---\n", csv.ConversionRecord{})
+	if err != nil || body != "content" || fm != "---\ntitle: x\n---\n" {
+		t.Errorf("Transform() = %q, %q, %v, want input unchanged", body, fm, err)
+	}
+	if transformer.FileExtension() != ".md" {
+		t.Errorf("FileExtension() = %q, want .md", transformer.FileExtension())
+	}
+}
+
+func TestWriteDocumentTransformer(t *testing.T) {
+	outputDir := t.TempDir()
+	c := NewConverter(nil, outputDir, false, false, Options{Transformer: upperTransformer{}})
+
+	target := csv.ConversionRecord{Link: "https://docs.google.com/document/d/target1/edit", Title: "Target", Fragments: []string{"guides"}}
+	c.linkMap[target.Link] = &target
+
+	record := &csv.ConversionRecord{Title: "Source"}
+	content := c.rewriteLinks("see [target]("+target.Link+")", record)
+	if !strings.Contains(content, "(guides/target.txt)") {
+		t.Errorf("rewriteLinks() = %q, want link with the transformer's extension", content)
+	}
+
+	if err := c.writeDocument(record, "---\n---\n", content, nil); err != nil {
+		t.Fatalf("writeDocument() error = %v", err)
+	}
+
+	written, err := os.ReadFile(filepath.Join(outputDir, "source.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(written) != "SEE [TARGET](GUIDES/TARGET.TXT)" {
+		t.Errorf("written = %q, want transformed body without frontmatter", written)
+	}
+
+	c = NewConverter(nil, outputDir, false, false, Options{Transformer: upperTransformer{err: errors.New("boom")}})
+	if err := c.writeDocument(record, "", "content", nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("writeDocument() error = %v, want transformer error", err)
+	}
+}
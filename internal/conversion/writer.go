@@ -0,0 +1,86 @@
+package conversion
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// WriteJob is a file to write to the output tree
+type WriteJob struct {
+	path    string
+	content []byte
+	record  *csv.ConversionRecord // Record the file belongs to
+}
+
+// writeBatch holds the files written for one converted record
+type writeBatch struct {
+	record *csv.ConversionRecord
+	jobs   []WriteJob
+}
+
+// write writes a file, or queues it for the I/O workers while Convert is running
+func (c *Converter) write(record *csv.ConversionRecord, job WriteJob) error {
+	c.mu.Lock()
+	if c.pendingWrites != nil {
+		c.pendingWrites[record] = append(c.pendingWrites[record], job)
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+
+	if err := c.writeFile(job); err != nil {
+		return newRecordError(ErrWriteFailed, record, err)
+	}
+	return nil
+}
+
+// takeWrites removes and returns the writes queued for record
+func (c *Converter) takeWrites(record *csv.ConversionRecord) []WriteJob {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	jobs := c.pendingWrites[record]
+	delete(c.pendingWrites, record)
+	return jobs
+}
+
+// writeFile creates the directories for a file and writes it
+func (c *Converter) writeFile(job WriteJob) error {
+	dir := filepath.Dir(job.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	_, statErr := os.Stat(job.path)
+	if err := os.WriteFile(job.path, job.content, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", job.path, err)
+	}
+	c.recordWrite(job, statErr == nil)
+
+	if c.verbose {
+		log.Printf("Wrote: %s", job.path)
+	}
+	return nil
+}
+
+// writeBatches writes each batch in order and reports the record as converted once all of its
+// files are written
+func (c *Converter) writeBatches(batches <-chan writeBatch, results chan<- recordResult) {
+	for batch := range batches {
+		var err error
+		for _, job := range batch.jobs {
+			if err = c.writeFile(job); err != nil {
+				err = newRecordError(ErrWriteFailed, batch.record, err)
+				break
+			}
+		}
+		if err != nil {
+			log.Printf("Error: %s", err)
+		} else {
+			c.recordConverted(batch.record, batch.jobs)
+		}
+		results <- recordResult{record: batch.record, err: err}
+	}
+}
@@ -0,0 +1,148 @@
+package conversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+func TestConvertWritesThroughIOWorkers(t *testing.T) {
+	var records []csv.ConversionRecord
+	for i := 0; i < 6; i++ {
+		records = append(records, csv.ConversionRecord{
+			Link:  fmt.Sprintf("https://docs.google.com/forms/d/form%d/viewform", i),
+			Title: fmt.Sprintf("Form %d", i),
+		})
+	}
+
+	outputDir := t.TempDir()
+	c := newTestConverter(t, newFakeDrive(), outputDir, Options{IOWorkers: 2})
+	if err := c.Convert(context.Background(), records, 3); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	for i := range records {
+		path := filepath.Join(outputDir, fmt.Sprintf("form-%d.md", i))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", path, err)
+		}
+		if !strings.Contains(string(data), "This is a Google Form") {
+			t.Errorf("%s content = %q, want a form stub", path, data)
+		}
+	}
+	if c.pendingWrites != nil {
+		t.Errorf("pendingWrites still set after Convert()")
+	}
+}
+
+func TestConvertReportsWriteErrors(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/forms/d/form1/viewform", Title: "Form 1"},
+		{Link: "https://docs.google.com/forms/d/form2/viewform", Title: "Form 2"},
+	}
+
+	// The output directory cannot be created below a regular file
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	failedPath := filepath.Join(t.TempDir(), "failed.csv")
+	c := newTestConverter(t, newFakeDrive(), filepath.Join(blocker, "out"), Options{IOWorkers: 1, FailedOutput: failedPath})
+	err := c.Convert(context.Background(), records, 2)
+	if err == nil || !strings.Contains(err.Error(), "conversion had 2 errors") {
+		t.Fatalf("Convert() error = %v, want 2 errors", err)
+	}
+
+	failed, err := csv.ParseConversionCSV(failedPath)
+	if err != nil {
+		t.Fatalf("ParseConversionCSV() error = %v", err)
+	}
+	if len(failed) != len(records) {
+		t.Errorf("failed output has %d records, want %d", len(failed), len(records))
+	}
+
+	data, err := os.ReadFile(failedPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got := strings.Count(string(data), ",write_failed\n"); got != len(records) {
+		t.Errorf("failed output has %d write_failed records, want %d:\n%s", got, len(records), data)
+	}
+}
+
+func TestWriteOutputLongTitleCollision(t *testing.T) {
+	outputDir := t.TempDir()
+	c := NewConverter(nil, outputDir, false, false, Options{})
+
+	// Both titles truncate to the same 255-byte filename
+	prefix := strings.Repeat("quarterly planning review ", 12)
+	first := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/a/edit", Title: prefix + "north"}
+	second := &csv.ConversionRecord{Link: "https://docs.google.com/document/d/b/edit", Title: prefix + "south"}
+	for _, record := range []*csv.ConversionRecord{first, second} {
+		if err := c.writeOutput(record, record.Title); err != nil {
+			t.Fatalf("writeOutput() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("wrote %d files, want 2", len(entries))
+	}
+	for _, entry := range entries {
+		if len(entry.Name()) > 255 {
+			t.Errorf("filename %q is %d bytes, want at most 255", entry.Name(), len(entry.Name()))
+		}
+	}
+
+	hashed := filepath.Join(outputDir, utils.ShortFilename(utils.NormalizeFilename(second.Title), ".md"))
+	if data, err := os.ReadFile(hashed); err != nil || string(data) != second.Title {
+		t.Errorf("second document at %s = %q, %v, want its content", hashed, data, err)
+	}
+}
+
+func TestConvertCancelled(t *testing.T) {
+	records := []csv.ConversionRecord{
+		{Link: "https://docs.google.com/forms/d/form1/viewform", Title: "Form 1"},
+		{Link: "https://docs.google.com/forms/d/form2/viewform", Title: "Form 2"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outputDir := t.TempDir()
+	failedPath := filepath.Join(t.TempDir(), "failed.csv")
+	c := newTestConverter(t, newFakeDrive(), outputDir, Options{FailedOutput: failedPath})
+	err := c.Convert(ctx, records, 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Convert() error = %v, want %v", err, context.Canceled)
+	}
+
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+		t.Errorf("wrote %d files after cancellation, want none", len(entries))
+	}
+
+	// Cancelled records are listed as not processed, without an error type
+	failed, err := csv.ParseConversionCSV(failedPath)
+	if err != nil {
+		t.Fatalf("ParseConversionCSV() error = %v", err)
+	}
+	if len(failed) != len(records) {
+		t.Errorf("failed output has %d records, want %d", len(failed), len(records))
+	}
+	data, _ := os.ReadFile(failedPath)
+	if strings.Contains(string(data), "other") {
+		t.Errorf("cancelled records have an error type:\n%s", data)
+	}
+}
@@ -0,0 +1,40 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// DefaultDelimiter is the field delimiter used when none is configured
+const DefaultDelimiter = ','
+
+// ParseDelimiter parses a delimiter flag value. It must be a single printable ASCII
+// character; a tab may be given literally or as the escape `\t`.
+func ParseDelimiter(value string) (rune, error) {
+	if value == `\t` || value == "\t" {
+		return '\t', nil
+	}
+	if len(value) != 1 {
+		return 0, fmt.Errorf("delimiter must be a single character, got %q", value)
+	}
+
+	c := rune(value[0])
+	if c < ' ' || c > '~' {
+		return 0, fmt.Errorf("delimiter must be a printable ASCII character, got %q", value)
+	}
+	if c == '"' {
+		return 0, fmt.Errorf("delimiter cannot be a double quote")
+	}
+	return c, nil
+}
+
+// newReader returns a CSV reader using the given delimiter
+func newReader(r io.Reader, delimiter rune) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	// Trimming leading space would also swallow empty fields when the delimiter is whitespace;
+	// values are trimmed by getString instead
+	reader.TrimLeadingSpace = delimiter != '\t' && delimiter != ' '
+	return reader
+}
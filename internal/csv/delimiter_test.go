@@ -0,0 +1,113 @@
+package csv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rune
+		wantErr bool
+	}{
+		{value: ",", want: ','},
+		{value: ";", want: ';'},
+		{value: "|", want: '|'},
+		{value: `\t`, want: '\t'},
+		{value: "\t", want: '\t'},
+		{value: "", wantErr: true},
+		{value: ";;", wantErr: true},
+		{value: "\n", wantErr: true},
+		{value: `"`, wantErr: true},
+		{value: "é", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDelimiter(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDelimiter(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseDelimiter(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConversionCSVWithDelimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		delimiter rune
+	}{
+		{
+			name:      "semicolon",
+			content:   "link;title;tags;frag1;frag2;frag3;frag4;frag5\nhttps://docs.google.com/document/d/abc/edit;Doc, with comma;;guides;;;;\n",
+			delimiter: ';',
+		},
+		{
+			name:      "tab keeps empty fields",
+			content:   "link\ttitle\ttags\tfrag1\tfrag2\tfrag3\tfrag4\tfrag5\nhttps://docs.google.com/document/d/abc/edit\tDoc, with comma\t\tguides\t\t\t\t\n",
+			delimiter: '\t',
+		},
+	}
+
+	want := []ConversionRecord{{
+		Link:      "https://docs.google.com/document/d/abc/edit",
+		Title:     "Doc, with comma",
+		Fragments: []string{"guides"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "input.csv")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			records, err := ParseConversionCSVWithDelimiter(path, tt.delimiter)
+			if err != nil {
+				t.Fatalf("ParseConversionCSVWithDelimiter() error = %v", err)
+			}
+			if !reflect.DeepEqual(records, want) {
+				t.Errorf("records = %+v, want %+v", records, want)
+			}
+		})
+	}
+}
+
+func TestDiscoveryTSVRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discovery.tsv")
+	records := []DiscoveryRecord{
+		{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Tab\there", Status: "available"},
+		{Link: "https://docs.google.com/document/d/FILE_ID_2/edit", Title: "Plain", Status: "deleted", ModifiedTime: "2024-01-15T10:30:00.000Z"},
+	}
+
+	if err := WriteDiscoveryCSVWithDelimiter(path, records, '\t'); err != nil {
+		t.Fatalf("WriteDiscoveryCSVWithDelimiter() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.HasPrefix(string(content), "link\ttitle\tstatus\tmodified_time\tbreadcrumb\tfile_type\n") {
+		t.Errorf("unexpected header in %q", content)
+	}
+	if !strings.Contains(string(content), "\"Tab\there\"") {
+		t.Errorf("field containing a tab was not quoted: %q", content)
+	}
+
+	parsed, err := ParseDiscoveryCSVWithDelimiter(path, '\t')
+	if err != nil {
+		t.Fatalf("ParseDiscoveryCSVWithDelimiter() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("parsed = %+v, want %+v", parsed, records)
+	}
+}
@@ -1,47 +1,67 @@
 package csv
 
 import (
-	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 // InputRecord represents a record from the input CSV for discovery mode
 type InputRecord struct {
-	URL string
+	URL   string
+	Depth *int // Maximum link depth for this URL from the depth column (nil = the global depth)
 }
 
 // DiscoveryRecord represents a record for discovery output
 type DiscoveryRecord struct {
-	Link   string
-	Title  string
-	Status string // "available", "deleted", "invalid", or "permission_denied"
+	Link         string   `json:"link"`
+	Title        string   `json:"title"`
+	Status       string   `json:"status"`                  // "available", "deleted", "invalid", "permission_denied", "filtered_owner" or "external_shortcut"
+	ModifiedTime string   `json:"modified_time,omitempty"` // RFC3339 Drive modification time (empty if unknown)
+	Breadcrumb   []string `json:"breadcrumb,omitempty"`    // Drive folder names from the discovered root folder to the file's parent
+	FileType     string   `json:"file_type,omitempty"`     // Category of the file's MIME type, such as "google-doc" (empty if unknown)
 }
 
+// BreadcrumbSeparator joins breadcrumb folder names in the discovery CSV breadcrumb column
+const BreadcrumbSeparator = "|"
+
 // ConversionRecord represents a record from the enhanced CSV for conversion mode
 type ConversionRecord struct {
-	Link  string
-	Title string
-	Tags  string
-	Frag1 string
-	Frag2 string
-	Frag3 string
-	Frag4 string
-	Frag5 string
+	Link         string
+	Title        string
+	Tags         string
+	Fragments    []string // frag1, frag2, ... without trailing empty fragments
+	MIMEOverride string   // MIME type used instead of the one Drive reports (empty = Drive's)
 }
 
+const (
+	// DefaultFragmentDepth is the number of fragment columns, frag1 to frag5, read by default
+	DefaultFragmentDepth = 5
+
+	// MaxFragmentDepth is the deepest supported fragment column, frag10
+	MaxFragmentDepth = 10
+)
+
+// fragColumnPattern matches fragment column names such as "frag3"
+var fragColumnPattern = regexp.MustCompile(`^frag([1-9][0-9]*)$`)
+
 // ParseInputCSV reads the input CSV file for discovery mode
 func ParseInputCSV(filePath string) ([]InputRecord, error) {
+	return ParseInputCSVWithDelimiter(filePath, DefaultDelimiter)
+}
+
+// ParseInputCSVWithDelimiter reads the input CSV file for discovery mode using the given delimiter
+func ParseInputCSVWithDelimiter(filePath string, delimiter rune) ([]InputRecord, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open input CSV: %w", err)
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.TrimLeadingSpace = true
+	reader := newReader(file, delimiter)
 
 	// Read header
 	header, err := reader.Read()
@@ -62,6 +82,15 @@ func ParseInputCSV(filePath string) ([]InputRecord, error) {
 		return nil, fmt.Errorf("no 'url' or 'link' column found in CSV")
 	}
 
+	// Optional per-URL link depth
+	depthIdx := -1
+	for i, col := range header {
+		if strings.EqualFold(strings.TrimSpace(col), "depth") {
+			depthIdx = i
+			break
+		}
+	}
+
 	// Read records
 	var records []InputRecord
 	for {
@@ -78,9 +107,18 @@ func ParseInputCSV(filePath string) ([]InputRecord, error) {
 		}
 
 		url := strings.TrimSpace(row[urlIdx])
-		if url != "" {
-			records = append(records, InputRecord{URL: url})
+		if url == "" {
+			continue
+		}
+		record := InputRecord{URL: url}
+		if value := getString(row, depthIdx); value != "" {
+			depth, err := strconv.Atoi(value)
+			if err != nil || depth < 0 {
+				return nil, fmt.Errorf("invalid depth %q for %s: must be a non-negative integer", value, url)
+			}
+			record.Depth = &depth
 		}
+		records = append(records, record)
 	}
 
 	return records, nil
@@ -88,14 +126,24 @@ func ParseInputCSV(filePath string) ([]InputRecord, error) {
 
 // ParseConversionCSV reads the enhanced CSV file for conversion mode
 func ParseConversionCSV(filePath string) ([]ConversionRecord, error) {
+	return ParseConversionCSVWithDelimiter(filePath, DefaultDelimiter)
+}
+
+// ParseConversionCSVWithDelimiter reads the enhanced CSV file for conversion mode using the given delimiter
+func ParseConversionCSVWithDelimiter(filePath string, delimiter rune) ([]ConversionRecord, error) {
+	return ParseConversionCSVWithDepth(filePath, delimiter, DefaultFragmentDepth)
+}
+
+// ParseConversionCSVWithDepth reads the enhanced CSV file for conversion mode, with fragments
+// from the frag1 to frag<maxDepth> columns. A value in a deeper fragment column is an error.
+func ParseConversionCSVWithDepth(filePath string, delimiter rune, maxDepth int) ([]ConversionRecord, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open conversion CSV: %w", err)
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.TrimLeadingSpace = true
+	reader := newReader(file, delimiter)
 
 	// Read header
 	header, err := reader.Read()
@@ -117,6 +165,29 @@ func ParseConversionCSV(filePath string) ([]ConversionRecord, error) {
 		}
 	}
 
+	// Optional columns; a missing column reads as empty
+	tagsIdx := -1
+	if idx, exists := colMap["tags"]; exists {
+		tagsIdx = idx
+	}
+	mimeOverrideIdx := -1
+	if idx, exists := colMap["mime-override"]; exists {
+		mimeOverrideIdx = idx
+	}
+
+	// Fragment columns by depth (1-based); missing columns in between read as empty
+	fragIdx := make(map[int]int)
+	depth := 0
+	for col, idx := range colMap {
+		match := fragColumnPattern.FindStringSubmatch(col)
+		if match == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(match[1])
+		fragIdx[n] = idx
+		depth = max(depth, n)
+	}
+
 	// Read records
 	var records []ConversionRecord
 	for {
@@ -129,16 +200,22 @@ func ParseConversionCSV(filePath string) ([]ConversionRecord, error) {
 		}
 
 		record := ConversionRecord{
-			Link:  getString(row, colMap["link"]),
-			Title: getString(row, colMap["title"]),
-			Tags:  getString(row, colMap["tags"]),
-			Frag1: getString(row, colMap["frag1"]),
-			Frag2: getString(row, colMap["frag2"]),
-			Frag3: getString(row, colMap["frag3"]),
-			Frag4: getString(row, colMap["frag4"]),
-			Frag5: getString(row, colMap["frag5"]),
+			Link:         getString(row, colMap["link"]),
+			Title:        getString(row, colMap["title"]),
+			Tags:         getString(row, tagsIdx),
+			MIMEOverride: getString(row, mimeOverrideIdx),
 		}
 
+		fragments := make([]string, depth)
+		for n, idx := range fragIdx {
+			fragments[n-1] = getString(row, idx)
+		}
+		fragments = trimFragments(fragments)
+		if len(fragments) > maxDepth {
+			return nil, fmt.Errorf("record %q has fragment frag%d, deeper than the maximum fragment depth %d", record.Title, len(fragments), maxDepth)
+		}
+		record.Fragments = fragments
+
 		if record.Link != "" && record.Title != "" {
 			records = append(records, record)
 		}
@@ -147,6 +224,88 @@ func ParseConversionCSV(filePath string) ([]ConversionRecord, error) {
 	return records, nil
 }
 
+// ParseDiscoveryCSV reads a discovery output CSV (link, title, status, modified_time columns)
+func ParseDiscoveryCSV(filePath string) ([]DiscoveryRecord, error) {
+	return ParseDiscoveryCSVWithDelimiter(filePath, DefaultDelimiter)
+}
+
+// ParseDiscoveryCSVWithDelimiter reads a discovery output CSV using the given delimiter
+func ParseDiscoveryCSVWithDelimiter(filePath string, delimiter rune) ([]DiscoveryRecord, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open discovery CSV: %w", err)
+	}
+	defer file.Close()
+
+	reader := newReader(file, delimiter)
+
+	// Read header
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	// Find column indices
+	colMap := make(map[string]int)
+	for i, col := range header {
+		colMap[strings.ToLower(col)] = i
+	}
+
+	// Validate required columns
+	requiredCols := []string{"link", "title"}
+	for _, col := range requiredCols {
+		if _, exists := colMap[col]; !exists {
+			return nil, fmt.Errorf("required column '%s' not found in CSV", col)
+		}
+	}
+
+	// Optional columns; older CSVs may lack them
+	optionalIdx := func(col string) int {
+		if idx, exists := colMap[col]; exists {
+			return idx
+		}
+		return -1
+	}
+	statusIdx := optionalIdx("status")
+	modifiedIdx := optionalIdx("modified_time")
+	breadcrumbIdx := optionalIdx("breadcrumb")
+	fileTypeIdx := optionalIdx("file_type")
+
+	// Read records
+	var records []DiscoveryRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV row: %w", err)
+		}
+
+		record := DiscoveryRecord{
+			Link:         getString(row, colMap["link"]),
+			Title:        getString(row, colMap["title"]),
+			Status:       getString(row, statusIdx),
+			ModifiedTime: getString(row, modifiedIdx),
+			FileType:     getString(row, fileTypeIdx),
+		}
+		if breadcrumb := getString(row, breadcrumbIdx); breadcrumb != "" {
+			record.Breadcrumb = strings.Split(breadcrumb, BreadcrumbSeparator)
+		}
+
+		// The writer leaves the status empty for available files
+		if record.Status == "" {
+			record.Status = "available"
+		}
+
+		if record.Link != "" {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
 // getString safely gets a string from a row at the given index
 func getString(row []string, idx int) string {
 	if idx >= 0 && idx < len(row) {
@@ -157,20 +316,74 @@ func getString(row []string, idx int) string {
 
 // GetFragments returns the fragments as a slice
 func (r *ConversionRecord) GetFragments() []string {
-	return []string{r.Frag1, r.Frag2, r.Frag3, r.Frag4, r.Frag5}
+	return r.Fragments
+}
+
+// trimFragments removes trailing empty fragments, returning nil when none are left
+func trimFragments(fragments []string) []string {
+	for len(fragments) > 0 && fragments[len(fragments)-1] == "" {
+		fragments = fragments[:len(fragments)-1]
+	}
+	if len(fragments) == 0 {
+		return nil
+	}
+	return fragments
+}
+
+// TagOptions configures how GetTagsListWithOptions splits and normalizes tags
+type TagOptions struct {
+	Separator    string // Tag separator (default: auto-detected, see DetectTagSeparator)
+	PreserveCase bool   // Keep each tag's case instead of lowercasing it
 }
 
-// GetTagsList returns tags as a slice
+// GetTagsList returns tags as a slice, auto-detecting the separator
 func (r *ConversionRecord) GetTagsList() []string {
+	return r.GetTagsListWithOptions(TagOptions{})
+}
+
+// GetTagsListWithSeparator returns tags as a slice split on sep, auto-detecting the
+// separator when sep is empty
+func (r *ConversionRecord) GetTagsListWithSeparator(sep string) []string {
+	return r.GetTagsListWithOptions(TagOptions{Separator: sep})
+}
+
+// GetTagsListWithOptions returns tags as a slice. When opts.Separator is empty the separator
+// is auto-detected: semicolon if present, otherwise comma. Tags are trimmed and lowercased,
+// empty tags are dropped and duplicates are removed keeping the first occurrence. With
+// opts.PreserveCase the tags keep their case and duplicates are still compared ignoring case.
+func (r *ConversionRecord) GetTagsListWithOptions(opts TagOptions) []string {
 	if r.Tags == "" {
 		return nil
 	}
+	sep := opts.Separator
+	if sep == "" {
+		sep = DetectTagSeparator(r.Tags)
+	}
+
 	var tags []string
-	for _, tag := range strings.Split(r.Tags, ";") {
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(r.Tags, sep) {
 		tag = strings.TrimSpace(tag)
-		if tag != "" {
-			tags = append(tags, tag)
+		if tag == "" {
+			continue
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !opts.PreserveCase {
+			tag = key
 		}
+		tags = append(tags, tag)
 	}
 	return tags
 }
+
+// DetectTagSeparator returns ";" if the tags string contains a semicolon, otherwise ","
+func DetectTagSeparator(tags string) string {
+	if strings.Contains(tags, ";") {
+		return ";"
+	}
+	return ","
+}
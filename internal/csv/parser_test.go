@@ -1,8 +1,12 @@
 package csv
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -97,6 +101,88 @@ Item 2,Value 2`,
 	}
 }
 
+func TestParseInputCSVDepth(t *testing.T) {
+	depth := func(n int) *int { return &n }
+
+	tests := []struct {
+		name       string
+		csvContent string
+		want       []InputRecord
+		wantErr    string
+	}{
+		{
+			name:       "no depth column",
+			csvContent: "url\nhttps://drive.google.com/drive/folders/f1\n",
+			want:       []InputRecord{{URL: "https://drive.google.com/drive/folders/f1"}},
+		},
+		{
+			name: "depth column",
+			csvContent: `url,Depth
+https://drive.google.com/drive/folders/f1,1
+https://docs.google.com/document/d/d1/edit,0
+https://docs.google.com/document/d/d2/edit,
+https://docs.google.com/document/d/d3/edit, 8 `,
+			want: []InputRecord{
+				{URL: "https://drive.google.com/drive/folders/f1", Depth: depth(1)},
+				{URL: "https://docs.google.com/document/d/d1/edit", Depth: depth(0)},
+				{URL: "https://docs.google.com/document/d/d2/edit"},
+				{URL: "https://docs.google.com/document/d/d3/edit", Depth: depth(8)},
+			},
+		},
+		{
+			name:       "short row",
+			csvContent: "depth,url\n2,https://drive.google.com/drive/folders/f1\n",
+			want:       []InputRecord{{URL: "https://drive.google.com/drive/folders/f1", Depth: depth(2)}},
+		},
+		{
+			name:       "not a number",
+			csvContent: "url,depth\nhttps://drive.google.com/drive/folders/f1,deep\n",
+			wantErr:    `invalid depth "deep"`,
+		},
+		{
+			name:       "negative",
+			csvContent: "url,depth\nhttps://drive.google.com/drive/folders/f1,-1\n",
+			wantErr:    `invalid depth "-1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csvPath := filepath.Join(t.TempDir(), "input.csv")
+			if err := os.WriteFile(csvPath, []byte(tt.csvContent), 0644); err != nil {
+				t.Fatalf("Failed to create test CSV: %v", err)
+			}
+
+			records, err := ParseInputCSV(csvPath)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseInputCSV() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseInputCSV() error = %v", err)
+			}
+			if !reflect.DeepEqual(records, tt.want) {
+				t.Errorf("ParseInputCSV() = %s, want %s", formatInputRecords(records), formatInputRecords(tt.want))
+			}
+		})
+	}
+}
+
+// formatInputRecords formats records with their depths rather than the depth pointers
+func formatInputRecords(records []InputRecord) string {
+	var parts []string
+	for _, record := range records {
+		depth := "global"
+		if record.Depth != nil {
+			depth = strconv.Itoa(*record.Depth)
+		}
+		parts = append(parts, record.URL+"@"+depth)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
 func TestParseConversionCSV(t *testing.T) {
 	// Create temp directory
 	tempDir := t.TempDir()
@@ -175,21 +261,196 @@ https://drive.google.com/file/d/1AbCdEf/view,tag1,guides`,
 
 func TestConversionRecordGetFragments(t *testing.T) {
 	record := ConversionRecord{
-		Frag1: "guides",
-		Frag2: "tutorials",
-		Frag3: "",
-		Frag4: "",
-		Frag5: "",
+		Fragments: []string{"guides", "tutorials"},
 	}
 
 	fragments := record.GetFragments()
 
-	if len(fragments) != 5 {
-		t.Errorf("GetFragments() returned %d fragments, want 5", len(fragments))
+	if !reflect.DeepEqual(fragments, []string{"guides", "tutorials"}) {
+		t.Errorf("GetFragments() = %q, want [guides tutorials]", fragments)
 	}
+}
 
-	if fragments[0] != "guides" || fragments[1] != "tutorials" {
-		t.Errorf("GetFragments() = %v, want [guides tutorials   ]", fragments)
+func TestParseConversionCSVWithDepth(t *testing.T) {
+	tests := []struct {
+		name        string
+		csvContent  string
+		maxDepth    int
+		expected    []ConversionRecord
+		expectError bool
+	}{
+		{
+			name: "trailing empty fragments are dropped",
+			csvContent: `link,title,tags,frag1,frag2,frag3,frag4,frag5
+https://docs.google.com/document/d/A/edit,Doc A,,guides,,setup,,
+https://docs.google.com/document/d/B/edit,Doc B,,,,,,`,
+			maxDepth: DefaultFragmentDepth,
+			expected: []ConversionRecord{
+				{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"guides", "", "setup"}},
+				{Link: "https://docs.google.com/document/d/B/edit", Title: "Doc B"},
+			},
+		},
+		{
+			name: "deep fragments",
+			csvContent: `link,title,frag1,frag2,frag3,frag4,frag5,frag6,frag7
+https://docs.google.com/document/d/A/edit,Doc A,Engineering,Backend,Services,Auth,Concepts,Tokens,Refresh`,
+			maxDepth: 7,
+			expected: []ConversionRecord{
+				{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"Engineering", "Backend", "Services", "Auth", "Concepts", "Tokens", "Refresh"}},
+			},
+		},
+		{
+			name: "eight fragment columns with mixed-case headers",
+			csvContent: `Link,Title,Frag1,FRAG2,frag3,frag4,frag5,frag6,Frag7,frag8
+https://docs.google.com/document/d/A/edit,Doc A,a,b,c,d,e,f,g,h
+https://docs.google.com/document/d/B/edit,Doc B,a,b,c,,,,,`,
+			maxDepth: 8,
+			expected: []ConversionRecord{
+				{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"a", "b", "c", "d", "e", "f", "g", "h"}},
+				{Link: "https://docs.google.com/document/d/B/edit", Title: "Doc B", Fragments: []string{"a", "b", "c"}},
+			},
+		},
+		{
+			name: "ten fragment columns at the maximum depth",
+			csvContent: `link,title,frag1,frag2,frag3,frag4,frag5,frag6,frag7,frag8,frag9,frag10
+https://docs.google.com/document/d/A/edit,Doc A,1,2,3,4,5,6,7,8,9,10`,
+			maxDepth: MaxFragmentDepth,
+			expected: []ConversionRecord{
+				{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}},
+			},
+		},
+		{
+			name: "ten fragment columns beyond a depth of eight",
+			csvContent: `link,title,frag1,frag2,frag3,frag4,frag5,frag6,frag7,frag8,frag9,frag10
+https://docs.google.com/document/d/A/edit,Doc A,1,2,3,4,5,6,7,8,9,10`,
+			maxDepth:    8,
+			expectError: true,
+		},
+		{
+			name: "missing fragment columns read as empty",
+			csvContent: `link,title,frag3,frag1
+https://docs.google.com/document/d/A/edit,Doc A,setup,guides`,
+			maxDepth: DefaultFragmentDepth,
+			expected: []ConversionRecord{
+				{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"guides", "", "setup"}},
+			},
+		},
+		{
+			name: "empty columns beyond the depth are allowed",
+			csvContent: `link,title,frag1,frag2,frag3,frag4,frag5,frag6
+https://docs.google.com/document/d/A/edit,Doc A,guides,,,,,`,
+			maxDepth: DefaultFragmentDepth,
+			expected: []ConversionRecord{
+				{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"guides"}},
+			},
+		},
+		{
+			name: "fragment beyond the depth",
+			csvContent: `link,title,frag1,frag2,frag3,frag4,frag5,frag6
+https://docs.google.com/document/d/A/edit,Doc A,a,b,c,d,e,f`,
+			maxDepth:    DefaultFragmentDepth,
+			expectError: true,
+		},
+		{
+			name: "mime-override column",
+			csvContent: `link,title,frag1,MIME-Override
+https://drive.google.com/file/d/A/view,Doc A,guides, application/vnd.google-apps.document
+https://drive.google.com/file/d/B/view,Doc B,guides,`,
+			maxDepth: DefaultFragmentDepth,
+			expected: []ConversionRecord{
+				{Link: "https://drive.google.com/file/d/A/view", Title: "Doc A", Fragments: []string{"guides"}, MIMEOverride: "application/vnd.google-apps.document"},
+				{Link: "https://drive.google.com/file/d/B/view", Title: "Doc B", Fragments: []string{"guides"}},
+			},
+		},
+		{
+			name: "missing tags column",
+			csvContent: `link,title,frag1
+https://docs.google.com/document/d/A/edit,Doc A,guides`,
+			maxDepth: DefaultFragmentDepth,
+			expected: []ConversionRecord{
+				{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"guides"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csvPath := filepath.Join(t.TempDir(), "conversion.csv")
+			if err := os.WriteFile(csvPath, []byte(tt.csvContent), 0644); err != nil {
+				t.Fatalf("Failed to create test CSV: %v", err)
+			}
+
+			records, err := ParseConversionCSVWithDepth(csvPath, DefaultDelimiter, tt.maxDepth)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(records, tt.expected) {
+				t.Errorf("records = %+v, want %+v", records, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteConversionCSVFragmentColumns(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "records.csv")
+	records := []ConversionRecord{
+		{Link: "https://docs.google.com/document/d/A/edit", Title: "Doc A", Fragments: []string{"a", "b", "c", "d", "e", "f", "g"}},
+		{Link: "https://docs.google.com/document/d/B/edit", Title: "Doc B"},
+	}
+	if err := WriteConversionCSV(csvPath, records); err != nil {
+		t.Fatalf("WriteConversionCSV() error = %v", err)
+	}
+
+	data, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := "link,title,tags,frag1,frag2,frag3,frag4,frag5,frag6,frag7\n" +
+		"https://docs.google.com/document/d/A/edit,Doc A,,a,b,c,d,e,f,g\n" +
+		"https://docs.google.com/document/d/B/edit,Doc B,,,,,,,,\n"
+	if string(data) != want {
+		t.Errorf("CSV = %q, want %q", data, want)
+	}
+
+	parsed, err := ParseConversionCSVWithDepth(csvPath, DefaultDelimiter, 7)
+	if err != nil {
+		t.Fatalf("ParseConversionCSVWithDepth() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("parsed = %+v, want %+v", parsed, records)
+	}
+}
+
+func TestWriteConversionCSVMIMEOverride(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "records.csv")
+	records := []ConversionRecord{
+		{Link: "https://drive.google.com/file/d/A/view", Title: "Doc A", MIMEOverride: "application/pdf"},
+		{Link: "https://drive.google.com/file/d/B/view", Title: "Doc B"},
+	}
+	if err := WriteConversionCSV(csvPath, records); err != nil {
+		t.Fatalf("WriteConversionCSV() error = %v", err)
+	}
+
+	data, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if header, _, _ := strings.Cut(string(data), "\n"); header != "link,title,tags,frag1,frag2,frag3,frag4,frag5,mime-override" {
+		t.Errorf("header = %q, want a mime-override column", header)
+	}
+
+	parsed, err := ParseConversionCSV(csvPath)
+	if err != nil {
+		t.Fatalf("ParseConversionCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("parsed = %+v, want %+v", parsed, records)
 	}
 }
 
@@ -220,9 +481,39 @@ func TestConversionRecordGetTagsList(t *testing.T) {
 			expected: nil,
 		},
 		{
-			name:     "comma in tags (not a separator)",
+			name:     "comma separator when no semicolon present",
 			tags:     "tutorial, advanced",
-			expected: []string{"tutorial, advanced"}, // Comma is part of the tag, not a separator
+			expected: []string{"tutorial", "advanced"},
+		},
+		{
+			name:     "mixed separators prefer semicolon",
+			tags:     "tutorial;beginner, guide",
+			expected: []string{"tutorial", "beginner, guide"},
+		},
+		{
+			name:     "duplicate tags case-insensitive",
+			tags:     "Tutorial;tutorial; TUTORIAL ;guide",
+			expected: []string{"tutorial", "guide"},
+		},
+		{
+			name:     "mixed case lowercased",
+			tags:     "Kubernetes, DevOps, api",
+			expected: []string{"kubernetes", "devops", "api"},
+		},
+		{
+			name:     "duplicates keep first occurrence order",
+			tags:     "guide, Tutorial, api, tutorial, GUIDE",
+			expected: []string{"guide", "tutorial", "api"},
+		},
+		{
+			name:     "duplicate tags with comma separator",
+			tags:     "api, guide, API, guide",
+			expected: []string{"api", "guide"},
+		},
+		{
+			name:     "empty tags dropped",
+			tags:     "tutorial;;  ;guide;",
+			expected: []string{"tutorial", "guide"},
 		},
 		{
 			name:     "mixed whitespace",
@@ -249,3 +540,267 @@ func TestConversionRecordGetTagsList(t *testing.T) {
 		})
 	}
 }
+
+func TestConversionRecordGetTagsListPreserveCase(t *testing.T) {
+	record := ConversionRecord{Tags: " Tutorial ;tutorial;DevOps; TUTORIAL;devops;API"}
+
+	got := record.GetTagsListWithOptions(TagOptions{PreserveCase: true})
+	want := []string{"Tutorial", "DevOps", "API"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTagsListWithOptions(PreserveCase) = %v, want %v", got, want)
+	}
+
+	got = record.GetTagsListWithOptions(TagOptions{})
+	want = []string{"tutorial", "devops", "api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTagsListWithOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestConversionRecordGetTagsListWithSeparator(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      string
+		separator string
+		expected  []string
+	}{
+		{
+			name:      "forced comma with semicolon present",
+			tags:      "a;b, c",
+			separator: ",",
+			expected:  []string{"a;b", "c"},
+		},
+		{
+			name:      "forced semicolon without semicolon present",
+			tags:      "tutorial, advanced",
+			separator: ";",
+			expected:  []string{"tutorial, advanced"},
+		},
+		{
+			name:      "forced pipe separator",
+			tags:      "one | two | One",
+			separator: "|",
+			expected:  []string{"one", "two"},
+		},
+		{
+			name:      "empty separator auto-detects",
+			tags:      "x, y",
+			separator: "",
+			expected:  []string{"x", "y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := ConversionRecord{Tags: tt.tags}
+			result := record.GetTagsListWithSeparator(tt.separator)
+
+			if len(result) != len(tt.expected) {
+				t.Errorf("GetTagsListWithSeparator() = %v, want %v", result, tt.expected)
+				return
+			}
+
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("GetTagsListWithSeparator()[%d] = %q, want %q", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseDiscoveryCSV(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		csvContent  string
+		expected    []DiscoveryRecord
+		expectError bool
+	}{
+		{
+			name: "statuses with empty meaning available",
+			csvContent: `link,title,status
+https://docs.google.com/document/d/FILE_ID_1/edit,Doc 1,
+https://docs.google.com/document/d/FILE_ID_2/edit,FILE_ID_2,deleted
+https://docs.google.com/document/d/FILE_ID_3/edit,FILE_ID_3,permission_denied`,
+			expected: []DiscoveryRecord{
+				{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc 1", Status: "available"},
+				{Link: "https://docs.google.com/document/d/FILE_ID_2/edit", Title: "FILE_ID_2", Status: "deleted"},
+				{Link: "https://docs.google.com/document/d/FILE_ID_3/edit", Title: "FILE_ID_3", Status: "permission_denied"},
+			},
+		},
+		{
+			name: "with modified_time column",
+			csvContent: `link,title,status,modified_time
+https://docs.google.com/document/d/FILE_ID_1/edit,Doc 1,,2024-01-15T10:30:00.000Z
+https://docs.google.com/document/d/FILE_ID_2/edit,FILE_ID_2,deleted,`,
+			expected: []DiscoveryRecord{
+				{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc 1", Status: "available", ModifiedTime: "2024-01-15T10:30:00.000Z"},
+				{Link: "https://docs.google.com/document/d/FILE_ID_2/edit", Title: "FILE_ID_2", Status: "deleted"},
+			},
+		},
+		{
+			name: "with breadcrumb column",
+			csvContent: `link,title,status,modified_time,breadcrumb
+https://docs.google.com/document/d/FILE_ID_1/edit,Doc 1,,,Engineering|Kubernetes|Run Books
+https://docs.google.com/document/d/FILE_ID_2/edit,Doc 2,,,`,
+			expected: []DiscoveryRecord{
+				{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc 1", Status: "available", Breadcrumb: []string{"Engineering", "Kubernetes", "Run Books"}},
+				{Link: "https://docs.google.com/document/d/FILE_ID_2/edit", Title: "Doc 2", Status: "available"},
+			},
+		},
+		{
+			name: "with file_type column",
+			csvContent: `link,title,status,modified_time,breadcrumb,file_type
+https://docs.google.com/document/d/FILE_ID_1/edit,Doc 1,,,,google-doc
+https://drive.google.com/file/d/FILE_ID_2/view,Photo,,,,image`,
+			expected: []DiscoveryRecord{
+				{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc 1", Status: "available", FileType: "google-doc"},
+				{Link: "https://drive.google.com/file/d/FILE_ID_2/view", Title: "Photo", Status: "available", FileType: "image"},
+			},
+		},
+		{
+			name: "missing status column",
+			csvContent: `link,title
+https://docs.google.com/document/d/FILE_ID_1/edit,Doc 1`,
+			expected: []DiscoveryRecord{
+				{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc 1", Status: "available"},
+			},
+		},
+		{
+			name: "missing required column",
+			csvContent: `link,status
+https://docs.google.com/document/d/FILE_ID_1/edit,deleted`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csvPath := filepath.Join(tempDir, "discovery.csv")
+			if err := os.WriteFile(csvPath, []byte(tt.csvContent), 0644); err != nil {
+				t.Fatalf("Failed to create test CSV: %v", err)
+			}
+
+			records, err := ParseDiscoveryCSV(csvPath)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(records) != len(tt.expected) {
+				t.Fatalf("Got %d records, want %d", len(records), len(tt.expected))
+			}
+
+			for i := range records {
+				if !reflect.DeepEqual(records[i], tt.expected[i]) {
+					t.Errorf("Record %d = %+v, want %+v", i, records[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoveryCSVRoundTrip(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "discovery.csv")
+	records := []DiscoveryRecord{
+		{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc, with comma", Status: "available", ModifiedTime: "2024-01-15T10:30:00.000Z", Breadcrumb: []string{"Engineering", "Kubernetes"}, FileType: "google-doc"},
+		{Link: "https://docs.google.com/document/d/FILE_ID_2/edit", Title: "FILE_ID_2", Status: "deleted"},
+	}
+
+	if err := WriteDiscoveryCSV(csvPath, records); err != nil {
+		t.Fatalf("WriteDiscoveryCSV() error = %v", err)
+	}
+
+	parsed, err := ParseDiscoveryCSV(csvPath)
+	if err != nil {
+		t.Fatalf("ParseDiscoveryCSV() error = %v", err)
+	}
+
+	if len(parsed) != len(records) {
+		t.Fatalf("Got %d records, want %d", len(parsed), len(records))
+	}
+	for i := range records {
+		if !reflect.DeepEqual(parsed[i], records[i]) {
+			t.Errorf("Record %d = %+v, want %+v", i, parsed[i], records[i])
+		}
+	}
+}
+
+func TestDiscoveryJSONRoundTrip(t *testing.T) {
+	jsonPath := filepath.Join(t.TempDir(), "discovery.json")
+	records := []DiscoveryRecord{
+		{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc \"quoted\", with comma", Status: "available", ModifiedTime: "2024-01-15T10:30:00.000Z", Breadcrumb: []string{"Engineering", "Kubernetes"}, FileType: "google-doc"},
+		{Link: "https://docs.google.com/document/d/FILE_ID_2/edit", Title: "FILE_ID_2", Status: "deleted"},
+	}
+
+	if err := WriteDiscoveryJSON(jsonPath, records); err != nil {
+		t.Fatalf("WriteDiscoveryJSON() error = %v", err)
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON: %v", err)
+	}
+	var objects []map[string]any
+	if err := json.Unmarshal(data, &objects); err != nil {
+		t.Fatalf("Output is not a JSON array of objects: %v", err)
+	}
+	for i, object := range objects {
+		for _, key := range []string{"link", "title", "status"} {
+			if _, ok := object[key]; !ok {
+				t.Errorf("Object %d is missing key %q: %v", i, key, object)
+			}
+		}
+	}
+
+	var parsed []DiscoveryRecord
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Failed to decode JSON: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("Round trip = %+v, want %+v", parsed, records)
+	}
+}
+
+func TestWriteDiscoveryJSONEmpty(t *testing.T) {
+	jsonPath := filepath.Join(t.TempDir(), "discovery.json")
+	if err := WriteDiscoveryJSON(jsonPath, nil); err != nil {
+		t.Fatalf("WriteDiscoveryJSON() error = %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to read JSON: %v", err)
+	}
+	if got := string(data); got != "[]\n" {
+		t.Errorf("Empty output = %q, want %q", got, "[]\n")
+	}
+}
+
+func TestConversionCSVRoundTrip(t *testing.T) {
+	csvPath := filepath.Join(t.TempDir(), "failed.csv")
+	records := []ConversionRecord{
+		{Link: "https://docs.google.com/document/d/FILE_ID_1/edit", Title: "Doc, with comma", Tags: "a;b", Fragments: []string{"guides", "setup"}},
+		{Link: "https://docs.google.com/document/d/FILE_ID_2/edit", Title: "Second", Fragments: []string{"", "", "", "", "deep"}},
+	}
+
+	if err := WriteConversionCSV(csvPath, records); err != nil {
+		t.Fatalf("WriteConversionCSV() error = %v", err)
+	}
+
+	parsed, err := ParseConversionCSV(csvPath)
+	if err != nil {
+		t.Fatalf("ParseConversionCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, records) {
+		t.Errorf("parsed = %+v, want %+v", parsed, records)
+	}
+}
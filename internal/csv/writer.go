@@ -2,12 +2,19 @@ package csv
 
 import (
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // WriteDiscoveryCSV writes discovery results to a CSV file
 func WriteDiscoveryCSV(filePath string, records []DiscoveryRecord) error {
+	return WriteDiscoveryCSVWithDelimiter(filePath, records, DefaultDelimiter)
+}
+
+// WriteDiscoveryCSVWithDelimiter writes discovery results to a CSV file using the given delimiter
+func WriteDiscoveryCSVWithDelimiter(filePath string, records []DiscoveryRecord, delimiter rune) error {
 	file, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to create output CSV: %w", err)
@@ -15,10 +22,11 @@ func WriteDiscoveryCSV(filePath string, records []DiscoveryRecord) error {
 	defer file.Close()
 
 	writer := csv.NewWriter(file)
+	writer.Comma = delimiter
 	defer writer.Flush()
 
 	// Write header
-	if err := writer.Write([]string{"link", "title", "status"}); err != nil {
+	if err := writer.Write([]string{"link", "title", "status", "modified_time", "breadcrumb", "file_type"}); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
@@ -29,10 +37,115 @@ func WriteDiscoveryCSV(filePath string, records []DiscoveryRecord) error {
 		if status == "available" {
 			status = ""
 		}
-		if err := writer.Write([]string{record.Link, record.Title, status}); err != nil {
+		breadcrumb := strings.Join(record.Breadcrumb, BreadcrumbSeparator)
+		if err := writer.Write([]string{record.Link, record.Title, status, record.ModifiedTime, breadcrumb, record.FileType}); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	return writer.Error()
+}
+
+// WriteDiscoveryJSON writes discovery results to a file as an indented JSON array. Unlike the
+// CSV, available files keep their "available" status.
+func WriteDiscoveryJSON(filePath string, records []DiscoveryRecord) error {
+	if records == nil {
+		records = []DiscoveryRecord{}
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode discovery JSON: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write output JSON: %w", err)
+	}
+	return nil
+}
+
+// FailedRecord is a conversion record that could not be converted
+type FailedRecord struct {
+	ConversionRecord
+	ErrorType string // Kind of failure, such as "export_failed" (empty for records not processed)
+}
+
+// WriteConversionCSV writes conversion records to a CSV file in the conversion input format
+func WriteConversionCSV(filePath string, records []ConversionRecord) error {
+	return writeConversionCSV(filePath, records, nil)
+}
+
+// WriteFailedCSV writes failed records in the conversion input format with an added error_type
+// column, so the file can be converted again as is
+func WriteFailedCSV(filePath string, records []FailedRecord) error {
+	conversionRecords := make([]ConversionRecord, len(records))
+	errorTypes := make([]string, len(records))
+	for i, record := range records {
+		conversionRecords[i] = record.ConversionRecord
+		errorTypes[i] = record.ErrorType
+	}
+	return writeConversionCSV(filePath, conversionRecords, errorTypes)
+}
+
+// writeConversionCSV writes conversion records, adding an error_type column when errorTypes is
+// not nil
+func writeConversionCSV(filePath string, records []ConversionRecord, errorTypes []string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output CSV: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Write fragment columns up to the deepest record, and always frag1 to frag5. The
+	// mime-override column is only written when a record has one.
+	depth := DefaultFragmentDepth
+	hasMIMEOverride := false
+	for _, record := range records {
+		depth = max(depth, len(record.Fragments))
+		hasMIMEOverride = hasMIMEOverride || record.MIMEOverride != ""
+	}
+
+	// Write header
+	header := []string{"link", "title", "tags"}
+	for n := 1; n <= depth; n++ {
+		header = append(header, fmt.Sprintf("frag%d", n))
+	}
+	if hasMIMEOverride {
+		header = append(header, "mime-override")
+	}
+	if errorTypes != nil {
+		header = append(header, "error_type")
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	// Write records
+	for i, record := range records {
+		row := []string{record.Link, record.Title, record.Tags}
+		for n := 0; n < depth; n++ {
+			row = append(row, getFragment(record.Fragments, n))
+		}
+		if hasMIMEOverride {
+			row = append(row, record.MIMEOverride)
+		}
+		if errorTypes != nil {
+			row = append(row, errorTypes[i])
+		}
+		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
 
+	writer.Flush()
 	return writer.Error()
 }
+
+// getFragment returns fragments[i], or "" past the last fragment
+func getFragment(fragments []string, i int) string {
+	if i < len(fragments) {
+		return fragments[i]
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConflict is returned by Merge when both sides changed the same lines
+var ErrConflict = errors.New("changes overlap")
+
+// Merge applies the changes from base to theirs onto ours, which was also derived from base.
+// Lines ours changed are kept as long as theirs left them alone; when both changed the same
+// lines, or inserted at the same place, Merge returns an error wrapping ErrConflict.
+func Merge(base, ours, theirs []string) ([]string, error) {
+	// For each line of base, where it starts in ours and whether ours deleted it, and for
+	// each gap before a base line (or the end), whether ours inserted lines there
+	start := make([]int, len(base)+1)
+	deleted := make([]bool, len(base))
+	inserted := make([]bool, len(base)+1)
+	baseLine, ourLine := 0, 0
+	for _, edit := range Lines(base, ours) {
+		switch edit.Op {
+		case Insert:
+			inserted[baseLine] = true
+			ourLine++
+		case Delete:
+			start[baseLine] = ourLine
+			deleted[baseLine] = true
+			baseLine++
+		case Equal:
+			start[baseLine] = ourLine
+			baseLine++
+			ourLine++
+		}
+	}
+	start[len(base)] = ourLine
+
+	// Move each of their hunks onto the matching lines of ours
+	hunks := Hunks(Lines(base, theirs))
+	for i, hunk := range hunks {
+		end := hunk.OldStart + hunk.OldCount
+		if hunk.OldCount == 0 && inserted[hunk.OldStart] {
+			return nil, fmt.Errorf("insertion at line %d: %w", hunk.OldStart+1, ErrConflict)
+		}
+		for line := hunk.OldStart; line < end; line++ {
+			if deleted[line] || (line > hunk.OldStart && inserted[line]) {
+				return nil, fmt.Errorf("lines %d-%d: %w", hunk.OldStart+1, end, ErrConflict)
+			}
+		}
+		hunks[i].OldStart = start[hunk.OldStart]
+	}
+
+	return ApplyHunks(ours, hunks)
+}
@@ -0,0 +1,62 @@
+package diff
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		wantConflict       bool
+	}{
+		{name: "only theirs changed", base: "abcdef", ours: "abcdef", theirs: "aXcdef", want: "aXcdef"},
+		{name: "only ours changed", base: "abcdef", ours: "abcdeY", theirs: "abcdef", want: "abcdeY"},
+		{name: "separate changes", base: "abcdef", ours: "abcdeY", theirs: "aXcdef", want: "aXcdeY"},
+		{name: "ours inserted before their change", base: "abcdef", ours: "aZbcdef", theirs: "aXcdef", want: "aZXcdef"},
+		{name: "ours deleted elsewhere", base: "abcdef", ours: "abcf", theirs: "Xabcdef", want: "Xabcf"},
+		{name: "theirs appended", base: "abc", ours: "Zabc", theirs: "abcd", want: "Zabcd"},
+		{name: "same line changed", base: "abcdef", ours: "aYcdef", theirs: "aXcdef", wantConflict: true},
+		{name: "ours deleted their changed line", base: "abcdef", ours: "acdef", theirs: "aXcdef", wantConflict: true},
+		{name: "ours inserted inside their change", base: "abcdef", ours: "abZcdef", theirs: "aXYdef", wantConflict: true},
+		{name: "both inserted at the same place", base: "abc", ours: "abZc", theirs: "abXc", wantConflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Merge(chars(tt.base), chars(tt.ours), chars(tt.theirs))
+			if tt.wantConflict {
+				if !errors.Is(err, ErrConflict) {
+					t.Errorf("Merge() = %v, %v, want ErrConflict", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			if strings.Join(got, "") != tt.want {
+				t.Errorf("Merge() = %q, want %q", strings.Join(got, ""), tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		base := randomLines(rng)
+		changed := randomLines(rng)
+
+		// A side that left base alone takes all the changes of the other side
+		if got, err := Merge(base, base, changed); err != nil || strings.Join(got, "") != strings.Join(changed, "") {
+			t.Fatalf("Merge(%v, base, %v) = %v, %v", base, changed, got, err)
+		}
+		if got, err := Merge(base, changed, base); err != nil || strings.Join(got, "") != strings.Join(changed, "") {
+			t.Fatalf("Merge(%v, %v, base) = %v, %v", base, changed, got, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of an edit
+type Op int
+
+const (
+	Equal  Op = iota // Line is in both old and new
+	Insert           // Line is only in new
+	Delete           // Line is only in old
+)
+
+// Edit is one line of an edit script
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// Hunk replaces OldCount lines of old, starting at the 0-based line OldStart, with NewLines
+type Hunk struct {
+	OldStart int
+	OldCount int
+	NewLines []string
+}
+
+// SplitLines splits text into lines, keeping the line terminators so that joining the lines
+// gives back the original text
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns the shortest edit script turning a into b, using Myers' O(ND) algorithm
+func Lines(a, b []string) []Edit {
+	edits, _ := LinesWithLimit(a, b, len(a)+len(b))
+	return edits
+}
+
+// LinesWithLimit is like Lines but gives up once more than maxEdits inserted and deleted
+// lines are needed, returning false. This bounds the time and memory spent on documents that
+// were rewritten wholesale.
+func LinesWithLimit(a, b []string, maxEdits int) ([]Edit, bool) {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil, true
+	}
+
+	limit := n + m
+	if maxEdits < limit {
+		limit = maxEdits
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k. trace[d] keeps the part of v
+	// that round d reads, for k in [-d-1, d+1], so the path can be walked back afterwards.
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= limit; d++ {
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down: insert b[y-1]
+			} else {
+				x = v[offset+k-1] + 1 // Right: delete a[x-1]
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// backtrack walks the recorded rounds from the end of both inputs back to the start
+func backtrack(trace [][]int, a, b []string) []Edit {
+	var reversed []Edit
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Edit{Op: Equal, Line: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, Edit{Op: Insert, Line: b[y-1]})
+			} else {
+				reversed = append(reversed, Edit{Op: Delete, Line: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	edits := make([]Edit, len(reversed))
+	for i, edit := range reversed {
+		edits[len(reversed)-1-i] = edit
+	}
+	return edits
+}
+
+// Hunks groups the changes of an edit script into hunks against the old lines
+func Hunks(edits []Edit) []Hunk {
+	var hunks []Hunk
+	var current *Hunk
+	oldLine := 0
+
+	for _, edit := range edits {
+		switch edit.Op {
+		case Equal:
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+			oldLine++
+		case Delete:
+			if current == nil {
+				current = &Hunk{OldStart: oldLine}
+			}
+			current.OldCount++
+			oldLine++
+		case Insert:
+			if current == nil {
+				current = &Hunk{OldStart: oldLine}
+			}
+			current.NewLines = append(current.NewLines, edit.Line)
+		}
+	}
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	return hunks
+}
+
+// ApplyHunks applies hunks, in order, to the old lines
+func ApplyHunks(old []string, hunks []Hunk) ([]string, error) {
+	result := make([]string, 0, len(old))
+	next := 0
+
+	for _, hunk := range hunks {
+		if hunk.OldStart < next || hunk.OldStart+hunk.OldCount > len(old) {
+			return nil, fmt.Errorf("hunk at line %d does not fit %d lines", hunk.OldStart+1, len(old))
+		}
+		result = append(result, old[next:hunk.OldStart]...)
+		result = append(result, hunk.NewLines...)
+		next = hunk.OldStart + hunk.OldCount
+	}
+
+	return append(result, old[next:]...), nil
+}
+
+// ChangeRatio returns the share of lines in an edit script that were inserted or deleted
+func ChangeRatio(edits []Edit) float64 {
+	if len(edits) == 0 {
+		return 0
+	}
+
+	changed := 0
+	for _, edit := range edits {
+		if edit.Op != Equal {
+			changed++
+		}
+	}
+	return float64(changed) / float64(len(edits))
+}
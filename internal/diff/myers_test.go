@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        string
+		wantChanges int
+	}{
+		{name: "both empty", a: "", b: "", wantChanges: 0},
+		{name: "identical", a: "abc", b: "abc", wantChanges: 0},
+		{name: "insert into empty", a: "", b: "abc", wantChanges: 3},
+		{name: "delete all", a: "abc", b: "", wantChanges: 3},
+		{name: "replace middle", a: "abc", b: "axc", wantChanges: 2},
+		{name: "insert at start", a: "bc", b: "abc", wantChanges: 1},
+		{name: "classic example", a: "abcabba", b: "cbabac", wantChanges: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := chars(tt.a), chars(tt.b)
+			edits := Lines(a, b)
+
+			changes := 0
+			for _, edit := range edits {
+				if edit.Op != Equal {
+					changes++
+				}
+			}
+			if changes != tt.wantChanges {
+				t.Errorf("Lines() has %d changes, want %d: %v", changes, tt.wantChanges, edits)
+			}
+
+			got, err := ApplyHunks(a, Hunks(edits))
+			if err != nil {
+				t.Fatalf("ApplyHunks() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, b) && !(len(got) == 0 && len(b) == 0) {
+				t.Errorf("ApplyHunks() = %v, want %v", got, b)
+			}
+		})
+	}
+}
+
+func TestLinesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a := randomLines(rng)
+		b := randomLines(rng)
+
+		edits := Lines(a, b)
+		got, err := ApplyHunks(a, Hunks(edits))
+		if err != nil {
+			t.Fatalf("ApplyHunks() error = %v", err)
+		}
+		if strings.Join(got, "") != strings.Join(b, "") {
+			t.Fatalf("ApplyHunks(%v -> %v) = %v", a, b, got)
+		}
+
+		// The script must be minimal: everything outside the longest common subsequence changes
+		changes := 0
+		for _, edit := range edits {
+			if edit.Op != Equal {
+				changes++
+			}
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+			t.Fatalf("Lines(%v -> %v) has %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+func TestLinesWithLimit(t *testing.T) {
+	a, b := chars("abcdef"), chars("uvwxyz")
+
+	if _, ok := LinesWithLimit(a, b, 5); ok {
+		t.Error("LinesWithLimit() succeeded, want it to give up after 5 edits")
+	}
+	if edits, ok := LinesWithLimit(a, b, 12); !ok || len(edits) != 12 {
+		t.Errorf("LinesWithLimit() = %v, %v, want 12 edits", edits, ok)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	edits := Lines(chars("abcdef"), chars("aXcdYZf"))
+	want := []Hunk{
+		{OldStart: 1, OldCount: 1, NewLines: []string{"X"}},
+		{OldStart: 4, OldCount: 1, NewLines: []string{"Y", "Z"}},
+	}
+	if got := Hunks(edits); !reflect.DeepEqual(got, want) {
+		t.Errorf("Hunks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyHunksOutOfRange(t *testing.T) {
+	if _, err := ApplyHunks(chars("ab"), []Hunk{{OldStart: 1, OldCount: 5}}); err == nil {
+		t.Error("ApplyHunks() expected error for hunk past the end")
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "a", want: []string{"a"}},
+		{text: "a\nb\n", want: []string{"a\n", "b\n"}},
+		{text: "a\n\nb", want: []string{"a\n", "\n", "b"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitLines(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitLines(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestChangeRatio(t *testing.T) {
+	if got := ChangeRatio(Lines(chars("abcd"), chars("abcd"))); got != 0 {
+		t.Errorf("ChangeRatio(identical) = %v, want 0", got)
+	}
+	if got := ChangeRatio(Lines(chars("abcd"), chars("abXd"))); got != 0.4 {
+		t.Errorf("ChangeRatio(one replaced) = %v, want 0.4", got)
+	}
+	if got := ChangeRatio(Lines(chars("ab"), chars("xy"))); got != 1 {
+		t.Errorf("ChangeRatio(all replaced) = %v, want 1", got)
+	}
+}
+
+// chars splits s into one-character lines
+func chars(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "")
+}
+
+func randomLines(rng *rand.Rand) []string {
+	lines := make([]string, rng.Intn(12))
+	for i := range lines {
+		lines[i] = string(rune('a' + rng.Intn(4)))
+	}
+	return lines
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else {
+				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
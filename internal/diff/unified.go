@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change in a unified diff
+const DefaultContext = 3
+
+// Unified returns a unified diff of oldText and newText with the given number of context
+// lines, labelled with oldName and newName. It returns an empty string when the texts are
+// equal.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	edits := Lines(SplitLines(oldText), SplitLines(newText))
+
+	var sb strings.Builder
+	for start := 0; start < len(edits); {
+		// Find the next change
+		for start < len(edits) && edits[start].Op == Equal {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		// Extend the hunk while the gap to the next change is at most twice the context
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].Op != Equal {
+				end = i + 1
+			} else if i-end >= 2*context {
+				break
+			}
+		}
+
+		from := max(start-context, 0)
+		to := min(end+context, len(edits))
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&sb, edits, from, to)
+		start = to
+	}
+	return sb.String()
+}
+
+// writeHunk writes the edits from index from up to to as a hunk with its @@ header
+func writeHunk(sb *strings.Builder, edits []Edit, from, to int) {
+	// Line numbers of the hunk's first old and new line, 1-based
+	oldLine, newLine := 1, 1
+	for _, edit := range edits[:from] {
+		if edit.Op != Insert {
+			oldLine++
+		}
+		if edit.Op != Delete {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, edit := range edits[from:to] {
+		if edit.Op != Insert {
+			oldCount++
+		}
+		if edit.Op != Delete {
+			newCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+
+	for _, edit := range edits[from:to] {
+		prefix := " "
+		switch edit.Op {
+		case Insert:
+			prefix = "+"
+		case Delete:
+			prefix = "-"
+		}
+		sb.WriteString(prefix + edit.Line)
+		if !strings.HasSuffix(edit.Line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start and length of one side of a hunk. An empty side starts at the
+// line before it, as in diff -u.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
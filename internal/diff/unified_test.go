@@ -0,0 +1,51 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{name: "equal", old: "a\nb\n", new: "a\nb\n", want: ""},
+		{
+			name: "changed line",
+			old:  "intro\nstep one\nstep two\noutro\n",
+			new:  "intro\nstep one\nstep 2\noutro\n",
+			want: "--- old.md\n+++ new.md\n@@ -1,4 +1,4 @@\n intro\n step one\n-step two\n+step 2\n outro\n",
+		},
+		{
+			name: "added and removed lines",
+			old:  "title\nremoved\nkept\n",
+			new:  "title\nkept\nadded one\nadded two\n",
+			want: "--- old.md\n+++ new.md\n@@ -1,3 +1,4 @@\n title\n-removed\n kept\n+added one\n+added two\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: "--- old.md\n+++ new.md\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name: "into empty",
+			old:  "",
+			new:  "first\n",
+			want: "--- old.md\n+++ new.md\n@@ -0,0 +1 @@\n+first\n",
+		},
+		{
+			name: "no newline at end",
+			old:  "a\nb",
+			new:  "a\nc",
+			want: "--- old.md\n+++ new.md\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old.md", "new.md", tt.old, tt.new, DefaultContext); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package discovery
+
+import "sync"
+
+// callCache runs a Drive call once per key and keeps its result, so inputs reaching the same
+// files do not repeat the calls. It is safe for concurrent use.
+type callCache[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*cachedCall[T]
+}
+
+// cachedCall is the result of one cached call
+type cachedCall[T any] struct {
+	once  sync.Once
+	value T
+	err   error
+}
+
+// do returns the result of fn for key, calling it only the first time key is asked for.
+// Concurrent callers for the same key wait for the first call to finish.
+func (c *callCache[T]) do(key string, fn func() (T, error)) (T, error) {
+	c.mu.Lock()
+	if c.calls == nil {
+		c.calls = make(map[string]*cachedCall[T])
+	}
+	call, ok := c.calls[key]
+	if !ok {
+		call = &cachedCall[T]{}
+		c.calls[key] = call
+	}
+	c.mu.Unlock()
+
+	call.once.Do(func() {
+		call.value, call.err = fn()
+	})
+	return call.value, call.err
+}
@@ -0,0 +1,41 @@
+package discovery
+
+import (
+	"log"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// DeduplicateRecords removes records for a file that was already listed, such as a file reached
+// through both its original URL and an /open?id= link. Records are keyed on the file ID in
+// their link, or on the link itself when it has no ID. Of two records for the same file the one
+// with the longer, more specific link is kept, at the position of the first; a warning is logged
+// for each dropped record.
+func DeduplicateRecords(records []csv.DiscoveryRecord) []csv.DiscoveryRecord {
+	index := make(map[string]int, len(records))
+	deduplicated := make([]csv.DiscoveryRecord, 0, len(records))
+
+	for _, record := range records {
+		key, err := utils.ExtractFileID(record.Link)
+		if err != nil {
+			key = record.Link
+		}
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(deduplicated)
+			deduplicated = append(deduplicated, record)
+			continue
+		}
+
+		kept, dropped := deduplicated[i], record
+		if len(record.Link) > len(kept.Link) {
+			kept, dropped = record, deduplicated[i]
+			deduplicated[i] = record
+		}
+		log.Printf("Warning: Duplicate record for %s: keeping %s, dropping %s", key, kept.Link, dropped.Link)
+	}
+
+	return deduplicated
+}
@@ -0,0 +1,69 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+func TestDeduplicateRecords(t *testing.T) {
+	const docID = "1AbCdEfGhIjKlMnOpQrStUvWxYz0123456789"
+	const otherID = "1ZyXwVuTsRqPoNmLkJiHgFeDcBa9876543210"
+
+	tests := []struct {
+		name    string
+		records []csv.DiscoveryRecord
+		want    []string
+	}{
+		{
+			name: "exact duplicate links",
+			records: []csv.DiscoveryRecord{
+				{Link: "https://docs.google.com/document/d/" + docID + "/edit", Title: "Guide"},
+				{Link: "https://docs.google.com/document/d/" + docID + "/edit", Title: "Guide"},
+			},
+			want: []string{"https://docs.google.com/document/d/" + docID + "/edit"},
+		},
+		{
+			name: "same file ID in different URL formats keeps the longer link",
+			records: []csv.DiscoveryRecord{
+				{Link: "https://drive.google.com/open?id=" + docID, Title: "Guide"},
+				{Link: "https://docs.google.com/document/d/" + otherID + "/edit", Title: "Other"},
+				{Link: "https://docs.google.com/document/d/" + docID + "/edit?usp=sharing", Title: "Guide"},
+				{Link: "https://docs.google.com/document/d/" + docID, Title: "Guide"},
+			},
+			want: []string{
+				"https://docs.google.com/document/d/" + docID + "/edit?usp=sharing",
+				"https://docs.google.com/document/d/" + otherID + "/edit",
+			},
+		},
+		{
+			name: "different files are kept",
+			records: []csv.DiscoveryRecord{
+				{Link: "https://docs.google.com/document/d/" + docID + "/edit", Title: "Guide"},
+				{Link: "https://docs.google.com/document/d/" + otherID + "/edit", Title: "Other"},
+				{Link: "https://example.com/page", Status: "invalid"},
+				{Link: "https://example.com/other", Status: "invalid"},
+			},
+			want: []string{
+				"https://docs.google.com/document/d/" + docID + "/edit",
+				"https://docs.google.com/document/d/" + otherID + "/edit",
+				"https://example.com/page",
+				"https://example.com/other",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DeduplicateRecords(tt.records)
+			links := make([]string, len(got))
+			for i, record := range got {
+				links[i] = record.Link
+			}
+			if !reflect.DeepEqual(links, tt.want) {
+				t.Errorf("DeduplicateRecords() links = %v, want %v", links, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,15 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
-	"time"
 
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/googleapi"
@@ -16,58 +18,298 @@ import (
 	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
 )
 
+// folderMimeType is the MIME type of Google Drive folders
+const folderMimeType = "application/vnd.google-apps.folder"
+
+// shortcutMimeType is the MIME type of Google Drive shortcuts
+const shortcutMimeType = "application/vnd.google-apps.shortcut"
+
+// DefaultSharedWithMeLimit is the default number of "Shared with me" files listed by
+// Options.IncludeSharedWithMe
+const DefaultSharedWithMeLimit = 100
+
+// DefaultWorkers is the default number of input URLs discovered in parallel
+const DefaultWorkers = 3
+
+const (
+	// DefaultPageSize is the default number of files requested per Files.List page
+	DefaultPageSize = 100
+
+	// MaxPageSize is the largest page size the Drive API accepts
+	MaxPageSize = 1000
+)
+
 // Discoverer handles discovery of files in Google Drive
 type Discoverer struct {
 	service  *drive.Service
 	verbose  bool
 	maxDepth int
+	opts     Options
 	mu       sync.Mutex
-	seen     map[string]bool // Track seen file IDs to avoid duplicates
-	depth    map[string]int  // Track depth level for each file
+	seen     map[int]map[string]bool  // File IDs found by each input, by input index, to avoid duplicates
+	depth    map[string]int           // Track depth level for each file
+	metadata callCache[*drive.File]   // File metadata by file ID
+	listings callCache[[]*drive.File] // Folder contents by folder ID
+	links    callCache[[]string]      // Drive URLs found in each document, by file ID
+	ctx      context.Context          // Context of the running DiscoverFromURLs (nil = context.Background())
+}
+
+// Options holds optional discovery settings
+type Options struct {
+	ExcludeFolderPatterns []string          // Case-insensitive glob patterns of folder names to skip
+	ExcludeFolderIDs      []string          // Folder IDs to skip
+	SharedDriveID         string            // Shared Drive to list folder contents from (empty = user corpus)
+	OwnerEmails           []string          // Only discover files owned by one of these users (empty = any owner)
+	NotOwnerEmails        []string          // Skip files owned by any of these users
+	MimeFilter            []string          // Glob patterns of the MIME types discovered, such as "application/vnd.google-apps.*" (empty = all)
+	NamePattern           string            // filepath.Match glob that file names must match to be listed; others are still searched for links (empty = all)
+	IncludeSharedWithMe   bool              // Also discover the files in the user's "Shared with me" view
+	SharedWithMeLimit     int               // Maximum number of "Shared with me" files listed (0 = DefaultSharedWithMeLimit)
+	Workers               int               // Input URLs discovered in parallel (0 = DefaultWorkers)
+	PageSize              int               // Files requested per Files.List page, up to MaxPageSize (0 = DefaultPageSize)
+	IncludeTrashed        bool              // Also list files in the trash
+	FollowShortcuts       bool              // Discover the target of shortcuts to Drive files instead of the shortcut itself
+	Retry                 utils.RetryConfig // Backoff for rate-limited and failing API calls (zero value = utils.DefaultRetryConfig)
 }
 
 // NewDiscoverer creates a new Discoverer
-func NewDiscoverer(service *drive.Service, verbose bool, maxDepth int) *Discoverer {
+func NewDiscoverer(service *drive.Service, verbose bool, maxDepth int, opts Options) *Discoverer {
+	if opts.Retry == (utils.RetryConfig{}) {
+		opts.Retry = utils.DefaultRetryConfig()
+	}
+	if opts.SharedWithMeLimit <= 0 {
+		opts.SharedWithMeLimit = DefaultSharedWithMeLimit
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	opts.PageSize = min(opts.PageSize, MaxPageSize)
+
 	return &Discoverer{
 		service:  service,
 		verbose:  verbose,
 		maxDepth: maxDepth,
-		seen:     make(map[string]bool),
+		opts:     opts,
+		seen:     make(map[int]map[string]bool),
 		depth:    make(map[string]int),
 	}
 }
 
-// DiscoverFromURLs discovers all files from a list of URLs
-func (d *Discoverer) DiscoverFromURLs(urls []string) ([]csv.DiscoveryRecord, error) {
+// DiscoverFromURLs discovers all files from a list of URLs. Once ctx is cancelled it stops
+// and returns the records discovered so far with an error wrapping ctx.Err().
+func (d *Discoverer) DiscoverFromURLs(ctx context.Context, urls []string) ([]csv.DiscoveryRecord, error) {
+	inputs := make([]csv.InputRecord, len(urls))
+	for i, urlStr := range urls {
+		inputs[i] = csv.InputRecord{URL: urlStr}
+	}
+	return d.DiscoverFromRecords(ctx, inputs)
+}
+
+// DiscoverFromRecords discovers all files from input CSV records like DiscoverFromURLs. Links
+// are followed up to each record's own depth, or the global depth when it has none.
+func (d *Discoverer) DiscoverFromRecords(ctx context.Context, inputs []csv.InputRecord) ([]csv.DiscoveryRecord, error) {
+	d.ctx = ctx
+	defer func() { d.ctx = nil }()
+
+	// Discover the inputs in parallel. Each input follows its own folders and links to its own
+	// depth, whatever the other inputs found, and results are kept by input index, so records
+	// are returned in input order whichever worker finishes first. Drive calls are cached, so
+	// files reached from several inputs are only fetched once.
+	results := make([][]csv.DiscoveryRecord, len(inputs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(d.opts.Workers, len(inputs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = d.discoverInput(inputs[index], index)
+			}
+		}()
+	}
+
+	// Send inputs until all are sent or ctx is cancelled
+	for index := 0; index < len(inputs) && ctx.Err() == nil; index++ {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// A file reachable from several inputs is listed under the first of them
+	listed := make(map[string]bool)
 	var records []csv.DiscoveryRecord
-	var mu sync.Mutex
+	for _, inputRecords := range results {
+		for _, record := range inputRecords {
+			if id, err := utils.ExtractFileID(record.Link); err == nil {
+				if listed[id] {
+					continue
+				}
+				listed[id] = true
+			}
+			records = append(records, record)
+		}
+	}
 
-	for _, urlStr := range urls {
-		fileID, err := utils.ExtractFileID(urlStr)
+	// Files found above are marked as seen, so shared files inside the input folders keep
+	// their breadcrumbs and are not listed twice
+	if d.opts.IncludeSharedWithMe && ctx.Err() == nil {
+		d.inheritSeen(len(inputs))
+		sharedRecords, err := d.discoverSharedWithMe(len(inputs))
 		if err != nil {
-			// Invalid URL or malformed file ID - mark as invalid
-			log.Printf("Warning: invalid URL or file ID in %s: %v", urlStr, err)
-			record := csv.DiscoveryRecord{
-				Link:   urlStr,
-				Title:  "INVALID_URL",
-				Status: "invalid",
+			log.Printf("Warning: failed to discover files shared with me: %v", err)
+		}
+		records = append(records, sharedRecords...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return records, fmt.Errorf("discovery cancelled: %w", err)
+	}
+	return records, nil
+}
+
+// discoverInput discovers the file or folder of one input record, at index in the inputs, and
+// the files it links to
+func (d *Discoverer) discoverInput(input csv.InputRecord, index int) []csv.DiscoveryRecord {
+	if d.runContext().Err() != nil {
+		return nil
+	}
+	maxDepth := d.maxDepth
+	if input.Depth != nil {
+		maxDepth = *input.Depth
+	}
+
+	fileID, err := utils.ExtractFileID(input.URL)
+	if err != nil {
+		// Invalid URL or malformed file ID - mark as invalid
+		log.Printf("Warning: invalid URL or file ID in %s: %v", input.URL, err)
+		return []csv.DiscoveryRecord{{
+			Link:   input.URL,
+			Title:  "INVALID_URL",
+			Status: "invalid",
+		}}
+	}
+
+	// Discover from this file/folder at depth 0, preserving original URL
+	records, err := d.discoverFromFileIDWithURL(fileID, input.URL, nil, 0, maxDepth, index)
+	if err != nil {
+		log.Printf("Warning: failed to discover %s: %v", fileID, err)
+		return nil
+	}
+	return records
+}
+
+// claim marks fileID as found by the input at index and reports whether that input should
+// discover it, which it does once. Inputs do not see each other's files, so what an input
+// finds does not depend on which inputs ran before it.
+func (d *Discoverer) claim(fileID string, index int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := d.seen[index]
+	if seen == nil {
+		seen = make(map[string]bool)
+		d.seen[index] = seen
+	}
+	if seen[fileID] {
+		return false
+	}
+	seen[fileID] = true
+	return true
+}
+
+// inheritSeen marks the files found by every input before index as found by index too
+func (d *Discoverer) inheritSeen(index int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	seen := make(map[string]bool)
+	for i, found := range d.seen {
+		if i < index {
+			for fileID := range found {
+				seen[fileID] = true
 			}
-			mu.Lock()
-			records = append(records, record)
-			mu.Unlock()
-			continue
+		}
+	}
+	d.seen[index] = seen
+}
+
+// runContext returns the context of the running DiscoverFromURLs, or context.Background()
+// outside it
+func (d *Discoverer) runContext() context.Context {
+	if d.ctx == nil {
+		return context.Background()
+	}
+	return d.ctx
+}
+
+// discoverSharedWithMe discovers up to SharedWithMeLimit files from the user's "Shared with me"
+// view. Shared folders are searched like input folders. The listing always uses the user
+// corpus, whatever SharedDriveID is set to. index follows the inputs; files they found are
+// skipped once marked with inheritSeen.
+func (d *Discoverer) discoverSharedWithMe(index int) ([]csv.DiscoveryRecord, error) {
+	var records []csv.DiscoveryRecord
+
+	query := "sharedWithMe = true" + d.trashedQuery()
+	if d.filtersOwners() {
+		query += " and " + d.ownerQuery()
+	}
+
+	listed := 0
+	truncated := false
+	pageToken := ""
+	for {
+		call := d.service.Files.List().
+			Q(query).
+			Fields("nextPageToken, files(id, name, mimeType, modifiedTime, shortcutDetails(targetId))").
+			PageSize(int64(min(d.opts.PageSize, d.opts.SharedWithMeLimit-listed))).
+			Corpora("user")
+		if pageToken != "" {
+			call.PageToken(pageToken)
 		}
 
-		// Discover from this file/folder at depth 0, preserving original URL
-		fileRecords, err := d.discoverFromFileIDWithURL(fileID, urlStr, 0)
+		res, err := d.executeFileListWithRetry(func() (*drive.FileList, error) {
+			return call.Context(d.runContext()).Do()
+		})
 		if err != nil {
-			log.Printf("Warning: failed to discover %s: %v", fileID, err)
-			continue
+			return records, fmt.Errorf("failed to list files shared with me: %w", err)
 		}
 
-		mu.Lock()
-		records = append(records, fileRecords...)
-		mu.Unlock()
+		for _, file := range res.Files {
+			if d.runContext().Err() != nil {
+				return records, nil
+			}
+			if listed == d.opts.SharedWithMeLimit {
+				truncated = true
+				break
+			}
+			listed++
+
+			if !d.claim(file.Id, index) {
+				continue
+			}
+
+			if d.verbose {
+				log.Printf("Found shared with me: %s (%s)", file.Name, file.MimeType)
+			}
+			records = append(records, d.listedFileRecords(file, nil, false, index)...)
+		}
+
+		pageToken = res.NextPageToken
+		if pageToken == "" || truncated {
+			break
+		}
+		if listed == d.opts.SharedWithMeLimit {
+			truncated = true
+			break
+		}
+	}
+
+	if truncated {
+		log.Printf("Warning: stopped after %d files shared with me; raise the limit to discover more", listed)
 	}
 
 	return records, nil
@@ -75,25 +317,33 @@ func (d *Discoverer) DiscoverFromURLs(urls []string) ([]csv.DiscoveryRecord, err
 
 // discoverFromFileID discovers a file and recursively follows links within it
 func (d *Discoverer) discoverFromFileID(fileID string, currentDepth int) ([]csv.DiscoveryRecord, error) {
-	return d.discoverFromFileIDWithURL(fileID, "", currentDepth)
+	return d.discoverFromFileIDWithURL(fileID, "", nil, currentDepth, d.maxDepth, 0)
 }
 
-// discoverFromFileIDWithURL discovers a file with an optional original URL and recursively follows links within it
-func (d *Discoverer) discoverFromFileIDWithURL(fileID string, originalURL string, currentDepth int) ([]csv.DiscoveryRecord, error) {
+// discoverFromFileIDWithURL discovers a file with an optional original URL and recursively follows links within it.
+// breadcrumb holds the folder names from the discovered root folder to the file's parent.
+// Links are followed while currentDepth is below maxDepth. index is the input being discovered.
+func (d *Discoverer) discoverFromFileIDWithURL(fileID string, originalURL string, breadcrumb []string, currentDepth, maxDepth, index int) ([]csv.DiscoveryRecord, error) {
 	var records []csv.DiscoveryRecord
 
+	// Stop following files once the run is cancelled
+	if d.runContext().Err() != nil {
+		return records, nil
+	}
+
 	// Check if already seen
-	d.mu.Lock()
-	if d.seen[fileID] {
-		d.mu.Unlock()
+	if !d.claim(fileID, index) {
 		return records, nil
 	}
-	d.seen[fileID] = true
+	d.mu.Lock()
 	d.depth[fileID] = currentDepth
 	d.mu.Unlock()
 
 	// Get file metadata
 	file, err := d.getFileMetadata(fileID)
+	if err != nil && d.runContext().Err() != nil {
+		return records, nil
+	}
 	if err != nil {
 		// Determine error type
 		status := determineErrorStatus(err)
@@ -104,9 +354,10 @@ func (d *Discoverer) discoverFromFileIDWithURL(fileID string, originalURL string
 			link = utils.BuildFileLink(fileID, "")
 		}
 		return []csv.DiscoveryRecord{{
-			Link:   link,
-			Title:  fileID,
-			Status: status,
+			Link:       link,
+			Title:      fileID,
+			Status:     status,
+			Breadcrumb: breadcrumb,
 		}}, nil
 	}
 
@@ -114,131 +365,422 @@ func (d *Discoverer) discoverFromFileIDWithURL(fileID string, originalURL string
 		log.Printf("Processing: %s (%s) at depth %d", file.Name, file.MimeType, currentDepth)
 	}
 
-	if file.MimeType == "application/vnd.google-apps.folder" {
+	// The target is discovered in place of the shortcut, so it is filtered by its own MIME type
+	// and owner. Seen targets are skipped, which also ends shortcut cycles.
+	if targetID := d.shortcutTarget(file); targetID != "" {
+		if d.verbose {
+			log.Printf("Following shortcut %s (%s) to %s", file.Name, fileID, targetID)
+		}
+		return d.discoverFromFileIDWithURL(targetID, "", breadcrumb, currentDepth, maxDepth, index)
+	}
+
+	if file.MimeType != folderMimeType && !d.mimeAllowed(file.MimeType) {
+		if d.verbose {
+			log.Printf("Skipping %s (%s): MIME type %s filtered", file.Name, fileID, file.MimeType)
+		}
+		return records, nil
+	}
+
+	if file.MimeType != folderMimeType && !d.ownerAllowed(file.Owners) {
+		if !d.nameAllowed(file.Name) {
+			return records, nil
+		}
+		if d.verbose {
+			log.Printf("Skipping %s (%s): filtered by owner", file.Name, fileID)
+		}
+		link := originalURL
+		if link == "" {
+			link = utils.BuildFileLink(fileID, file.MimeType)
+		}
+		return []csv.DiscoveryRecord{{
+			Link:         link,
+			Title:        file.Name,
+			Status:       "filtered_owner",
+			ModifiedTime: file.ModifiedTime,
+			Breadcrumb:   breadcrumb,
+			FileType:     utils.ClassifyMimeType(file.MimeType),
+		}}, nil
+	}
+
+	if isExternalShortcut(file) {
+		if !d.nameAllowed(file.Name) {
+			return records, nil
+		}
+		if d.verbose {
+			log.Printf("External shortcut: %s (%s)", file.Name, fileID)
+		}
+		link := file.WebViewLink
+		if link == "" {
+			link = utils.BuildFileLink(fileID, file.MimeType)
+		}
+		return []csv.DiscoveryRecord{{
+			Link:         link,
+			Title:        file.Name,
+			Status:       "external_shortcut",
+			ModifiedTime: file.ModifiedTime,
+			Breadcrumb:   breadcrumb,
+			FileType:     utils.ClassifyMimeType(file.MimeType),
+		}}, nil
+	}
+
+	if file.MimeType == folderMimeType {
+		if reason := d.folderExclusionReason(fileID, file.Name); reason != "" {
+			if d.verbose {
+				log.Printf("Skipping folder %s (%s): %s", file.Name, fileID, reason)
+			}
+			return records, nil
+		}
+
 		// Recursively discover folder contents
-		folderRecords, err := d.discoverFolder(fileID)
+		folderRecords, err := d.discoverFolder(fileID, appendBreadcrumb(breadcrumb, file.Name), index)
 		if err != nil {
 			log.Printf("Warning: failed to discover folder %s: %v", fileID, err)
 		}
 		records = append(records, folderRecords...)
 	} else {
-		// Add this file to records
+		// Add this file to records; files not matching the name pattern are only searched for links
 		// Use original URL if available, otherwise construct one based on MIME type
 		link := originalURL
 		if link == "" {
 			link = utils.BuildFileLink(fileID, file.MimeType)
 		}
-		records = append(records, csv.DiscoveryRecord{
-			Link:   link,
-			Title:  file.Name,
-			Status: "available",
-		})
+		if d.nameAllowed(file.Name) {
+			records = append(records, csv.DiscoveryRecord{
+				Link:         link,
+				Title:        file.Name,
+				Status:       "available",
+				ModifiedTime: file.ModifiedTime,
+				Breadcrumb:   breadcrumb,
+				FileType:     utils.ClassifyMimeType(file.MimeType),
+			})
+		} else if d.verbose {
+			log.Printf("Not listing %s (%s): name does not match %q", file.Name, fileID, d.opts.NamePattern)
+		}
 
 		// If we haven't reached max depth, discover links within the document
-		if currentDepth < d.maxDepth {
-			linkedURLs := d.extractLinksFromDocument(fileID, file.MimeType)
+		if currentDepth < maxDepth {
+			linkedURLs := d.extractLinksFromDocument(fileID, file.MimeType, index)
 			for _, linkedURL := range linkedURLs {
 				linkedID, err := utils.ExtractFileID(linkedURL)
 				if err != nil {
 					log.Printf("Warning: failed to extract file ID from %s: %v", linkedURL, err)
 					continue
 				}
-				linkedRecords, err := d.discoverFromFileIDWithURL(linkedID, linkedURL, currentDepth+1)
+				// Linked files are not inside the folder being discovered, so they have no breadcrumb
+				linkedRecords, err := d.discoverFromFileIDWithURL(linkedID, linkedURL, nil, currentDepth+1, maxDepth, index)
 				if err != nil {
 					log.Printf("Warning: failed to discover linked file %s: %v", linkedID, err)
 					continue
 				}
 				records = append(records, linkedRecords...)
 			}
-		} else if d.verbose && currentDepth >= d.maxDepth {
-			log.Printf("Max depth %d reached for %s, skipping link discovery", d.maxDepth, file.Name)
+		} else if d.verbose && currentDepth >= maxDepth {
+			log.Printf("Max depth %d reached for %s, skipping link discovery", maxDepth, file.Name)
 		}
 	}
 
 	return records, nil
 }
 
-// discoverFolder recursively discovers all files in a folder
-func (d *Discoverer) discoverFolder(folderID string) ([]csv.DiscoveryRecord, error) {
+// trashedQuery returns the Files.List query predicate leaving out trashed files, or an empty
+// string with IncludeTrashed
+func (d *Discoverer) trashedQuery() string {
+	if d.opts.IncludeTrashed {
+		return ""
+	}
+	return " and trashed = false"
+}
+
+// discoverFolder recursively discovers all files in a folder. breadcrumb holds the folder names
+// from the discovered root folder down to and including this folder.
+// Callers claim the folder for the input at index before calling, so each input lists it at
+// most once.
+func (d *Discoverer) discoverFolder(folderID string, breadcrumb []string, index int) ([]csv.DiscoveryRecord, error) {
 	var records []csv.DiscoveryRecord
 
-	// Check if we've already processed this folder
-	d.mu.Lock()
-	if d.seen[folderID] {
-		d.mu.Unlock()
-		return records, nil
+	files, err := d.listFolder(folderID)
+	if err != nil {
+		return nil, err
 	}
-	d.seen[folderID] = true
-	d.mu.Unlock()
+
+	for _, file := range files {
+		if d.runContext().Err() != nil {
+			return records, nil
+		}
+		if !d.claim(file.Id, index) {
+			continue
+		}
+
+		if d.verbose {
+			log.Printf("Found: %s (%s)", file.Name, file.MimeType)
+		}
+
+		records = append(records, d.listedFileRecords(file, breadcrumb, d.opts.SharedDriveID != "", index)...)
+	}
+
+	return records, nil
+}
+
+// listFolder returns the files in a folder, listing it once per run
+func (d *Discoverer) listFolder(folderID string) ([]*drive.File, error) {
+	return d.listings.do(folderID, func() ([]*drive.File, error) {
+		return d.fetchFolder(folderID)
+	})
+}
+
+// fetchFolder lists the files in a folder, all pages of them
+func (d *Discoverer) fetchFolder(folderID string) ([]*drive.File, error) {
+	var files []*drive.File
 
 	pageToken := ""
 	for {
-		query := fmt.Sprintf("'%s' in parents and trashed = false", folderID)
+		query := fmt.Sprintf("'%s' in parents", folderID) + d.trashedQuery()
+		fields := "nextPageToken, files(id, name, mimeType, modifiedTime, shortcutDetails(targetId))"
+		if d.filtersOwners() {
+			if d.opts.SharedDriveID == "" {
+				query += " and " + d.ownerQuery()
+			} else {
+				// Shared Drives do not support owner queries, so owners are checked per file below
+				fields = "nextPageToken, files(id, name, mimeType, modifiedTime, shortcutDetails(targetId), owners(emailAddress))"
+			}
+		}
 		call := d.service.Files.List().
 			Q(query).
-			Fields("nextPageToken, files(id, name, mimeType)").
-			PageSize(100).
+			Fields(googleapi.Field(fields)).
+			PageSize(int64(d.opts.PageSize)).
 			SupportsAllDrives(true).
 			IncludeItemsFromAllDrives(true)
 
+		// Shared Drive content is only returned from the drive corpus
+		if d.opts.SharedDriveID != "" {
+			call.Corpora("drive").DriveId(d.opts.SharedDriveID)
+		}
+
 		if pageToken != "" {
 			call.PageToken(pageToken)
 		}
 
 		res, err := d.executeFileListWithRetry(func() (*drive.FileList, error) {
-			return call.Do()
+			return call.Context(d.runContext()).Do()
 		})
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to list files in folder %s: %w", folderID, err)
 		}
+		files = append(files, res.Files...)
 
-		for _, file := range res.Files {
-			d.mu.Lock()
-			if d.seen[file.Id] {
-				d.mu.Unlock()
-				continue
-			}
-			d.seen[file.Id] = true
-			d.mu.Unlock()
+		pageToken = res.NextPageToken
+		if pageToken == "" || d.runContext().Err() != nil {
+			break
+		}
+	}
+
+	return files, nil
+}
+
+// listedFileRecords returns the records for a file returned by a Files.List call, searching it
+// if it is a folder. checkOwners filters the file by its listed owners, for listings whose
+// query could not filter them. index is the input being discovered.
+func (d *Discoverer) listedFileRecords(file *drive.File, breadcrumb []string, checkOwners bool, index int) []csv.DiscoveryRecord {
+	if targetID := d.shortcutTarget(file); targetID != "" {
+		if d.verbose {
+			log.Printf("Following shortcut %s (%s) to %s", file.Name, file.Id, targetID)
+		}
+		// Like the other listed files, the target is not searched for links
+		records, err := d.discoverFromFileIDWithURL(targetID, "", breadcrumb, d.maxDepth, d.maxDepth, index)
+		if err != nil {
+			log.Printf("Warning: failed to discover shortcut target %s: %v", targetID, err)
+			return nil
+		}
+		return records
+	}
 
+	if file.MimeType == folderMimeType {
+		// Excluded folders are not visited, so none of their children are either
+		if reason := d.folderExclusionReason(file.Id, file.Name); reason != "" {
 			if d.verbose {
-				log.Printf("Found: %s (%s)", file.Name, file.MimeType)
+				log.Printf("Skipping folder %s (%s): %s", file.Name, file.Id, reason)
 			}
+			return nil
+		}
 
-			if file.MimeType == "application/vnd.google-apps.folder" {
-				// Recursively process subfolder
-				subRecords, err := d.discoverFolder(file.Id)
-				if err != nil {
-					log.Printf("Warning: failed to discover subfolder %s: %v", file.Id, err)
-					continue
+		// Recursively process subfolder
+		subRecords, err := d.discoverFolder(file.Id, appendBreadcrumb(breadcrumb, file.Name), index)
+		if err != nil {
+			log.Printf("Warning: failed to discover subfolder %s: %v", file.Id, err)
+			return nil
+		}
+		return subRecords
+	}
+
+	if !d.mimeAllowed(file.MimeType) {
+		if d.verbose {
+			log.Printf("Skipping %s (%s): MIME type %s filtered", file.Name, file.Id, file.MimeType)
+		}
+		return nil
+	}
+
+	if !d.nameAllowed(file.Name) {
+		if d.verbose {
+			log.Printf("Not listing %s (%s): name does not match %q", file.Name, file.Id, d.opts.NamePattern)
+		}
+		return nil
+	}
+
+	if checkOwners && !d.ownerAllowed(file.Owners) {
+		if d.verbose {
+			log.Printf("Skipping %s (%s): filtered by owner", file.Name, file.Id)
+		}
+		return []csv.DiscoveryRecord{{
+			Link:         utils.BuildFileLink(file.Id, file.MimeType),
+			Title:        file.Name,
+			Status:       "filtered_owner",
+			ModifiedTime: file.ModifiedTime,
+			Breadcrumb:   breadcrumb,
+			FileType:     utils.ClassifyMimeType(file.MimeType),
+		}}
+	}
+
+	// Add file record - mark as available since we successfully retrieved it
+	return []csv.DiscoveryRecord{{
+		Link:         utils.BuildFileLink(file.Id, file.MimeType),
+		Title:        file.Name,
+		Status:       "available",
+		ModifiedTime: file.ModifiedTime,
+		Breadcrumb:   breadcrumb,
+		FileType:     utils.ClassifyMimeType(file.MimeType),
+	}}
+}
+
+// appendBreadcrumb returns breadcrumb extended with a folder name, without modifying the
+// backing array that sibling folders share
+func appendBreadcrumb(breadcrumb []string, name string) []string {
+	return append(breadcrumb[:len(breadcrumb):len(breadcrumb)], name)
+}
+
+// folderExclusionReason returns why a folder should be skipped, or "" if it should be visited
+func (d *Discoverer) folderExclusionReason(folderID, name string) string {
+	for _, id := range d.opts.ExcludeFolderIDs {
+		if id == folderID {
+			return "excluded by ID"
+		}
+	}
+
+	lowerName := strings.ToLower(name)
+	for _, pattern := range d.opts.ExcludeFolderPatterns {
+		if matched, _ := filepath.Match(strings.ToLower(pattern), lowerName); matched {
+			return "excluded by pattern"
+		}
+	}
+
+	return ""
+}
+
+// filtersOwners reports whether files are filtered by owner
+func (d *Discoverer) filtersOwners() bool {
+	return len(d.opts.OwnerEmails) > 0 || len(d.opts.NotOwnerEmails) > 0
+}
+
+// ownerQuery returns the Drive query clause that keeps folders and the files passing the owner
+// filter. Folders are kept whatever their owner so that their contents are still listed.
+func (d *Discoverer) ownerQuery() string {
+	var clauses []string
+	if len(d.opts.OwnerEmails) > 0 {
+		owned := make([]string, len(d.opts.OwnerEmails))
+		for i, email := range d.opts.OwnerEmails {
+			owned[i] = fmt.Sprintf("'%s' in owners", escapeQuery(email))
+		}
+		clauses = append(clauses, "("+strings.Join(owned, " or ")+")")
+	}
+	for _, email := range d.opts.NotOwnerEmails {
+		clauses = append(clauses, fmt.Sprintf("not '%s' in owners", escapeQuery(email)))
+	}
+	return fmt.Sprintf("(mimeType = '%s' or (%s))", folderMimeType, strings.Join(clauses, " and "))
+}
+
+// ownerAllowed reports whether a file with the given owners passes the owner filter. Emails
+// are compared case-insensitively.
+func (d *Discoverer) ownerAllowed(owners []*drive.User) bool {
+	isOwner := func(emails []string) bool {
+		for _, owner := range owners {
+			for _, email := range emails {
+				if strings.EqualFold(owner.EmailAddress, email) {
+					return true
 				}
-				records = append(records, subRecords...)
-			} else {
-				// Add file record - mark as available since we successfully retrieved it
-				records = append(records, csv.DiscoveryRecord{
-					Link:   utils.BuildFileLink(file.Id, file.MimeType),
-					Title:  file.Name,
-					Status: "available",
-				})
 			}
 		}
+		return false
+	}
 
-		pageToken = res.NextPageToken
-		if pageToken == "" {
-			break
+	if len(d.opts.OwnerEmails) > 0 && !isOwner(d.opts.OwnerEmails) {
+		return false
+	}
+	return !isOwner(d.opts.NotOwnerEmails)
+}
+
+// mimeAllowed reports whether a file with mimeType passes the MIME filter. Patterns are
+// matched case-insensitively with path.Match, so "*" does not match the "/".
+func (d *Discoverer) mimeAllowed(mimeType string) bool {
+	if len(d.opts.MimeFilter) == 0 {
+		return true
+	}
+	lowerType := strings.ToLower(mimeType)
+	for _, pattern := range d.opts.MimeFilter {
+		if matched, _ := path.Match(strings.ToLower(pattern), lowerType); matched {
+			return true
 		}
 	}
+	return false
+}
 
-	return records, nil
+// nameAllowed reports whether a file named name matches the name pattern
+func (d *Discoverer) nameAllowed(name string) bool {
+	if d.opts.NamePattern == "" {
+		return true
+	}
+	matched, _ := filepath.Match(d.opts.NamePattern, name)
+	return matched
+}
+
+// isExternalShortcut reports whether file is a shortcut to an external URL rather than to a
+// Drive file. Such shortcuts have no target ID.
+func isExternalShortcut(file *drive.File) bool {
+	return file.MimeType == shortcutMimeType && (file.ShortcutDetails == nil || file.ShortcutDetails.TargetId == "")
+}
+
+// shortcutTarget returns the ID of the Drive file a shortcut points to when FollowShortcuts is
+// set, or "" for other files and shortcuts to external URLs
+func (d *Discoverer) shortcutTarget(file *drive.File) string {
+	if !d.opts.FollowShortcuts || file.MimeType != shortcutMimeType || file.ShortcutDetails == nil {
+		return ""
+	}
+	return file.ShortcutDetails.TargetId
+}
+
+// escapeQuery escapes a value for a single-quoted Drive query string
+func escapeQuery(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
 }
 
-// getFileMetadata retrieves metadata for a file
+// getFileMetadata retrieves metadata for a file, fetching it once per run
 func (d *Discoverer) getFileMetadata(fileID string) (*drive.File, error) {
+	return d.metadata.do(fileID, func() (*drive.File, error) {
+		return d.fetchFileMetadata(fileID)
+	})
+}
+
+// fetchFileMetadata fetches the metadata of a file from Drive
+func (d *Discoverer) fetchFileMetadata(fileID string) (*drive.File, error) {
+	fields := "id, name, mimeType, modifiedTime, webViewLink, shortcutDetails(targetId, targetMimeType)"
+	if d.filtersOwners() {
+		fields += ", owners(emailAddress)"
+	}
+
 	file, err := d.executeFileWithRetry(func() (*drive.File, error) {
 		return d.service.Files.Get(fileID).
-			Fields("id, name, mimeType").
+			Fields(googleapi.Field(fields)).
 			SupportsAllDrives(true).
+			Context(d.runContext()).
 			Do()
 	})
 
@@ -251,64 +793,60 @@ func (d *Discoverer) getFileMetadata(fileID string) (*drive.File, error) {
 
 // executeFileListWithRetry executes a FileList function with exponential backoff retry
 func (d *Discoverer) executeFileListWithRetry(fn func() (*drive.FileList, error)) (*drive.FileList, error) {
-	maxRetries := 5
-	baseDelay := time.Second
-
-	for i := 0; i < maxRetries; i++ {
-		result, err := fn()
-		if err == nil {
-			return result, nil
-		}
-
-		// Check if it's a rate limit error
-		if apiErr, ok := err.(*googleapi.Error); ok {
-			if apiErr.Code == 403 || apiErr.Code == 429 {
-				delay := baseDelay * time.Duration(1<<uint(i))
-				if d.verbose {
-					log.Printf("Rate limited, retrying in %v...", delay)
-				}
-				time.Sleep(delay)
-				continue
-			}
-		}
-
-		return nil, err
-	}
-
-	return fn() // Final attempt
+	var result *drive.FileList
+	err := d.opts.Retry.Do(d.runContext(), d.verbose, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
 }
 
 // executeFileWithRetry executes a File function with exponential backoff retry
 func (d *Discoverer) executeFileWithRetry(fn func() (*drive.File, error)) (*drive.File, error) {
-	maxRetries := 5
-	baseDelay := time.Second
+	var result *drive.File
+	err := d.opts.Retry.Do(d.runContext(), d.verbose, func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
 
-	for i := 0; i < maxRetries; i++ {
-		result, err := fn()
-		if err == nil {
-			return result, nil
+// extractLinksFromDocument returns the Google Drive/Docs URLs in a document, leaving out files
+// the input at index already found
+func (d *Discoverer) extractLinksFromDocument(fileID, mimeType string, index int) []string {
+	urls, _ := d.links.do(fileID, func() ([]string, error) {
+		return d.documentLinks(fileID, mimeType), nil
+	})
+
+	var linkedURLs []string
+	for _, urlStr := range urls {
+		id, err := utils.ExtractFileID(urlStr)
+		if err != nil {
+			continue // Skip invalid URLs
 		}
 
-		// Check if it's a rate limit error
-		if apiErr, ok := err.(*googleapi.Error); ok {
-			if apiErr.Code == 403 || apiErr.Code == 429 {
-				delay := baseDelay * time.Duration(1<<uint(i))
-				if d.verbose {
-					log.Printf("Rate limited, retrying in %v...", delay)
-				}
-				time.Sleep(delay)
-				continue
-			}
+		// Check against the input's seen map to avoid re-processing
+		d.mu.Lock()
+		alreadySeen := d.seen[index][id]
+		d.mu.Unlock()
+
+		// Avoid duplicates and self-references
+		if !alreadySeen && id != fileID {
+			linkedURLs = append(linkedURLs, urlStr)
 		}
+	}
 
-		return nil, err
+	if d.verbose && len(linkedURLs) > 0 {
+		log.Printf("Found %d new linked documents in %s", len(linkedURLs), fileID)
 	}
 
-	return fn() // Final attempt
+	return linkedURLs
 }
 
-// extractLinksFromDocument exports a document and extracts Google Drive/Docs URLs
-func (d *Discoverer) extractLinksFromDocument(fileID, mimeType string) []string {
+// documentLinks exports a document and extracts all Google Drive/Docs URLs in it
+func (d *Discoverer) documentLinks(fileID, mimeType string) []string {
 	var linkedURLs []string
 	var content []byte
 	var err error
@@ -324,12 +862,12 @@ func (d *Discoverer) extractLinksFromDocument(fileID, mimeType string) []string
 		}
 	} else if strings.HasPrefix(mimeType, "application/vnd.google-apps.") {
 		// Skip folders
-		if mimeType == "application/vnd.google-apps.folder" {
+		if mimeType == folderMimeType {
 			return linkedURLs
 		}
 
 		// Export Google Workspace document as markdown to search for links
-		resp, err := d.service.Files.Export(fileID, "text/markdown").Download()
+		resp, err := d.service.Files.Export(fileID, "text/markdown").Context(d.runContext()).Download()
 		if err != nil {
 			if d.verbose {
 				log.Printf("Warning: failed to export %s for link extraction: %v", fileID, err)
@@ -357,31 +895,7 @@ func (d *Discoverer) extractLinksFromDocument(fileID, mimeType string) []string
 	// Find all Google Drive/Docs URLs in the content
 	// Pattern matches both drive.google.com and docs.google.com URLs
 	linkPattern := regexp.MustCompile(`https://(?:drive\.google\.com|docs\.google\.com)/[^\s\)]+`)
-	matches := linkPattern.FindAllString(normalizedContent, -1)
-
-	// Process URLs and preserve them
-	for _, urlStr := range matches {
-		id, err := utils.ExtractFileID(urlStr)
-		if err != nil {
-			continue // Skip invalid URLs
-		}
-
-		// Check against global seen map to avoid re-processing
-		d.mu.Lock()
-		alreadySeen := d.seen[id]
-		d.mu.Unlock()
-
-		// Avoid duplicates and self-references
-		if !alreadySeen && id != fileID {
-			linkedURLs = append(linkedURLs, urlStr)
-		}
-	}
-
-	if d.verbose && len(linkedURLs) > 0 {
-		log.Printf("Found %d new linked documents in %s", len(linkedURLs), fileID)
-	}
-
-	return linkedURLs
+	return linkPattern.FindAllString(normalizedContent, -1)
 }
 
 // extractLinksFromPDF converts a PDF to Google Docs format and extracts its content for link discovery
@@ -397,14 +911,15 @@ func (d *Discoverer) extractLinksFromPDF(fileID string) ([]byte, error) {
 		MimeType: "application/vnd.google-apps.document",
 	}
 
-	copiedFile, err := d.service.Files.Copy(fileID, copyFile).SupportsAllDrives(true).Do()
+	copiedFile, err := d.service.Files.Copy(fileID, copyFile).SupportsAllDrives(true).Context(d.runContext()).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert PDF to Google Docs: %w", err)
 	}
 
-	// Delete the temporary converted file when done
+	// Delete the temporary converted file when done, even if the run was cancelled
 	defer func() {
-		if err := d.service.Files.Delete(copiedFile.Id).SupportsAllDrives(true).Do(); err != nil {
+		ctx := context.WithoutCancel(d.runContext())
+		if err := d.service.Files.Delete(copiedFile.Id).SupportsAllDrives(true).Context(ctx).Do(); err != nil {
 			if d.verbose {
 				log.Printf("Warning: Failed to delete temporary file %s: %v", copiedFile.Id, err)
 			}
@@ -412,7 +927,7 @@ func (d *Discoverer) extractLinksFromPDF(fileID string) ([]byte, error) {
 	}()
 
 	// Export the converted Google Doc as markdown
-	resp, err := d.service.Files.Export(copiedFile.Id, "text/markdown").Download()
+	resp, err := d.service.Files.Export(copiedFile.Id, "text/markdown").Context(d.runContext()).Download()
 	if err != nil {
 		return nil, fmt.Errorf("failed to export converted document: %w", err)
 	}
@@ -1,8 +1,19 @@
 package discovery
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
 	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
 )
 
@@ -271,3 +282,888 @@ func TestDetermineErrorStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestFolderExclusionReason(t *testing.T) {
+	d := NewDiscoverer(nil, false, 0, Options{
+		ExcludeFolderPatterns: []string{"Archive", "*old*", "Meeting Notes"},
+		ExcludeFolderIDs:      []string{"folderID123"},
+	})
+
+	tests := []struct {
+		name     string
+		folderID string
+		folder   string
+		want     string
+	}{
+		{
+			name:     "exact name match",
+			folderID: "a",
+			folder:   "Archive",
+			want:     "excluded by pattern",
+		},
+		{
+			name:     "case-insensitive match",
+			folderID: "b",
+			folder:   "meeting notes",
+			want:     "excluded by pattern",
+		},
+		{
+			name:     "wildcard match",
+			folderID: "c",
+			folder:   "2019 OLD docs",
+			want:     "excluded by pattern",
+		},
+		{
+			name:     "excluded by ID",
+			folderID: "folderID123",
+			folder:   "Engineering",
+			want:     "excluded by ID",
+		},
+		{
+			name:     "not excluded",
+			folderID: "d",
+			folder:   "Engineering",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.folderExclusionReason(tt.folderID, tt.folder); got != tt.want {
+				t.Errorf("folderExclusionReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverFolderSharedDrive(t *testing.T) {
+	tests := []struct {
+		name          string
+		sharedDriveID string
+		wantCorpora   string
+		wantDriveID   string
+	}{
+		{
+			name:          "shared drive uses drive corpus",
+			sharedDriveID: "sharedDrive123",
+			wantCorpora:   "drive",
+			wantDriveID:   "sharedDrive123",
+		},
+		{
+			name:          "no shared drive leaves corpus unset",
+			sharedDriveID: "",
+			wantCorpora:   "",
+			wantDriveID:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.addFile("", &drive.File{Id: "folder1", Name: "Docs", MimeType: "application/vnd.google-apps.folder"})
+			fake.addFile("folder1", &drive.File{Id: "doc1", Name: "Guide", MimeType: "application/vnd.google-apps.document"})
+
+			d := newTestDiscoverer(t, fake, 0, Options{SharedDriveID: tt.sharedDriveID})
+			records, err := d.DiscoverFromURLs(context.Background(), []string{"https://drive.google.com/drive/folders/folder1"})
+			if err != nil {
+				t.Fatalf("DiscoverFromURLs() error = %v", err)
+			}
+
+			if len(records) != 1 || records[0].Title != "Guide" {
+				t.Errorf("DiscoverFromURLs() = %+v, want the single shared drive document", records)
+			}
+
+			listReqs := fake.listRequests()
+			if len(listReqs) != 1 {
+				t.Fatalf("Got %d list requests, want 1", len(listReqs))
+			}
+			query := listReqs[0].URL.Query()
+			if got := query.Get("corpora"); got != tt.wantCorpora {
+				t.Errorf("corpora = %q, want %q", got, tt.wantCorpora)
+			}
+			if got := query.Get("driveId"); got != tt.wantDriveID {
+				t.Errorf("driveId = %q, want %q", got, tt.wantDriveID)
+			}
+			if got := query.Get("includeItemsFromAllDrives"); got != "true" {
+				t.Errorf("includeItemsFromAllDrives = %q, want true", got)
+			}
+		})
+	}
+}
+
+func TestDiscoverFolderPageSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         Options
+		wantPageSize string
+		wantQuery    string
+	}{
+		{
+			name:         "defaults",
+			opts:         Options{},
+			wantPageSize: "100",
+			wantQuery:    "'folder1' in parents and trashed = false",
+		},
+		{
+			name:         "custom page size",
+			opts:         Options{PageSize: 500},
+			wantPageSize: "500",
+			wantQuery:    "'folder1' in parents and trashed = false",
+		},
+		{
+			name:         "page size capped at the API maximum",
+			opts:         Options{PageSize: 5000},
+			wantPageSize: "1000",
+			wantQuery:    "'folder1' in parents and trashed = false",
+		},
+		{
+			name:         "include trashed",
+			opts:         Options{PageSize: 10, IncludeTrashed: true},
+			wantPageSize: "10",
+			wantQuery:    "'folder1' in parents",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.addFile("", &drive.File{Id: "folder1", Name: "Docs", MimeType: "application/vnd.google-apps.folder"})
+			fake.addFile("folder1", &drive.File{Id: "doc1", Name: "Guide", MimeType: "application/vnd.google-apps.document"})
+
+			d := newTestDiscoverer(t, fake, 0, tt.opts)
+			if _, err := d.DiscoverFromURLs(context.Background(), []string{"https://drive.google.com/drive/folders/folder1"}); err != nil {
+				t.Fatalf("DiscoverFromURLs() error = %v", err)
+			}
+
+			listReqs := fake.listRequests()
+			if len(listReqs) != 1 {
+				t.Fatalf("Got %d list requests, want 1", len(listReqs))
+			}
+			query := listReqs[0].URL.Query()
+			if got := query.Get("pageSize"); got != tt.wantPageSize {
+				t.Errorf("pageSize = %q, want %q", got, tt.wantPageSize)
+			}
+			if got := query.Get("q"); got != tt.wantQuery {
+				t.Errorf("q = %q, want %q", got, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestDiscoverFolderBreadcrumb(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "root", Name: "Engineering", MimeType: "application/vnd.google-apps.folder"})
+	fake.addFile("root", &drive.File{Id: "readme", Name: "Readme", MimeType: "application/vnd.google-apps.document"})
+	fake.addFile("root", &drive.File{Id: "k8s", Name: "Kubernetes", MimeType: "application/vnd.google-apps.folder"})
+	fake.addFile("root", &drive.File{Id: "ci", Name: "CI / CD", MimeType: "application/vnd.google-apps.folder"})
+	fake.addFile("k8s", &drive.File{Id: "runbooks", Name: "Runbooks", MimeType: "application/vnd.google-apps.folder"})
+	fake.addFile("runbooks", &drive.File{Id: "restart", Name: "Restart pods", MimeType: "application/vnd.google-apps.document"})
+	fake.addFile("ci", &drive.File{Id: "deploy", Name: "Deploy", MimeType: "application/vnd.google-apps.document"})
+	fake.addFile("", &drive.File{Id: "single", Name: "Single", MimeType: "application/vnd.google-apps.document"})
+
+	d := newTestDiscoverer(t, fake, 0, Options{})
+	records, err := d.DiscoverFromURLs(context.Background(), []string{
+		"https://drive.google.com/drive/folders/root",
+		"https://docs.google.com/document/d/single/edit",
+	})
+	if err != nil {
+		t.Fatalf("DiscoverFromURLs() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"Readme":       {"Engineering"},
+		"Restart pods": {"Engineering", "Kubernetes", "Runbooks"},
+		"Deploy":       {"Engineering", "CI / CD"},
+		"Single":       nil,
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %+v", len(records), len(want), records)
+	}
+	for _, record := range records {
+		if !reflect.DeepEqual(record.Breadcrumb, want[record.Title]) {
+			t.Errorf("Breadcrumb of %s = %q, want %q", record.Title, record.Breadcrumb, want[record.Title])
+		}
+	}
+}
+
+func TestDiscoverFileType(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "root", Name: "Shared", MimeType: "application/vnd.google-apps.folder"})
+	fake.addFile("root", &drive.File{Id: "doc", Name: "Doc", MimeType: "application/vnd.google-apps.document"})
+	fake.addFile("root", &drive.File{Id: "manual", Name: "Manual", MimeType: "application/pdf"})
+	fake.addFile("root", &drive.File{Id: "photo", Name: "Photo", MimeType: "image/png"})
+	fake.addFile("", &drive.File{Id: "sheet", Name: "Sheet", MimeType: "application/vnd.google-apps.spreadsheet"})
+
+	d := newTestDiscoverer(t, fake, 0, Options{})
+	records, err := d.DiscoverFromURLs(context.Background(), []string{
+		"https://drive.google.com/drive/folders/root",
+		"https://docs.google.com/spreadsheets/d/sheet/edit",
+		"https://docs.google.com/document/d/missing/edit",
+	})
+	if err != nil {
+		t.Fatalf("DiscoverFromURLs() error = %v", err)
+	}
+
+	want := map[string]string{
+		"Doc":     "google-doc",
+		"Manual":  "pdf",
+		"Photo":   "image",
+		"Sheet":   "google-sheet",
+		"missing": "", // Unknown without metadata
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Got %d records, want %d: %+v", len(records), len(want), records)
+	}
+	for _, record := range records {
+		if record.FileType != want[record.Title] {
+			t.Errorf("FileType of %s = %q, want %q", record.Title, record.FileType, want[record.Title])
+		}
+	}
+}
+
+func TestDiscoverExternalShortcut(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "ext", Name: "Vendor Docs", MimeType: "application/vnd.google-apps.shortcut", WebViewLink: "https://drive.google.com/file/d/ext/view"})
+	fake.addFile("", &drive.File{Id: "internal", Name: "Handbook", MimeType: "application/vnd.google-apps.shortcut", ShortcutDetails: &drive.FileShortcutDetails{TargetId: "doc1"}})
+
+	d := newTestDiscoverer(t, fake, 0, Options{})
+	records, err := d.DiscoverFromURLs(context.Background(), []string{
+		"https://drive.google.com/file/d/ext/view",
+		"https://drive.google.com/file/d/internal/view",
+	})
+	if err != nil {
+		t.Fatalf("DiscoverFromURLs() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Got %d records, want 2: %+v", len(records), records)
+	}
+
+	if records[0].Status != "external_shortcut" || records[0].Link != "https://drive.google.com/file/d/ext/view" {
+		t.Errorf("external shortcut record = %+v, want status external_shortcut with its webViewLink", records[0])
+	}
+	if records[1].Status != "available" {
+		t.Errorf("shortcut to a Drive file has status %q, want available", records[1].Status)
+	}
+}
+
+func TestDiscoverFollowShortcuts(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "doc1", Name: "Handbook", MimeType: "application/vnd.google-apps.document"})
+	fake.addFile("", &drive.File{Id: "short1", Name: "Handbook shortcut", MimeType: "application/vnd.google-apps.shortcut",
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: "doc1", TargetMimeType: "application/vnd.google-apps.document"}})
+	fake.addFile("", &drive.File{Id: "root", Name: "Team", MimeType: "application/vnd.google-apps.folder"})
+	fake.addFile("root", &drive.File{Id: "short2", Name: "Another shortcut", MimeType: "application/vnd.google-apps.shortcut",
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: "doc1", TargetMimeType: "application/vnd.google-apps.document"}})
+	fake.addFile("root", &drive.File{Id: "loop", Name: "Loop", MimeType: "application/vnd.google-apps.shortcut",
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: "root", TargetMimeType: "application/vnd.google-apps.folder"}})
+
+	d := newTestDiscoverer(t, fake, 0, Options{FollowShortcuts: true})
+	records, err := d.DiscoverFromURLs(context.Background(), []string{
+		"https://drive.google.com/file/d/short1/view",
+		"https://drive.google.com/drive/folders/root",
+	})
+	if err != nil {
+		t.Fatalf("DiscoverFromURLs() error = %v", err)
+	}
+
+	// The Doc is found once through its first shortcut, and the shortcut back to the folder is
+	// not listed again
+	if len(records) != 1 {
+		t.Fatalf("Got %d records, want 1: %+v", len(records), records)
+	}
+	want := csv.DiscoveryRecord{
+		Link:     "https://docs.google.com/document/d/doc1/edit",
+		Title:    "Handbook",
+		Status:   "available",
+		FileType: "google-doc",
+	}
+	if !reflect.DeepEqual(records[0], want) {
+		t.Errorf("record = %+v, want %+v", records[0], want)
+	}
+}
+
+func TestDiscoverFollowShortcutsInFolder(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "root", Name: "Team", MimeType: "application/vnd.google-apps.folder"})
+	fake.addFile("root", &drive.File{Id: "short1", Name: "Handbook shortcut", MimeType: "application/vnd.google-apps.shortcut",
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: "doc1"}})
+	fake.addFile("", &drive.File{Id: "doc1", Name: "Handbook", MimeType: "application/vnd.google-apps.document"})
+
+	d := newTestDiscoverer(t, fake, 0, Options{FollowShortcuts: true})
+	records, err := d.DiscoverFromURLs(context.Background(), []string{"https://drive.google.com/drive/folders/root"})
+	if err != nil {
+		t.Fatalf("DiscoverFromURLs() error = %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Got %d records, want 1: %+v", len(records), records)
+	}
+	if records[0].Title != "Handbook" || !reflect.DeepEqual(records[0].Breadcrumb, []string{"Team"}) {
+		t.Errorf("record = %+v, want the Doc with the shortcut's breadcrumb", records[0])
+	}
+}
+
+func TestDiscoverSharedWithMe(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "root", Name: "Team", MimeType: "application/vnd.google-apps.folder"})
+	inFolder := &drive.File{Id: "doc1", Name: "Doc 1", MimeType: "application/vnd.google-apps.document"}
+	fake.addFile("root", inFolder)
+	sharedFolder := &drive.File{Id: "folder2", Name: "Shared Folder", MimeType: "application/vnd.google-apps.folder"}
+	fake.addFile("", sharedFolder)
+	fake.addFile("folder2", &drive.File{Id: "doc3", Name: "Doc 3", MimeType: "application/vnd.google-apps.document"})
+	fake.shared = []*drive.File{
+		inFolder,
+		sharedFolder,
+		{Id: "doc2", Name: "Doc 2", MimeType: "application/vnd.google-apps.document"},
+		{Id: "doc4", Name: "Doc 4", MimeType: "application/vnd.google-apps.document"},
+	}
+
+	tests := []struct {
+		name  string
+		opts  Options
+		want  map[string]string // Title to breadcrumb
+		lists int               // Shared with me listings
+	}{
+		{name: "disabled", opts: Options{}, want: map[string]string{"Doc 1": "Team"}},
+		{
+			name:  "merged by file ID",
+			opts:  Options{IncludeSharedWithMe: true},
+			want:  map[string]string{"Doc 1": "Team", "Doc 2": "", "Doc 3": "Shared Folder", "Doc 4": ""},
+			lists: 1,
+		},
+		{
+			name:  "limited",
+			opts:  Options{IncludeSharedWithMe: true, SharedWithMeLimit: 3},
+			want:  map[string]string{"Doc 1": "Team", "Doc 2": "", "Doc 3": "Shared Folder"},
+			lists: 1,
+		},
+		{
+			name:  "user corpus with a shared drive",
+			opts:  Options{IncludeSharedWithMe: true, SharedDriveID: "drive1"},
+			want:  map[string]string{"Doc 1": "Team", "Doc 2": "", "Doc 3": "Shared Folder", "Doc 4": ""},
+			lists: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.requests = nil
+			d := newTestDiscoverer(t, fake, 0, tt.opts)
+			records, err := d.DiscoverFromURLs(context.Background(), []string{"https://drive.google.com/drive/folders/root"})
+			if err != nil {
+				t.Fatalf("DiscoverFromURLs() error = %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, record := range records {
+				got[record.Title] = strings.Join(record.Breadcrumb, "/")
+			}
+			if len(records) != len(tt.want) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %v, want %v", got, tt.want)
+			}
+
+			var lists int
+			for _, r := range fake.listRequests() {
+				query := r.URL.Query()
+				if !strings.HasPrefix(query.Get("q"), "sharedWithMe = true") {
+					continue
+				}
+				lists++
+				if query.Get("corpora") != "user" || query.Get("driveId") != "" {
+					t.Errorf("shared with me listing corpora = %q, driveId = %q, want the user corpus", query.Get("corpora"), query.Get("driveId"))
+				}
+			}
+			if lists != tt.lists {
+				t.Errorf("made %d shared with me listings, want %d", lists, tt.lists)
+			}
+		})
+	}
+}
+
+func TestDiscoverCancelled(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "doc", Name: "Doc", MimeType: "application/vnd.google-apps.document"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	d := newTestDiscoverer(t, fake, 0, Options{IncludeSharedWithMe: true})
+	records, err := d.DiscoverFromURLs(ctx, []string{"https://docs.google.com/document/d/doc/edit"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DiscoverFromURLs() error = %v, want %v", err, context.Canceled)
+	}
+	if len(records) != 0 {
+		t.Errorf("DiscoverFromURLs() = %+v, want no records", records)
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("made %d requests after cancellation, want none", len(fake.requests))
+	}
+}
+
+func TestOwnerQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "owners",
+			opts: Options{OwnerEmails: []string{"a@example.com", "b@example.com"}},
+			want: "(mimeType = 'application/vnd.google-apps.folder' or (('a@example.com' in owners or 'b@example.com' in owners)))",
+		},
+		{
+			name: "not owners",
+			opts: Options{NotOwnerEmails: []string{"bot@example.com", "o'neil@example.com"}},
+			want: `(mimeType = 'application/vnd.google-apps.folder' or (not 'bot@example.com' in owners and not 'o\'neil@example.com' in owners))`,
+		},
+		{
+			name: "both",
+			opts: Options{OwnerEmails: []string{"a@example.com"}, NotOwnerEmails: []string{"bot@example.com"}},
+			want: "(mimeType = 'application/vnd.google-apps.folder' or (('a@example.com' in owners) and not 'bot@example.com' in owners))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiscoverer(nil, false, 0, tt.opts)
+			if got := d.ownerQuery(); got != tt.want {
+				t.Errorf("ownerQuery() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnerAllowed(t *testing.T) {
+	owners := func(emails ...string) []*drive.User {
+		var users []*drive.User
+		for _, email := range emails {
+			users = append(users, &drive.User{EmailAddress: email})
+		}
+		return users
+	}
+
+	tests := []struct {
+		name   string
+		opts   Options
+		owners []*drive.User
+		want   bool
+	}{
+		{name: "no filter", owners: owners("x@example.com"), want: true},
+		{name: "owner matches", opts: Options{OwnerEmails: []string{"a@example.com"}}, owners: owners("A@Example.com"), want: true},
+		{name: "owner does not match", opts: Options{OwnerEmails: []string{"a@example.com"}}, owners: owners("x@example.com"), want: false},
+		{name: "no owners", opts: Options{OwnerEmails: []string{"a@example.com"}}, want: false},
+		{name: "excluded owner", opts: Options{NotOwnerEmails: []string{"bot@example.com"}}, owners: owners("bot@example.com"), want: false},
+		{name: "not excluded", opts: Options{NotOwnerEmails: []string{"bot@example.com"}}, owners: owners("a@example.com"), want: true},
+		{name: "no owners not excluded", opts: Options{NotOwnerEmails: []string{"bot@example.com"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiscoverer(nil, false, 0, tt.opts)
+			if got := d.ownerAllowed(tt.owners); got != tt.want {
+				t.Errorf("ownerAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverOwnerFilter(t *testing.T) {
+	employee := []*drive.User{{EmailAddress: "alice@example.com"}}
+	external := []*drive.User{{EmailAddress: "guest@partner.com"}}
+
+	tests := []struct {
+		name          string
+		sharedDriveID string
+		wantStatuses  map[string]string
+		wantQuery     bool
+	}{
+		{
+			name:         "personal drive filters in the query",
+			wantStatuses: map[string]string{"Internal": "available", "External": "available", "Linked external": "filtered_owner"},
+			wantQuery:    true,
+		},
+		{
+			name:          "shared drive filters listed files",
+			sharedDriveID: "sharedDrive123",
+			wantStatuses:  map[string]string{"Internal": "available", "External": "filtered_owner", "Linked external": "filtered_owner"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.addFile("", &drive.File{Id: "folder1", Name: "Docs", MimeType: "application/vnd.google-apps.folder"})
+			// The fake ignores owner queries, so both files are listed
+			fake.addFile("folder1", &drive.File{Id: "doc1", Name: "Internal", MimeType: "application/vnd.google-apps.document", Owners: employee})
+			fake.addFile("folder1", &drive.File{Id: "doc2", Name: "External", MimeType: "application/vnd.google-apps.document", Owners: external})
+			fake.addFile("", &drive.File{Id: "doc3", Name: "Linked external", MimeType: "application/vnd.google-apps.document", Owners: external})
+
+			d := newTestDiscoverer(t, fake, 0, Options{SharedDriveID: tt.sharedDriveID, OwnerEmails: []string{"alice@example.com"}})
+			records, err := d.DiscoverFromURLs(context.Background(), []string{
+				"https://drive.google.com/drive/folders/folder1",
+				"https://docs.google.com/document/d/doc3/edit",
+			})
+			if err != nil {
+				t.Fatalf("DiscoverFromURLs() error = %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, record := range records {
+				got[record.Title] = record.Status
+			}
+			if !reflect.DeepEqual(got, tt.wantStatuses) {
+				t.Errorf("statuses = %v, want %v", got, tt.wantStatuses)
+			}
+
+			query := fake.listRequests()[0].URL.Query().Get("q")
+			if hasClause := strings.Contains(query, "'alice@example.com' in owners"); hasClause != tt.wantQuery {
+				t.Errorf("query = %q, owner clause present = %v, want %v", query, hasClause, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestMimeAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   []string
+		mimeType string
+		want     bool
+	}{
+		{name: "empty filter", mimeType: "image/png", want: true},
+		{name: "exact match", filter: []string{"application/vnd.google-apps.document", "application/pdf"}, mimeType: "application/pdf", want: true},
+		{name: "exact mismatch", filter: []string{"application/vnd.google-apps.document"}, mimeType: "application/vnd.google-apps.spreadsheet", want: false},
+		{name: "wildcard match", filter: []string{"application/vnd.google-apps.*"}, mimeType: "application/vnd.google-apps.spreadsheet", want: true},
+		{name: "wildcard mismatch", filter: []string{"application/vnd.google-apps.*"}, mimeType: "application/pdf", want: false},
+		{name: "wildcard subtype", filter: []string{"image/*"}, mimeType: "image/png", want: true},
+		{name: "star does not match slash", filter: []string{"*"}, mimeType: "application/pdf", want: false},
+		{name: "case-insensitive", filter: []string{"Application/PDF"}, mimeType: "application/pdf", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiscoverer(nil, false, 0, Options{MimeFilter: tt.filter})
+			if got := d.mimeAllowed(tt.mimeType); got != tt.want {
+				t.Errorf("mimeAllowed(%q) = %v, want %v", tt.mimeType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverMimeFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter []string
+		want   []string
+	}{
+		{name: "empty filter", want: []string{"Doc", "Linked sheet", "Manual", "Nested doc", "Photo"}},
+		{name: "exact match", filter: []string{"application/pdf"}, want: []string{"Manual"}},
+		{name: "wildcard match", filter: []string{"application/vnd.google-apps.*"}, want: []string{"Doc", "Linked sheet", "Nested doc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.addFile("", &drive.File{Id: "root", Name: "Shared", MimeType: "application/vnd.google-apps.folder"})
+			fake.addFile("root", &drive.File{Id: "doc", Name: "Doc", MimeType: "application/vnd.google-apps.document"})
+			fake.addFile("root", &drive.File{Id: "manual", Name: "Manual", MimeType: "application/pdf"})
+			fake.addFile("root", &drive.File{Id: "photo", Name: "Photo", MimeType: "image/png"})
+			// Folders are searched whatever the filter
+			fake.addFile("root", &drive.File{Id: "sub", Name: "Sub", MimeType: "application/vnd.google-apps.folder"})
+			fake.addFile("sub", &drive.File{Id: "nested", Name: "Nested doc", MimeType: "application/vnd.google-apps.document"})
+			// Files given directly go through the same check as linked documents
+			fake.addFile("", &drive.File{Id: "sheet", Name: "Linked sheet", MimeType: "application/vnd.google-apps.spreadsheet"})
+
+			d := newTestDiscoverer(t, fake, 0, Options{MimeFilter: tt.filter})
+			records, err := d.DiscoverFromURLs(context.Background(), []string{
+				"https://drive.google.com/drive/folders/root",
+				"https://docs.google.com/spreadsheets/d/sheet/edit",
+			})
+			if err != nil {
+				t.Fatalf("DiscoverFromURLs() error = %v", err)
+			}
+
+			var got []string
+			for _, record := range records {
+				got = append(got, record.Title)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discovered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameAllowed(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "", name: "Anything", want: true},
+		{pattern: "RFC-*", name: "RFC-042 Storage", want: true},
+		{pattern: "RFC-*", name: "Draft RFC-042", want: false},
+		{pattern: "*Design Doc*", name: "Search Design Doc v2", want: true},
+		{pattern: "*Design Doc*", name: "search design doc", want: false}, // Case-sensitive
+	}
+
+	for _, tt := range tests {
+		d := NewDiscoverer(nil, false, 0, Options{NamePattern: tt.pattern})
+		if got := d.nameAllowed(tt.name); got != tt.want {
+			t.Errorf("nameAllowed(%q) with pattern %q = %v, want %v", tt.name, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverNamePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{name: "no pattern", want: []string{"Index", "Meeting notes", "RFC-1 Linked", "RFC-2 Top", "RFC-3 Nested"}},
+		{name: "pattern", pattern: "RFC-*", want: []string{"RFC-1 Linked", "RFC-2 Top", "RFC-3 Nested"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.addFile("", &drive.File{Id: "root", Name: "Design", MimeType: "application/vnd.google-apps.folder"})
+			fake.addFile("root", &drive.File{Id: "top", Name: "RFC-2 Top", MimeType: "application/vnd.google-apps.document"})
+			fake.addFile("root", &drive.File{Id: "notes", Name: "Meeting notes", MimeType: "application/vnd.google-apps.document"})
+			// Folder names do not have to match for their contents to be searched
+			fake.addFile("root", &drive.File{Id: "sub", Name: "Archive", MimeType: "application/vnd.google-apps.folder"})
+			fake.addFile("sub", &drive.File{Id: "nested", Name: "RFC-3 Nested", MimeType: "application/vnd.google-apps.document"})
+			// A document that does not match still has its links followed
+			fake.addFile("", &drive.File{Id: "index", Name: "Index", MimeType: "application/vnd.google-apps.document"})
+			fake.exports["index"] = "See https://docs.google.com/document/d/linked/edit"
+			fake.addFile("", &drive.File{Id: "linked", Name: "RFC-1 Linked", MimeType: "application/vnd.google-apps.document"})
+
+			d := newTestDiscoverer(t, fake, 1, Options{NamePattern: tt.pattern})
+			records, err := d.DiscoverFromURLs(context.Background(), []string{
+				"https://drive.google.com/drive/folders/root",
+				"https://docs.google.com/document/d/index/edit",
+			})
+			if err != nil {
+				t.Fatalf("DiscoverFromURLs() error = %v", err)
+			}
+
+			var got []string
+			for _, record := range records {
+				got = append(got, record.Title)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discovered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverFromRecordsDepth(t *testing.T) {
+	zero, two := 0, 2
+	tests := []struct {
+		name  string
+		depth *int
+		want  []string
+	}{
+		{name: "global depth", want: []string{"Index", "Linked"}},
+		{name: "depth 0", depth: &zero, want: []string{"Index"}},
+		{name: "deeper than global", depth: &two, want: []string{"Index", "Linked", "Second"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeDrive()
+			fake.addFile("", &drive.File{Id: "index", Name: "Index", MimeType: "application/vnd.google-apps.document"})
+			fake.exports["index"] = "See https://docs.google.com/document/d/linked/edit"
+			fake.addFile("", &drive.File{Id: "linked", Name: "Linked", MimeType: "application/vnd.google-apps.document"})
+			fake.exports["linked"] = "See https://docs.google.com/document/d/second/edit"
+			fake.addFile("", &drive.File{Id: "second", Name: "Second", MimeType: "application/vnd.google-apps.document"})
+
+			d := newTestDiscoverer(t, fake, 1, Options{})
+			records, err := d.DiscoverFromRecords(context.Background(), []csv.InputRecord{
+				{URL: "https://docs.google.com/document/d/index/edit", Depth: tt.depth},
+			})
+			if err != nil {
+				t.Fatalf("DiscoverFromRecords() error = %v", err)
+			}
+
+			var got []string
+			for _, record := range records {
+				got = append(got, record.Title)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discovered %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverFromURLsParallel(t *testing.T) {
+	fake := newFakeDrive()
+	fake.addFile("", &drive.File{Id: "common", Name: "Common", MimeType: "application/vnd.google-apps.document"})
+	var urls []string
+	for i := 0; i < 10; i++ {
+		folderID := fmt.Sprintf("folder%d", i)
+		fake.addFile("", &drive.File{Id: folderID, Name: fmt.Sprintf("Folder %d", i), MimeType: "application/vnd.google-apps.folder"})
+		for j := 0; j < 3; j++ {
+			fake.addFile(folderID, &drive.File{Id: fmt.Sprintf("doc%d-%d", i, j), Name: fmt.Sprintf("Doc %d-%d", i, j), MimeType: "application/vnd.google-apps.document"})
+		}
+		// Every folder holds the same document, which must be listed once
+		fake.addFile(folderID, fake.files["common"])
+		urls = append(urls, "https://drive.google.com/drive/folders/"+folderID)
+	}
+	// Two of the folders share another document
+	fake.addFile("folder7", &drive.File{Id: "pair", Name: "Pair", MimeType: "application/vnd.google-apps.document"})
+	fake.addFile("folder3", fake.files["pair"])
+
+	d := newTestDiscoverer(t, fake, 0, Options{Workers: 4})
+	records, err := d.DiscoverFromURLs(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("DiscoverFromURLs() error = %v", err)
+	}
+
+	if len(records) != 32 {
+		t.Errorf("Got %d records, want 32", len(records))
+	}
+	links := make(map[string]bool)
+	for _, record := range records {
+		if links[record.Link] {
+			t.Errorf("%s listed more than once", record.Link)
+		}
+		links[record.Link] = true
+	}
+	// Each folder is listed once
+	if reqs := fake.listRequests(); len(reqs) != 10 {
+		t.Errorf("made %d list requests, want 10", len(reqs))
+	}
+
+	// Shared documents are listed under the first input that reaches them
+	for _, record := range records {
+		want := map[string]string{"Common": "Folder 0", "Pair": "Folder 3"}[record.Title]
+		if want != "" && !reflect.DeepEqual(record.Breadcrumb, []string{want}) {
+			t.Errorf("%s breadcrumb = %v, want [%s]", record.Title, record.Breadcrumb, want)
+		}
+	}
+
+	// Whichever worker reaches a shared document first, every run gives the same records
+	for run := 0; run < 20; run++ {
+		again, err := newTestDiscoverer(t, fake, 0, Options{Workers: 4}).DiscoverFromURLs(context.Background(), urls)
+		if err != nil {
+			t.Fatalf("DiscoverFromURLs() error = %v", err)
+		}
+		if !reflect.DeepEqual(again, records) {
+			t.Fatalf("run %d gave different records:\n%v\nwant\n%v", run, again, records)
+		}
+	}
+
+	// Records are returned in input order, whichever worker finished first
+	previous := -1
+	for _, record := range records {
+		var folder, doc int
+		if _, err := fmt.Sscanf(record.Title, "Doc %d-%d", &folder, &doc); err != nil {
+			continue
+		}
+		if folder < previous {
+			t.Errorf("%s listed after a document of folder %d", record.Title, previous)
+		}
+		previous = folder
+	}
+}
+
+func TestDiscoverFromURLsOverlappingDepth(t *testing.T) {
+	// The first input reaches Shared at the maximum depth, where its links are not followed;
+	// the second input starts from it, so Linked is found through the second input
+	urls := []string{
+		"https://docs.google.com/document/d/index/edit",
+		"https://docs.google.com/document/d/shared/edit",
+	}
+	newFake := func() *fakeDrive {
+		fake := newFakeDrive()
+		fake.addFile("", &drive.File{Id: "index", Name: "Index", MimeType: "application/vnd.google-apps.document"})
+		fake.exports["index"] = "See https://docs.google.com/document/d/shared/edit"
+		fake.addFile("", &drive.File{Id: "shared", Name: "Shared", MimeType: "application/vnd.google-apps.document"})
+		fake.exports["shared"] = "See https://docs.google.com/document/d/linked/edit"
+		fake.addFile("", &drive.File{Id: "linked", Name: "Linked", MimeType: "application/vnd.google-apps.document"})
+		return fake
+	}
+
+	// One worker discovers the first input before the second; with two, holding back the
+	// first input lets the second reach Shared first
+	var runs [][]csv.DiscoveryRecord
+	for _, workers := range []int{1, 2} {
+		fake := newFake()
+		if workers > 1 {
+			fake.delays["index"] = 50 * time.Millisecond
+		}
+		records, err := newTestDiscoverer(t, fake, 1, Options{Workers: workers}).DiscoverFromURLs(context.Background(), urls)
+		if err != nil {
+			t.Fatalf("DiscoverFromURLs() error = %v", err)
+		}
+		runs = append(runs, records)
+
+		// Both inputs reach Shared, but it is fetched and exported once
+		for _, path := range []string{"/files/shared", "/files/shared/export"} {
+			n := 0
+			for _, r := range fake.requests {
+				if r.URL.Path == path {
+					n++
+				}
+			}
+			if n != 1 {
+				t.Errorf("%d workers: made %d requests to %s, want 1", workers, n, path)
+			}
+		}
+	}
+
+	var titles []string
+	for _, record := range runs[0] {
+		titles = append(titles, record.Title)
+	}
+	if want := []string{"Index", "Shared", "Linked"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("discovered %v, want %v", titles, want)
+	}
+	if !reflect.DeepEqual(runs[0], runs[1]) {
+		t.Errorf("input order changed the records:\n%v\nwant\n%v", runs[1], runs[0])
+	}
+}
+
+func TestExecuteFileWithRetry(t *testing.T) {
+	retry := utils.RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		failures  int
+		errCode   int
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "succeeds after rate limit", failures: 2, errCode: 429, wantCalls: 3},
+		{name: "gives up after max retries", failures: 5, errCode: 429, wantErr: true, wantCalls: 3},
+		{name: "non-retryable error", failures: 1, errCode: 404, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiscoverer(nil, false, 0, Options{Retry: retry})
+
+			calls := 0
+			_, err := d.executeFileWithRetry(func() (*drive.File, error) {
+				calls++
+				if calls <= tt.failures {
+					return nil, &googleapi.Error{Code: tt.errCode}
+				}
+				return &drive.File{Id: "file1"}, nil
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("executeFileWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("executeFileWithRetry() made %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
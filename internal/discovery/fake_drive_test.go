@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeDrive is a minimal in-memory Drive API server for discovery tests
+type fakeDrive struct {
+	mu       sync.Mutex
+	files    map[string]*drive.File   // File metadata by ID
+	children map[string][]*drive.File // Folder ID to child files
+	shared   []*drive.File            // Files in the "Shared with me" view
+	exports  map[string]string        // Exported markdown by file ID, searched for links
+	delays   map[string]time.Duration // Time to wait before answering a metadata request, by file ID
+	requests []*http.Request          // All requests received, in order
+}
+
+func newFakeDrive() *fakeDrive {
+	return &fakeDrive{
+		files:    make(map[string]*drive.File),
+		children: make(map[string][]*drive.File),
+		exports:  make(map[string]string),
+		delays:   make(map[string]time.Duration),
+	}
+}
+
+// addFile registers a file under the given parent folder ("" for none)
+func (f *fakeDrive) addFile(parentID string, file *drive.File) {
+	f.files[file.Id] = file
+	if parentID != "" {
+		f.children[parentID] = append(f.children[parentID], file)
+	}
+}
+
+// listRequests returns the Files.List requests received so far
+func (f *fakeDrive) listRequests() []*http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var reqs []*http.Request
+	for _, r := range f.requests {
+		if r.URL.Path == "/files" {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+func (f *fakeDrive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.URL.Path == "/files" {
+		// Only the "'<id>' in parents" and "sharedWithMe = true" forms of query are supported
+		query := r.URL.Query().Get("q")
+		if strings.HasPrefix(query, "sharedWithMe = true") {
+			json.NewEncoder(w).Encode(&drive.FileList{Files: f.shared})
+			return
+		}
+		parentID := strings.TrimPrefix(strings.SplitN(query, "'", 3)[1], "'")
+		json.NewEncoder(w).Encode(&drive.FileList{Files: f.children[parentID]})
+		return
+	}
+
+	if id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/files/"), "/export"); ok {
+		content, ok := f.exports[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"message":"File not found"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown")
+		w.Write([]byte(content))
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/files/")
+	time.Sleep(f.delays[id])
+	file, ok := f.files[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"message":"File not found"}}`))
+		return
+	}
+	json.NewEncoder(w).Encode(file)
+}
+
+// newTestDiscoverer returns a Discoverer backed by the fake Drive server
+func newTestDiscoverer(t *testing.T, fake *fakeDrive, maxDepth int, opts Options) *Discoverer {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	service, err := drive.NewService(context.Background(),
+		option.WithHTTPClient(server.Client()),
+		option.WithEndpoint(server.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create Drive service: %v", err)
+	}
+
+	return NewDiscoverer(service, false, maxDepth, opts)
+}
@@ -0,0 +1,292 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/sitemap"
+	"github.com/yourusername/webscrape-to-wikijs/internal/sync"
+)
+
+// Format selects the syntax of the generated feed
+type Format string
+
+const (
+	FormatAtom Format = "atom" // Atom 1.0, written to atom.xml (default)
+	FormatRSS2 Format = "rss2" // RSS 2.0, written to rss.xml
+)
+
+const (
+	// DefaultMaxItems is the number of most recently modified documents in the feed
+	DefaultMaxItems = 20
+
+	// DefaultTitle is the feed title used when none is configured
+	DefaultTitle = "Documentation updates"
+
+	// AtomFileName is the file an Atom feed is written to
+	AtomFileName = "atom.xml"
+
+	// RSSFileName is the file an RSS 2.0 feed is written to
+	RSSFileName = "rss.xml"
+
+	// summaryLength is the number of characters of body content in an entry summary
+	summaryLength = 200
+)
+
+// ValidateFormat returns an error if format is not a supported feed format
+func ValidateFormat(format string) error {
+	switch Format(format) {
+	case FormatAtom, FormatRSS2:
+		return nil
+	default:
+		return fmt.Errorf("unknown feed format %q (want %q or %q)", format, FormatAtom, FormatRSS2)
+	}
+}
+
+// Options configures a generated feed
+type Options struct {
+	BaseURL  string // Wiki URL that document paths are appended to
+	Title    string // Feed title (empty = DefaultTitle)
+	Author   string // Feed author (empty = the title)
+	Format   Format // Feed syntax (empty = FormatAtom)
+	MaxItems int    // Number of entries (0 = DefaultMaxItems)
+}
+
+// Entry is a document in the feed
+type Entry struct {
+	Title   string
+	URL     string
+	Updated time.Time
+	Summary string
+}
+
+// Collect returns an entry for every published markdown file under outputDir with a hash-gdrive
+// frontmatter field, newest first. Entries are dated by the Drive modifiedTime in hash-gdrive,
+// or by the file's modification time when hash-gdrive holds a revision ID. Stubs are left out.
+func Collect(outputDir, baseURL string) ([]Entry, error) {
+	var entries []Entry
+
+	err := filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		frontmatter, body, err := sync.ParseFrontmatter(string(content))
+		if err != nil || frontmatter["published"] == "false" {
+			return nil
+		}
+		hash := frontmatter["hash-gdrive"]
+		if hash == "" || hash == "stub" {
+			return nil
+		}
+		updated, err := time.Parse(time.RFC3339, hash)
+		if err != nil {
+			// A head revision ID, which carries no date; the file was written when it was synced
+			updated = info.ModTime()
+		}
+
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		title := frontmatter["title"]
+		if title == "" {
+			title = strings.TrimSuffix(filepath.Base(path), ".md")
+		}
+
+		entries = append(entries, Entry{
+			Title:   title,
+			URL:     sitemap.DocumentURL(baseURL, rel),
+			Updated: updated.UTC(),
+			Summary: summarize(body),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Updated.Equal(entries[j].Updated) {
+			return entries[i].Updated.After(entries[j].Updated)
+		}
+		return entries[i].URL < entries[j].URL
+	})
+	return entries, nil
+}
+
+// summarize returns the first characters of body with whitespace collapsed
+func summarize(body string) string {
+	summary := []rune(strings.Join(strings.Fields(body), " "))
+	if len(summary) > summaryLength {
+		summary = summary[:summaryLength]
+	}
+	return string(summary)
+}
+
+// Generate writes a feed of the most recently modified documents under outputDir and returns
+// the name of the written file
+func Generate(outputDir string, opts Options) (string, error) {
+	if opts.Title == "" {
+		opts.Title = DefaultTitle
+	}
+	if opts.Author == "" {
+		opts.Author = opts.Title
+	}
+	if opts.Format == "" {
+		opts.Format = FormatAtom
+	}
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = DefaultMaxItems
+	}
+
+	entries, err := Collect(outputDir, opts.BaseURL)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) > opts.MaxItems {
+		entries = entries[:opts.MaxItems]
+	}
+
+	var name string
+	var doc any
+	switch opts.Format {
+	case FormatRSS2:
+		name, doc = RSSFileName, buildRSS(entries, opts)
+	default:
+		name, doc = AtomFileName, buildAtom(entries, opts)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := os.WriteFile(filepath.Join(outputDir, name), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return name, nil
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Summary string   `xml:"summary,omitempty"`
+	Link    atomLink `xml:"link"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// buildAtom builds an Atom 1.0 feed. The feed is identified by the base URL and is as recent
+// as its newest entry.
+func buildAtom(entries []Entry, opts Options) atomFeed {
+	baseURL := strings.TrimRight(opts.BaseURL, "/")
+	feed := atomFeed{
+		Title:  opts.Title,
+		ID:     baseURL + "/",
+		Author: atomPerson{Name: opts.Author},
+		Links: []atomLink{
+			{Href: baseURL + "/"},
+			{Href: baseURL + "/" + AtomFileName, Rel: "self"},
+		},
+		Updated: time.Now().UTC().Format(time.RFC3339),
+	}
+	if len(entries) > 0 {
+		feed.Updated = entries[0].Updated.Format(time.RFC3339)
+	}
+
+	for _, entry := range entries {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   entry.Title,
+			ID:      entry.URL,
+			Updated: entry.Updated.Format(time.RFC3339),
+			Summary: entry.Summary,
+			Link:    atomLink{Href: entry.URL},
+		})
+	}
+	return feed
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// buildRSS builds an RSS 2.0 feed. RSS authors must be e-mail addresses, so the configured
+// author is not used.
+func buildRSS(entries []Entry, opts Options) rssFeed {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       opts.Title,
+			Link:        strings.TrimRight(opts.BaseURL, "/") + "/",
+			Description: opts.Title,
+		},
+	}
+	if len(entries) > 0 {
+		feed.Channel.LastBuildDate = entries[0].Updated.Format(time.RFC1123Z)
+	}
+
+	for _, entry := range entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       entry.Title,
+			Link:        entry.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: entry.URL},
+			PubDate:     entry.Updated.Format(time.RFC1123Z),
+			Description: entry.Summary,
+		})
+	}
+	return feed
+}
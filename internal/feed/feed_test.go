@@ -0,0 +1,213 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeDocs(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func doc(title, modified, published, body string) string {
+	return fmt.Sprintf("---\nhash-gdrive: %q\npublished: %s\ntitle: %s\n---\n\n%s", modified, published, title, body)
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	writeDocs(t, dir, map[string]string{
+		"ops/runbook.md": doc("Runbook", "2024-03-01T10:00:00.000Z", "true", "Restart the\n\nservice.\n"),
+		"ops/old.md":     doc("Old", "2023-01-01T10:00:00.000Z", "true", "Old content.\n"),
+		"ops/draft.md":   doc("Draft", "2024-05-01T10:00:00.000Z", "false", "Draft.\n"),
+		"forms/form.md":  doc("Form", "stub", "true", "Stub.\n"),
+		"plain.md":       "no frontmatter\n",
+		"long.md":        doc("Long", "2024-02-01T10:00:00.000Z", "true", strings.Repeat("é", 250)),
+		"manual.md":      doc("Manual", "0B-rev42", "true", "Manual.\n"),
+	})
+	revised := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(dir, "manual.md"), revised, revised); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	entries, err := Collect(dir, "https://wiki.example.com/")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	var urls []string
+	for _, entry := range entries {
+		urls = append(urls, entry.URL)
+	}
+	want := []string{
+		"https://wiki.example.com/manual",
+		"https://wiki.example.com/ops/runbook",
+		"https://wiki.example.com/long",
+		"https://wiki.example.com/ops/old",
+	}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Fatalf("Collect() URLs = %v, want %v", urls, want)
+	}
+
+	if !entries[0].Updated.Equal(revised) {
+		t.Errorf("Updated = %v, want the file time for a revision ID hash", entries[0].Updated)
+	}
+	if entries[1].Title != "Runbook" || entries[1].Summary != "Restart the service." {
+		t.Errorf("entry = %+v, want title Runbook and collapsed summary", entries[1])
+	}
+	if !entries[1].Updated.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Updated = %v", entries[1].Updated)
+	}
+	if n := len([]rune(entries[2].Summary)); n != summaryLength {
+		t.Errorf("summary has %d characters, want %d", n, summaryLength)
+	}
+}
+
+// atomDoc mirrors the elements RFC 4287 requires of a feed and its entries
+type atomDoc struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Author  []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Entries []struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Updated string `xml:"updated"`
+		Summary string `xml:"summary"`
+		Link    struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+func TestGenerateAtom(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{}
+	for i := 1; i <= 3; i++ {
+		files[fmt.Sprintf("doc%d.md", i)] = doc(fmt.Sprintf("Doc & %d", i), fmt.Sprintf("2024-0%d-01T10:00:00.000Z", i), "true", "Body.\n")
+	}
+	writeDocs(t, dir, files)
+
+	name, err := Generate(dir, Options{BaseURL: "https://wiki.example.com", Title: "Ops docs", Author: "Ops team", MaxItems: 2})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if name != AtomFileName {
+		t.Errorf("Generate() wrote %s, want %s", name, AtomFileName)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed atomDoc
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("atom.xml is not an Atom feed: %v\n%s", err, data)
+	}
+
+	if feed.ID == "" || feed.Title != "Ops docs" || len(feed.Author) != 1 || feed.Author[0].Name != "Ops team" {
+		t.Errorf("feed metadata = %+v", feed)
+	}
+	if feed.Updated != "2024-03-01T10:00:00Z" {
+		t.Errorf("feed updated = %s, want the newest entry", feed.Updated)
+	}
+	hasSelf := false
+	for _, link := range feed.Links {
+		hasSelf = hasSelf || (link.Rel == "self" && link.Href == "https://wiki.example.com/atom.xml")
+	}
+	if !hasSelf {
+		t.Errorf("feed has no self link: %+v", feed.Links)
+	}
+
+	if len(feed.Entries) != 2 {
+		t.Fatalf("feed has %d entries, want 2", len(feed.Entries))
+	}
+	entry := feed.Entries[0]
+	if entry.ID != "https://wiki.example.com/doc3" || entry.Title != "Doc & 3" || entry.Link.Href != entry.ID || entry.Summary != "Body." {
+		t.Errorf("entry = %+v", entry)
+	}
+	for _, e := range feed.Entries {
+		if _, err := time.Parse(time.RFC3339, e.Updated); err != nil {
+			t.Errorf("entry updated %q is not an RFC 3339 date", e.Updated)
+		}
+	}
+}
+
+func TestGenerateRSS(t *testing.T) {
+	dir := t.TempDir()
+	writeDocs(t, dir, map[string]string{
+		"ops/runbook.md": doc("Runbook", "2024-03-01T10:00:00.000Z", "true", "Restart the service.\n"),
+	})
+
+	name, err := Generate(dir, Options{BaseURL: "https://wiki.example.com", Format: FormatRSS2})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if name != RSSFileName {
+		t.Errorf("Generate() wrote %s, want %s", name, RSSFileName)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			Items       []struct {
+				Title   string `xml:"title"`
+				Link    string `xml:"link"`
+				GUID    string `xml:"guid"`
+				PubDate string `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("rss.xml is not XML: %v", err)
+	}
+
+	if feed.Version != "2.0" || feed.Channel.Title != DefaultTitle || feed.Channel.Link == "" || feed.Channel.Description == "" {
+		t.Errorf("channel = %+v", feed.Channel)
+	}
+	if len(feed.Channel.Items) != 1 {
+		t.Fatalf("channel has %d items, want 1", len(feed.Channel.Items))
+	}
+	item := feed.Channel.Items[0]
+	if item.Link != "https://wiki.example.com/ops/runbook" || item.GUID != item.Link || item.PubDate != "Fri, 01 Mar 2024 10:00:00 +0000" {
+		t.Errorf("item = %+v", item)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{"atom", "rss2"} {
+		if err := ValidateFormat(format); err != nil {
+			t.Errorf("ValidateFormat(%q) error = %v", format, err)
+		}
+	}
+	if err := ValidateFormat("rss1"); err == nil {
+		t.Error("ValidateFormat(\"rss1\") expected error")
+	}
+}
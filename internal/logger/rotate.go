@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+const (
+	// DefaultMaxSizeMB is the default size in megabytes at which a log file is rotated
+	DefaultMaxSizeMB = 100
+
+	// DefaultMaxBackups is the default number of rotated log files kept
+	DefaultMaxBackups = 3
+)
+
+// RotatingWriter appends to a log file and rotates it once it would grow past a maximum size.
+// Rotated files are renamed to <path>.1, <path>.2, ... with .1 the most recent. It is safe for
+// concurrent use.
+type RotatingWriter struct {
+	path       string
+	maxSize    int64 // Bytes; 0 = never rotate
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingWriter opens path for appending, creating it with 0600 permissions. A maxSizeMB of
+// 0 never rotates; a maxBackups of 0 keeps no rotated files.
+func NewRotatingWriter(path string, maxSizeMB, maxBackups int) (*RotatingWriter, error) {
+	if maxSizeMB < 0 {
+		return nil, fmt.Errorf("max size must not be negative, got %d", maxSizeMB)
+	}
+	if maxBackups < 0 {
+		return nil, fmt.Errorf("max backups must not be negative, got %d", maxBackups)
+	}
+
+	w := &RotatingWriter{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the log file, rotating first when p would take it past the maximum size.
+// A single write larger than the maximum is written whole to a fresh file.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups up by one, dropping the oldest, and starts a new log file
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	if w.maxBackups == 0 {
+		if err := os.Remove(w.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+		return w.open()
+	}
+
+	if err := os.Remove(w.backupPath(w.maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove old log file: %w", err)
+	}
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(w.backupPath(i), w.backupPath(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	return w.open()
+}
+
+func (w *RotatingWriter) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
+
+// Setup sends the standard logger's output to a rotating log file at path, and also to stderr
+// when stderr is true. An empty path leaves the output on stderr. The returned writer is nil
+// without a log file.
+func Setup(path string, stderr bool, maxSizeMB, maxBackups int) (*RotatingWriter, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	w, err := NewRotatingWriter(path, maxSizeMB, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	var out io.Writer = w
+	if stderr {
+		out = io.MultiWriter(os.Stderr, w)
+	}
+	log.SetOutput(out)
+	return w, nil
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestWriter returns a writer on path that rotates above maxSize bytes
+func newTestWriter(t *testing.T, path string, maxSize int64, maxBackups int) *RotatingWriter {
+	t.Helper()
+	w, err := NewRotatingWriter(path, 1, maxBackups)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter() error = %v", err)
+	}
+	w.maxSize = maxSize
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) error = %v", path, err)
+	}
+	return string(data)
+}
+
+func TestRotatingWriterAppendsWithPrivatePermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawler.log")
+	if err := os.WriteFile(path, []byte("earlier run\n"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	w := newTestWriter(t, path, 0, DefaultMaxBackups)
+	w.Write([]byte("this run\n"))
+
+	if got := readFile(t, path); got != "earlier run\nthis run\n" {
+		t.Errorf("log = %q, want the earlier run kept", got)
+	}
+
+	fresh := filepath.Join(t.TempDir(), "new.log")
+	newTestWriter(t, fresh, 0, DefaultMaxBackups)
+	info, err := os.Stat(fresh)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permissions = %o, want 600", perm)
+	}
+}
+
+func TestRotatingWriterRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawler.log")
+	w := newTestWriter(t, path, 10, 2)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	// Each line fills most of the 10 bytes, so every write starts a new file and only two
+	// backups are kept
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for file, content := range want {
+		if got := readFile(t, file); got != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s.3 exists, want at most 2 backups", filepath.Base(path))
+	}
+}
+
+func TestRotatingWriterNoBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawler.log")
+	w := newTestWriter(t, path, 10, 0)
+
+	w.Write([]byte("first\n"))
+	w.Write([]byte("second\n"))
+
+	if got := readFile(t, path); got != "second\n" {
+		t.Errorf("log = %q, want only the latest write", got)
+	}
+	if _, err := os.Stat(path + ".1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("backup exists with max backups 0")
+	}
+}
+
+func TestNewRotatingWriterInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawler.log")
+	if _, err := NewRotatingWriter(path, -1, 3); err == nil || !strings.Contains(err.Error(), "max size") {
+		t.Errorf("NewRotatingWriter() negative size error = %v, want error", err)
+	}
+	if _, err := NewRotatingWriter(path, 100, -1); err == nil || !strings.Contains(err.Error(), "max backups") {
+		t.Errorf("NewRotatingWriter() negative backups error = %v, want error", err)
+	}
+	if _, err := NewRotatingWriter(filepath.Join(path, "missing", "crawler.log"), 100, 3); err == nil {
+		t.Errorf("NewRotatingWriter() unopenable path error = nil, want error")
+	}
+}
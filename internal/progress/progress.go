@@ -0,0 +1,95 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// Statuses of a completed record
+const (
+	StatusOK       = "ok"
+	StatusError    = "error"
+	StatusFinished = "finished" // Status of the summary event written by Finish
+)
+
+// Event is one line of progress output
+type Event struct {
+	File      string `json:"file,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	ElapsedMS int64  `json:"elapsed_ms"` // Milliseconds since Start
+	Total     int    `json:"total"`
+	Done      int    `json:"done"`
+	Errors    int    `json:"errors,omitempty"` // Records completed with an error, in the Finish event
+}
+
+// ProgressReporter writes a JSON object per line to its writer each time a record completes,
+// so long runs can be followed by scripts and dashboards. It is safe for concurrent use.
+type ProgressReporter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	now     func() time.Time
+	started time.Time
+	total   int
+	done    int
+	errors  int
+}
+
+// NewProgressReporter returns a reporter writing to w
+func NewProgressReporter(w io.Writer) *ProgressReporter {
+	return &ProgressReporter{w: w, now: time.Now}
+}
+
+// Start begins a run of total records
+func (p *ProgressReporter) Start(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.started = p.now()
+	p.total = total
+	p.done = 0
+	p.errors = 0
+}
+
+// RecordDone reports that the record for path completed with status, StatusOK or StatusError
+func (p *ProgressReporter) RecordDone(path, status string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	if status == StatusError {
+		p.errors++
+	}
+	event := Event{File: path, Status: status, ElapsedMS: p.elapsedMS(), Total: p.total, Done: p.done}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	p.write(event)
+}
+
+// Finish reports the end of the run with the number of records completed and failed
+func (p *ProgressReporter) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.write(Event{Status: StatusFinished, ElapsedMS: p.elapsedMS(), Total: p.total, Done: p.done, Errors: p.errors})
+}
+
+func (p *ProgressReporter) elapsedMS() int64 {
+	return p.now().Sub(p.started).Milliseconds()
+}
+
+// write writes event as a line; failures are logged, since progress output never stops a run
+func (p *ProgressReporter) write(event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Warning: failed to encode progress: %v", err)
+		return
+	}
+	if _, err := p.w.Write(append(data, '\n')); err != nil {
+		log.Printf("Warning: failed to write progress: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package progress
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// decodeEvents returns the events written as JSON lines
+func decodeEvents(t *testing.T, data []byte) []Event {
+	t.Helper()
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q is not a JSON event: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestProgressReporter(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgressReporter(&buf)
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return clock }
+
+	p.Start(3)
+	clock = clock.Add(120 * time.Millisecond)
+	p.RecordDone("https://example.com/a", StatusOK, nil)
+	clock = clock.Add(80 * time.Millisecond)
+	p.RecordDone("https://example.com/b", StatusError, errors.New("export failed"))
+	clock = clock.Add(50 * time.Millisecond)
+	p.RecordDone("https://example.com/c", StatusOK, nil)
+	p.Finish()
+
+	want := []Event{
+		{File: "https://example.com/a", Status: StatusOK, ElapsedMS: 120, Total: 3, Done: 1},
+		{File: "https://example.com/b", Status: StatusError, Error: "export failed", ElapsedMS: 200, Total: 3, Done: 2},
+		{File: "https://example.com/c", Status: StatusOK, ElapsedMS: 250, Total: 3, Done: 3},
+		{Status: StatusFinished, ElapsedMS: 250, Total: 3, Done: 3, Errors: 1},
+	}
+	got := decodeEvents(t, buf.Bytes())
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d:\n%s", len(got), len(want), buf.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestProgressReporterKeys(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgressReporter(&buf)
+	p.Start(1)
+	p.RecordDone("doc", StatusOK, nil)
+
+	var object map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &object); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	for _, key := range []string{"file", "status", "elapsed_ms", "total", "done"} {
+		if _, ok := object[key]; !ok {
+			t.Errorf("event %s is missing %q", buf.String(), key)
+		}
+	}
+	if _, ok := object["error"]; ok {
+		t.Errorf("successful event %s has an error", buf.String())
+	}
+}
@@ -0,0 +1,230 @@
+package search
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/conversion"
+	"github.com/yourusername/webscrape-to-wikijs/internal/sync"
+)
+
+// DefaultDuplicateThreshold is the Hamming distance below which documents are duplicates
+const DefaultDuplicateThreshold = 3
+
+// CanonicalLinkKey is the frontmatter field pointing duplicates at their canonical document
+const CanonicalLinkKey = "canonical_link"
+
+// DuplicateDocument is a converted document that belongs to a duplicate group
+type DuplicateDocument struct {
+	File     string // Path relative to the output directory
+	Path     string // Path on disk
+	Link     string // Google Drive link from the frontmatter
+	Modified string // hash-gdrive frontmatter field: a Drive modifiedTime, or a revision ID without a date
+}
+
+// DuplicatePair is two documents whose fingerprints are within the threshold
+type DuplicatePair struct {
+	GroupID  int
+	File1    string
+	File2    string
+	Link1    string
+	Link2    string
+	Distance int // Hamming distance between the SimHash fingerprints
+}
+
+// Identical reports whether the pair has the same fingerprint
+func (p DuplicatePair) Identical() bool {
+	return p.Distance == 0
+}
+
+// DuplicateGroup is a set of documents connected by duplicate pairs
+type DuplicateGroup struct {
+	ID        int
+	Documents []DuplicateDocument // In file order
+	Pairs     []DuplicatePair
+}
+
+// Canonical returns the document that was modified first. Documents without a parseable
+// modification time come last; ties keep file order.
+func (g DuplicateGroup) Canonical() DuplicateDocument {
+	best := g.Documents[0]
+	bestTime, bestOK := parseModified(best.Modified)
+	for _, doc := range g.Documents[1:] {
+		t, ok := parseModified(doc.Modified)
+		if ok && (!bestOK || t.Before(bestTime)) {
+			best, bestTime, bestOK = doc, t, true
+		}
+	}
+	return best
+}
+
+func parseModified(value string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, value)
+	return t, err == nil
+}
+
+// FindDuplicates computes a SimHash fingerprint of the body of every markdown file under
+// outputDir and groups files whose fingerprints differ in fewer than threshold bits. Files
+// without any terms are ignored.
+func FindDuplicates(outputDir string, threshold int) ([]DuplicateGroup, error) {
+	docs, err := readDocuments(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []int
+	var fingerprints []uint64
+	for i, doc := range docs {
+		if len(Tokenize(doc.Text())) == 0 {
+			continue
+		}
+		indexes = append(indexes, i)
+		fingerprints = append(fingerprints, SimHash(doc.Text()))
+	}
+
+	// Union-find over the documents, joined by every pair within the threshold
+	parent := make([]int, len(indexes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type pair struct{ a, b, distance int }
+	var pairs []pair
+	inPair := make([]bool, len(indexes))
+	for a := range indexes {
+		for b := a + 1; b < len(indexes); b++ {
+			distance := HammingDistance(fingerprints[a], fingerprints[b])
+			if distance >= threshold {
+				continue
+			}
+			pairs = append(pairs, pair{a, b, distance})
+			inPair[a], inPair[b] = true, true
+			if ra, rb := find(a), find(b); ra != rb {
+				parent[max(ra, rb)] = min(ra, rb)
+			}
+		}
+	}
+
+	// Number groups in order of their first document
+	var groups []DuplicateGroup
+	groupOf := make(map[int]int)
+	member := func(a int) DuplicateDocument {
+		doc := docs[indexes[a]]
+		return DuplicateDocument{
+			File:     doc.File,
+			Path:     doc.Path,
+			Link:     doc.Frontmatter["gdrive-link"],
+			Modified: doc.Frontmatter["hash-gdrive"],
+		}
+	}
+	for a := range indexes {
+		if !inPair[a] {
+			continue
+		}
+		root := find(a)
+		g, ok := groupOf[root]
+		if !ok {
+			g = len(groups)
+			groupOf[root] = g
+			groups = append(groups, DuplicateGroup{ID: g + 1})
+		}
+		groups[g].Documents = append(groups[g].Documents, member(a))
+	}
+
+	for _, p := range pairs {
+		g := &groups[groupOf[find(p.a)]]
+		doc1, doc2 := member(p.a), member(p.b)
+		g.Pairs = append(g.Pairs, DuplicatePair{
+			GroupID:  g.ID,
+			File1:    doc1.File,
+			File2:    doc2.File,
+			Link1:    doc1.Link,
+			Link2:    doc2.Link,
+			Distance: p.distance,
+		})
+	}
+
+	return groups, nil
+}
+
+// SetCanonicalLinks adds a canonical_link frontmatter field to every document of each group
+// except its canonical one, pointing at the canonical document's Drive link. Documents without
+// frontmatter are skipped. The files that were updated are returned.
+func SetCanonicalLinks(groups []DuplicateGroup) ([]string, error) {
+	var updated []string
+	for _, group := range groups {
+		canonical := group.Canonical()
+		if canonical.Link == "" {
+			continue
+		}
+
+		for _, doc := range group.Documents {
+			if doc.Path == canonical.Path {
+				continue
+			}
+
+			content, err := os.ReadFile(doc.Path)
+			if err != nil {
+				return updated, fmt.Errorf("failed to read %s: %w", doc.File, err)
+			}
+			frontmatter, tags, body, err := sync.ParseFrontmatterWithTags(string(content))
+			if err != nil {
+				continue
+			}
+
+			frontmatter[CanonicalLinkKey] = canonical.Link
+			format := sync.DetectFrontmatterFormat(string(content))
+			if err := os.WriteFile(doc.Path, []byte(conversion.RenderFrontmatter(frontmatter, tags, format)+body), 0644); err != nil {
+				return updated, fmt.Errorf("failed to write %s: %w", doc.File, err)
+			}
+			updated = append(updated, doc.File)
+		}
+	}
+
+	return updated, nil
+}
+
+// WriteDuplicatesCSV writes duplicate pairs to a CSV file with the columns group_id, file1,
+// file2, similarity_score, drive_link1 and drive_link2
+func WriteDuplicatesCSV(filePath string, groups []DuplicateGroup) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output CSV: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"group_id", "file1", "file2", "similarity_score", "drive_link1", "drive_link2"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, group := range groups {
+		for _, p := range group.Pairs {
+			row := []string{
+				strconv.Itoa(p.GroupID),
+				p.File1,
+				p.File2,
+				strconv.FormatFloat(Similarity(p.Distance), 'f', 3, 64),
+				p.Link1,
+				p.Link2,
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	return writer.Error()
+}
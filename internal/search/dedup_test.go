@@ -0,0 +1,105 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const runbookBody = "Restart the payment service, check the queue depth and page the on-call engineer " +
+	"when the error rate stays above five percent for ten minutes after the deploy.\n"
+
+func writeDoc(t *testing.T, dir, name, link, modified, body string) {
+	t.Helper()
+	content := "---\ngdrive-link: " + link + "\nhash-gdrive: \"" + modified + "\"\ntitle: " + name + "\n---\n\n" + body
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeDoc(t, dir, "a/runbook.md", "https://docs.google.com/document/d/a/edit", "2024-03-01T10:00:00.000Z", runbookBody)
+	writeDoc(t, dir, "b/runbook-copy.md", "https://docs.google.com/document/d/b/edit", "2023-06-01T10:00:00.000Z", runbookBody)
+	writeDoc(t, dir, "c/planning.md", "https://docs.google.com/document/d/c/edit", "2024-01-01T10:00:00.000Z",
+		"Quarterly planning starts with a review of last quarter's objectives and budget.\n")
+	writeDoc(t, dir, "d/empty.md", "https://docs.google.com/document/d/d/edit", "2024-01-01T10:00:00.000Z", "")
+	writeDoc(t, dir, "e/empty.md", "https://docs.google.com/document/d/e/edit", "2024-01-01T10:00:00.000Z", "")
+
+	groups, err := FindDuplicates(dir, DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatalf("FindDuplicates() error = %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("FindDuplicates() = %d groups, want 1: %+v", len(groups), groups)
+	}
+
+	group := groups[0]
+	if len(group.Documents) != 2 || len(group.Pairs) != 1 {
+		t.Fatalf("group = %+v, want 2 documents and 1 pair", group)
+	}
+	p := group.Pairs[0]
+	if p.File1 != "a/runbook.md" || p.File2 != "b/runbook-copy.md" || !p.Identical() || p.GroupID != 1 {
+		t.Errorf("pair = %+v, want identical a/runbook.md and b/runbook-copy.md in group 1", p)
+	}
+	if canonical := group.Canonical(); canonical.File != "b/runbook-copy.md" {
+		t.Errorf("Canonical() = %s, want the older b/runbook-copy.md", canonical.File)
+	}
+
+	csvPath := filepath.Join(t.TempDir(), "duplicates.csv")
+	if err := WriteDuplicatesCSV(csvPath, groups); err != nil {
+		t.Fatalf("WriteDuplicatesCSV() error = %v", err)
+	}
+	data, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "group_id,file1,file2,similarity_score,drive_link1,drive_link2\n" +
+		"1,a/runbook.md,b/runbook-copy.md,1.000,https://docs.google.com/document/d/a/edit,https://docs.google.com/document/d/b/edit\n"
+	if string(data) != want {
+		t.Errorf("CSV = %q, want %q", data, want)
+	}
+}
+
+func TestSetCanonicalLinks(t *testing.T) {
+	dir := t.TempDir()
+	writeDoc(t, dir, "a/runbook.md", "https://docs.google.com/document/d/a/edit", "2024-03-01T10:00:00.000Z", runbookBody)
+	writeDoc(t, dir, "b/runbook-copy.md", "https://docs.google.com/document/d/b/edit", "2023-06-01T10:00:00.000Z", runbookBody)
+
+	groups, err := FindDuplicates(dir, DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatalf("FindDuplicates() error = %v", err)
+	}
+
+	updated, err := SetCanonicalLinks(groups)
+	if err != nil {
+		t.Fatalf("SetCanonicalLinks() error = %v", err)
+	}
+	if len(updated) != 1 || updated[0] != "a/runbook.md" {
+		t.Errorf("SetCanonicalLinks() updated %v, want [a/runbook.md]", updated)
+	}
+
+	newer, err := os.ReadFile(filepath.Join(dir, "a/runbook.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(newer), "---\ncanonical_link: \"https://docs.google.com/document/d/b/edit\"\n") {
+		t.Errorf("canonical_link missing:\n%s", newer)
+	}
+	if !strings.HasSuffix(string(newer), "---\n\n"+runbookBody) {
+		t.Errorf("body changed:\n%s", newer)
+	}
+
+	older, err := os.ReadFile(filepath.Join(dir, "b/runbook-copy.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(older), CanonicalLinkKey) {
+		t.Errorf("canonical document was given a canonical_link:\n%s", older)
+	}
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/sync"
+)
+
+// linkTargetPattern matches the target of a markdown link or image, which is not prose
+var linkTargetPattern = regexp.MustCompile(`\]\([^)]*\)`)
+
+// document is a converted markdown file
+type document struct {
+	File        string            // Path relative to the output directory, with forward slashes
+	Path        string            // Path on disk
+	Frontmatter map[string]string // Parsed frontmatter (nil without frontmatter)
+	Body        string            // Content after the frontmatter
+}
+
+// Text returns the prose of the body, without link targets
+func (d document) Text() string {
+	return linkTargetPattern.ReplaceAllString(d.Body, "]")
+}
+
+// readDocuments reads every markdown file under outputDir in lexical order
+func readDocuments(outputDir string) ([]document, error) {
+	var docs []document
+
+	err := filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+
+		doc := document{File: filepath.ToSlash(rel), Path: path, Body: string(content)}
+		if frontmatter, body, err := sync.ParseFrontmatter(doc.Body); err == nil {
+			doc.Frontmatter = frontmatter
+			doc.Body = body
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+
+	return docs, err
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"hash/fnv"
+	"math/bits"
+)
+
+// SimHashBits is the size of a SimHash fingerprint
+const SimHashBits = 64
+
+// SimHash returns a 64-bit SimHash fingerprint of text. Each term votes on every bit with the
+// bits of its FNV-1a hash, weighted by how often it occurs, so similar texts get fingerprints
+// with a small Hamming distance. Text without terms has the fingerprint 0.
+func SimHash(text string) uint64 {
+	var votes [SimHashBits]int
+	for _, term := range Tokenize(text) {
+		h := fnv.New64a()
+		h.Write([]byte(term))
+		sum := h.Sum64()
+		for bit := 0; bit < SimHashBits; bit++ {
+			if sum&(1<<bit) != 0 {
+				votes[bit]++
+			} else {
+				votes[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, vote := range votes {
+		if vote > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// HammingDistance returns the number of bits in which a and b differ
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similarity returns the share of equal bits for a Hamming distance between fingerprints
+func Similarity(distance int) float64 {
+	return 1 - float64(distance)/SimHashBits
+}
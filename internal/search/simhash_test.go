@@ -0,0 +1,44 @@
+package search
+
+import "testing"
+
+func TestSimHash(t *testing.T) {
+	base := "Restart the payment service, check the queue depth and page the on-call engineer " +
+		"when the error rate stays above five percent for ten minutes after the deploy."
+	edited := "Restart the payment service, check the queue depth and page the on-call engineer " +
+		"when the error rate stays above five percent for fifteen minutes after the deploy."
+	other := "Quarterly planning starts with a review of last quarter's objectives and budget."
+
+	if SimHash(base) != SimHash(base) {
+		t.Error("SimHash() is not deterministic")
+	}
+	if d := HammingDistance(SimHash(base), SimHash(edited)); d > 10 {
+		t.Errorf("distance of a small edit = %d, want at most 10", d)
+	}
+	if d := HammingDistance(SimHash(base), SimHash(other)); d < 10 {
+		t.Errorf("distance of unrelated text = %d, want at least 10", d)
+	}
+	if got := SimHash(""); got != 0 {
+		t.Errorf("SimHash(\"\") = %x, want 0", got)
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{a: 0, b: 0, want: 0},
+		{a: 0b1010, b: 0b0110, want: 2},
+		{a: 0, b: ^uint64(0), want: 64},
+	}
+	for _, tt := range tests {
+		if got := HammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("HammingDistance(%b, %b) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if got := Similarity(16); got != 0.75 {
+		t.Errorf("Similarity(16) = %v, want 0.75", got)
+	}
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// DefaultTopN is the number of tags suggested per document
+const DefaultTopN = 5
+
+// TagSuggestion is the suggested tags for one converted document
+type TagSuggestion struct {
+	File          string   // Path of the markdown file, relative to the output directory
+	Link          string   // Google Drive link from the frontmatter
+	ExistingTags  []string // Tags from the frontmatter
+	SuggestedTags []string // Highest-scoring terms not already tagged
+}
+
+// SuggestTags reads every markdown file under outputDir, scores the terms of their bodies with
+// TF-IDF across all files, and suggests the topN highest-scoring terms of each file as tags.
+// Suggestions are normalized like filenames and exclude the file's existing tags.
+func SuggestTags(outputDir string, topN int, stopwords map[string]bool) ([]TagSuggestion, error) {
+	docs, err := readDocuments(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	corpus := NewCorpus(stopwords)
+	suggestions := make([]TagSuggestion, len(docs))
+	for i, doc := range docs {
+		corpus.Add(doc.Text())
+		suggestions[i] = TagSuggestion{
+			File:         doc.File,
+			Link:         doc.Frontmatter["gdrive-link"],
+			ExistingTags: splitTags(doc.Frontmatter["tags"]),
+		}
+	}
+
+	for i := range suggestions {
+		existing := make(map[string]bool, len(suggestions[i].ExistingTags))
+		for _, tag := range suggestions[i].ExistingTags {
+			existing[utils.NormalizeFilename(tag)] = true
+		}
+
+		for _, term := range corpus.TopTerms(i, math.MaxInt) {
+			if len(suggestions[i].SuggestedTags) == topN {
+				break
+			}
+			tag := utils.NormalizeFilename(term)
+			if tag == "" || existing[tag] {
+				continue
+			}
+			existing[tag] = true
+			suggestions[i].SuggestedTags = append(suggestions[i].SuggestedTags, tag)
+		}
+	}
+
+	return suggestions, nil
+}
+
+// splitTags splits a normalized frontmatter tags value
+func splitTags(value string) []string {
+	var result []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// WriteSuggestionsCSV writes tag suggestions to a CSV file with the columns file, link,
+// existing_tags and suggested_tags. Tag lists are separated by semicolons.
+func WriteSuggestionsCSV(filePath string, suggestions []TagSuggestion) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output CSV: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"file", "link", "existing_tags", "suggested_tags"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, s := range suggestions {
+		row := []string{s.File, s.Link, strings.Join(s.ExistingTags, ";"), strings.Join(s.SuggestedTags, ";")}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	return writer.Error()
+}
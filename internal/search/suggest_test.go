@@ -0,0 +1,66 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggestTags(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ops/cluster.md": "---\ngdrive-link: https://docs.google.com/document/d/abc/edit\ntags: Kubernetes, ops\n---\n\n" +
+			"Kubernetes cluster upgrades. Drain every cluster node before the upgrade. [Guide](https://example.com/terraform)\n",
+		"hr/leave.md": "---\ngdrive-link: https://docs.google.com/document/d/def/edit\ntags: hr\n---\n\n" +
+			"Vacation requests need approval. Vacation balance resets yearly.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	suggestions, err := SuggestTags(dir, 2, DefaultStopwords())
+	if err != nil {
+		t.Fatalf("SuggestTags() error = %v", err)
+	}
+
+	want := []TagSuggestion{
+		{
+			File:          "hr/leave.md",
+			Link:          "https://docs.google.com/document/d/def/edit",
+			ExistingTags:  []string{"hr"},
+			SuggestedTags: []string{"vacation", "approval"},
+		},
+		{
+			File:          "ops/cluster.md",
+			Link:          "https://docs.google.com/document/d/abc/edit",
+			ExistingTags:  []string{"Kubernetes", "ops"},
+			SuggestedTags: []string{"cluster", "drain"},
+		},
+	}
+	if !reflect.DeepEqual(suggestions, want) {
+		t.Errorf("SuggestTags() = %+v, want %+v", suggestions, want)
+	}
+
+	csvPath := filepath.Join(t.TempDir(), "suggestions.csv")
+	if err := WriteSuggestionsCSV(csvPath, suggestions); err != nil {
+		t.Fatalf("WriteSuggestionsCSV() error = %v", err)
+	}
+	data, err := os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "file,link,existing_tags,suggested_tags\n") {
+		t.Errorf("CSV header missing:\n%s", data)
+	}
+	if !strings.Contains(string(data), "ops/cluster.md,https://docs.google.com/document/d/abc/edit,Kubernetes;ops,cluster;drain\n") {
+		t.Errorf("CSV row missing:\n%s", data)
+	}
+}
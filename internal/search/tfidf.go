@@ -0,0 +1,161 @@
+package search
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minTermLength is the shortest token counted as a term
+const minTermLength = 3
+
+// defaultStopwords are common English words that never make useful tags
+var defaultStopwords = []string{
+	"about", "above", "after", "again", "against", "all", "also", "and", "any", "are", "because",
+	"been", "before", "being", "below", "between", "both", "but", "can", "could", "did", "does",
+	"doing", "down", "during", "each", "few", "for", "from", "further", "had", "has", "have",
+	"having", "her", "here", "hers", "herself", "him", "himself", "his", "how", "into", "its",
+	"itself", "just", "may", "more", "most", "must", "myself", "nor", "not", "now", "off", "once",
+	"only", "other", "our", "ours", "ourselves", "out", "over", "own", "same", "she", "should",
+	"some", "such", "than", "that", "the", "their", "theirs", "them", "themselves", "then",
+	"there", "these", "they", "this", "those", "through", "too", "under", "until", "use", "used",
+	"using", "very", "was", "were", "what", "when", "where", "which", "while", "who", "whom",
+	"why", "will", "with", "would", "you", "your", "yours", "yourself", "yourselves",
+	"http", "https", "www", "com",
+}
+
+// DefaultStopwords returns the built-in English stopword set
+func DefaultStopwords() map[string]bool {
+	stopwords := make(map[string]bool, len(defaultStopwords))
+	for _, word := range defaultStopwords {
+		stopwords[word] = true
+	}
+	return stopwords
+}
+
+// LoadStopwords reads a stopword set from a file with one word per line. Blank lines and lines
+// starting with # are ignored.
+func LoadStopwords(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stopwords file: %w", err)
+	}
+	defer file.Close()
+
+	stopwords := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		stopwords[word] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stopwords file: %w", err)
+	}
+
+	return stopwords, nil
+}
+
+// Tokenize splits text into lowercase terms of letters and digits. Terms shorter than three
+// characters and purely numeric terms are dropped.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(field)) < minTermLength || isNumeric(field) {
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Corpus computes TF-IDF scores over a set of documents
+type Corpus struct {
+	stopwords map[string]bool
+	docs      []map[string]int // Term counts per document
+	lengths   []int            // Number of counted terms per document
+	df        map[string]int   // Number of documents containing each term
+}
+
+// NewCorpus creates an empty corpus that ignores the given stopwords
+func NewCorpus(stopwords map[string]bool) *Corpus {
+	return &Corpus{stopwords: stopwords, df: make(map[string]int)}
+}
+
+// Add tokenizes text and adds it as a document, returning the document's index
+func (c *Corpus) Add(text string) int {
+	counts := make(map[string]int)
+	length := 0
+	for _, term := range Tokenize(text) {
+		if c.stopwords[term] {
+			continue
+		}
+		counts[term]++
+		length++
+	}
+
+	for term := range counts {
+		c.df[term]++
+	}
+	c.docs = append(c.docs, counts)
+	c.lengths = append(c.lengths, length)
+	return len(c.docs) - 1
+}
+
+// Len returns the number of documents in the corpus
+func (c *Corpus) Len() int {
+	return len(c.docs)
+}
+
+// Score returns the TF-IDF score of term in document doc. Term frequency is the share of the
+// document's terms; inverse document frequency is smoothed as log(1 + N/df) so that a corpus
+// of one document still ranks its terms.
+func (c *Corpus) Score(doc int, term string) float64 {
+	count := c.docs[doc][term]
+	if count == 0 {
+		return 0
+	}
+	tf := float64(count) / float64(c.lengths[doc])
+	idf := math.Log(1 + float64(len(c.docs))/float64(c.df[term]))
+	return tf * idf
+}
+
+// TopTerms returns up to n terms of document doc with the highest TF-IDF scores. Ties are
+// broken alphabetically.
+func (c *Corpus) TopTerms(doc, n int) []string {
+	terms := make([]string, 0, len(c.docs[doc]))
+	scores := make(map[string]float64, len(c.docs[doc]))
+	for term := range c.docs[doc] {
+		terms = append(terms, term)
+		scores[term] = c.Score(doc, term)
+	}
+
+	sort.Slice(terms, func(i, j int) bool {
+		if scores[terms[i]] != scores[terms[j]] {
+			return scores[terms[i]] > scores[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
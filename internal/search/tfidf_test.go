@@ -0,0 +1,55 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Deploy the Kubernetes cluster (v2) in 2024; on-call runbook!")
+	want := []string{"deploy", "the", "kubernetes", "cluster", "call", "runbook"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestCorpusTopTerms(t *testing.T) {
+	corpus := NewCorpus(DefaultStopwords())
+	kube := corpus.Add("The kubernetes cluster runs kubernetes pods. The cluster is shared.")
+	corpus.Add("The payroll cluster exports payroll reports.")
+
+	got := corpus.TopTerms(kube, 3)
+	want := []string{"kubernetes", "cluster", "pods"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopTerms() = %v, want %v", got, want)
+	}
+
+	if score := corpus.Score(kube, "payroll"); score != 0 {
+		t.Errorf("Score() of absent term = %v, want 0", score)
+	}
+	if corpus.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", corpus.Len())
+	}
+}
+
+func TestLoadStopwords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stopwords.txt")
+	if err := os.WriteFile(path, []byte("# custom\nKubernetes\n\n  cluster \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadStopwords(path)
+	if err != nil {
+		t.Fatalf("LoadStopwords() error = %v", err)
+	}
+	want := map[string]bool{"kubernetes": true, "cluster": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadStopwords() = %v, want %v", got, want)
+	}
+
+	if _, err := LoadStopwords(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadStopwords() expected error for missing file")
+	}
+}
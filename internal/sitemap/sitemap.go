@@ -0,0 +1,172 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/sync"
+)
+
+const (
+	// DefaultMaxURLs is the largest number of URLs in one sitemap, the limit set by the protocol
+	DefaultMaxURLs = 50000
+
+	// FileName is the sitemap written when all URLs fit in one file
+	FileName = "sitemap.xml"
+
+	// IndexFileName is the sitemap index written when the URLs are split over several sitemaps
+	IndexFileName = "sitemap_index.xml"
+
+	// ChangeFreq is the change frequency given for every document
+	ChangeFreq = "weekly"
+
+	namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+// URL is a <url> entry of a sitemap
+type URL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []URL    `xml:"url"`
+}
+
+type sitemapRef struct {
+	Loc string `xml:"loc"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+// Collect returns a sitemap entry for every markdown file under outputDir, sorted by location.
+// The location is baseURL followed by the file's path without .md; the last modification is the
+// Drive modifiedTime from the hash-gdrive frontmatter field. Files with published: false are
+// left out.
+func Collect(outputDir, baseURL string) ([]URL, error) {
+	var urls []URL
+
+	err := filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+
+		entry := URL{Loc: DocumentURL(baseURL, rel), ChangeFreq: ChangeFreq}
+		if frontmatter, _, err := sync.ParseFrontmatter(string(content)); err == nil {
+			if frontmatter["published"] == "false" {
+				return nil
+			}
+			if modified, err := time.Parse(time.RFC3339, frontmatter["hash-gdrive"]); err == nil {
+				entry.LastMod = modified.UTC().Format(time.RFC3339)
+			}
+		}
+		urls = append(urls, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(urls, func(i, j int) bool { return urls[i].Loc < urls[j].Loc })
+	return urls, nil
+}
+
+// DocumentURL returns the wiki URL of a markdown file: baseURL followed by the file's path
+// relative to the output directory, without .md
+func DocumentURL(baseURL, relPath string) string {
+	path := strings.TrimSuffix(filepath.ToSlash(relPath), ".md")
+	return strings.TrimRight(baseURL, "/") + "/" + escapePath(path)
+}
+
+// escapePath escapes each segment of a slash-separated path for use in a URL
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// Write writes urls to sitemap.xml in outputDir. When there are more than maxURLs, they are
+// split over sitemap-1.xml, sitemap-2.xml, ... and listed in sitemap_index.xml instead. The
+// names of the written files are returned.
+func Write(outputDir, baseURL string, urls []URL, maxURLs int) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no published documents to list")
+	}
+	if maxURLs < 1 || maxURLs > DefaultMaxURLs {
+		maxURLs = DefaultMaxURLs
+	}
+
+	if len(urls) <= maxURLs {
+		if err := writeXML(filepath.Join(outputDir, FileName), urlSet{Xmlns: namespace, URLs: urls}); err != nil {
+			return nil, err
+		}
+		return []string{FileName}, nil
+	}
+
+	baseURL = strings.TrimRight(baseURL, "/")
+	index := sitemapIndex{Xmlns: namespace}
+	var written []string
+	for start := 0; start < len(urls); start += maxURLs {
+		end := min(start+maxURLs, len(urls))
+		name := fmt.Sprintf("sitemap-%d.xml", len(written)+1)
+		if err := writeXML(filepath.Join(outputDir, name), urlSet{Xmlns: namespace, URLs: urls[start:end]}); err != nil {
+			return written, err
+		}
+		written = append(written, name)
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: baseURL + "/" + name})
+	}
+
+	if err := writeXML(filepath.Join(outputDir, IndexFileName), index); err != nil {
+		return written, err
+	}
+	return append(written, IndexFileName), nil
+}
+
+// Generate collects the documents under outputDir and writes their sitemap
+func Generate(outputDir, baseURL string, maxURLs int) ([]string, error) {
+	urls, err := Collect(outputDir, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return Write(outputDir, baseURL, urls, maxURLs)
+}
+
+func writeXML(path string, v any) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
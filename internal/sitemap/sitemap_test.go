@@ -0,0 +1,140 @@
+package sitemap
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeDocs(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// validate checks an XML file against a schema in testdata with xmllint
+func validate(t *testing.T, path, schema string) {
+	t.Helper()
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not installed")
+	}
+	out, err := exec.Command(xmllint, "--noout", "--schema", filepath.Join("testdata", schema), path).CombinedOutput()
+	if err != nil {
+		t.Errorf("%s does not validate against %s: %v\n%s", filepath.Base(path), schema, err, out)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	writeDocs(t, dir, map[string]string{
+		"ops/runbook.md":       "---\nhash-gdrive: \"2024-01-15T10:30:00.000Z\"\npublished: true\n---\n\nbody\n",
+		"ops/draft.md":         "---\nhash-gdrive: \"2024-01-15T10:30:00.000Z\"\npublished: false\n---\n\nbody\n",
+		"forms/signup form.md": "---\nhash-gdrive: stub\npublished: true\n---\n\nbody\n",
+		"plain.md":             "no frontmatter\n",
+		"ops/image.png":        "not markdown",
+	})
+
+	urls, err := Collect(dir, "https://wiki.example.com/docs/")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	want := []URL{
+		{Loc: "https://wiki.example.com/docs/forms/signup%20form", ChangeFreq: "weekly"},
+		{Loc: "https://wiki.example.com/docs/ops/runbook", LastMod: "2024-01-15T10:30:00Z", ChangeFreq: "weekly"},
+		{Loc: "https://wiki.example.com/docs/plain", ChangeFreq: "weekly"},
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("Collect() = %+v, want %+v", urls, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	writeDocs(t, dir, map[string]string{
+		"ops/runbook.md":    "---\nhash-gdrive: \"2024-01-15T10:30:00.000Z\"\npublished: true\n---\n\nbody\n",
+		"hr/leave & pto.md": "---\nhash-gdrive: \"2023-05-01T08:00:00.000Z\"\npublished: true\n---\n\nbody\n",
+	})
+
+	written, err := Generate(dir, "https://wiki.example.com", DefaultMaxURLs)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !reflect.DeepEqual(written, []string{FileName}) {
+		t.Errorf("Generate() wrote %v, want [%s]", written, FileName)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		"<loc>https://wiki.example.com/hr/leave%20&amp;%20pto</loc>",
+		"<lastmod>2023-05-01T08:00:00Z</lastmod>",
+		"<changefreq>weekly</changefreq>",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("sitemap missing %q:\n%s", want, data)
+		}
+	}
+
+	validate(t, filepath.Join(dir, FileName), "sitemap.xsd")
+}
+
+func TestGenerateSplitsIntoIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeDocs(t, dir, map[string]string{
+		"a.md": "a\n",
+		"b.md": "b\n",
+		"c.md": "c\n",
+	})
+
+	written, err := Generate(dir, "https://wiki.example.com", 2)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := []string{"sitemap-1.xml", "sitemap-2.xml", IndexFileName}
+	if !reflect.DeepEqual(written, want) {
+		t.Fatalf("Generate() wrote %v, want %v", written, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FileName)); !os.IsNotExist(err) {
+		t.Errorf("%s written alongside the index", FileName)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "<loc>https://wiki.example.com/sitemap-2.xml</loc>") {
+		t.Errorf("index missing second sitemap:\n%s", index)
+	}
+
+	second, err := os.ReadFile(filepath.Join(dir, "sitemap-2.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(second), "<url>") != 1 {
+		t.Errorf("sitemap-2.xml should hold the last URL:\n%s", second)
+	}
+
+	validate(t, filepath.Join(dir, "sitemap-1.xml"), "sitemap.xsd")
+	validate(t, filepath.Join(dir, IndexFileName), "siteindex.xsd")
+}
+
+func TestWriteWithoutURLs(t *testing.T) {
+	if _, err := Write(t.TempDir(), "https://wiki.example.com", nil, DefaultMaxURLs); err == nil {
+		t.Error("Write() expected error without URLs, since an empty urlset is not a valid sitemap")
+	}
+}
@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeFile is a file served by fakeDrive
+type fakeFile struct {
+	MimeType       string
+	ModifiedTime   string
+	HeadRevisionID string // Omitted from the metadata when empty
+	Markdown       string // Returned by the markdown export endpoint
+}
+
+// fakeDrive is a minimal Drive API server serving file metadata and markdown exports
+type fakeDrive struct {
+	files map[string]fakeFile
+}
+
+func newFakeDrive() *fakeDrive {
+	return &fakeDrive{files: make(map[string]fakeFile)}
+}
+
+func (f *fakeDrive) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/files/")
+	id, export := strings.CutSuffix(path, "/export")
+
+	file, ok := f.files[id]
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+		return
+	}
+
+	if export {
+		w.Header().Set("Content-Type", "text/markdown")
+		w.Write([]byte(file.Markdown))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	revision := ""
+	if file.HeadRevisionID != "" {
+		revision = `,"headRevisionId":"` + file.HeadRevisionID + `"`
+	}
+	w.Write([]byte(`{"id":"` + id + `","mimeType":"` + file.MimeType + `","modifiedTime":"` + file.ModifiedTime + `"` + revision + `}`))
+}
+
+// newTestSyncer returns a Syncer backed by the fake Drive server
+func newTestSyncer(t *testing.T, fake *fakeDrive, outputDir string, opts Options) *Syncer {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	service, err := drive.NewService(context.Background(),
+		option.WithHTTPClient(server.Client()),
+		option.WithEndpoint(server.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create Drive service: %v", err)
+	}
+
+	return NewSyncer(service, outputDir, false, false, opts)
+}
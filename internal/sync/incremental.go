@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/diff"
+)
+
+// DefaultMinChangeRatio is the share of changed lines above which incremental sync rewrites
+// the whole body
+const DefaultMinChangeRatio = 0.5
+
+// BaseDir is the directory, inside the output directory, where incremental sync keeps the
+// body it last exported for each file
+const BaseDir = ".sync-base"
+
+// incrementalBody merges the changes from base, the body last exported from Drive, to newBody
+// into localBody, so lines edited locally are kept where Drive left them alone. newBody is
+// returned as a full rewrite when more than MinChangeRatio of the lines changed in Drive or
+// when the changes overlap local edits.
+func (s *Syncer) incrementalBody(filePath, base, localBody, newBody string) string {
+	baseLines, newLines := diff.SplitLines(base), diff.SplitLines(newBody)
+
+	// An edit script within the ratio never needs more edits than this, so the diff can stop
+	// early on documents that were rewritten wholesale
+	maxEdits := int(s.opts.MinChangeRatio * float64(len(baseLines)+len(newLines)))
+	edits, ok := diff.LinesWithLimit(baseLines, newLines, maxEdits)
+	if !ok || diff.ChangeRatio(edits) > s.opts.MinChangeRatio {
+		if s.verbose {
+			log.Printf("More than %.0f%% of lines changed, rewriting: %s", 100*s.opts.MinChangeRatio, filePath)
+		}
+		return newBody
+	}
+
+	lines, err := diff.Merge(baseLines, diff.SplitLines(localBody), newLines)
+	if err != nil {
+		log.Printf("Warning: incremental update of %s failed, rewriting: %v", filePath, err)
+		return newBody
+	}
+
+	if s.verbose {
+		log.Printf("Incremental update: %s (%.0f%% of lines changed)", filePath, 100*diff.ChangeRatio(edits))
+	}
+	return strings.Join(lines, "")
+}
+
+// mergeLocalEdits returns the body to write when incremental sync updates the file at filePath
+// from localBody to the exported newBody. contentHash is the hash-content the file was last
+// written with, if any: without a saved base, a local body still matching it is the last
+// export. Files with neither are rewritten.
+func (s *Syncer) mergeLocalEdits(filePath, localBody, newBody, contentHash string) string {
+	base, ok := s.readBase(filePath)
+	if !ok && contentHash != "" && s.opts.HashFunc([]byte(localBody)) == contentHash {
+		base, ok = localBody, true
+	}
+	if !ok {
+		if s.verbose {
+			log.Printf("No incremental sync base for %s, rewriting", filePath)
+		}
+		return newBody
+	}
+	return s.incrementalBody(filePath, base, localBody, newBody)
+}
+
+// saveExport keeps the exported body of an updated file as its base, with IncrementalSync
+func (s *Syncer) saveExport(result SyncResult, exported string) {
+	if s.opts.IncrementalSync && !s.dryRun && result.Status == "updated" {
+		s.saveBase(result.FilePath, exported)
+	}
+}
+
+// basePath returns where the last exported body of the file at filePath is kept, or false for
+// files outside the output directory
+func (s *Syncer) basePath(filePath string) (string, bool) {
+	rel, err := filepath.Rel(s.outputDir, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(s.outputDir, BaseDir, rel+".base"), true
+}
+
+// readBase returns the body last exported for the file at filePath
+func (s *Syncer) readBase(filePath string) (string, bool) {
+	path, ok := s.basePath(filePath)
+	if !ok {
+		return "", false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// saveBase keeps body as the last exported body of the file at filePath, for the next
+// incremental sync to merge against
+func (s *Syncer) saveBase(filePath, body string) {
+	path, ok := s.basePath(filePath)
+	if !ok {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("Warning: failed to save the incremental sync base of %s: %v", filePath, err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		log.Printf("Warning: failed to save the incremental sync base of %s: %v", filePath, err)
+	}
+}
+
+// bodyDiff returns a unified diff of the old and new body of the file at filePath, labelled
+// with its path relative to the output directory
+func (s *Syncer) bodyDiff(filePath, oldBody, newBody string) string {
+	name, err := filepath.Rel(s.outputDir, filePath)
+	if err != nil {
+		name = filePath
+	}
+	name = filepath.ToSlash(name)
+	return diff.Unified("a/"+name, "b/"+name, oldBody, newBody, diff.DefaultContext)
+}
@@ -0,0 +1,143 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+func TestIncrementalBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		local string
+		new   string
+		want  string
+	}{
+		{
+			name:  "local edit kept",
+			base:  "# Title\n\nIntro\n\n## Section\n\nOld text\n",
+			local: "# Title\n\nIntro, edited locally\n\n## Section\n\nOld text\n",
+			new:   "# Title\n\nIntro\n\n## Section\n\nNew text\n",
+			want:  "# Title\n\nIntro, edited locally\n\n## Section\n\nNew text\n",
+		},
+		{
+			name:  "no local edits",
+			base:  "a\nb",
+			local: "a\nb",
+			new:   "a\nb\nc",
+			want:  "a\nb\nc",
+		},
+		{
+			name:  "wholesale rewrite",
+			base:  "a\nb\nc\n",
+			local: "a\nB\nc\n",
+			new:   "x\ny\nz\n",
+			want:  "x\ny\nz\n",
+		},
+		{
+			name:  "conflicting edit",
+			base:  "a\nb\nc\nd\n",
+			local: "a\nB\nc\nd\n",
+			new:   "a\nbee\nc\nd\n",
+			want:  "a\nbee\nc\nd\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSyncer(nil, t.TempDir(), false, false, Options{IncrementalSync: true})
+			if got := s.incrementalBody("doc.md", tt.base, tt.local, tt.new); got != tt.want {
+				t.Errorf("incrementalBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncIncremental(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	const preamble = "> Link: " + link + "\n\n"
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "doc.md")
+	body := preamble + "Line 1\nLine 2\nLine 3\nLine 4\nLine 5\nLine 6\n"
+	content := "---\ngdrive-link: \"" + link + "\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\nhash-content: \"" + utils.CalculateContentHash([]byte(body)) + "\"\n---\n\n" + body
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	s := newTestSyncer(t, fake, tempDir, Options{IncrementalSync: true})
+	s.linkMap["doc123"] = &csv.ConversionRecord{Link: link, Title: "Doc"}
+
+	update := func(modifiedTime, markdown string) string {
+		t.Helper()
+		fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: modifiedTime, Markdown: markdown}
+		if result := s.syncFile(filePath); result.Status != "updated" {
+			t.Fatalf("syncFile() status = %q, want updated (error: %v)", result.Status, result.Error)
+		}
+		updated, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if !strings.Contains(string(updated), "hash-gdrive: \""+modifiedTime+"\"") {
+			t.Errorf("hash-gdrive not updated:\n%s", updated)
+		}
+		return string(updated)
+	}
+
+	// The file still matches its hash-content, so it is the base of the first update
+	updated := update("2024-02-01T00:00:00.000Z", "Line 1\nLine two\nLine 3\nLine 4\nLine 5\nLine 6\n")
+	if !strings.HasSuffix(updated, "---\n\n"+preamble+"Line 1\nLine two\nLine 3\nLine 4\nLine 5\nLine 6\n") {
+		t.Fatalf("body not updated:\n%s", updated)
+	}
+
+	// A local edit survives the next update, which merges against the saved export
+	edited := strings.Replace(updated, "Line 5\n", "Line 5, edited locally\n", 1)
+	if err := os.WriteFile(filePath, []byte(edited), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	updated = update("2024-03-01T00:00:00.000Z", "Line one\nLine two\nLine 3\nLine 4\nLine 5\nLine 6\n")
+	if !strings.HasSuffix(updated, "---\n\n"+preamble+"Line one\nLine two\nLine 3\nLine 4\nLine 5, edited locally\nLine 6\n") {
+		t.Errorf("local edit not kept:\n%s", updated)
+	}
+
+	base, err := os.ReadFile(filepath.Join(tempDir, BaseDir, "doc.md.base"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := preamble + "Line one\nLine two\nLine 3\nLine 4\nLine 5\nLine 6\n"; string(base) != want {
+		t.Errorf("saved base = %q, want %q", base, want)
+	}
+}
+
+func TestSyncDiff(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "guides", "doc.md")
+	content := "---\ngdrive-link: \"" + link + "\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\n---\n\n> Link: " + link + "\n\nLine 1\nLine 2\nLine 3\n"
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-02-01T00:00:00.000Z", Markdown: "Line 1\nLine 3\nLine 4\n"}
+	s := newTestSyncer(t, fake, tempDir, Options{Diff: true})
+	s.linkMap["doc123"] = &csv.ConversionRecord{Link: link, Title: "Doc"}
+
+	result := s.syncFile(filePath)
+	if result.Status != "updated" {
+		t.Fatalf("syncFile() status = %q, want updated (error: %v)", result.Status, result.Error)
+	}
+
+	want := "--- a/guides/doc.md\n+++ b/guides/doc.md\n@@ -1,5 +1,5 @@\n > Link: " + link + "\n \n Line 1\n-Line 2\n Line 3\n+Line 4\n"
+	if result.Diff != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", result.Diff, want)
+	}
+}
@@ -0,0 +1,88 @@
+package sync
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/conversion"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// partitionOrphans splits files into the files to sync and the orphans: files whose Drive link
+// matches none of the input records, e.g. because the document was removed from the CSV after
+// its source was deleted. Files without a Drive link, or that cannot be read, are synced as usual.
+func (s *Syncer) partitionOrphans(files []string) (toSync, orphans []string) {
+	for _, filePath := range files {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			toSync = append(toSync, filePath)
+			continue
+		}
+
+		link := s.sourceLink(string(content))
+		if link == "" || s.isKnownLink(link) {
+			toSync = append(toSync, filePath)
+			continue
+		}
+		orphans = append(orphans, filePath)
+	}
+	return toSync, orphans
+}
+
+// sourceLink returns the Drive link of a local file: its gdrive-link frontmatter field, or the
+// "> Link:" preamble for files without frontmatter
+func (s *Syncer) sourceLink(content string) string {
+	if s.opts.NoFrontmatter {
+		if matches := preambleLinkPattern.FindStringSubmatch(content); matches != nil {
+			return matches[1]
+		}
+		return ""
+	}
+
+	frontmatter, _, err := s.parseFrontmatter(content)
+	if err != nil {
+		return ""
+	}
+	return frontmatter["gdrive-link"]
+}
+
+// isKnownLink reports whether link belongs to one of the input records, by URL or file ID
+func (s *Syncer) isKnownLink(link string) bool {
+	if _, ok := s.linkMap[conversion.LinkKey(link)]; ok {
+		return true
+	}
+	fileID, err := utils.ExtractFileID(link)
+	if err != nil {
+		return false
+	}
+	_, ok := s.linkMap[fileID]
+	return ok
+}
+
+// orphanResult reports an orphaned file, deleting it when Options.DeleteOrphans is set
+func (s *Syncer) orphanResult(filePath string) SyncResult {
+	result := SyncResult{FilePath: filePath, Status: "orphan"}
+	if !s.opts.DeleteOrphans {
+		log.Printf("Orphaned file, its Drive link is not in the input: %s", filePath)
+		return result
+	}
+	return s.deleteLocalFile(result, "orphaned")
+}
+
+// deleteLocalFile deletes the file of result, honouring dry-run. reason describes the file in
+// log messages, e.g. "orphaned". A failed delete turns the result into an error.
+func (s *Syncer) deleteLocalFile(result SyncResult, reason string) SyncResult {
+	if s.dryRun {
+		log.Printf("Would delete %s file: %s", reason, result.FilePath)
+		return result
+	}
+	if err := os.Remove(result.FilePath); err != nil {
+		result.Status = "error"
+		result.Error = fmt.Errorf("failed to delete %s file: %w", reason, err)
+		return result
+	}
+	result.Deleted = true
+	log.Printf("Deleted %s file: %s", reason, result.FilePath)
+	return result
+}
@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+)
+
+// writeOrphanTree writes a synced file, an orphaned file and a file without a Drive link to dir
+func writeOrphanTree(t *testing.T, dir string) (valid, orphan, manual string) {
+	t.Helper()
+	files := map[string]string{
+		"valid.md":  "---\ngdrive-link: \"https://docs.google.com/document/d/doc123/edit\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\ntitle: Valid\n---\n\nContent",
+		"orphan.md": "---\ngdrive-link: \"https://docs.google.com/document/d/gone456/edit\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\ntitle: Orphan\n---\n\nContent",
+		"manual.md": "---\ntitle: Manual\n---\n\n# Written by hand",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	return filepath.Join(dir, "valid.md"), filepath.Join(dir, "orphan.md"), filepath.Join(dir, "manual.md")
+}
+
+// resultsByPath indexes sync results by file path
+func resultsByPath(results []SyncResult) map[string]SyncResult {
+	byPath := make(map[string]SyncResult, len(results))
+	for _, result := range results {
+		byPath[result.FilePath] = result
+	}
+	return byPath
+}
+
+func TestSyncDetectOrphans(t *testing.T) {
+	// The link of the valid file uses a different URL form than the record
+	records := []csv.ConversionRecord{{Link: "https://drive.google.com/open?id=doc123", Title: "Valid"}}
+
+	tests := []struct {
+		name        string
+		opts        Options
+		dryRun      bool
+		wantDeleted bool
+	}{
+		{name: "report only", opts: Options{DetectOrphans: true}},
+		{name: "delete", opts: Options{DetectOrphans: true, DeleteOrphans: true}, wantDeleted: true},
+		{name: "delete dry run", opts: Options{DetectOrphans: true, DeleteOrphans: true}, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			valid, orphan, manual := writeOrphanTree(t, dir)
+
+			fake := newFakeDrive()
+			fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-01-01T00:00:00.000Z"}
+			s := newTestSyncer(t, fake, dir, tt.opts)
+			s.dryRun = tt.dryRun
+
+			results, err := s.Sync(context.Background(), records, 1)
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+			if len(results) != 3 {
+				t.Fatalf("Sync() returned %d results, want 3: %+v", len(results), results)
+			}
+
+			byPath := resultsByPath(results)
+			if got := byPath[valid].Status; got != "unchanged" {
+				t.Errorf("valid file status = %q, want unchanged", got)
+			}
+			if got := byPath[manual].Status; got != "skipped" {
+				t.Errorf("file without link status = %q, want skipped", got)
+			}
+			if got := byPath[orphan]; got.Status != "orphan" || got.Deleted != tt.wantDeleted {
+				t.Errorf("orphan result = %+v, want status orphan with deleted %v", got, tt.wantDeleted)
+			}
+
+			_, statErr := os.Stat(orphan)
+			if exists := statErr == nil; exists == tt.wantDeleted {
+				t.Errorf("orphan exists = %v, want %v", exists, !tt.wantDeleted)
+			}
+			if _, err := os.Stat(valid); err != nil {
+				t.Errorf("valid file removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestSyncWithoutDetectOrphansSyncsEveryFile(t *testing.T) {
+	dir := t.TempDir()
+	_, orphan, _ := writeOrphanTree(t, dir)
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-01-01T00:00:00.000Z"}
+	s := newTestSyncer(t, fake, dir, Options{})
+
+	results, err := s.Sync(context.Background(), []csv.ConversionRecord{{Link: "https://docs.google.com/document/d/doc123/edit"}}, 1)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// The orphan's Drive file no longer exists, so it is synced and found deleted
+	if got := resultsByPath(results)[orphan].Status; got != "deleted" {
+		t.Errorf("orphan status = %q, want deleted", got)
+	}
+}
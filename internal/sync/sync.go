@@ -1,6 +1,9 @@
 package sync
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -9,67 +12,174 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"text/template"
+	"time"
 
+	"github.com/BurntSushi/toml"
 	"google.golang.org/api/drive/v3"
+	"gopkg.in/yaml.v3"
 
+	"github.com/yourusername/webscrape-to-wikijs/internal/auth"
+	"github.com/yourusername/webscrape-to-wikijs/internal/conversion"
 	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
 	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
 )
 
 // Syncer handles synchronization of existing markdown files with Google Drive
 type Syncer struct {
-	service      *drive.Service
-	outputDir    string
-	verbose      bool
-	dryRun       bool
-	linkMap      map[string]*csv.ConversionRecord // Maps file ID to record
-	linkRewriter *LinkRewriter
-	mu           sync.Mutex
+	service   *drive.Service
+	outputDir string
+	verbose   bool
+	dryRun    bool
+	linkMap   map[string]*csv.ConversionRecord // Maps file ID to record
+	converter *conversion.Converter            // Post-processes exported content the way convert does
+	opts      Options
+	ctx       context.Context // Context of the running Sync (nil = context.Background())
+	mu        sync.Mutex
 }
 
+// Options holds optional sync settings
+type Options struct {
+	NoFrontmatter  bool   // Files have no frontmatter; compare Drive modifiedTime to file mtime
+	FilenamePrefix string // Title prefix included in output filenames by convert -prefix-in-filename
+	FilenameSuffix string // Title suffix included in output filenames by convert -prefix-in-filename
+
+	SourceLinkTemplate *template.Template           // Source link line convert placed first in the content (nil = none)
+	StripComments      bool                         // Remove HTML comments from exported content
+	EmbedImages        bool                         // Convert ran with -embed-images; download images to the assets directory
+	DescriptionSource  conversion.DescriptionSource // What updated descriptions are taken from (empty = first paragraph)
+	DetectLanguage     bool                         // Convert ran with -detect-language; detect the language of updated bodies
+	LanguageConfidence float64                      // Confidence below which the language is "und" (0 = conversion.DefaultLanguageConfidence)
+	HashFunc           utils.HashFunc               // Hash for the hash-content field (nil = utils.CalculateContentHash)
+	SplitByFrag1       bool                         // Convert ran with -split-by-frag1
+	SplitByFrag2       bool                         // Convert ran with -split-by-frag2
+	IncrementalSync    bool                         // Apply only the changed lines to the existing body
+	MinChangeRatio     float64                      // Rewrite the whole body when more lines changed (0 = DefaultMinChangeRatio)
+	DetectOrphans      bool                         // Report files whose Drive link is not in the records instead of syncing them
+	DeleteOrphans      bool                         // Also delete orphaned files (requires DetectOrphans)
+	Diff               bool                         // Record a unified diff of the body of updated files in SyncResult.Diff
+	DeleteLocal        bool                         // Delete local files whose Drive file was deleted
+}
+
+// preambleLinkPattern matches the "> Link:" preamble written by convert
+var preambleLinkPattern = regexp.MustCompile(`(?m)^> Link: (\S+)`)
+
 // SyncResult represents the result of syncing a single file
 type SyncResult struct {
-	FilePath      string
-	Status        string // "updated", "unchanged", "error", "skipped"
-	Error         error
-	OldHash       string
-	NewHash       string
-	ContentLength int
+	FilePath      string `json:"file_path"`
+	Status        string `json:"status"` // "updated", "unchanged", "error", "skipped", "orphan", "deleted"
+	Error         error  `json:"-"`
+	OldHash       string `json:"old_hash,omitempty"`
+	NewHash       string `json:"new_hash,omitempty"`
+	ContentLength int    `json:"content_length,omitempty"`
+	Deleted       bool   `json:"deleted,omitempty"` // Local file was deleted (orphaned, or deleted in Drive)
+	Diff          string `json:"diff,omitempty"`    // Unified diff of the body of an updated file (with Options.Diff)
+}
+
+// SyncSummary counts sync results by status
+type SyncSummary struct {
+	Updated        int `json:"updated"`
+	Unchanged      int `json:"unchanged"`
+	Skipped        int `json:"skipped"`
+	Errors         int `json:"errors"`
+	Orphans        int `json:"orphans"`
+	DeletedInDrive int `json:"deleted_in_drive"`
+	Removed        int `json:"removed"` // Local files deleted, orphaned or deleted in Drive
+}
+
+// Summarize counts results by status
+func Summarize(results []SyncResult) SyncSummary {
+	var summary SyncSummary
+	for _, result := range results {
+		switch result.Status {
+		case "updated":
+			summary.Updated++
+		case "unchanged":
+			summary.Unchanged++
+		case "error":
+			summary.Errors++
+		case "skipped":
+			summary.Skipped++
+		case "orphan":
+			summary.Orphans++
+		case "deleted":
+			summary.DeletedInDrive++
+		}
+		if result.Deleted {
+			summary.Removed++
+		}
+	}
+	return summary
+}
+
+// String returns the counts as logged at the end of a sync
+func (s SyncSummary) String() string {
+	return fmt.Sprintf("%d updated, %d unchanged, %d skipped, %d orphaned, %d deleted in Drive, %d local files removed, %d errors",
+		s.Updated, s.Unchanged, s.Skipped, s.Orphans, s.DeletedInDrive, s.Removed, s.Errors)
 }
 
-// LinkRewriter handles rewriting Google Drive links to relative paths
-type LinkRewriter struct {
-	linkMap map[string]*csv.ConversionRecord
+// MarshalJSON encodes the result for sync reports, with the error as its message
+func (r SyncResult) MarshalJSON() ([]byte, error) {
+	type plain SyncResult
+	report := struct {
+		plain
+		Error     string `json:"error,omitempty"`
+		ErrorType string `json:"error_type,omitempty"`
+	}{plain: plain(r), ErrorType: conversion.ErrorType(r.Error)}
+	if r.Error != nil {
+		report.Error = r.Error.Error()
+	}
+	return json.Marshal(report)
 }
 
 // NewSyncer creates a new Syncer
-func NewSyncer(service *drive.Service, outputDir string, verbose, dryRun bool) *Syncer {
+func NewSyncer(service *drive.Service, outputDir string, verbose, dryRun bool, opts Options) *Syncer {
+	if opts.HashFunc == nil {
+		opts.HashFunc = utils.CalculateContentHash
+	}
+	if opts.MinChangeRatio == 0 {
+		opts.MinChangeRatio = DefaultMinChangeRatio
+	}
+
 	return &Syncer{
-		service:      service,
-		outputDir:    outputDir,
-		verbose:      verbose,
-		dryRun:       dryRun,
-		linkMap:      make(map[string]*csv.ConversionRecord),
-		linkRewriter: &LinkRewriter{linkMap: make(map[string]*csv.ConversionRecord)},
+		service:   service,
+		outputDir: outputDir,
+		verbose:   verbose,
+		dryRun:    dryRun,
+		opts:      opts,
+		linkMap:   make(map[string]*csv.ConversionRecord),
+		converter: conversion.NewConverter(service, outputDir, verbose, dryRun, conversion.Options{
+			TitlePrefix:        opts.FilenamePrefix,
+			TitleSuffix:        opts.FilenameSuffix,
+			PrefixInFilename:   true,
+			SourceLinkTemplate: opts.SourceLinkTemplate,
+			StripComments:      opts.StripComments,
+			EmbedImages:        opts.EmbedImages,
+			DescriptionSource:  opts.DescriptionSource,
+			DetectLanguage:     opts.DetectLanguage,
+			LanguageConfidence: opts.LanguageConfidence,
+			HashFunc:           opts.HashFunc,
+			SplitByFrag1:       opts.SplitByFrag1,
+			SplitByFrag2:       opts.SplitByFrag2,
+		}),
 	}
 }
 
-// Sync synchronizes all markdown files in the output directory with Google Drive
-func (s *Syncer) Sync(records []csv.ConversionRecord, workers int) ([]SyncResult, error) {
-	// Build link map for O(1) lookup
-	for i := range records {
-		s.linkMap[records[i].Link] = &records[i]
-		s.linkRewriter.linkMap[records[i].Link] = &records[i]
+// NewSyncerWithSharedService creates a Syncer that uses a shared Drive service. Syncers for
+// different output directories created from the same service share its rate limit, so
+// together they stay within the Drive quota.
+func NewSyncerWithSharedService(service auth.SharedDriveService, outputDir string, verbose, dryRun bool, opts Options) *Syncer {
+	return NewSyncer(service.Drive(), outputDir, verbose, dryRun, opts)
+}
 
-		// Also index by file ID
-		fileID, err := utils.ExtractFileID(records[i].Link)
-		if err != nil {
-			log.Printf("Warning: failed to extract file ID from %s: %v", records[i].Link, err)
-			continue
-		}
-		s.linkMap[fileID] = &records[i]
-		s.linkRewriter.linkMap[fileID] = &records[i]
-	}
+// Sync synchronizes all markdown files in the output directory with Google Drive. Once ctx is
+// cancelled no further files are checked, and the results so far are returned with an error
+// wrapping ctx.Err().
+func (s *Syncer) Sync(ctx context.Context, records []csv.ConversionRecord, workers int) ([]SyncResult, error) {
+	s.ctx = ctx
+	defer func() { s.ctx = nil }()
+
+	s.indexRecords(records)
 
 	// Find all markdown files in output directory
 	markdownFiles, err := s.findMarkdownFiles()
@@ -77,6 +187,11 @@ func (s *Syncer) Sync(records []csv.ConversionRecord, workers int) ([]SyncResult
 		return nil, fmt.Errorf("failed to find markdown files: %w", err)
 	}
 
+	var orphans []string
+	if s.opts.DetectOrphans {
+		markdownFiles, orphans = s.partitionOrphans(markdownFiles)
+	}
+
 	if s.verbose {
 		log.Printf("Found %d markdown files to check for updates", len(markdownFiles))
 	}
@@ -92,7 +207,14 @@ func (s *Syncer) Sync(records []csv.ConversionRecord, workers int) ([]SyncResult
 		go func() {
 			defer wg.Done()
 			for filePath := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
 				result := s.syncFile(filePath)
+				// Files cut short by cancellation are reported as not checked
+				if ctx.Err() != nil && errors.Is(result.Error, ctx.Err()) {
+					continue
+				}
 				results <- result
 			}
 		}()
@@ -110,32 +232,80 @@ func (s *Syncer) Sync(records []csv.ConversionRecord, workers int) ([]SyncResult
 
 	// Collect results
 	var syncResults []SyncResult
-	updated := 0
-	unchanged := 0
-	errors := 0
-	skipped := 0
-
 	for result := range results {
 		syncResults = append(syncResults, result)
-		switch result.Status {
-		case "updated":
-			updated++
-		case "unchanged":
-			unchanged++
-		case "error":
-			errors++
-		case "skipped":
-			skipped++
+	}
+
+	// Report orphans once the sync has completed
+	if ctx.Err() == nil {
+		for _, filePath := range orphans {
+			syncResults = append(syncResults, s.orphanResult(filePath))
 		}
 	}
 
-	if s.verbose || errors > 0 {
-		log.Printf("Sync complete: %d updated, %d unchanged, %d skipped, %d errors", updated, unchanged, skipped, errors)
+	summary := Summarize(syncResults)
+	if s.verbose || summary.Errors+summary.Orphans+summary.DeletedInDrive > 0 {
+		log.Printf("Sync complete: %s", summary)
 	}
 
+	if err := ctx.Err(); err != nil {
+		log.Printf("Cancelled, %d of %d files not checked", len(markdownFiles)-len(syncResults), len(markdownFiles))
+		return syncResults, fmt.Errorf("sync cancelled: %w", err)
+	}
 	return syncResults, nil
 }
 
+// SyncSingle synchronizes one markdown file with Google Drive like Sync, without walking the
+// output directory, e.g. to refresh a file after editing its Drive document. Failures are
+// reported in the result like Sync's; the error is only set once ctx is cancelled.
+func (s *Syncer) SyncSingle(ctx context.Context, records []csv.ConversionRecord, filePath string) (SyncResult, error) {
+	s.ctx = ctx
+	defer func() { s.ctx = nil }()
+
+	s.indexRecords(records)
+
+	if err := ctx.Err(); err != nil {
+		return SyncResult{FilePath: filePath, Status: "skipped"}, fmt.Errorf("sync cancelled: %w", err)
+	}
+
+	if s.opts.DetectOrphans {
+		if _, orphans := s.partitionOrphans([]string{filePath}); len(orphans) > 0 {
+			return s.orphanResult(filePath), nil
+		}
+	}
+
+	result := s.syncFile(filePath)
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("sync cancelled: %w", err)
+	}
+	return result, nil
+}
+
+// indexRecords adds records to the link maps, by link and by file ID, for O(1) lookup
+func (s *Syncer) indexRecords(records []csv.ConversionRecord) {
+	s.converter.IndexRecords(records)
+	for i := range records {
+		// Index by the URL without sharing parameters, so URL variants of one file match
+		s.linkMap[conversion.LinkKey(records[i].Link)] = &records[i]
+
+		// Also index by file ID
+		fileID, err := utils.ExtractFileID(records[i].Link)
+		if err != nil {
+			log.Printf("Warning: failed to extract file ID from %s: %v", records[i].Link, err)
+			continue
+		}
+		s.linkMap[fileID] = &records[i]
+	}
+}
+
+// runContext returns the context of the running Sync, or context.Background() outside it
+func (s *Syncer) runContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
 // findMarkdownFiles finds all markdown files in the output directory
 func (s *Syncer) findMarkdownFiles() ([]string, error) {
 	var files []string
@@ -170,8 +340,18 @@ func (s *Syncer) syncFile(filePath string) SyncResult {
 		return result
 	}
 
+	if s.opts.NoFrontmatter {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			result.Status = "error"
+			result.Error = fmt.Errorf("failed to stat file: %w", err)
+			return result
+		}
+		return s.syncFileByModTime(filePath, content, info)
+	}
+
 	// Parse frontmatter
-	frontmatter, _, err := s.parseFrontmatter(string(content))
+	frontmatter, tags, body, err := ParseFrontmatterWithTags(string(content))
 	if err != nil {
 		result.Status = "error"
 		result.Error = fmt.Errorf("failed to parse frontmatter: %w", err)
@@ -216,15 +396,23 @@ func (s *Syncer) syncFile(filePath string) SyncResult {
 	// Get current metadata from Google Drive
 	file, err := s.getFileMetadata(fileID)
 	if err != nil {
-		result.Status = "error"
-		result.Error = fmt.Errorf("failed to get file metadata: %w", err)
-		return result
+		return s.metadataFailure(result, fileID, err)
 	}
 
-	result.NewHash = file.ModifiedTime
+	newHash := conversion.RevisionHash(file)
+	result.NewHash = newHash
+
+	// Check if file has been updated. Files converted before head revisions were used have
+	// the modifiedTime as their hash, which still means unchanged.
+	if oldHash == newHash || oldHash == file.ModifiedTime {
+		if s.hashAlgorithmChanged(frontmatter) {
+			log.Printf("Warning: hash-content of %s was written with a different hash algorithm, re-hashing", filePath)
+			contentBody := strings.TrimPrefix(body, "\n")
+			frontmatter["hash-content"] = s.opts.HashFunc([]byte(contentBody))
+			finalContent := s.buildFrontmatter(frontmatter, tags, DetectFrontmatterFormat(string(content))) + "\n" + contentBody
+			return s.writeUpdate(result, finalContent)
+		}
 
-	// Check if file has been updated
-	if oldHash == file.ModifiedTime {
 		result.Status = "unchanged"
 		if s.verbose {
 			log.Printf("No changes: %s", filePath)
@@ -234,66 +422,232 @@ func (s *Syncer) syncFile(filePath string) SyncResult {
 
 	// File has been updated - fetch new content
 	if s.verbose {
-		log.Printf("Updating: %s (old: %s, new: %s)", filePath, oldHash, file.ModifiedTime)
+		log.Printf("Updating: %s (old: %s, new: %s)", filePath, oldHash, newHash)
 	}
 
-	// Export new content
-	newContent, err := s.exportDocument(fileID, file.MimeType)
+	// Fetch new content
+	contentWithPreamble, err := s.fetchContent(fileID, file.MimeType)
 	if err != nil {
 		result.Status = "error"
-		result.Error = fmt.Errorf("failed to export document: %w", err)
+		result.Error = err
 		return result
 	}
 
-	// Get record for link rewriting context
-	record := s.linkMap[fileID]
-	if record == nil {
+	// Update frontmatter
+	oldContentHash := frontmatter["hash-content"]
+	frontmatter["hash-gdrive"] = newHash
+	frontmatter["hash-content"] = s.opts.HashFunc([]byte(contentWithPreamble))
+
+	oldBody := strings.TrimPrefix(body, "\n")
+	newBody := contentWithPreamble
+	if s.opts.IncrementalSync {
+		newBody = s.mergeLocalEdits(filePath, oldBody, contentWithPreamble, oldContentHash)
+	}
+	if s.opts.Diff {
+		result.Diff = s.bodyDiff(filePath, oldBody, newBody)
+	}
+
+	// The description and language follow the new body, as convert would write them
+	s.converter.SetContentFields(frontmatter, s.linkMap[fileID], newBody)
+
+	// Reconstruct file
+	finalContent := s.buildFrontmatter(frontmatter, tags, DetectFrontmatterFormat(string(content))) + "\n" + newBody
+
+	result = s.writeUpdate(result, finalContent)
+	s.saveExport(result, contentWithPreamble)
+	return result
+}
+
+// syncFileByModTime syncs a markdown file written without frontmatter. The Drive link is
+// read from the "> Link:" preamble and the file is updated when Drive's modifiedTime is
+// newer than the file's mtime.
+func (s *Syncer) syncFileByModTime(filePath string, content []byte, info os.FileInfo) SyncResult {
+	result := SyncResult{
+		FilePath: filePath,
+		Status:   "unchanged",
+		OldHash:  info.ModTime().UTC().Format(time.RFC3339),
+	}
+
+	matches := preambleLinkPattern.FindStringSubmatch(string(content))
+	if matches == nil {
+		result.Status = "skipped"
+		result.Error = fmt.Errorf("no Link preamble found")
+		return result
+	}
+	gdriveLink := matches[1]
+
+	fileID, err := utils.ExtractFileID(gdriveLink)
+	if err != nil {
 		result.Status = "error"
-		result.Error = fmt.Errorf("record not found in link map")
+		result.Error = fmt.Errorf("failed to extract file ID: %w", err)
 		return result
 	}
 
-	// Rewrite links in new content
-	newContentStr := s.linkRewriter.RewriteLinks(string(newContent), record)
+	file, err := s.getFileMetadata(fileID)
+	if err != nil {
+		return s.metadataFailure(result, fileID, err)
+	}
 
-	// Build content with preamble (matching convert behavior)
-	preamble := fmt.Sprintf("> Link: %s", gdriveLink)
-	contentWithPreamble := preamble + "\n\n" + newContentStr
+	result.NewHash = file.ModifiedTime
 
-	// Update frontmatter
-	frontmatter["hash-gdrive"] = file.ModifiedTime
-	frontmatter["hash-content"] = utils.CalculateStringHash(contentWithPreamble)
+	// Stubs cannot be told apart without a hash, so only sync exportable documents
+	if file.MimeType != "application/vnd.google-apps.document" {
+		result.Status = "skipped"
+		if s.verbose {
+			log.Printf("Skipping non-document file: %s (%s)", filePath, file.MimeType)
+		}
+		return result
+	}
 
-	// Reconstruct file
-	finalContent := s.buildFrontmatter(frontmatter) + "\n" + contentWithPreamble
+	modifiedTime, err := time.Parse(time.RFC3339, file.ModifiedTime)
+	if err != nil {
+		result.Status = "error"
+		result.Error = fmt.Errorf("failed to parse modified time %q: %w", file.ModifiedTime, err)
+		return result
+	}
+
+	if !modifiedTime.After(info.ModTime()) {
+		if s.verbose {
+			log.Printf("No changes: %s", filePath)
+		}
+		return result
+	}
+
+	if s.verbose {
+		log.Printf("Updating: %s (file mtime: %s, drive: %s)", filePath, result.OldHash, file.ModifiedTime)
+	}
+
+	finalContent, err := s.fetchContent(fileID, file.MimeType)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err
+		return result
+	}
+
+	exported := finalContent
+	if s.opts.IncrementalSync {
+		finalContent = s.mergeLocalEdits(filePath, string(content), exported, "")
+	}
+	if s.opts.Diff {
+		result.Diff = s.bodyDiff(filePath, string(content), finalContent)
+	}
+
+	result = s.writeUpdate(result, finalContent)
+	s.saveExport(result, exported)
+	return result
+}
+
+// fetchContent exports a document and post-processes it the way convert does: links are
+// rewritten, images embedded with EmbedImages, and the link preamble and source link added
+func (s *Syncer) fetchContent(fileID, mimeType string) (string, error) {
+	newContent, err := s.exportDocument(fileID, mimeType)
+	if err != nil {
+		return "", fmt.Errorf("failed to export document: %w", err)
+	}
 
+	// Get record for link rewriting context
+	record := s.linkMap[fileID]
+	if record == nil {
+		return "", fmt.Errorf("record not found in link map")
+	}
+
+	return s.converter.ProcessContent(string(newContent), record)
+}
+
+// writeUpdate writes the updated content for result's file, honouring dry-run
+func (s *Syncer) writeUpdate(result SyncResult, finalContent string) SyncResult {
 	result.ContentLength = len(finalContent)
 
 	if s.dryRun {
-		log.Printf("Would update: %s", filePath)
+		log.Printf("Would update: %s", result.FilePath)
 		result.Status = "updated"
 		return result
 	}
 
 	// Write updated file
-	if err := os.WriteFile(filePath, []byte(finalContent), 0644); err != nil {
+	if err := os.WriteFile(result.FilePath, []byte(finalContent), 0644); err != nil {
 		result.Status = "error"
-		result.Error = fmt.Errorf("failed to write file: %w", err)
+		result.Error = &conversion.RecordError{Kind: conversion.ErrWriteFailed, Err: fmt.Errorf("failed to write file: %w", err)}
 		return result
 	}
 
 	result.Status = "updated"
 	if s.verbose {
-		log.Printf("Updated: %s", filePath)
+		log.Printf("Updated: %s", result.FilePath)
 	}
 
 	return result
 }
 
-// parseFrontmatter parses YAML frontmatter from markdown content
+// parseFrontmatter parses the frontmatter of a markdown file
 func (s *Syncer) parseFrontmatter(content string) (map[string]string, string, error) {
-	frontmatter := make(map[string]string)
+	return ParseFrontmatter(content)
+}
+
+// ParseFrontmatter parses YAML, TOML or JSON frontmatter from markdown content and returns it
+// with the body. Values are normalized to strings; arrays are joined with ", ".
+func ParseFrontmatter(content string) (map[string]string, string, error) {
+	frontmatter, _, body, err := ParseFrontmatterWithTags(content)
+	return frontmatter, body, err
+}
+
+// ParseFrontmatterWithTags parses frontmatter like ParseFrontmatter, and also returns the tags
+// as a list, or nil when there is no tags field. The items of a tags sequence are kept whole,
+// so a tag containing a comma stays one tag; an inline tags string is split on commas.
+func ParseFrontmatterWithTags(content string) (map[string]string, []string, string, error) {
+	var raw map[string]any
+	var body string
+	var err error
+	switch DetectFrontmatterFormat(content) {
+	case conversion.FrontmatterTOML:
+		raw, body, err = parseTOMLFrontmatter(content)
+	case conversion.FrontmatterJSON:
+		raw, body, err = parseJSONFrontmatter(content)
+	default:
+		raw, body, err = parseYAMLFrontmatter(content)
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
 
+	frontmatter := make(map[string]string, len(raw))
+	for key, value := range raw {
+		frontmatter[key] = normalizeFrontmatterValue(value)
+	}
+
+	var tags []string
+	if value, ok := raw["tags"]; ok {
+		tags = frontmatterTagList(value)
+	}
+	return frontmatter, tags, body, nil
+}
+
+// frontmatterTagList returns the tags of a decoded tags value, always as a non-nil slice
+func frontmatterTagList(value any) []string {
+	tags := []string{}
+	add := func(tag string) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			add(normalizeFrontmatterValue(item))
+		}
+	case string:
+		for _, tag := range strings.Split(v, ",") {
+			add(tag)
+		}
+	default:
+		add(normalizeFrontmatterValue(v))
+	}
+	return tags
+}
+
+// parseYAMLFrontmatter parses --- delimited YAML frontmatter
+func parseYAMLFrontmatter(content string) (map[string]any, string, error) {
 	// Check for frontmatter markers
 	if !strings.HasPrefix(content, "---\n") {
 		return nil, "", fmt.Errorf("no frontmatter found")
@@ -309,60 +663,131 @@ func (s *Syncer) parseFrontmatter(content string) (map[string]string, string, er
 	frontmatterStr := content[4 : endIdx+4]
 	body := content[endIdx+9:]
 
-	// Parse frontmatter lines
-	lines := strings.Split(frontmatterStr, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatterStr), &nodes); err != nil {
+		return nil, "", fmt.Errorf("invalid YAML frontmatter: %w", err)
+	}
 
-		// Split on first colon
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			continue
+	raw := make(map[string]any, len(nodes))
+	for key, node := range nodes {
+		value, err := yamlNodeValue(&node)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid YAML frontmatter field %s: %w", key, err)
 		}
+		raw[key] = value
+	}
+
+	return raw, body, nil
+}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+// yamlNodeValue decodes a YAML frontmatter value. Scalars keep their source text, so
+// timestamps and numeric-looking titles are not reformatted; sequences become a []any.
+func yamlNodeValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias)
+	case yaml.SequenceNode:
+		items := make([]any, len(node.Content))
+		for i, item := range node.Content {
+			value, err := yamlNodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	default:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
 
-		// Remove quotes if present
-		value = strings.Trim(value, "\"")
+// parseTOMLFrontmatter parses +++ delimited TOML frontmatter
+func parseTOMLFrontmatter(content string) (map[string]any, string, error) {
+	endIdx := strings.Index(content[4:], "\n+++\n")
+	if endIdx == -1 {
+		return nil, "", fmt.Errorf("frontmatter not closed")
+	}
 
-		frontmatter[key] = value
+	var raw map[string]any
+	if _, err := toml.Decode(content[4:endIdx+4], &raw); err != nil {
+		return nil, "", fmt.Errorf("invalid TOML frontmatter: %w", err)
 	}
 
-	return frontmatter, body, nil
+	return raw, content[endIdx+9:], nil
 }
 
-// buildFrontmatter builds YAML frontmatter from a map
-func (s *Syncer) buildFrontmatter(fm map[string]string) string {
-	var sb strings.Builder
-	sb.WriteString("---\n")
+// parseJSONFrontmatter parses a JSON object frontmatter closed by a "}" line
+func parseJSONFrontmatter(content string) (map[string]any, string, error) {
+	endIdx := strings.Index(content, "\n}\n")
+	if endIdx == -1 {
+		return nil, "", fmt.Errorf("frontmatter not closed")
+	}
 
-	// Write fields in a consistent order
-	order := []string{"description", "editor", "gdrive-link", "hash-gdrive", "hash-content", "published", "tags", "title"}
-	for _, key := range order {
-		if value, exists := fm[key]; exists {
-			// Quote values that might contain special characters
-			if strings.ContainsAny(value, ":#@&*!|>'\"%[]{}") || strings.HasPrefix(value, "-") {
-				value = strings.ReplaceAll(value, "\"", "\\\"")
-				sb.WriteString(fmt.Sprintf("%s: \"%s\"\n", key, value))
-			} else {
-				sb.WriteString(fmt.Sprintf("%s: %s\n", key, value))
-			}
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(content[:endIdx+2]), &raw); err != nil {
+		return nil, "", fmt.Errorf("invalid JSON frontmatter: %w", err)
+	}
+
+	return raw, content[endIdx+3:], nil
+}
+
+// normalizeFrontmatterValue converts a decoded frontmatter value to a string
+func normalizeFrontmatterValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+
+	case time.Time:
+		// Matches the format of Drive modifiedTime so hashes compare equal
+		return v.Format("2006-01-02T15:04:05.000Z07:00")
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = normalizeFrontmatterValue(item)
 		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v)
 	}
+}
+
+// DetectFrontmatterFormat returns the frontmatter format based on the opening delimiter
+func DetectFrontmatterFormat(content string) conversion.FrontmatterFormat {
+	switch {
+	case strings.HasPrefix(content, "+++\n"):
+		return conversion.FrontmatterTOML
+	case strings.HasPrefix(content, "{\n"):
+		return conversion.FrontmatterJSON
+	default:
+		return conversion.FrontmatterYAML
+	}
+}
 
-	sb.WriteString("---\n")
-	return sb.String()
+// hashAlgorithmChanged reports whether the frontmatter's hash-content has a different length
+// than hashes from the configured algorithm, meaning it was written with another algorithm
+func (s *Syncer) hashAlgorithmChanged(frontmatter map[string]string) bool {
+	hash, ok := frontmatter["hash-content"]
+	return ok && len(hash) != len(s.opts.HashFunc(nil))
+}
+
+// buildFrontmatter builds frontmatter from a map and tags in the given format. In YAML, tags
+// are written as a sequence, also when the file had them as an inline string.
+func (s *Syncer) buildFrontmatter(fm map[string]string, tags []string, format conversion.FrontmatterFormat) string {
+	return conversion.RenderFrontmatter(fm, tags, format)
 }
 
 // getFileMetadata retrieves metadata for a file
 func (s *Syncer) getFileMetadata(fileID string) (*drive.File, error) {
 	file, err := s.service.Files.Get(fileID).
-		Fields("id, name, mimeType, modifiedTime").
+		Fields("id, name, mimeType, modifiedTime, headRevisionId").
 		SupportsAllDrives(true).
+		Context(s.runContext()).
 		Do()
 
 	if err != nil {
@@ -372,67 +797,48 @@ func (s *Syncer) getFileMetadata(fileID string) (*drive.File, error) {
 	return file, nil
 }
 
+// metadataFailure reports a failed metadata lookup for result's file. A Drive file that no
+// longer exists gets the status "deleted", and its local file is deleted with
+// Options.DeleteLocal; other failures are errors.
+func (s *Syncer) metadataFailure(result SyncResult, fileID string, err error) SyncResult {
+	result.Error = metadataError(fileID, err)
+	if !errors.Is(result.Error, conversion.ErrFileNotFound) {
+		result.Status = "error"
+		return result
+	}
+
+	result.Status = "deleted"
+	if !s.opts.DeleteLocal {
+		log.Printf("Deleted in Drive: %s", result.FilePath)
+		return result
+	}
+	return s.deleteLocalFile(result, "Drive-deleted")
+}
+
+// metadataError classifies a failed metadata request, so a deleted file can be told apart from
+// a network failure
+func metadataError(fileID string, err error) error {
+	err = fmt.Errorf("failed to get file metadata: %w", err)
+	return &conversion.RecordError{Kind: conversion.APIErrorKind(err, conversion.ErrExportFailed), FileID: fileID, Err: err}
+}
+
 // exportDocument exports a Google Workspace document as markdown
 func (s *Syncer) exportDocument(fileID, mimeType string) ([]byte, error) {
 	if !strings.HasPrefix(mimeType, "application/vnd.google-apps.") {
-		return nil, fmt.Errorf("unsupported MIME type: %s", mimeType)
+		return nil, &conversion.RecordError{Kind: conversion.ErrUnsupportedType, FileID: fileID, Err: fmt.Errorf("unsupported MIME type: %s", mimeType)}
 	}
 
-	resp, err := s.service.Files.Export(fileID, "text/markdown").Download()
+	resp, err := s.service.Files.Export(fileID, "text/markdown").Context(s.runContext()).Download()
 	if err != nil {
-		return nil, fmt.Errorf("failed to export document: %w", err)
+		err = fmt.Errorf("failed to export document: %w", err)
+		return nil, &conversion.RecordError{Kind: conversion.APIErrorKind(err, conversion.ErrExportFailed), FileID: fileID, Err: err}
 	}
 	defer resp.Body.Close()
 
 	content, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, &conversion.RecordError{Kind: conversion.ErrExportFailed, FileID: fileID, Err: fmt.Errorf("failed to read response: %w", err)}
 	}
 
 	return content, nil
 }
-
-// RewriteLinks rewrites Google Drive/Docs links to relative paths
-func (lr *LinkRewriter) RewriteLinks(content string, sourceRecord *csv.ConversionRecord) string {
-	// Normalize content to fix URLs broken across multiple lines
-	content = utils.NormalizeMultilineURLs(content)
-
-	// Pattern to match Google Drive and Google Docs links
-	linkPattern := regexp.MustCompile(`\[([^\]]+)\]\((https://(?:drive\.google\.com|docs\.google\.com)/[^\)]+)\)`)
-
-	return linkPattern.ReplaceAllStringFunc(content, func(match string) string {
-		matches := linkPattern.FindStringSubmatch(match)
-		if len(matches) != 3 {
-			return match
-		}
-
-		linkText := matches[1]
-		linkURL := matches[2]
-
-		// Look up target in link map by exact URL first
-		targetRecord, exists := lr.linkMap[linkURL]
-
-		// If not found by URL, try by file ID (for cross-format matching)
-		if !exists {
-			targetID, err := utils.ExtractFileID(linkURL)
-			if err != nil {
-				return match // Keep original if we can't extract ID
-			}
-			targetRecord, exists = lr.linkMap[targetID]
-			if !exists {
-				// Not in our inventory - keep original URL as-is
-				return match
-			}
-		}
-
-		// Calculate relative path with normalized filename
-		normalizedTargetTitle := utils.NormalizeFilename(targetRecord.Title)
-		relPath := utils.CalculateRelativePath(
-			sourceRecord.GetFragments(),
-			targetRecord.GetFragments(),
-			normalizedTargetTitle,
-		)
-
-		return fmt.Sprintf("[%s](%s)", linkText, relPath)
-	})
-}
@@ -1,9 +1,22 @@
 package sync
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/conversion"
+	"github.com/yourusername/webscrape-to-wikijs/internal/csv"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
 )
 
 func TestParseFrontmatter(t *testing.T) {
@@ -67,6 +80,119 @@ Body content`,
 			},
 			expectError: false,
 		},
+		{
+			name: "url and timestamp values",
+			content: `---
+gdrive-link: "https://docs.google.com/document/d/abc123/edit?usp=sharing#heading=h.1"
+hash-gdrive: "2024-01-15T10:30:00.000Z"
+published: 2024-01-15T10:30:00+02:00
+---
+
+Body content`,
+			wantFM: map[string]string{
+				"gdrive-link": "https://docs.google.com/document/d/abc123/edit?usp=sharing#heading=h.1",
+				"hash-gdrive": "2024-01-15T10:30:00.000Z",
+				"published":   "2024-01-15T10:30:00+02:00",
+			},
+			wantBody: "\nBody content",
+		},
+		{
+			name: "escaped quotes and block scalars",
+			content: `---
+title: "Say \"hi\": a guide"
+description: |
+  First line: intro
+  Second line
+tags: >-
+  folded
+  tags
+version: 1.10
+---
+
+Body content`,
+			wantFM: map[string]string{
+				"title":       `Say "hi": a guide`,
+				"description": "First line: intro\nSecond line\n",
+				"tags":        "folded tags",
+				"version":     "1.10",
+			},
+			wantBody: "\nBody content",
+		},
+		{
+			name: "yaml list values",
+			content: `---
+tags:
+  - tag1
+  - tag2
+---
+
+Body content`,
+			wantFM: map[string]string{
+				"tags": "tag1, tag2",
+			},
+		},
+		{
+			name: "invalid yaml frontmatter",
+			content: `---
+title: "unterminated
+---
+
+Body content`,
+			expectError: true,
+		},
+		{
+			name: "toml frontmatter",
+			content: `+++
+title = "Test Document"
+hash-gdrive = 2024-01-15T10:30:00.000Z
+published = true
+tags = ["tag1", "tag2"]
++++
+
+Body content`,
+			wantFM: map[string]string{
+				"title":       "Test Document",
+				"hash-gdrive": "2024-01-15T10:30:00.000Z",
+				"published":   "true",
+				"tags":        "tag1, tag2",
+			},
+			wantBody:    "\nBody content",
+			expectError: false,
+		},
+		{
+			name: "json frontmatter",
+			content: `{
+  "title": "Test Document",
+  "hash-gdrive": "2024-01-15T10:30:00.000Z",
+  "published": false,
+  "tags": ["tag1", "tag2"]
+}
+
+Body content`,
+			wantFM: map[string]string{
+				"title":       "Test Document",
+				"hash-gdrive": "2024-01-15T10:30:00.000Z",
+				"published":   "false",
+				"tags":        "tag1, tag2",
+			},
+			wantBody:    "\nBody content",
+			expectError: false,
+		},
+		{
+			name: "invalid toml frontmatter",
+			content: `+++
+title = unquoted
++++
+`,
+			expectError: true,
+		},
+		{
+			name: "unclosed toml frontmatter",
+			content: `+++
+title = "Test"
+`,
+			expectError: true,
+		},
 		{
 			name:        "no frontmatter",
 			content:     "Just plain markdown content",
@@ -122,6 +248,7 @@ func TestBuildFrontmatter(t *testing.T) {
 	tests := []struct {
 		name     string
 		fm       map[string]string
+		tags     []string
 		wantKeys []string
 	}{
 		{
@@ -131,8 +258,8 @@ func TestBuildFrontmatter(t *testing.T) {
 				"hash-gdrive":  "2024-01-15T10:30:00Z",
 				"hash-content": "abc123def456",
 				"gdrive-link":  "https://docs.google.com/document/d/abc123/edit",
-				"tags":         "tag1;tag2",
 			},
+			tags:     []string{"tag1", "tag2"},
 			wantKeys: []string{"title", "hash-gdrive", "hash-content", "gdrive-link", "tags"},
 		},
 		{
@@ -148,7 +275,7 @@ func TestBuildFrontmatter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := s.buildFrontmatter(tt.fm)
+			result := s.buildFrontmatter(tt.fm, tt.tags, conversion.FrontmatterYAML)
 
 			// Check that result starts and ends with ---
 			if result[:4] != "---\n" {
@@ -168,6 +295,63 @@ func TestBuildFrontmatter(t *testing.T) {
 	}
 }
 
+func TestBuildFrontmatterTagSequence(t *testing.T) {
+	s := &Syncer{}
+
+	got := s.buildFrontmatter(map[string]string{"title": "Doc"}, []string{"tutorial", "c#", "beginner"}, conversion.FrontmatterYAML)
+	want := "---\ntags:\n  - tutorial\n  - \"c#\"\n  - beginner\ntitle: Doc\n---\n"
+	if got != want {
+		t.Errorf("buildFrontmatter() = %q, want %q", got, want)
+	}
+
+	// Inline tags written by earlier versions are parsed and written back as a sequence
+	fm, tags, _, err := ParseFrontmatterWithTags("---\ntags: tutorial, beginner\n---\nBody")
+	if err != nil {
+		t.Fatalf("ParseFrontmatterWithTags() error = %v", err)
+	}
+	if got := s.buildFrontmatter(fm, tags, conversion.FrontmatterYAML); got != "---\ntags:\n  - tutorial\n  - beginner\n---\n" {
+		t.Errorf("buildFrontmatter() of inline tags = %q, want a sequence", got)
+	}
+}
+
+func TestFrontmatterRoundTrip(t *testing.T) {
+	s := &Syncer{}
+	fm := map[string]string{
+		"title":       "Test Document",
+		"description": `Paths like C:\docs and "quoted: text"`,
+		"hash-gdrive": "2024-01-15T10:30:00.000Z",
+		"gdrive-link": "https://docs.google.com/document/d/abc123/edit",
+		"published":   "true",
+	}
+	tags := []string{"tag1", "tips, tricks"}
+
+	for _, format := range []conversion.FrontmatterFormat{conversion.FrontmatterYAML, conversion.FrontmatterTOML, conversion.FrontmatterJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			content := s.buildFrontmatter(fm, tags, format) + "\nBody"
+
+			if got := DetectFrontmatterFormat(content); got != format {
+				t.Errorf("DetectFrontmatterFormat() = %q, want %q", got, format)
+			}
+
+			parsed, parsedTags, body, err := ParseFrontmatterWithTags(content)
+			if err != nil {
+				t.Fatalf("ParseFrontmatterWithTags() error = %v", err)
+			}
+			if body != "\nBody" {
+				t.Errorf("body = %q, want %q", body, "\nBody")
+			}
+			for key, want := range fm {
+				if parsed[key] != want {
+					t.Errorf("frontmatter[%s] = %q, want %q", key, parsed[key], want)
+				}
+			}
+			if !slices.Equal(parsedTags, tags) {
+				t.Errorf("tags = %q, want %q", parsedTags, tags)
+			}
+		})
+	}
+}
+
 func TestFindMarkdownFiles(t *testing.T) {
 	// Create temp directory
 	tempDir := t.TempDir()
@@ -202,6 +386,300 @@ func TestFindMarkdownFiles(t *testing.T) {
 	}
 }
 
+func TestSyncNoFrontmatter(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	fileTime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		content     string
+		driveFile   fakeFile
+		wantStatus  string
+		wantContent string
+	}{
+		{
+			name:        "drive newer than file",
+			content:     "> Link: " + link + "\n\nOld content",
+			driveFile:   fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-02-01T00:00:00.000Z", Markdown: "New content"},
+			wantStatus:  "updated",
+			wantContent: "> Link: " + link + "\n\nNew content",
+		},
+		{
+			name:        "drive older than file",
+			content:     "> Link: " + link + "\n\nOld content",
+			driveFile:   fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-01-01T00:00:00.000Z", Markdown: "New content"},
+			wantStatus:  "unchanged",
+			wantContent: "> Link: " + link + "\n\nOld content",
+		},
+		{
+			name:        "stub file type skipped",
+			content:     "> Link: " + link + "\n\n*This is a Google Form.*",
+			driveFile:   fakeFile{MimeType: "application/vnd.google-apps.form", ModifiedTime: "2024-02-01T00:00:00.000Z"},
+			wantStatus:  "skipped",
+			wantContent: "> Link: " + link + "\n\n*This is a Google Form.*",
+		},
+		{
+			name:        "no link preamble",
+			content:     "Just content",
+			wantStatus:  "skipped",
+			wantContent: "Just content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			filePath := filepath.Join(tempDir, "doc.md")
+			if err := os.WriteFile(filePath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			if err := os.Chtimes(filePath, fileTime, fileTime); err != nil {
+				t.Fatalf("Chtimes() error = %v", err)
+			}
+
+			fake := newFakeDrive()
+			fake.files["doc123"] = tt.driveFile
+			s := newTestSyncer(t, fake, tempDir, Options{NoFrontmatter: true})
+			record := &csv.ConversionRecord{Link: link, Title: "Doc"}
+			s.linkMap["doc123"] = record
+
+			result := s.syncFile(filePath)
+			if result.Status != tt.wantStatus {
+				t.Fatalf("syncFile() status = %q, want %q (error: %v)", result.Status, tt.wantStatus, result.Error)
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+			if strings.HasPrefix(string(content), "---") {
+				t.Errorf("frontmatter was added to %s", filePath)
+			}
+		})
+	}
+}
+
+func TestSyncPreservesTitlePrefix(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "doc.md")
+	content := "---\ndescription: Limits\ngdrive-link: \"" + link + "\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\ntitle: \"API: Limits\"\n---\n\nOld content"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-02-01T00:00:00.000Z", Markdown: "New content"}
+	s := newTestSyncer(t, fake, tempDir, Options{})
+	s.linkMap["doc123"] = &csv.ConversionRecord{Link: link, Title: "Limits"}
+
+	if result := s.syncFile(filePath); result.Status != "updated" {
+		t.Fatalf("syncFile() status = %q, want updated (error: %v)", result.Status, result.Error)
+	}
+
+	updated, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(updated), "title: \"API: Limits\"\n") {
+		t.Errorf("prefixed title not preserved:\n%s", updated)
+	}
+}
+
+func TestSyncSkipsStubDocuments(t *testing.T) {
+	const link = "https://docs.google.com/forms/d/form123/edit"
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "form.md")
+	fm := map[string]string{
+		"description": "Form",
+		"gdrive-link": link,
+		"hash-gdrive": "stub",
+		"title":       "Form",
+	}
+	content := conversion.RenderFrontmatter(fm, nil, conversion.FrontmatterYAML) + "\n*This is a Google Form.*"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.files["form123"] = fakeFile{MimeType: "application/vnd.google-apps.form", ModifiedTime: "2024-02-01T00:00:00.000Z"}
+	s := newTestSyncer(t, fake, tempDir, Options{})
+	s.linkMap["form123"] = &csv.ConversionRecord{Link: link, Title: "Form"}
+
+	result := s.syncFile(filePath)
+	if result.Status != "skipped" || result.Error != nil {
+		t.Fatalf("syncFile() status = %q, error = %v, want skipped without error", result.Status, result.Error)
+	}
+
+	got, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("stub document was modified:\n%s", got)
+	}
+}
+
+func TestSyncErrorTypes(t *testing.T) {
+	tempDir := t.TempDir()
+	writeDoc := func(name, link string) string {
+		fm := map[string]string{"gdrive-link": link, "hash-gdrive": "2024-01-01T00:00:00.000Z", "title": name}
+		path := filepath.Join(tempDir, name+".md")
+		if err := os.WriteFile(path, []byte(conversion.RenderFrontmatter(fm, nil, conversion.FrontmatterYAML)+"\nbody"), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+
+	fake := newFakeDrive()
+	fake.files["pdf1"] = fakeFile{MimeType: "application/pdf", ModifiedTime: "2024-02-01T00:00:00.000Z"}
+	s := newTestSyncer(t, fake, tempDir, Options{})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus string
+		want       error
+	}{
+		{name: "deleted", path: writeDoc("deleted", "https://docs.google.com/document/d/gone1/edit"), wantStatus: "deleted", want: conversion.ErrFileNotFound},
+		{name: "unsupported", path: writeDoc("unsupported", "https://drive.google.com/file/d/pdf1/view"), wantStatus: "error", want: conversion.ErrUnsupportedType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := s.syncFile(tt.path)
+			if result.Status != tt.wantStatus || !errors.Is(result.Error, tt.want) {
+				t.Fatalf("syncFile() status = %q, error = %v, want %s with %v", result.Status, result.Error, tt.wantStatus, tt.want)
+			}
+
+			var recordErr *conversion.RecordError
+			if !errors.As(result.Error, &recordErr) || recordErr.FileID == "" {
+				t.Errorf("syncFile() error = %#v, want a RecordError with the file ID", result.Error)
+			}
+		})
+	}
+}
+
+func TestSyncDeletedInDrive(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteLocal bool
+		dryRun      bool
+		wantDeleted bool
+	}{
+		{name: "report only"},
+		{name: "delete local", deleteLocal: true, wantDeleted: true},
+		{name: "delete local dry run", deleteLocal: true, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			filePath := filepath.Join(tempDir, "gone.md")
+			fm := map[string]string{"gdrive-link": "https://docs.google.com/document/d/gone1/edit", "hash-gdrive": "2024-01-01T00:00:00.000Z", "title": "Gone"}
+			if err := os.WriteFile(filePath, []byte(conversion.RenderFrontmatter(fm, nil, conversion.FrontmatterYAML)+"\nbody"), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			// The fake Drive answers 404 for files it does not have
+			s := newTestSyncer(t, newFakeDrive(), tempDir, Options{DeleteLocal: tt.deleteLocal})
+			s.dryRun = tt.dryRun
+
+			result := s.syncFile(filePath)
+			if result.Status != "deleted" || result.Deleted != tt.wantDeleted {
+				t.Fatalf("syncFile() = %+v, want status deleted with deleted %v", result, tt.wantDeleted)
+			}
+			_, err := os.Stat(filePath)
+			if exists := err == nil; exists == tt.wantDeleted {
+				t.Errorf("local file exists = %v, want %v", exists, !tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestFetchContentFilenamePrefix(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", Markdown: "\uFEFF[limits](https://docs.google.com/document/d/target1/edit)"}
+	s := newTestSyncer(t, fake, t.TempDir(), Options{FilenamePrefix: "API: "})
+	s.indexRecords([]csv.ConversionRecord{
+		{Link: link, Title: "Overview"},
+		{Link: "https://docs.google.com/document/d/target1/edit", Title: "Rate Limits"},
+	})
+
+	got, err := s.fetchContent("doc123", "application/vnd.google-apps.document")
+	if err != nil {
+		t.Fatalf("fetchContent() error = %v", err)
+	}
+	if want := "> Link: " + link + "\n\n[limits](api-rate-limits.md)"; got != want {
+		t.Errorf("fetchContent() = %q, want %q", got, want)
+	}
+}
+
+func TestSyncEmbedImages(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png data"))
+	}))
+	t.Cleanup(server.Close)
+	tempDir := t.TempDir()
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-01-01T00:00:00.000Z", Markdown: "![Flow](" + server.URL + "/diagram.png)\n"}
+	s := newTestSyncer(t, fake, tempDir, Options{EmbedImages: true})
+	records := []csv.ConversionRecord{{Link: link, Title: "Doc", Fragments: []string{"guides"}}}
+
+	c := conversion.NewConverter(s.service, tempDir, false, false, conversion.Options{EmbedImages: true})
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	// The image stays embedded when the document changes in Drive
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-02-01T00:00:00.000Z", Markdown: "Intro\n\n![Flow](" + server.URL + "/diagram.png)\n"}
+	results, err := s.Sync(context.Background(), records, 1)
+	if err != nil || len(results) != 1 || results[0].Status != "updated" {
+		t.Fatalf("Sync() = %+v, %v, want the file updated", results, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "guides", "doc.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	name := utils.CalculateContentHash([]byte("png data")) + ".png"
+	if want := "![Flow](../assets/" + name + ")"; !strings.Contains(string(content), want) || strings.Contains(string(content), server.URL) {
+		t.Errorf("synced content = %q, want it to contain %q and not the image URL", content, want)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "assets", name)); err != nil {
+		t.Errorf("asset missing: %v", err)
+	}
+}
+
+func TestFetchContentSourceLink(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	tmpl, err := conversion.ParseSourceLinkTemplate(conversion.DefaultSourceLinkTemplate)
+	if err != nil {
+		t.Fatalf("ParseSourceLinkTemplate() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", Markdown: "New content"}
+	s := newTestSyncer(t, fake, t.TempDir(), Options{SourceLinkTemplate: tmpl})
+	s.linkMap["doc123"] = &csv.ConversionRecord{Link: link, Title: "Doc"}
+
+	got, err := s.fetchContent("doc123", "application/vnd.google-apps.document")
+	if err != nil {
+		t.Fatalf("fetchContent() error = %v", err)
+	}
+	want := "> **Source:** [View in Google Drive](" + link + ")\n\n> Link: " + link + "\n\nNew content"
+	if got != want {
+		t.Errorf("fetchContent() = %q, want %q", got, want)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsHelper(s, substr)))
@@ -215,3 +693,301 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestSyncHeadRevision(t *testing.T) {
+	const link = "https://drive.google.com/file/d/doc123/view"
+	const modifiedTime = "2024-02-01T00:00:00.000Z"
+
+	tests := []struct {
+		name       string
+		oldHash    string
+		revision   string
+		wantStatus string
+		wantHash   string
+	}{
+		{name: "modifiedTime touched without a new revision", oldHash: "rev2", revision: "rev2", wantStatus: "unchanged", wantHash: "rev2"},
+		{name: "new revision", oldHash: "rev1", revision: "rev2", wantStatus: "updated", wantHash: "rev2"},
+		{name: "old modifiedTime hash", oldHash: modifiedTime, revision: "rev2", wantStatus: "unchanged", wantHash: modifiedTime},
+		{name: "no revision falls back to modifiedTime", oldHash: "2024-01-01T00:00:00.000Z", wantStatus: "updated", wantHash: modifiedTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			filePath := filepath.Join(tempDir, "doc.md")
+			content := "---\ngdrive-link: \"" + link + "\"\nhash-gdrive: \"" + tt.oldHash + "\"\n---\n\nOld content"
+			if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			fake := newFakeDrive()
+			fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: modifiedTime, HeadRevisionID: tt.revision, Markdown: "New content"}
+			s := newTestSyncer(t, fake, tempDir, Options{})
+			s.linkMap["doc123"] = &csv.ConversionRecord{Link: link, Title: "Doc"}
+
+			if result := s.syncFile(filePath); result.Status != tt.wantStatus {
+				t.Fatalf("syncFile() status = %q, want %q (error: %v)", result.Status, tt.wantStatus, result.Error)
+			}
+
+			updated, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			frontmatter, _, err := ParseFrontmatter(string(updated))
+			if err != nil {
+				t.Fatalf("ParseFrontmatter() error = %v", err)
+			}
+			if got := frontmatter["hash-gdrive"]; got != tt.wantHash {
+				t.Errorf("hash-gdrive = %q, want %q", got, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestSyncRehashesOnAlgorithmChange(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	const modifiedTime = "2024-01-01T00:00:00.000Z"
+	body := "> Link: " + link + "\n\nContent"
+
+	md5, err := utils.ParseHashAlgorithm(utils.HashMD5)
+	if err != nil {
+		t.Fatalf("ParseHashAlgorithm() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hash       string
+		wantStatus string
+	}{
+		{name: "same algorithm", hash: md5([]byte(body)), wantStatus: "unchanged"},
+		{name: "different algorithm", hash: utils.CalculateStringHash(body), wantStatus: "updated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			filePath := filepath.Join(tempDir, "doc.md")
+			content := "---\ngdrive-link: \"" + link + "\"\nhash-gdrive: \"" + modifiedTime + "\"\nhash-content: " + tt.hash + "\n---\n\n" + body
+			if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			fake := newFakeDrive()
+			fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: modifiedTime}
+			s := newTestSyncer(t, fake, tempDir, Options{HashFunc: md5})
+
+			if result := s.syncFile(filePath); result.Status != tt.wantStatus {
+				t.Fatalf("syncFile() status = %q, want %q (error: %v)", result.Status, tt.wantStatus, result.Error)
+			}
+
+			updated, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !strings.Contains(string(updated), "hash-content: "+md5([]byte(body))+"\n") {
+				t.Errorf("hash-content not re-hashed with md5:\n%s", updated)
+			}
+			if !strings.HasSuffix(string(updated), "---\n\n"+body) {
+				t.Errorf("body changed:\n%s", updated)
+			}
+		})
+	}
+}
+
+func TestSyncUpdatesContentFields(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	const german = "Der Dienst wird neu gestartet und die Warteschlange ist leer, bevor wir die neue Version mit dem Skript installieren."
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "doc.md")
+	content := "---\ndescription: Restart the service before you deploy.\ngdrive-link: \"" + link + "\"\nhash-gdrive: rev1\nlanguage: en\n---\n\n> Link: " + link + "\n\nRestart the service before you deploy."
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", HeadRevisionID: "rev2", Markdown: "# Betrieb\n\n" + german}
+	s := newTestSyncer(t, fake, tempDir, Options{DetectLanguage: true})
+	s.linkMap["doc123"] = &csv.ConversionRecord{Link: link, Title: "Doc"}
+
+	if result := s.syncFile(filePath); result.Status != "updated" {
+		t.Fatalf("syncFile() status = %q, want updated (error: %v)", result.Status, result.Error)
+	}
+
+	updated, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	frontmatter, _, err := ParseFrontmatter(string(updated))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if want := conversion.FirstParagraph(german, conversion.MaxDescriptionLength); frontmatter["description"] != want {
+		t.Errorf("description = %q, want %q", frontmatter["description"], want)
+	}
+	if frontmatter["language"] != "de" {
+		t.Errorf("language = %q, want de", frontmatter["language"])
+	}
+}
+
+func TestSyncResultJSON(t *testing.T) {
+	results := []SyncResult{
+		{FilePath: "docs/a.md", Status: "updated", OldHash: "old", NewHash: "new", ContentLength: 42},
+		{FilePath: "docs/b.md", Status: "error", Error: fmt.Errorf("export failed")},
+		{FilePath: "docs/c.md", Status: "error", Error: &conversion.RecordError{Kind: conversion.ErrFileNotFound, Err: fmt.Errorf("gone")}},
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `[{"file_path":"docs/a.md","status":"updated","old_hash":"old","new_hash":"new","content_length":42},` +
+		`{"file_path":"docs/b.md","status":"error","error":"export failed","error_type":"other"},` +
+		`{"file_path":"docs/c.md","status":"error","error":"gone","error_type":"file_not_found"}]`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []SyncResult{
+		{Status: "updated"},
+		{Status: "updated"},
+		{Status: "unchanged"},
+		{Status: "skipped"},
+		{Status: "error"},
+		{Status: "orphan"},
+		{Status: "orphan", Deleted: true},
+		{Status: "deleted"},
+		{Status: "deleted", Deleted: true},
+	}
+
+	summary := Summarize(results)
+	want := SyncSummary{Updated: 2, Unchanged: 1, Skipped: 1, Errors: 1, Orphans: 2, DeletedInDrive: 2, Removed: 2}
+	if summary != want {
+		t.Errorf("Summarize() = %+v, want %+v", summary, want)
+	}
+	wantLine := "2 updated, 1 unchanged, 1 skipped, 2 orphaned, 2 deleted in Drive, 2 local files removed, 1 errors"
+	if got := summary.String(); got != wantLine {
+		t.Errorf("String() = %q, want %q", got, wantLine)
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	wantJSON := `{"updated":2,"unchanged":1,"skipped":1,"errors":1,"orphans":2,"deleted_in_drive":2,"removed":2}`
+	if string(data) != wantJSON {
+		t.Errorf("json.Marshal() = %s, want %s", data, wantJSON)
+	}
+}
+
+func TestSyncCancelled(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	tempDir := t.TempDir()
+	content := "---\ngdrive-link: \"" + link + "\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\n---\n\n> Link: " + link + "\n\nOld\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "doc.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-02-01T00:00:00.000Z", Markdown: "New\n"}
+	s := newTestSyncer(t, fake, tempDir, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := s.Sync(ctx, []csv.ConversionRecord{{Link: link, Title: "Doc"}}, 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Sync() error = %v, want %v", err, context.Canceled)
+	}
+	if len(results) != 0 {
+		t.Errorf("Sync() = %+v, want no files checked", results)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "doc.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("file changed after a cancelled sync:\n%s", data)
+	}
+}
+
+func TestSyncSingle(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "doc.md")
+	content := "---\ngdrive-link: \"" + link + "\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\n---\n\nOld\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	// Another file in the output directory is not checked
+	other := "---\ngdrive-link: \"https://docs.google.com/document/d/other/edit\"\nhash-gdrive: \"2024-01-01T00:00:00.000Z\"\n---\n\nOther\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "other.md"), []byte(other), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-02-01T00:00:00.000Z", Markdown: "New\n"}
+	s := newTestSyncer(t, fake, tempDir, Options{})
+
+	result, err := s.SyncSingle(context.Background(), []csv.ConversionRecord{{Link: link, Title: "Doc"}}, filePath)
+	if err != nil {
+		t.Fatalf("SyncSingle() error = %v", err)
+	}
+	if result.FilePath != filePath || result.Status != "updated" {
+		t.Fatalf("SyncSingle() = %+v, want %s updated", result, filePath)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "New") {
+		t.Errorf("synced file = %q, want the new content", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "other.md")); string(data) != other {
+		t.Errorf("other file changed:\n%s", data)
+	}
+}
+
+func TestSyncConvertedFile(t *testing.T) {
+	const link = "https://docs.google.com/document/d/doc123/edit"
+	tempDir := t.TempDir()
+
+	fake := newFakeDrive()
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-01-01T00:00:00.000Z", Markdown: "Old\n"}
+	s := newTestSyncer(t, fake, tempDir, Options{})
+	records := []csv.ConversionRecord{{Link: link, Title: "Doc"}}
+
+	c := conversion.NewConverter(s.service, tempDir, false, false, conversion.Options{})
+	if err := c.Convert(context.Background(), records, 1); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	files, err := s.findMarkdownFiles()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("findMarkdownFiles() = %v, %v, want the converted file", files, err)
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	frontmatter, _, err := ParseFrontmatter(string(content))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if frontmatter["gdrive-link"] != link {
+		t.Fatalf("gdrive-link = %q, want %q", frontmatter["gdrive-link"], link)
+	}
+
+	// The link lets sync find the Drive file of the converted document
+	fake.files["doc123"] = fakeFile{MimeType: "application/vnd.google-apps.document", ModifiedTime: "2024-02-01T00:00:00.000Z", Markdown: "New\n"}
+	results, err := s.Sync(context.Background(), records, 1)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Status != "updated" {
+		t.Errorf("Sync() = %+v, want the converted file updated", results)
+	}
+}
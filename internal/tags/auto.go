@@ -0,0 +1,35 @@
+package tags
+
+import "strings"
+
+// FromFragments returns a tag for each non-empty fragment, lowercased with spaces replaced
+// by hyphens and prefixed with prefix
+func FromFragments(fragments []string, prefix string) []string {
+	var result []string
+	for _, frag := range fragments {
+		frag = strings.TrimSpace(frag)
+		if frag == "" {
+			continue
+		}
+		result = append(result, prefix+strings.Join(strings.Fields(strings.ToLower(frag)), "-"))
+	}
+	return result
+}
+
+// Merge returns a followed by the tags of b that are not already present. Tags are compared
+// case-insensitively and the result is deduplicated in first-seen order.
+func Merge(a, b []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{a, b} {
+		for _, tag := range list {
+			key := strings.ToLower(tag)
+			if tag == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromFragments(t *testing.T) {
+	tests := []struct {
+		name      string
+		fragments []string
+		prefix    string
+		want      []string
+	}{
+		{
+			name:      "normalized",
+			fragments: []string{"Engineering", "Platform  Team", "", "", ""},
+			want:      []string{"engineering", "platform-team"},
+		},
+		{
+			name:      "prefixed",
+			fragments: []string{"HR", "", "Leave", "", ""},
+			prefix:    "category:",
+			want:      []string{"category:hr", "category:leave"},
+		},
+		{
+			name:      "no fragments",
+			fragments: []string{"", " ", "", "", ""},
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromFragments(tt.fragments, tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromFragments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	got := Merge([]string{"Kubernetes", "ops"}, []string{"engineering", "kubernetes", "", "ops", "new"})
+	want := []string{"Kubernetes", "ops", "engineering", "new"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+
+	if got := Merge(nil, nil); got != nil {
+		t.Errorf("Merge(nil, nil) = %v, want nil", got)
+	}
+}
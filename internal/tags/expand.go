@@ -0,0 +1,108 @@
+package tags
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// hierarchyEntry represents a single entry in a tag hierarchy YAML file
+type hierarchyEntry struct {
+	Tag     string   `yaml:"tag"`
+	Parents []string `yaml:"parents"`
+}
+
+// LoadHierarchy reads a tag hierarchy YAML file into a map of tag to parent tags.
+// The hierarchy is checked for cycles before it is returned.
+func LoadHierarchy(filePath string) (map[string][]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag hierarchy: %w", err)
+	}
+
+	var entries []hierarchyEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse tag hierarchy: %w", err)
+	}
+
+	hierarchy := make(map[string][]string)
+	for i, entry := range entries {
+		tag := strings.TrimSpace(entry.Tag)
+		if tag == "" {
+			return nil, fmt.Errorf("tag hierarchy entry %d has no tag", i+1)
+		}
+		hierarchy[tag] = append(hierarchy[tag], entry.Parents...)
+	}
+
+	// Expanding every tag surfaces any cycle up front
+	for tag := range hierarchy {
+		if _, err := ExpandTags([]string{tag}, hierarchy); err != nil {
+			return nil, err
+		}
+	}
+
+	return hierarchy, nil
+}
+
+// ExpandTags returns tags together with all of their transitive parents from hierarchy.
+// Tags are compared case-insensitively and the result is deduplicated in first-seen order.
+// An error is returned if the hierarchy contains a cycle.
+func ExpandTags(tags []string, hierarchy map[string][]string) ([]string, error) {
+	// Index the hierarchy case-insensitively
+	parents := make(map[string][]string, len(hierarchy))
+	for tag, p := range hierarchy {
+		key := strings.ToLower(tag)
+		parents[key] = append(parents[key], p...)
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+
+	for _, tag := range tags {
+		add(tag)
+	}
+
+	expanded := make(map[string]bool)
+	for _, tag := range tags {
+		if err := expandParents(tag, parents, make(map[string]bool), expanded, add); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// expandParents walks the parents of tag depth-first, tracking the current path to detect cycles
+func expandParents(tag string, parents map[string][]string, path, expanded map[string]bool, add func(string)) error {
+	key := strings.ToLower(strings.TrimSpace(tag))
+	if path[key] {
+		return fmt.Errorf("cycle detected in tag hierarchy at %q", tag)
+	}
+	if expanded[key] {
+		return nil
+	}
+
+	path[key] = true
+	defer delete(path, key)
+
+	for _, parent := range parents[key] {
+		add(parent)
+		if err := expandParents(parent, parents, path, expanded, add); err != nil {
+			return err
+		}
+	}
+
+	expanded[key] = true
+	return nil
+}
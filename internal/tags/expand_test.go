@@ -0,0 +1,131 @@
+package tags
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandTags(t *testing.T) {
+	hierarchy := map[string][]string{
+		"kubernetes": {"devops", "infrastructure"},
+		"devops":     {"engineering"},
+		"helm":       {"kubernetes"},
+	}
+
+	tests := []struct {
+		name      string
+		tags      []string
+		hierarchy map[string][]string
+		expected  []string
+		wantErr   bool
+	}{
+		{
+			name:      "direct parents",
+			tags:      []string{"kubernetes"},
+			hierarchy: hierarchy,
+			expected:  []string{"kubernetes", "devops", "engineering", "infrastructure"},
+		},
+		{
+			name:      "transitive parents",
+			tags:      []string{"helm"},
+			hierarchy: hierarchy,
+			expected:  []string{"helm", "kubernetes", "devops", "engineering", "infrastructure"},
+		},
+		{
+			name:      "deduplicates existing tags case-insensitively",
+			tags:      []string{"Kubernetes", "DevOps"},
+			hierarchy: hierarchy,
+			expected:  []string{"Kubernetes", "DevOps", "engineering", "infrastructure"},
+		},
+		{
+			name:      "tag without parents",
+			tags:      []string{"misc"},
+			hierarchy: hierarchy,
+			expected:  []string{"misc"},
+		},
+		{
+			name:      "empty hierarchy",
+			tags:      []string{"a", "b"},
+			hierarchy: nil,
+			expected:  []string{"a", "b"},
+		},
+		{
+			name:      "direct cycle",
+			tags:      []string{"a"},
+			hierarchy: map[string][]string{"a": {"b"}, "b": {"a"}},
+			wantErr:   true,
+		},
+		{
+			name:      "self cycle",
+			tags:      []string{"a"},
+			hierarchy: map[string][]string{"a": {"a"}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExpandTags(tt.tags, tt.hierarchy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ExpandTags() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadHierarchy(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string][]string
+		wantErr  bool
+	}{
+		{
+			name: "valid hierarchy",
+			content: `- tag: kubernetes
+  parents: [devops, infrastructure]
+- {tag: "devops", parents: ["engineering"]}`,
+			expected: map[string][]string{
+				"kubernetes": {"devops", "infrastructure"},
+				"devops":     {"engineering"},
+			},
+		},
+		{
+			name: "cycle is rejected",
+			content: `- tag: a
+  parents: [b]
+- tag: b
+  parents: [a]`,
+			wantErr: true,
+		},
+		{
+			name:    "entry without tag",
+			content: `- parents: [a]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "hierarchy.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write hierarchy file: %v", err)
+			}
+
+			result, err := LoadHierarchy(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadHierarchy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("LoadHierarchy() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,10 +1,46 @@
 package utils
 
 import (
+	"crypto/md5"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"fmt"
 )
 
+// HashFunc returns the hex-encoded hash of content
+type HashFunc func(content []byte) string
+
+// Supported hash algorithm names
+const (
+	HashSHA256 = "sha256"
+	HashSHA512 = "sha512"
+	HashSHA1   = "sha1"
+	HashMD5    = "md5"
+)
+
+// ParseHashAlgorithm returns the HashFunc for a hash algorithm name
+func ParseHashAlgorithm(name string) (HashFunc, error) {
+	switch name {
+	case HashSHA256:
+		return CalculateContentHash, nil
+	case HashSHA512:
+		return func(content []byte) string {
+			return fmt.Sprintf("%x", sha512.Sum512(content))
+		}, nil
+	case HashSHA1:
+		return func(content []byte) string {
+			return fmt.Sprintf("%x", sha1.Sum(content))
+		}, nil
+	case HashMD5:
+		return func(content []byte) string {
+			return fmt.Sprintf("%x", md5.Sum(content))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown hash algorithm %q (want %q, %q, %q or %q)", name, HashSHA256, HashSHA512, HashSHA1, HashMD5)
+	}
+}
+
 // CalculateContentHash generates a SHA256 hash of the content
 func CalculateContentHash(content []byte) string {
 	hash := sha256.Sum256(content)
@@ -95,3 +95,62 @@ func TestHashUniqueness(t *testing.T) {
 		t.Errorf("Different content produced same hash: %q", hash1)
 	}
 }
+
+func TestParseHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "sha256", wantLen: 64},
+		{name: "sha512", wantLen: 128},
+		{name: "sha1", wantLen: 40},
+		{name: "md5", wantLen: 32},
+		{name: "crc32", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := ParseHashAlgorithm(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHashAlgorithm(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := hash([]byte("hello world")); len(got) != tt.wantLen {
+				t.Errorf("hash length = %d, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+
+	// The default algorithm must keep producing the same hashes as before
+	sha256, _ := ParseHashAlgorithm(HashSHA256)
+	if got, want := sha256([]byte("hello world")), CalculateStringHash("hello world"); got != want {
+		t.Errorf("sha256 = %q, want %q", got, want)
+	}
+}
+
+func TestParseHashAlgorithmDigests(t *testing.T) {
+	// Digests of "hello world", as printed by md5sum, sha1sum, sha256sum and sha512sum
+	tests := map[string]string{
+		HashMD5:    "5eb63bbbe01eeed093cb22bb8f5acdc3",
+		HashSHA1:   "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed",
+		HashSHA256: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		HashSHA512: "309ecc489c12d6eb4cc40f50c902f2b4d0ed77ee511a7c7a9bcd3ca86d4cd86f989dd35bc5ff499670da34255b45b0cfd830e81f605dcf7dc5542e93ae9cd76f",
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			hash, err := ParseHashAlgorithm(name)
+			if err != nil {
+				t.Fatalf("ParseHashAlgorithm(%q) error = %v", name, err)
+			}
+			if got := hash([]byte("hello world")); got != want {
+				t.Errorf("%s(\"hello world\") = %q, want %q", name, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "strings"
+
+// File type categories of discovered files
+const (
+	FileTypeGoogleDoc   = "google-doc"
+	FileTypeGoogleSheet = "google-sheet"
+	FileTypeGoogleSlide = "google-slide"
+	FileTypeGoogleForm  = "google-form"
+	FileTypePDF         = "pdf"
+	FileTypeImage       = "image"
+	FileTypeVideo       = "video"
+	FileTypeFolder      = "folder"
+	FileTypeOther       = "other"
+)
+
+// ClassifyMimeType returns the file type category of a Drive MIME type, such as "google-doc"
+// for a Google Doc or "image" for any image/ type. Unknown and empty types are "other".
+func ClassifyMimeType(mimeType string) string {
+	switch {
+	case mimeType == "application/vnd.google-apps.document":
+		return FileTypeGoogleDoc
+	case mimeType == "application/vnd.google-apps.spreadsheet":
+		return FileTypeGoogleSheet
+	case mimeType == "application/vnd.google-apps.presentation":
+		return FileTypeGoogleSlide
+	case mimeType == "application/vnd.google-apps.form":
+		return FileTypeGoogleForm
+	case mimeType == "application/vnd.google-apps.folder":
+		return FileTypeFolder
+	case mimeType == "application/pdf":
+		return FileTypePDF
+	case strings.HasPrefix(mimeType, "image/"):
+		return FileTypeImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return FileTypeVideo
+	default:
+		return FileTypeOther
+	}
+}
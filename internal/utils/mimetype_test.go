@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestClassifyMimeType(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		want     string
+	}{
+		{mimeType: "application/vnd.google-apps.document", want: FileTypeGoogleDoc},
+		{mimeType: "application/vnd.google-apps.spreadsheet", want: FileTypeGoogleSheet},
+		{mimeType: "application/vnd.google-apps.presentation", want: FileTypeGoogleSlide},
+		{mimeType: "application/vnd.google-apps.form", want: FileTypeGoogleForm},
+		{mimeType: "application/vnd.google-apps.folder", want: FileTypeFolder},
+		{mimeType: "application/pdf", want: FileTypePDF},
+		{mimeType: "image/jpeg", want: FileTypeImage},
+		{mimeType: "image/png", want: FileTypeImage},
+		{mimeType: "video/mp4", want: FileTypeVideo},
+		{mimeType: "video/quicktime", want: FileTypeVideo},
+		{mimeType: "audio/mpeg", want: FileTypeOther},
+		{mimeType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", want: FileTypeOther},
+		{mimeType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", want: FileTypeOther},
+		{mimeType: "application/vnd.openxmlformats-officedocument.presentationml.presentation", want: FileTypeOther},
+		{mimeType: "application/octet-stream", want: FileTypeOther},
+		{mimeType: "", want: FileTypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mimeType, func(t *testing.T) {
+			if got := ClassifyMimeType(tt.mimeType); got != tt.want {
+				t.Errorf("ClassifyMimeType(%q) = %q, want %q", tt.mimeType, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 var (
@@ -15,7 +18,33 @@ var (
 	multiDots   = regexp.MustCompile(`\.+`)
 )
 
-// SanitizeFilename removes or replaces characters that are unsafe for filenames
+const (
+	// MaxComponentBytes is the longest file or directory name most filesystems allow. It is
+	// counted in bytes, which also keeps names within HFS+'s limit of 255 characters.
+	MaxComponentBytes = 255
+
+	// MaxPathBytes is the longest path Linux allows
+	MaxPathBytes = 4096
+
+	// maxExtBytes is the longest suffix truncateComponent keeps as a file extension
+	maxExtBytes = 16
+)
+
+// UncategorizedDir is the fragment directory for records without a value for a fragment
+// output is split by. Sanitizing strips leading underscores, so no real fragment maps to it.
+const UncategorizedDir = "_uncategorized"
+
+// sanitizeFragment sanitizes a fragment directory name, keeping UncategorizedDir as is
+func sanitizeFragment(frag string) string {
+	if frag == UncategorizedDir {
+		return frag
+	}
+	return SanitizeFilename(frag)
+}
+
+// SanitizeFilename removes or replaces characters that are unsafe for filenames. Only the
+// characters filesystems reject (<>:"/\|?* and control characters) are replaced, so Unicode
+// letters and digits such as é, ü and ñ are kept.
 func SanitizeFilename(name string) string {
 	// Replace unsafe characters with underscore
 	sanitized := unsafeChars.ReplaceAllString(name, "_")
@@ -34,27 +63,87 @@ func SanitizeFilename(name string) string {
 		sanitized = "untitled"
 	}
 
-	// Limit length to 255 characters (common filesystem limit)
-	if len(sanitized) > 255 {
-		sanitized = sanitized[:255]
+	return truncateComponent(sanitized)
+}
+
+// truncateComponent shortens a file or directory name to MaxComponentBytes, keeping a short
+// extension. The cut is made at the last space, hyphen or underscore in the second half of the
+// kept text, and never inside a UTF-8 sequence.
+func truncateComponent(name string) string {
+	if len(name) <= MaxComponentBytes {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	if len(ext) > maxExtBytes || strings.ContainsAny(ext, " \t") {
+		ext = ""
+	}
+	stem := strings.TrimSuffix(name, ext)
+
+	cut := MaxComponentBytes - len(ext)
+	for cut > 0 && !utf8.RuneStart(stem[cut]) {
+		cut--
+	}
+	stem = stem[:cut]
+	if i := strings.LastIndexAny(stem, " -_"); i > cut/2 {
+		stem = stem[:i]
+	}
+	return strings.TrimRight(stem, " -_.") + ext
+}
+
+// ValidateOutputPath returns path with every component longer than MaxComponentBytes
+// truncated, or an error when the truncated path is still longer than MaxPathBytes
+func ValidateOutputPath(path string) (string, error) {
+	parts := strings.Split(path, string(filepath.Separator))
+	for i, part := range parts {
+		parts[i] = truncateComponent(part)
+	}
+	validated := strings.Join(parts, string(filepath.Separator))
+
+	if len(validated) > MaxPathBytes {
+		return "", fmt.Errorf("output path is %d bytes, longer than the %d byte limit: %s...", len(validated), MaxPathBytes, validated[:64])
 	}
+	return validated, nil
+}
+
+// ShortFilename returns a filename made of a hash of name and ext, for names that cannot be
+// truncated without colliding with another
+func ShortFilename(name, ext string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:8]) + ext
+}
 
-	return sanitized
+// BuildFragmentDir returns the directory the fragments map to below an output directory,
+// <frag1>/<frag2>/.../<fragN>, skipping empty fragments
+func BuildFragmentDir(fragments []string) string {
+	var parts []string
+	for _, frag := range fragments {
+		if frag != "" {
+			parts = append(parts, sanitizeFragment(frag))
+		}
+	}
+	return filepath.Join(parts...)
 }
 
 // BuildOutputPath constructs the output path from fragments and title
-// output/<frag1>/<frag2>/<frag3>/<frag4>/<frag5>/<title>.md
+// output/<frag1>/<frag2>/.../<fragN>/<title>.md
 func BuildOutputPath(baseDir, title string, fragments []string) string {
+	return BuildOutputPathWithExt(baseDir, title, fragments, ".md")
+}
+
+// BuildOutputPathWithExt constructs the output path like BuildOutputPath, with the file
+// extension ext (including the dot) instead of .md
+func BuildOutputPathWithExt(baseDir, title string, fragments []string, ext string) string {
 	// Filter out empty fragments
 	var parts []string
 	for _, frag := range fragments {
 		if frag != "" {
-			parts = append(parts, SanitizeFilename(frag))
+			parts = append(parts, sanitizeFragment(frag))
 		}
 	}
 
-	// Add sanitized title with .md extension
-	filename := SanitizeFilename(title) + ".md"
+	// Add sanitized title with extension
+	filename := truncateComponent(SanitizeFilename(title) + ext)
 	parts = append(parts, filename)
 
 	// Join all parts
@@ -64,21 +153,27 @@ func BuildOutputPath(baseDir, title string, fragments []string) string {
 // CalculateRelativePath calculates the relative path from source to target
 // based on their fragment hierarchies
 func CalculateRelativePath(sourceFragments, targetFragments []string, targetTitle string) string {
+	return CalculateRelativePathWithExt(sourceFragments, targetFragments, targetTitle, ".md")
+}
+
+// CalculateRelativePathWithExt calculates the relative path like CalculateRelativePath, for a
+// target file with extension ext (including the dot) instead of .md
+func CalculateRelativePathWithExt(sourceFragments, targetFragments []string, targetTitle, ext string) string {
 	// Filter empty fragments
 	var srcParts, tgtParts []string
 	for _, frag := range sourceFragments {
 		if frag != "" {
-			srcParts = append(srcParts, SanitizeFilename(frag))
+			srcParts = append(srcParts, sanitizeFragment(frag))
 		}
 	}
 	for _, frag := range targetFragments {
 		if frag != "" {
-			tgtParts = append(tgtParts, SanitizeFilename(frag))
+			tgtParts = append(tgtParts, sanitizeFragment(frag))
 		}
 	}
 
 	// Add target filename
-	tgtParts = append(tgtParts, SanitizeFilename(targetTitle)+".md")
+	tgtParts = append(tgtParts, truncateComponent(SanitizeFilename(targetTitle)+ext))
 
 	// Find common prefix
 	commonLen := 0
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestSanitizeFilename(t *testing.T) {
@@ -46,6 +48,16 @@ func TestSanitizeFilename(t *testing.T) {
 			input:    "<<<>>>",
 			expected: "untitled",
 		},
+		{
+			name:     "unicode letters kept",
+			input:    "Café Über Niño 東京",
+			expected: "Café Über Niño 東京",
+		},
+		{
+			name:     "control characters",
+			input:    "tab\tnew\x01line",
+			expected: "tab_new_line",
+		},
 	}
 
 	for _, tt := range tests {
@@ -94,6 +106,27 @@ func TestBuildOutputPath(t *testing.T) {
 			fragments: []string{"guides/bad", "test<>", "", "", ""},
 			expected:  filepath.Join("/output", "guides_bad", "test", "Test_Doc.md"),
 		},
+		{
+			name:      "fragments with unicode",
+			baseDir:   "/output",
+			title:     "Año Nuevo",
+			fragments: []string{"Résumé", "Größe/Maße", "", "", ""},
+			expected:  filepath.Join("/output", "Résumé", "Größe_Maße", "Año Nuevo.md"),
+		},
+		{
+			name:      "uncategorized fragment kept",
+			baseDir:   "/output",
+			title:     "Notes",
+			fragments: []string{UncategorizedDir, "_drafts", "", "", ""},
+			expected:  filepath.Join("/output", "_uncategorized", "drafts", "Notes.md"),
+		},
+		{
+			name:      "ten fragments",
+			baseDir:   "/output",
+			title:     "Deep",
+			fragments: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"},
+			expected:  filepath.Join("/output", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "Deep.md"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -106,6 +139,17 @@ func TestBuildOutputPath(t *testing.T) {
 	}
 }
 
+func TestWithExtVariants(t *testing.T) {
+	if got, want := BuildOutputPathWithExt("/output", "Doc", []string{"guides", "", "", "", ""}, ".html"), filepath.Join("/output", "guides", "Doc.html"); got != want {
+		t.Errorf("BuildOutputPathWithExt() = %q, want %q", got, want)
+	}
+
+	got := CalculateRelativePathWithExt([]string{"guides", "", "", "", ""}, []string{"reference", "", "", "", ""}, "target", ".adoc")
+	if want := filepath.Join("..", "reference", "target.adoc"); got != want {
+		t.Errorf("CalculateRelativePathWithExt() = %q, want %q", got, want)
+	}
+}
+
 func TestCalculateRelativePath(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -156,6 +200,13 @@ func TestCalculateRelativePath(t *testing.T) {
 			targetTitle:     "target",
 			expected:        filepath.Join("guides", "tutorials", "target.md"),
 		},
+		{
+			name:            "eight and ten fragments",
+			sourceFragments: []string{"a", "b", "c", "d", "e", "f", "g", "h"},
+			targetFragments: []string{"a", "b", "c", "d", "e", "x", "y", "z", "i", "j"},
+			targetTitle:     "target",
+			expected:        filepath.Join("..", "..", "..", "x", "y", "z", "i", "j", "target.md"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -168,6 +219,74 @@ func TestCalculateRelativePath(t *testing.T) {
 	}
 }
 
+func TestTruncateComponent(t *testing.T) {
+	words := strings.Repeat("release notes ", 30) // 420 bytes
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "short", input: "runbook.md", want: "runbook.md"},
+		{name: "word boundary keeps extension", input: words + ".md", want: strings.TrimSpace(words[:252]) + ".md"},
+		{name: "cut mid-word backs up to the space", input: strings.Repeat("abcdefghij ", 30) + ".md", want: strings.TrimSpace(strings.Repeat("abcdefghij ", 22)) + ".md"},
+		{name: "no word boundary", input: strings.Repeat("a", 300), want: strings.Repeat("a", 255)},
+		{name: "multi-byte runes", input: strings.Repeat("ä", 200), want: strings.Repeat("ä", 127)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateComponent(tt.input)
+			if got != tt.want {
+				t.Errorf("truncateComponent() = %q (%d bytes), want %q (%d bytes)", got, len(got), tt.want, len(tt.want))
+			}
+			if len(got) > MaxComponentBytes || !utf8.ValidString(got) {
+				t.Errorf("truncateComponent() = %d bytes, valid UTF-8 %v", len(got), utf8.ValidString(got))
+			}
+		})
+	}
+}
+
+func TestValidateOutputPath(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	got, err := ValidateOutputPath(filepath.Join("output", long, "doc.md"))
+	if err != nil {
+		t.Fatalf("ValidateOutputPath() error = %v", err)
+	}
+	if want := filepath.Join("output", strings.Repeat("x", 255), "doc.md"); got != want {
+		t.Errorf("ValidateOutputPath() = %q, want the long directory truncated", got)
+	}
+
+	deep := filepath.Join(strings.Split(strings.Repeat("fragment-directory/", 250), "/")...)
+	if _, err := ValidateOutputPath(filepath.Join("output", deep, "doc.md")); err == nil {
+		t.Errorf("ValidateOutputPath() for a %d byte path error = nil, want error", len(deep))
+	}
+}
+
+func TestBuildOutputPathLongTitle(t *testing.T) {
+	title := strings.Repeat("quarterly planning ", 20)
+	path := BuildOutputPath("output", title, []string{"guides"})
+	filename := filepath.Base(path)
+	if len(filename) > MaxComponentBytes || !strings.HasSuffix(filename, ".md") {
+		t.Errorf("filename = %q (%d bytes), want at most %d bytes ending in .md", filename, len(filename), MaxComponentBytes)
+	}
+
+	// Links to the document use the same truncated filename
+	if rel := CalculateRelativePath([]string{"guides"}, []string{"guides"}, title); rel != filename {
+		t.Errorf("CalculateRelativePath() = %q, want %q", rel, filename)
+	}
+}
+
+func TestShortFilename(t *testing.T) {
+	a := ShortFilename(strings.Repeat("a", 300), ".md")
+	b := ShortFilename(strings.Repeat("a", 299)+"b", ".md")
+	if a == b || len(a) != 19 || !strings.HasSuffix(a, ".md") {
+		t.Errorf("ShortFilename() = %q and %q, want distinct 16-character hashes with .md", a, b)
+	}
+	if again := ShortFilename(strings.Repeat("a", 300), ".md"); again != a {
+		t.Errorf("ShortFilename() = %q, then %q, want stable names", a, again)
+	}
+}
+
 func TestEnsureUniquePath(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,261 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// RetryConfig controls exponential backoff for retried Drive API calls
+type RetryConfig struct {
+	MaxRetries     int           // Retries before the final attempt
+	BaseDelay      time.Duration // Delay before the first retry, doubled for each retry after it
+	MaxDelay       time.Duration // Upper bound on a single delay (0 = uncapped)
+	JitterFraction float64       // Randomizes each delay by up to ± this fraction (0-1)
+	Budget         *RetryBudget  // Retries shared by every call of the run (nil = unlimited)
+}
+
+// DefaultRetryBudget is the default number of retries allowed across all API calls of a run
+const DefaultRetryBudget = 100
+
+// retryBudgetWarningRatio is the share of the retry budget after which a warning is logged
+const retryBudgetWarningRatio = 0.8
+
+// ErrRetryBudgetExhausted is returned for errors that were not retried because the run's retry
+// budget is used up
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudget caps the retries made across all workers of a run, so a run where every file is
+// rate limited cannot make MaxRetries extra calls per file. It is safe for concurrent use.
+type RetryBudget struct {
+	limit     int64
+	remaining atomic.Int64
+	warned    atomic.Bool
+}
+
+// NewRetryBudget creates a budget that allows limit retries. A limit of 0 means unlimited.
+func NewRetryBudget(limit int64) *RetryBudget {
+	b := &RetryBudget{limit: limit}
+	b.remaining.Store(limit)
+	return b
+}
+
+// Take uses one retry from the budget and reports whether it was available. It logs a warning
+// once 80% of the budget is used. A nil budget always has retries left.
+func (b *RetryBudget) Take() bool {
+	if b == nil || b.limit == 0 {
+		return true
+	}
+
+	remaining := b.remaining.Add(-1)
+	if remaining < 0 {
+		b.remaining.Add(1)
+		return false
+	}
+
+	used := b.limit - remaining
+	if float64(used) >= retryBudgetWarningRatio*float64(b.limit) && b.warned.CompareAndSwap(false, true) {
+		log.Printf("Warning: %d of the retry budget of %d retries used", used, b.limit)
+	}
+	return true
+}
+
+// Used returns the number of retries taken from the budget
+func (b *RetryBudget) Used() int64 {
+	if b == nil || b.limit == 0 {
+		return 0
+	}
+	return b.limit - b.remaining.Load()
+}
+
+// Limit returns the number of retries the budget allows (0 = unlimited)
+func (b *RetryBudget) Limit() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
+
+// TakeRetry uses one retry from the budget, if any. When the budget is used up it returns err
+// wrapped with ErrRetryBudgetExhausted, to be returned instead of retrying.
+func (rc RetryConfig) TakeRetry(err error) error {
+	if rc.Budget.Take() {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+}
+
+// DefaultRetryConfig returns the retry settings used when none are configured. Delays are
+// randomized by ±25% so workers rate limited at the same moment do not retry in lockstep.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:     5,
+		BaseDelay:      time.Second,
+		MaxDelay:       60 * time.Second,
+		JitterFraction: 0.25,
+	}
+}
+
+// sleep waits for d or until ctx is done. Tests replace it to record delays.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Do calls fn until it succeeds, retrying rate limits and transient server errors (see
+// IsRetryableError) up to MaxRetries times with backoff from DelayForError. Each retry is
+// taken from the budget; once it is used up, the error is returned wrapped with
+// ErrRetryBudgetExhausted. Other errors are returned at once. verbose logs each retry.
+func (rc RetryConfig) Do(ctx context.Context, verbose bool, fn func() error) error {
+	return rc.DoIf(ctx, verbose, IsRetryableError, fn)
+}
+
+// DoIf calls fn like Do, retrying the errors for which retryable returns true
+func (rc RetryConfig) DoIf(ctx context.Context, verbose bool, retryable func(error) bool, fn func() error) error {
+	for i := 0; i < rc.MaxRetries; i++ {
+		err := fn()
+		if err == nil || !retryable(err) {
+			return err
+		}
+
+		if exhausted := rc.TakeRetry(err); exhausted != nil {
+			return exhausted
+		}
+		delay := rc.DelayForError(err, i)
+		if verbose {
+			log.Printf("Retryable error (%v), retrying in %v...", err, delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+
+	return fn() // Final attempt
+}
+
+// Validate returns an error if the configuration cannot be used
+func (rc RetryConfig) Validate() error {
+	if rc.MaxRetries < 0 {
+		return fmt.Errorf("max retries must not be negative, got %d", rc.MaxRetries)
+	}
+	if rc.BaseDelay <= 0 {
+		return fmt.Errorf("base delay must be positive, got %v", rc.BaseDelay)
+	}
+	if rc.MaxDelay < 0 {
+		return fmt.Errorf("max delay must not be negative, got %v", rc.MaxDelay)
+	}
+	if rc.JitterFraction < 0 || rc.JitterFraction > 1 {
+		return fmt.Errorf("jitter must be between 0 and 1, got %v", rc.JitterFraction)
+	}
+	if rc.Budget.Limit() < 0 {
+		return fmt.Errorf("retry budget must not be negative, got %d", rc.Budget.Limit())
+	}
+	return nil
+}
+
+// maxDuration is the longest delay Delay returns
+const maxDuration = time.Duration(math.MaxInt64)
+
+// Delay returns how long to wait before retry number attempt (0-based)
+func (rc RetryConfig) Delay(attempt int) time.Duration {
+	delay := rc.BaseDelay
+	for i := 0; i < attempt; i++ {
+		// Stop doubling once capped so large attempts cannot overflow
+		if rc.MaxDelay > 0 && delay >= rc.MaxDelay {
+			break
+		}
+		// Without a cap, saturate instead of overflowing into a negative delay
+		if delay > maxDuration/2 {
+			delay = maxDuration
+			break
+		}
+		delay *= 2
+	}
+	if rc.MaxDelay > 0 && delay > rc.MaxDelay {
+		delay = rc.MaxDelay
+	}
+
+	if rc.JitterFraction > 0 {
+		jittered := float64(delay) * (1 + (rand.Float64()*2-1)*rc.JitterFraction)
+		if jittered >= float64(maxDuration) {
+			return maxDuration
+		}
+		delay = time.Duration(jittered)
+	}
+
+	return delay
+}
+
+// DelayForError returns how long to wait before retry number attempt (0-based) of a request
+// that failed with err. Internal server errors are usually momentary, so their backoff starts
+// from half the base delay. When the response has a Retry-After header, the wait is at least
+// as long as the server asked for.
+func (rc RetryConfig) DelayForError(err error, attempt int) time.Duration {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return rc.Delay(attempt)
+	}
+
+	if apiErr.Code == http.StatusInternalServerError {
+		rc.BaseDelay /= 2
+	}
+	delay := rc.Delay(attempt)
+	if retryAfter, ok := parseRetryAfter(apiErr.Header.Get("Retry-After"), time.Now()); ok && retryAfter > delay {
+		delay = retryAfter
+	}
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header value, given either as seconds or as an HTTP
+// date, into the time to wait from now
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// IsRetryableError reports whether a Drive API call that failed with err should be retried:
+// rate limits (403, 429) and transient server errors (500, 503)
+func IsRetryableError(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.Code {
+	case http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,393 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestRetryConfigDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RetryConfig
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first retry", config: unjittered(), attempt: 0, want: time.Second},
+		{name: "doubles", config: unjittered(), attempt: 3, want: 8 * time.Second},
+		{name: "capped at max delay", config: unjittered(), attempt: 6, want: 60 * time.Second},
+		{name: "large attempt does not overflow", config: unjittered(), attempt: 100, want: 60 * time.Second},
+		{name: "uncapped", config: RetryConfig{BaseDelay: time.Millisecond}, attempt: 10, want: 1024 * time.Millisecond},
+		{name: "uncapped large attempt saturates", config: RetryConfig{BaseDelay: time.Second}, attempt: 40, want: time.Duration(math.MaxInt64)},
+		{name: "uncapped huge attempt saturates", config: RetryConfig{BaseDelay: time.Second}, attempt: 1000, want: time.Duration(math.MaxInt64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+// unjittered returns the default retry settings without jitter, so delays are exact
+func unjittered() RetryConfig {
+	config := DefaultRetryConfig()
+	config.JitterFraction = 0
+	return config
+}
+
+func TestRetryConfigDelayUncappedJitter(t *testing.T) {
+	config := RetryConfig{BaseDelay: time.Second, JitterFraction: 0.5}
+	for attempt := 0; attempt < 100; attempt++ {
+		if got := config.Delay(attempt); got <= 0 {
+			t.Fatalf("Delay(%d) = %v, want a positive delay", attempt, got)
+		}
+	}
+}
+
+func TestDefaultRetryConfigJitter(t *testing.T) {
+	config := DefaultRetryConfig()
+	for i := 0; i < 100; i++ {
+		got := config.Delay(2)
+		if got < 3*time.Second || got > 5*time.Second {
+			t.Fatalf("Delay(2) = %v, want within 4s ± 25%%", got)
+		}
+	}
+}
+
+func TestRetryConfigDelayJitter(t *testing.T) {
+	config := RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, JitterFraction: 0.5}
+
+	for i := 0; i < 100; i++ {
+		got := config.Delay(1)
+		if got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("Delay(1) = %v, want within 200ms ± 50%%", got)
+		}
+	}
+}
+
+func TestRetryConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RetryConfig
+		wantErr bool
+	}{
+		{name: "default", config: DefaultRetryConfig()},
+		{name: "no retries", config: RetryConfig{MaxRetries: 0, BaseDelay: time.Second}},
+		{name: "negative retries", config: RetryConfig{MaxRetries: -1, BaseDelay: time.Second}, wantErr: true},
+		{name: "zero base delay", config: RetryConfig{MaxRetries: 1}, wantErr: true},
+		{name: "negative max delay", config: RetryConfig{BaseDelay: time.Second, MaxDelay: -time.Second}, wantErr: true},
+		{name: "jitter above one", config: RetryConfig{BaseDelay: time.Second, JitterFraction: 1.5}, wantErr: true},
+		{name: "negative retry budget", config: RetryConfig{BaseDelay: time.Second, Budget: NewRetryBudget(-1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := NewRetryBudget(5)
+	for i := 0; i < 5; i++ {
+		if !b.Take() {
+			t.Fatalf("Take() call %d = false, want true", i+1)
+		}
+	}
+	if !b.warned.Load() {
+		t.Errorf("no warning after using the whole budget")
+	}
+	if b.Take() {
+		t.Errorf("Take() over the budget = true, want false")
+	}
+	if got := b.Used(); got != 5 {
+		t.Errorf("Used() = %d, want 5", got)
+	}
+
+	rc := RetryConfig{Budget: b}
+	cause := errors.New("rate limited")
+	if err := rc.TakeRetry(cause); !errors.Is(err, ErrRetryBudgetExhausted) || !errors.Is(err, cause) {
+		t.Errorf("TakeRetry() error = %v, want %v wrapping the cause", err, ErrRetryBudgetExhausted)
+	}
+
+	// Without a budget, or with a limit of 0, retries are unlimited
+	for _, unlimited := range []*RetryBudget{nil, NewRetryBudget(0)} {
+		for i := 0; i < 200; i++ {
+			if !unlimited.Take() {
+				t.Fatalf("Take() = false, want unlimited retries")
+			}
+		}
+		if got := unlimited.Used(); got != 0 {
+			t.Errorf("Used() = %d, want 0 for an unlimited budget", got)
+		}
+	}
+}
+
+func TestRetryBudgetWarning(t *testing.T) {
+	b := NewRetryBudget(10)
+	for i := 0; i < 7; i++ {
+		b.Take()
+	}
+	if b.warned.Load() {
+		t.Errorf("warned after 70%% of the budget")
+	}
+	b.Take()
+	if !b.warned.Load() {
+		t.Errorf("no warning after 80%% of the budget")
+	}
+}
+
+func TestRetryBudgetConcurrent(t *testing.T) {
+	b := NewRetryBudget(50)
+	var wg sync.WaitGroup
+	var taken atomic.Int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if b.Take() {
+					taken.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := taken.Load(); got != 50 {
+		t.Errorf("took %d retries, want 50", got)
+	}
+}
+
+// recordSleeps replaces sleep for the test and returns the delays slept so far
+func recordSleeps(t *testing.T) func() []time.Duration {
+	var mu sync.Mutex
+	var delays []time.Duration
+	original := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = original })
+
+	return func() []time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Duration(nil), delays...)
+	}
+}
+
+func TestRetryConfigDo(t *testing.T) {
+	rateLimited := &googleapi.Error{Code: 429}
+	notFound := &googleapi.Error{Code: 404}
+
+	tests := []struct {
+		name      string
+		failures  []error // Errors returned by the first calls, before succeeding
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success", wantCalls: 1},
+		{name: "retried", failures: []error{rateLimited, rateLimited}, wantCalls: 3},
+		{name: "not retryable", failures: []error{notFound}, wantCalls: 1, wantErr: notFound},
+		{name: "final attempt fails", failures: []error{rateLimited, rateLimited, rateLimited, rateLimited}, wantCalls: 4, wantErr: rateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slept := recordSleeps(t)
+			config := RetryConfig{MaxRetries: 3, BaseDelay: time.Second}
+
+			calls := 0
+			err := config.Do(context.Background(), false, func() error {
+				calls++
+				if calls <= len(tt.failures) {
+					return tt.failures[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+			if got := len(slept()); got != tt.wantCalls-1 {
+				t.Errorf("slept %d times, want %d", got, tt.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestRetryConfigDoIf(t *testing.T) {
+	slept := recordSleeps(t)
+	config := RetryConfig{MaxRetries: 3, BaseDelay: time.Second}
+	notFound := &googleapi.Error{Code: 404, Header: http.Header{"Retry-After": {"30"}}}
+	unavailable := errors.New("connection reset")
+
+	// The predicate decides what is retried, and the backoff still follows DelayForError
+	failures := []error{notFound, unavailable}
+	calls := 0
+	err := config.DoIf(context.Background(), false, func(error) bool { return true }, func() error {
+		calls++
+		if calls <= len(failures) {
+			return failures[calls-1]
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("DoIf() error = %v after %d calls, want nil after 3", err, calls)
+	}
+	if got, want := slept(), []time.Duration{30 * time.Second, 2 * time.Second}; !slices.Equal(got, want) {
+		t.Errorf("slept %v, want %v", got, want)
+	}
+
+	calls = 0
+	err = config.DoIf(context.Background(), false, func(error) bool { return false }, func() error {
+		calls++
+		return unavailable
+	})
+	if !errors.Is(err, unavailable) || calls != 1 {
+		t.Errorf("DoIf() error = %v after %d calls, want %v after 1", err, calls, unavailable)
+	}
+}
+
+func TestRetryConfigDoCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	config := RetryConfig{MaxRetries: 3, BaseDelay: time.Hour}
+	err := config.Do(ctx, false, func() error {
+		calls++
+		return &googleapi.Error{Code: 503}
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("Do() error = %v after %d calls, want %v after 1", err, calls, context.Canceled)
+	}
+}
+
+func TestRetryConfigDoConcurrentJitter(t *testing.T) {
+	slept := recordSleeps(t)
+	config := DefaultRetryConfig()
+	config.MaxRetries = 1
+
+	// Both workers are rate limited at once and back off at the same level
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			config.Do(context.Background(), false, func() error {
+				return &googleapi.Error{Code: 429}
+			})
+		}()
+	}
+	wg.Wait()
+
+	delays := slept()
+	if len(delays) != 2 {
+		t.Fatalf("slept %d times, want 2", len(delays))
+	}
+	if delays[0] == delays[1] {
+		t.Errorf("both workers slept %v, want different delays", delays[0])
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "forbidden", err: &googleapi.Error{Code: 403}, want: true},
+		{name: "too many requests", err: &googleapi.Error{Code: 429}, want: true},
+		{name: "internal server error", err: &googleapi.Error{Code: 500}, want: true},
+		{name: "service unavailable", err: &googleapi.Error{Code: 503}, want: true},
+		{name: "wrapped", err: fmt.Errorf("export failed: %w", &googleapi.Error{Code: 503}), want: true},
+		{name: "not found", err: &googleapi.Error{Code: 404}, want: false},
+		{name: "bad gateway", err: &googleapi.Error{Code: 502}, want: false},
+		{name: "not an API error", err: errors.New("connection reset"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryConfigDelayForError(t *testing.T) {
+	config := unjittered()
+
+	tests := []struct {
+		name    string
+		err     error
+		attempt int
+		want    time.Duration
+	}{
+		{name: "internal server error first retry", err: &googleapi.Error{Code: 500}, attempt: 0, want: 500 * time.Millisecond},
+		{name: "internal server error doubles", err: &googleapi.Error{Code: 500}, attempt: 2, want: 2 * time.Second},
+		{name: "service unavailable", err: &googleapi.Error{Code: 503}, attempt: 0, want: time.Second},
+		{name: "rate limited", err: &googleapi.Error{Code: 429}, attempt: 1, want: 2 * time.Second},
+		{name: "retry-after longer than backoff", err: &googleapi.Error{Code: 429, Header: http.Header{"Retry-After": {"30"}}}, attempt: 0, want: 30 * time.Second},
+		{name: "retry-after shorter than backoff", err: &googleapi.Error{Code: 429, Header: http.Header{"Retry-After": {"1"}}}, attempt: 3, want: 8 * time.Second},
+		{name: "invalid retry-after", err: &googleapi.Error{Code: 503, Header: http.Header{"Retry-After": {"soon"}}}, attempt: 0, want: time.Second},
+		{name: "not an API error", err: errors.New("connection reset"), attempt: 0, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.DelayForError(tt.err, tt.attempt); got != tt.want {
+				t.Errorf("DelayForError(%v, %d) = %v, want %v", tt.err, tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "120", want: 2 * time.Minute, wantOK: true},
+		{name: "zero seconds", value: "0", want: 0, wantOK: true},
+		{name: "http date", value: "Mon, 15 Jan 2024 10:30:45 GMT", want: 45 * time.Second, wantOK: true},
+		{name: "http date in the past", value: "Mon, 15 Jan 2024 10:00:00 GMT", want: 0, wantOK: true},
+		{name: "empty", value: ""},
+		{name: "negative", value: "-5"},
+		{name: "garbage", value: "later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
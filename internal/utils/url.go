@@ -22,6 +22,15 @@ func ExtractFileID(urlStr string) (string, error) {
 		return "", fmt.Errorf("not a Google Drive URL")
 	}
 
+	// Open-with-app links carry the ID only in the query: /open?id={id}. Other long query
+	// values, such as resource keys, must not be mistaken for the ID.
+	if u.Path == "/open" {
+		if id := u.Query().Get("id"); id != "" {
+			return id, nil
+		}
+		return "", fmt.Errorf("missing id parameter in URL: %s", urlStr)
+	}
+
 	// Try to extract ID from path
 	// Format: /file/d/{id}/...
 	// Format: /folders/{id}
@@ -53,6 +62,28 @@ func ExtractFileID(urlStr string) (string, error) {
 	return "", fmt.Errorf("could not extract file ID from URL: %s", urlStr)
 }
 
+// NormalizeURL strips the parts of a Google Drive URL that do not identify the file: sharing
+// and tracking query parameters such as usp, authuser and ouid, and the fragment. Only the id
+// parameter of /open?id= links is kept. The host is lowercased.
+// Example: "https://docs.google.com/document/d/abc/edit?usp=sharing#heading=h.1" -> "https://docs.google.com/document/d/abc/edit"
+func NormalizeURL(urlStr string) (string, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+
+	id := u.Query().Get("id")
+	u.RawQuery = ""
+	if id != "" {
+		u.RawQuery = url.Values{"id": {id}}.Encode()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Host = strings.ToLower(u.Host)
+
+	return u.String(), nil
+}
+
 // NormalizeMultilineURLs fixes Google Drive/Docs URLs that are broken across multiple lines
 // and unescapes markdown characters within URLs
 // Example: "*https://docs.google.com/document/d/abc*\n*defg/edit*" -> "https://docs.google.com/document/d/abcdefg/edit"
@@ -46,6 +46,36 @@ func TestExtractFileID(t *testing.T) {
 			url:  "https://docs.google.com/document/d/abc123/edit?usp=sharing",
 			want: "abc123",
 		},
+		{
+			name: "Open-with-app URL",
+			url:  "https://drive.google.com/open?id=1AbCdEfGhIjKlMnOpQrStUvWxYz012345",
+			want: "1AbCdEfGhIjKlMnOpQrStUvWxYz012345",
+		},
+		{
+			name: "Open-with-app URL with other long query parameters",
+			url:  "https://drive.google.com/open?resourcekey=0-AbCdEfGhIjKlMnOpQrStUvWxYz&id=1AbCdEfGhIjKlMnOpQrStUvWxYz012345&authuser=0",
+			want: "1AbCdEfGhIjKlMnOpQrStUvWxYz012345",
+		},
+		{
+			name: "Open-with-app URL with short id",
+			url:  "https://drive.google.com/open?id=abc123",
+			want: "abc123",
+		},
+		{
+			name: "Open-with-app URL with id after other query parameters",
+			url:  "https://drive.google.com/open?usp=sharing&id=abc123",
+			want: "abc123",
+		},
+		{
+			name: "Open-with-app URL with fragment",
+			url:  "https://drive.google.com/open?id=abc123#heading=h.1",
+			want: "abc123",
+		},
+		{
+			name:    "Open-with-app URL without id",
+			url:     "https://drive.google.com/open?resourcekey=0-AbCdEfGhIjKlMnOpQrStUvWxYz",
+			wantErr: true,
+		},
 		{
 			name:    "Invalid URL - not Google Drive",
 			url:     "https://example.com/document/123",
@@ -77,6 +107,58 @@ func TestExtractFileID(t *testing.T) {
 	}
 }
 
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no query",
+			url:  "https://docs.google.com/document/d/abc123/edit",
+			want: "https://docs.google.com/document/d/abc123/edit",
+		},
+		{
+			name: "sharing parameters",
+			url:  "https://docs.google.com/document/d/abc123/edit?usp=sharing&authuser=0&ouid=1234567890",
+			want: "https://docs.google.com/document/d/abc123/edit",
+		},
+		{
+			name: "fragment",
+			url:  "https://docs.google.com/document/d/abc123/edit?usp=drive_link#heading=h.abc",
+			want: "https://docs.google.com/document/d/abc123/edit",
+		},
+		{
+			name: "open-with-app id kept",
+			url:  "https://drive.google.com/open?authuser=0&id=abc123",
+			want: "https://drive.google.com/open?id=abc123",
+		},
+		{
+			name: "host lowercased",
+			url:  "https://Docs.Google.com/document/d/abc123/edit",
+			want: "https://docs.google.com/document/d/abc123/edit",
+		},
+		{
+			name:    "malformed",
+			url:     "https://docs.google.com/%zz",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestBuildFileLink(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,293 @@
+package validation
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ExternalLinkCacheFile is the name of the link check cache in the output directory
+	ExternalLinkCacheFile = ".external-link-cache.json"
+
+	// DefaultLinkCheckTimeout bounds a single external link check
+	DefaultLinkCheckTimeout = 10 * time.Second
+
+	// DefaultLinkCacheTTL is how long a cached link check result is reused
+	DefaultLinkCacheTTL = 24 * time.Hour
+)
+
+// driveHosts are the hosts of Google Drive links, which are not external dependencies
+var driveHosts = map[string]bool{
+	"drive.google.com": true,
+	"docs.google.com":  true,
+}
+
+// ExternalLink is a link from a converted document to an external http(s) URL
+type ExternalLink struct {
+	SourceFile string // Path of the markdown file, relative to the output directory
+	LinkText   string // Text of the markdown link
+	URL        string
+	Domain     string
+	Checked    bool   // Whether the URL was checked
+	StatusCode int    // HTTP status of the check (0 if the request failed)
+	Error      string // Why the request failed
+}
+
+// Dead reports whether the check found the link broken: an error status or a failed request
+func (l ExternalLink) Dead() bool {
+	return l.Checked && (l.Error != "" || l.StatusCode >= 400)
+}
+
+// FindExternalLinks returns every markdown link to an http(s) URL outside Google Drive in the
+// markdown files under outputDir
+func FindExternalLinks(outputDir string) ([]ExternalLink, error) {
+	files, err := listFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []ExternalLink
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".md") {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		for _, match := range markdownLinkPattern.FindAllStringSubmatch(string(content), -1) {
+			u, err := url.Parse(match[2])
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				continue
+			}
+			domain := strings.ToLower(u.Hostname())
+			if driveHosts[domain] {
+				continue
+			}
+
+			links = append(links, ExternalLink{
+				SourceFile: file,
+				LinkText:   match[1],
+				URL:        match[2],
+				Domain:     domain,
+			})
+		}
+	}
+
+	return links, nil
+}
+
+// LinkStatus is the cached result of checking a URL
+type LinkStatus struct {
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// LinkCache stores link check results by URL in a JSON file. It is safe for concurrent use.
+type LinkCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]LinkStatus
+}
+
+// LoadLinkCache reads the cache at path. A missing file gives an empty cache.
+func LoadLinkCache(path string) (*LinkCache, error) {
+	cache := &LinkCache{path: path, entries: make(map[string]LinkStatus)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read link cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse link cache %s: %w", path, err)
+	}
+
+	return cache, nil
+}
+
+// Get returns the result for rawURL if it was checked within ttl
+func (c *LinkCache) Get(rawURL string, ttl time.Duration) (LinkStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, ok := c.entries[rawURL]
+	if !ok || time.Since(status.CheckedAt) > ttl {
+		return LinkStatus{}, false
+	}
+	return status, true
+}
+
+// Put stores the result for rawURL
+func (c *LinkCache) Put(rawURL string, status LinkStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[rawURL] = status
+}
+
+// Save writes the cache back to its file
+func (c *LinkCache) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode link cache: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write link cache: %w", err)
+	}
+	return nil
+}
+
+// LinkChecker checks external links with HTTP HEAD requests
+type LinkChecker struct {
+	Client   *http.Client  // Client used for checks; its Timeout bounds each URL
+	Workers  int           // Concurrent checks (values below 1 mean 1)
+	Cache    *LinkCache    // Results reused across runs (nil = no cache)
+	CacheTTL time.Duration // How long cached results are reused
+}
+
+// NewLinkChecker creates a LinkChecker with a per-URL timeout and the default cache TTL
+func NewLinkChecker(workers int, timeout time.Duration, cache *LinkCache) *LinkChecker {
+	return &LinkChecker{
+		Client:   &http.Client{Timeout: timeout},
+		Workers:  workers,
+		Cache:    cache,
+		CacheTTL: DefaultLinkCacheTTL,
+	}
+}
+
+// Check fills in the status of every link. Each distinct URL is requested at most once, and not
+// at all when the cache has a recent result for it.
+func (lc *LinkChecker) Check(ctx context.Context, links []ExternalLink) {
+	statuses := make(map[string]LinkStatus)
+	var pending []string
+	for _, link := range links {
+		if _, seen := statuses[link.URL]; seen {
+			continue
+		}
+		if lc.Cache != nil {
+			if status, ok := lc.Cache.Get(link.URL, lc.CacheTTL); ok {
+				statuses[link.URL] = status
+				continue
+			}
+		}
+		statuses[link.URL] = LinkStatus{}
+		pending = append(pending, link.URL)
+	}
+
+	var mu sync.Mutex
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < max(1, lc.Workers); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rawURL := range jobs {
+				status := lc.checkURL(ctx, rawURL)
+				if lc.Cache != nil {
+					lc.Cache.Put(rawURL, status)
+				}
+				mu.Lock()
+				statuses[rawURL] = status
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, rawURL := range pending {
+		jobs <- rawURL
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range links {
+		status := statuses[links[i].URL]
+		links[i].Checked = true
+		links[i].StatusCode = status.StatusCode
+		links[i].Error = status.Error
+	}
+}
+
+// checkURL requests rawURL with HEAD, falling back to GET for servers that do not support HEAD
+func (lc *LinkChecker) checkURL(ctx context.Context, rawURL string) LinkStatus {
+	status := LinkStatus{CheckedAt: time.Now().UTC()}
+
+	code, err := lc.request(ctx, http.MethodHead, rawURL)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = lc.request(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.StatusCode = code
+	return status
+}
+
+func (lc *LinkChecker) request(ctx context.Context, method, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := lc.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// WriteExternalLinksCSV writes links to a CSV file with the columns source_file, link_text,
+// url, domain and status_code. The status code is empty for unchecked links and "error" for
+// failed requests.
+func WriteExternalLinksCSV(filePath string, links []ExternalLink) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output CSV: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"source_file", "link_text", "url", "domain", "status_code"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, link := range links {
+		status := ""
+		switch {
+		case !link.Checked:
+		case link.Error != "":
+			status = "error"
+		default:
+			status = strconv.Itoa(link.StatusCode)
+		}
+
+		if err := writer.Write([]string{link.SourceFile, link.LinkText, link.URL, link.Domain, status}); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	return writer.Error()
+}
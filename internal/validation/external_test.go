@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFindExternalLinks(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"guides/intro.md": "See [Repo](https://github.com/org/repo), [Setup](setup.md), " +
+			"[Doc](https://docs.google.com/document/d/abc/edit), [Mail](mailto:ops@example.com) " +
+			"and [Jira](http://JIRA.example.com/browse/OPS-1 \"ticket\").",
+		"guides/notes.txt": "[Ignored](https://example.com)",
+	})
+
+	links, err := FindExternalLinks(dir)
+	if err != nil {
+		t.Fatalf("FindExternalLinks() error = %v", err)
+	}
+
+	want := []ExternalLink{
+		{SourceFile: "guides/intro.md", LinkText: "Repo", URL: "https://github.com/org/repo", Domain: "github.com"},
+		{SourceFile: "guides/intro.md", LinkText: "Jira", URL: "http://JIRA.example.com/browse/OPS-1", Domain: "jira.example.com"},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("FindExternalLinks() = %+v, want %+v", links, want)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("link %d = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+}
+
+func TestLinkCheckerCheck(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// A listener that is closed straight away refuses connections
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refusedURL := refused.URL + "/gone"
+	refused.Close()
+
+	cachePath := filepath.Join(t.TempDir(), ExternalLinkCacheFile)
+	cache, err := LoadLinkCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadLinkCache() error = %v", err)
+	}
+
+	links := []ExternalLink{
+		{URL: server.URL + "/ok"},
+		{URL: server.URL + "/ok"},
+		{URL: server.URL + "/no-head"},
+		{URL: server.URL + "/missing"},
+		{URL: refusedURL},
+	}
+	checker := NewLinkChecker(3, time.Second, cache)
+	checker.Check(context.Background(), links)
+
+	wantCodes := []int{200, 200, 200, 404, 0}
+	wantDead := []bool{false, false, false, true, true}
+	for i, link := range links {
+		if !link.Checked || link.StatusCode != wantCodes[i] || link.Dead() != wantDead[i] {
+			t.Errorf("link %d = %+v, want status %d, dead %v", i, link, wantCodes[i], wantDead[i])
+		}
+	}
+	// The duplicate /ok is requested once and /no-head falls back to GET
+	if got := requests.Load(); got != 4 {
+		t.Errorf("server got %d requests, want 4", got)
+	}
+
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	reloaded, err := LoadLinkCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadLinkCache() error = %v", err)
+	}
+
+	links = []ExternalLink{{URL: server.URL + "/missing"}}
+	NewLinkChecker(1, time.Second, reloaded).Check(context.Background(), links)
+	if got := requests.Load(); got != 4 {
+		t.Errorf("cached URL was requested again: %d requests", got)
+	}
+	if links[0].StatusCode != http.StatusNotFound {
+		t.Errorf("cached status = %d, want 404", links[0].StatusCode)
+	}
+
+	expired := NewLinkChecker(1, time.Second, reloaded)
+	expired.CacheTTL = 0
+	expired.Check(context.Background(), links)
+	if got := requests.Load(); got != 5 {
+		t.Errorf("expired URL was not requested again: %d requests", got)
+	}
+}
+
+func TestWriteExternalLinksCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.csv")
+	links := []ExternalLink{
+		{SourceFile: "a.md", LinkText: "Repo", URL: "https://github.com/org/repo", Domain: "github.com"},
+		{SourceFile: "a.md", LinkText: "Wiki", URL: "https://wiki.example.com", Domain: "wiki.example.com", Checked: true, StatusCode: 404},
+		{SourceFile: "b.md", LinkText: "Old", URL: "https://old.example.com", Domain: "old.example.com", Checked: true, Error: "connection refused"},
+	}
+
+	if err := WriteExternalLinksCSV(path, links); err != nil {
+		t.Fatalf("WriteExternalLinksCSV() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"source_file,link_text,url,domain,status_code",
+		"a.md,Repo,https://github.com/org/repo,github.com,",
+		"a.md,Wiki,https://wiki.example.com,wiki.example.com,404",
+		"b.md,Old,https://old.example.com,old.example.com,error",
+	}, "\n") + "\n"
+	if string(data) != want {
+		t.Errorf("CSV = %q, want %q", data, want)
+	}
+}
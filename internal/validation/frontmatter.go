@@ -0,0 +1,94 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/sync"
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// DefaultRequiredFields are the frontmatter fields ValidateFrontmatter requires when
+// FrontmatterOptions.RequiredFields is empty
+var DefaultRequiredFields = []string{"title", "hash-gdrive", "editor"}
+
+// FrontmatterOptions configures ValidateFrontmatter
+type FrontmatterOptions struct {
+	RequiredFields []string       // Fields every file must have with a non-empty value (empty = DefaultRequiredFields)
+	HashFunc       utils.HashFunc // Hash the hash-content field was written with (nil = utils.CalculateContentHash)
+}
+
+// FrontmatterIssue describes a problem with the frontmatter of a converted file
+type FrontmatterIssue struct {
+	SourceFile string `json:"source_file"`     // Path of the markdown file, relative to the output directory
+	Field      string `json:"field,omitempty"` // Field the problem is about, if any
+	Problem    string `json:"problem"`         // "invalid_frontmatter", "missing_field", "empty_field" or "hash_mismatch"
+	Message    string `json:"message"`
+}
+
+// ValidateFrontmatter checks the frontmatter of every markdown file under outputDir: each
+// required field must be present with a non-empty value, and hash-content, when present, must
+// match the hash of the body. Files are reported in path order.
+func ValidateFrontmatter(outputDir string, opts FrontmatterOptions) ([]FrontmatterIssue, error) {
+	if len(opts.RequiredFields) == 0 {
+		opts.RequiredFields = DefaultRequiredFields
+	}
+	if opts.HashFunc == nil {
+		opts.HashFunc = utils.CalculateContentHash
+	}
+
+	files, err := listFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []FrontmatterIssue
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".md") {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		issues = append(issues, validateFileFrontmatter(file, string(content), opts)...)
+	}
+
+	return issues, nil
+}
+
+// validateFileFrontmatter returns the frontmatter issues of one file
+func validateFileFrontmatter(file, content string, opts FrontmatterOptions) []FrontmatterIssue {
+	frontmatter, body, err := sync.ParseFrontmatter(content)
+	if err != nil {
+		return []FrontmatterIssue{{SourceFile: file, Problem: "invalid_frontmatter", Message: err.Error()}}
+	}
+
+	var issues []FrontmatterIssue
+	for _, field := range opts.RequiredFields {
+		value, ok := frontmatter[field]
+		switch {
+		case !ok:
+			issues = append(issues, FrontmatterIssue{SourceFile: file, Field: field, Problem: "missing_field", Message: "missing field " + field})
+		case strings.TrimSpace(value) == "":
+			issues = append(issues, FrontmatterIssue{SourceFile: file, Field: field, Problem: "empty_field", Message: "empty field " + field})
+		}
+	}
+
+	// Convert writes the frontmatter, a blank line and then the hashed content
+	if want, ok := frontmatter["hash-content"]; ok && want != "" {
+		if got := opts.HashFunc([]byte(strings.TrimPrefix(body, "\n"))); got != want {
+			issues = append(issues, FrontmatterIssue{
+				SourceFile: file,
+				Field:      "hash-content",
+				Problem:    "hash_mismatch",
+				Message:    fmt.Sprintf("hash-content %s does not match the body hash %s", want, got),
+			})
+		}
+	}
+
+	return issues
+}
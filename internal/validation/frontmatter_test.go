@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+func TestValidateFrontmatter(t *testing.T) {
+	const body = "# Setup\n\nInstall the tools.\n"
+	hash := utils.CalculateStringHash(body)
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"guides/setup.md": "---\ntitle: \"Setup\"\neditor: markdown\nhash-gdrive: \"rev1\"\nhash-content: \"" + hash + "\"\n---\n\n" + body,
+		"no-hash.md":      "---\ntitle: \"No hash\"\neditor: markdown\nhash-gdrive: \"rev1\"\n---\n\nBody\n",
+		"missing.md":      "---\ntitle: \"\"\nhash-gdrive: \"rev1\"\n---\n\nBody\n",
+		"edited.md":       "---\ntitle: \"Edited\"\neditor: markdown\nhash-gdrive: \"rev1\"\nhash-content: \"" + hash + "\"\n---\n\n" + body + "Edited by hand.\n",
+		"plain.md":        "# No frontmatter\n",
+		"notes.txt":       "not markdown",
+	})
+
+	issues, err := ValidateFrontmatter(dir, FrontmatterOptions{})
+	if err != nil {
+		t.Fatalf("ValidateFrontmatter() error = %v", err)
+	}
+
+	type issue struct{ file, field, problem string }
+	var got []issue
+	for _, i := range issues {
+		got = append(got, issue{i.SourceFile, i.Field, i.Problem})
+	}
+	want := []issue{
+		{"edited.md", "hash-content", "hash_mismatch"},
+		{"missing.md", "title", "empty_field"},
+		{"missing.md", "editor", "missing_field"},
+		{"plain.md", "", "invalid_frontmatter"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateFrontmatter() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateFrontmatterOptions(t *testing.T) {
+	const body = "Body\n"
+	sha1, err := utils.ParseHashAlgorithm(utils.HashSHA1)
+	if err != nil {
+		t.Fatalf("ParseHashAlgorithm() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"doc.md": "---\ntitle: \"Doc\"\nhash-content: \"" + sha1([]byte(body)) + "\"\n---\n\n" + body,
+	})
+
+	issues, err := ValidateFrontmatter(dir, FrontmatterOptions{RequiredFields: []string{"title"}, HashFunc: sha1})
+	if err != nil {
+		t.Fatalf("ValidateFrontmatter() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("ValidateFrontmatter() = %+v, want no issues", issues)
+	}
+}
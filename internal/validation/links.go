@@ -0,0 +1,244 @@
+package validation
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the number of fix suggestions reported per broken link
+const maxSuggestions = 3
+
+// AutoFixMaxDistance is the largest edit distance a suggestion may have to be applied automatically
+const AutoFixMaxDistance = 3
+
+// markdownLinkPattern matches [text](target) and [text](target "title")
+var markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)(\s+"[^"]*")?\)`)
+
+// BrokenLink describes a relative link that does not resolve to an existing file
+type BrokenLink struct {
+	SourceFile   string       `json:"source_file"`   // Path of the markdown file, relative to the output directory
+	LinkText     string       `json:"link_text"`     // Text of the markdown link
+	Target       string       `json:"target"`        // Link target as written in the file
+	ResolvedPath string       `json:"resolved_path"` // Target resolved relative to the output directory
+	Suggestions  []Suggestion `json:"suggestions,omitempty"`
+}
+
+// ValidateLinks checks every relative link in the markdown files under outputDir
+// and returns the links whose targets do not exist, with fix suggestions
+func ValidateLinks(outputDir string) ([]BrokenLink, error) {
+	files, err := listFiles(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(files))
+	for _, file := range files {
+		existing[file] = true
+	}
+
+	var broken []BrokenLink
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".md") {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(outputDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		for _, match := range markdownLinkPattern.FindAllStringSubmatch(string(content), -1) {
+			linkText, target := match[1], match[2]
+
+			resolved, ok := resolveLink(file, target)
+			if !ok || existing[resolved] {
+				continue
+			}
+
+			broken = append(broken, BrokenLink{
+				SourceFile:   file,
+				LinkText:     linkText,
+				Target:       target,
+				ResolvedPath: resolved,
+				Suggestions:  Suggest(resolved, files, maxSuggestions),
+			})
+		}
+	}
+
+	return broken, nil
+}
+
+// FormatSuggestions renders suggestions as "Did you mean: a, b, c?"
+func FormatSuggestions(suggestions []Suggestion) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	paths := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		paths[i] = s.Path
+	}
+	return fmt.Sprintf("Did you mean: %s?", strings.Join(paths, ", "))
+}
+
+// WriteBrokenLinksReport writes one source_file:link_text:target_path line per broken link,
+// with the target resolved relative to the output directory
+func WriteBrokenLinksReport(w io.Writer, broken []BrokenLink) error {
+	for _, link := range broken {
+		if _, err := fmt.Fprintf(w, "%s:%s:%s\n", link.SourceFile, link.LinkText, link.ResolvedPath); err != nil {
+			return fmt.Errorf("failed to write broken links report: %w", err)
+		}
+	}
+	return nil
+}
+
+// AutoFix rewrites each broken link whose best suggestion is within AutoFixMaxDistance
+// to point at that suggestion. Files are replaced atomically. The links that were fixed
+// are returned.
+func AutoFix(outputDir string, broken []BrokenLink) ([]BrokenLink, error) {
+	// Group replacement targets by source file
+	fixes := make(map[string]map[string]string)
+	var fixed []BrokenLink
+	for _, link := range broken {
+		if len(link.Suggestions) == 0 || link.Suggestions[0].Distance > AutoFixMaxDistance {
+			continue
+		}
+
+		newTarget, err := relativeTarget(link.SourceFile, link.Suggestions[0].Path)
+		if err != nil {
+			return fixed, err
+		}
+		if _, frag, found := strings.Cut(link.Target, "#"); found {
+			newTarget += "#" + frag
+		}
+
+		if fixes[link.SourceFile] == nil {
+			fixes[link.SourceFile] = make(map[string]string)
+		}
+		fixes[link.SourceFile][link.Target] = newTarget
+		fixed = append(fixed, link)
+	}
+
+	sources := make([]string, 0, len(fixes))
+	for source := range fixes {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		if err := rewriteLinkTargets(filepath.Join(outputDir, source), fixes[source]); err != nil {
+			return fixed, err
+		}
+	}
+
+	return fixed, nil
+}
+
+// rewriteLinkTargets replaces link targets in a file and writes it back atomically
+func rewriteLinkTargets(path string, replacements map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated := markdownLinkPattern.ReplaceAllStringFunc(string(content), func(match string) string {
+		parts := markdownLinkPattern.FindStringSubmatch(match)
+		newTarget, ok := replacements[parts[2]]
+		if !ok {
+			return match
+		}
+		return fmt.Sprintf("[%s](%s%s)", parts[1], newTarget, parts[3])
+	})
+
+	return writeFileAtomic(path, []byte(updated))
+}
+
+// writeFileAtomic writes data to a temp file in the same directory and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// resolveLink resolves a link target found in sourceFile to a slash-separated path
+// relative to the output directory. ok is false for links that are not relative
+// file links (external URLs, anchors, absolute paths).
+func resolveLink(sourceFile, target string) (resolved string, ok bool) {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") ||
+		strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") {
+		return "", false
+	}
+
+	// Drop any anchor and decode escaped characters
+	target, _, _ = strings.Cut(target, "#")
+	if target == "" {
+		return "", false
+	}
+	if decoded, err := url.PathUnescape(target); err == nil {
+		target = decoded
+	}
+
+	dir := filepath.Dir(filepath.FromSlash(sourceFile))
+	return filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(target))), true
+}
+
+// relativeTarget returns the slash-separated link target from sourceFile to targetFile,
+// both relative to the output directory
+func relativeTarget(sourceFile, targetFile string) (string, error) {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(sourceFile)), filepath.FromSlash(targetFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to compute relative path to %s: %w", targetFile, err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// listFiles returns all regular files under dir as slash-separated relative paths
+func listFiles(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+
+		return nil
+	})
+
+	return files, err
+}
@@ -0,0 +1,184 @@
+package validation
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree creates files under dir from a map of slash-separated relative paths to content
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+}
+
+func TestResolveLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceFile string
+		target     string
+		want       string
+		wantOK     bool
+	}{
+		{name: "sibling", sourceFile: "guides/intro.md", target: "setup.md", want: "guides/setup.md", wantOK: true},
+		{name: "parent", sourceFile: "guides/tutorials/a.md", target: "../../reference/api.md", want: "reference/api.md", wantOK: true},
+		{name: "anchor stripped", sourceFile: "a.md", target: "b.md#section", want: "b.md", wantOK: true},
+		{name: "escaped space", sourceFile: "a.md", target: "my%20doc.md", want: "my doc.md", wantOK: true},
+		{name: "external url", sourceFile: "a.md", target: "https://example.com/b.md", wantOK: false},
+		{name: "mailto", sourceFile: "a.md", target: "mailto:someone@example.com", wantOK: false},
+		{name: "anchor only", sourceFile: "a.md", target: "#section", wantOK: false},
+		{name: "absolute path", sourceFile: "a.md", target: "/wiki/page", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resolveLink(tt.sourceFile, tt.target)
+			if ok != tt.wantOK {
+				t.Fatalf("resolveLink() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("resolveLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLinks(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"guides/intro.md": "See [Setup](setup-guid.md), [API](../reference/api.md) and [Google](https://google.com).\n" +
+			"Also [missing](nothing-like-this-exists.md).",
+		"guides/setup-guide.md": "[Back](intro.md#top)",
+		"reference/api.md":      "![diagram](diagram.png)",
+	})
+
+	broken, err := ValidateLinks(dir)
+	if err != nil {
+		t.Fatalf("ValidateLinks() error = %v", err)
+	}
+
+	if len(broken) != 3 {
+		t.Fatalf("ValidateLinks() found %d broken links, want 3: %+v", len(broken), broken)
+	}
+
+	first := broken[0]
+	if first.SourceFile != "guides/intro.md" || first.LinkText != "Setup" || first.Target != "setup-guid.md" || first.ResolvedPath != "guides/setup-guid.md" {
+		t.Errorf("unexpected broken link: %+v", first)
+	}
+	if len(first.Suggestions) != 3 {
+		t.Fatalf("got %d suggestions, want 3", len(first.Suggestions))
+	}
+	if first.Suggestions[0] != (Suggestion{Path: "guides/setup-guide.md", Distance: 1}) {
+		t.Errorf("best suggestion = %+v, want guides/setup-guide.md at distance 1", first.Suggestions[0])
+	}
+
+	if broken[2].SourceFile != "reference/api.md" || broken[2].ResolvedPath != "reference/diagram.png" {
+		t.Errorf("unexpected image link: %+v", broken[2])
+	}
+}
+
+func TestWriteBrokenLinksReport(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"index.md":              "[Guides](guides/intro.md) and [Old setup](guides/old-setup.md#install)",
+		"guides/intro.md":       "[Home](../index.md), [Assets](../assets/logo.png) and [Deleted](../reference/deleted.md)",
+		"assets/logo.png":       "png",
+		"reference/overview.md": "No links",
+	})
+
+	broken, err := ValidateLinks(dir)
+	if err != nil {
+		t.Fatalf("ValidateLinks() error = %v", err)
+	}
+
+	var report bytes.Buffer
+	if err := WriteBrokenLinksReport(&report, broken); err != nil {
+		t.Fatalf("WriteBrokenLinksReport() error = %v", err)
+	}
+	want := "guides/intro.md:Deleted:reference/deleted.md\n" +
+		"index.md:Old setup:guides/old-setup.md\n"
+	if report.String() != want {
+		t.Errorf("report = %q, want %q", report.String(), want)
+	}
+}
+
+func TestAutoFix(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"guides/intro.md":       `See [Setup](setup-guid.md#install "Setup guide") and [missing](nothing-like-this-exists.md).`,
+		"guides/setup-guide.md": "# Setup",
+		"reference/ap.md":       "[Intro](../guides/intro.md)",
+	})
+
+	broken, err := ValidateLinks(dir)
+	if err != nil {
+		t.Fatalf("ValidateLinks() error = %v", err)
+	}
+
+	fixed, err := AutoFix(dir, broken)
+	if err != nil {
+		t.Fatalf("AutoFix() error = %v", err)
+	}
+	if len(fixed) != 1 || fixed[0].Target != "setup-guid.md#install" {
+		t.Fatalf("AutoFix() fixed %+v, want only setup-guid.md#install", fixed)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "guides", "intro.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := `See [Setup](setup-guide.md#install "Setup guide") and [missing](nothing-like-this-exists.md).`
+	if string(content) != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+
+	// Only the unfixable link should remain, and no temp files should be left behind
+	remaining, err := ValidateLinks(dir)
+	if err != nil {
+		t.Fatalf("ValidateLinks() error = %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].Target != "nothing-like-this-exists.md" {
+		t.Errorf("remaining broken links = %+v", remaining)
+	}
+
+	files, err := listFiles(dir)
+	if err != nil {
+		t.Fatalf("listFiles() error = %v", err)
+	}
+	wantFiles := []string{"guides/intro.md", "guides/setup-guide.md", "reference/ap.md"}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("files = %v, want %v", files, wantFiles)
+	}
+}
+
+func TestRelativeTarget(t *testing.T) {
+	tests := []struct {
+		source, target, want string
+	}{
+		{"guides/intro.md", "guides/setup.md", "setup.md"},
+		{"guides/tutorials/a.md", "reference/api.md", "../../reference/api.md"},
+		{"index.md", "guides/intro.md", "guides/intro.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source+"->"+tt.target, func(t *testing.T) {
+			got, err := relativeTarget(tt.source, tt.target)
+			if err != nil {
+				t.Fatalf("relativeTarget() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("relativeTarget() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"sort"
+)
+
+// Suggestion is a candidate path for a broken link along with its edit distance
+type Suggestion struct {
+	Path     string `json:"path"`
+	Distance int    `json:"distance"`
+}
+
+// Suggest returns up to n candidates closest to target by Levenshtein distance.
+// Candidates that cannot beat the current n-th best distance are pruned early.
+func Suggest(target string, candidates []string, n int) []Suggestion {
+	if n <= 0 {
+		return nil
+	}
+
+	var best []Suggestion
+	for _, candidate := range candidates {
+		// Once we have n suggestions, only strictly closer candidates are of interest
+		limit := -1
+		if len(best) == n {
+			limit = best[n-1].Distance - 1
+		}
+
+		dist, ok := boundedLevenshtein(target, candidate, limit)
+		if !ok {
+			continue
+		}
+
+		best = append(best, Suggestion{Path: candidate, Distance: dist})
+		sort.SliceStable(best, func(i, j int) bool {
+			return best[i].Distance < best[j].Distance
+		})
+		if len(best) > n {
+			best = best[:n]
+		}
+	}
+
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	dist, _ := boundedLevenshtein(a, b, -1)
+	return dist
+}
+
+// boundedLevenshtein returns the edit distance between a and b. When limit is
+// non-negative the computation stops as soon as the distance must exceed limit,
+// and ok is false. A negative limit means unbounded.
+func boundedLevenshtein(a, b string, limit int) (dist int, ok bool) {
+	ra, rb := []rune(a), []rune(b)
+
+	// The distance is at least the difference in length
+	if limit >= 0 && abs(len(ra)-len(rb)) > limit {
+		return 0, false
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+
+		// Every path through the remaining rows costs at least rowMin
+		if limit >= 0 && rowMin > limit {
+			return 0, false
+		}
+		prev, curr = curr, prev
+	}
+
+	dist = prev[len(rb)]
+	if limit >= 0 && dist > limit {
+		return 0, false
+	}
+	return dist, true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
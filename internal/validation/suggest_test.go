@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"guides/setup.md", "guides/setup.md", 0},
+		{"guides/setup-guid.md", "guides/setup-guide.md", 1},
+		{"über", "uber", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := levenshtein(tt.a, tt.b); got != tt.want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoundedLevenshtein(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		limit    int
+		wantDist int
+		wantOK   bool
+	}{
+		{name: "within limit", a: "kitten", b: "sitting", limit: 3, wantDist: 3, wantOK: true},
+		{name: "exceeds limit", a: "kitten", b: "sitting", limit: 2, wantOK: false},
+		{name: "length difference exceeds limit", a: "a", b: "abcdef", limit: 2, wantOK: false},
+		{name: "unbounded", a: "a", b: "abcdef", limit: -1, wantDist: 5, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dist, ok := boundedLevenshtein(tt.a, tt.b, tt.limit)
+			if ok != tt.wantOK {
+				t.Fatalf("boundedLevenshtein() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && dist != tt.wantDist {
+				t.Errorf("boundedLevenshtein() = %d, want %d", dist, tt.wantDist)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{
+		"guides/setup-guide.md",
+		"reference/api.md",
+		"guides/setup.md",
+		"guides/intro.md",
+		"guides/setup-guides.md",
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		n      int
+		want   []Suggestion
+	}{
+		{
+			name:   "top three closest",
+			target: "guides/setup-guid.md",
+			n:      3,
+			want: []Suggestion{
+				{Path: "guides/setup-guide.md", Distance: 1},
+				{Path: "guides/setup-guides.md", Distance: 2},
+				{Path: "guides/setup.md", Distance: 5},
+			},
+		},
+		{
+			name:   "fewer candidates than n",
+			target: "guides/intro.md",
+			n:      10,
+			want: []Suggestion{
+				{Path: "guides/intro.md", Distance: 0},
+				{Path: "guides/setup.md", Distance: 4},
+				{Path: "guides/setup-guide.md", Distance: 10},
+				{Path: "guides/setup-guides.md", Distance: 11},
+				{Path: "reference/api.md", Distance: 12},
+			},
+		},
+		{
+			name:   "zero suggestions requested",
+			target: "guides/intro.md",
+			n:      0,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Suggest(tt.target, candidates, tt.n)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package wikijs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLocale is the locale pages are looked up and created in
+	DefaultLocale = "en"
+
+	// DefaultTimeout bounds a single GraphQL request
+	DefaultTimeout = 30 * time.Second
+
+	// pageNotFoundCode is the Wiki.js error code for a path without a page
+	pageNotFoundCode = 6003
+)
+
+// ErrPageNotFound is returned by GetPage when no page exists at the path
+var ErrPageNotFound = errors.New("page not found")
+
+// Page is a Wiki.js page
+type Page struct {
+	ID          int
+	Path        string
+	Locale      string
+	Title       string
+	Description string
+	Content     string
+	Tags        []string
+	IsPublished bool
+}
+
+// Client calls the GraphQL API of a Wiki.js 2 instance
+type Client struct {
+	BaseURL    string // Wiki URL, such as https://wiki.example.com
+	Token      string // API key sent as a bearer token
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for the wiki at baseURL authenticated with the API key token
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+const getPageQuery = `query ($path: String!, $locale: String!) {
+  pages {
+    singleByPath(path: $path, locale: $locale) {
+      id
+      path
+      locale
+      title
+      description
+      content
+      isPublished
+      tags {
+        tag
+      }
+    }
+  }
+}`
+
+const createPageQuery = `mutation ($content: String!, $description: String!, $editor: String!, $isPublished: Boolean!, $isPrivate: Boolean!, $locale: String!, $path: String!, $tags: [String]!, $title: String!) {
+  pages {
+    create(content: $content, description: $description, editor: $editor, isPublished: $isPublished, isPrivate: $isPrivate, locale: $locale, path: $path, tags: $tags, title: $title) {
+      responseResult {
+        succeeded
+        errorCode
+        message
+      }
+      page {
+        id
+      }
+    }
+  }
+}`
+
+const updatePageQuery = `mutation ($id: Int!, $content: String!, $description: String!, $editor: String!, $isPublished: Boolean!, $isPrivate: Boolean!, $locale: String!, $path: String!, $tags: [String]!, $title: String!) {
+  pages {
+    update(id: $id, content: $content, description: $description, editor: $editor, isPublished: $isPublished, isPrivate: $isPrivate, locale: $locale, path: $path, tags: $tags, title: $title) {
+      responseResult {
+        succeeded
+        errorCode
+        message
+      }
+    }
+  }
+}`
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+// graphQLError is an entry of the errors of a GraphQL response
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Exception struct {
+			Code int `json:"code"`
+		} `json:"exception"`
+	} `json:"extensions"`
+}
+
+// responseResult reports whether a Wiki.js mutation succeeded
+type responseResult struct {
+	Succeeded bool   `json:"succeeded"`
+	ErrorCode int    `json:"errorCode"`
+	Message   string `json:"message"`
+}
+
+func (r responseResult) err() error {
+	if r.Succeeded {
+		return nil
+	}
+	return fmt.Errorf("wiki.js error %d: %s", r.ErrorCode, r.Message)
+}
+
+// GetPage returns the page at path in locale, or ErrPageNotFound when there is none
+func (c *Client) GetPage(ctx context.Context, path, locale string) (*Page, error) {
+	var data struct {
+		Pages struct {
+			SingleByPath *struct {
+				ID          int    `json:"id"`
+				Path        string `json:"path"`
+				Locale      string `json:"locale"`
+				Title       string `json:"title"`
+				Description string `json:"description"`
+				Content     string `json:"content"`
+				IsPublished bool   `json:"isPublished"`
+				Tags        []struct {
+					Tag string `json:"tag"`
+				} `json:"tags"`
+			} `json:"singleByPath"`
+		} `json:"pages"`
+	}
+	err := c.do(ctx, getPageQuery, map[string]any{"path": path, "locale": locale}, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	found := data.Pages.SingleByPath
+	if found == nil {
+		return nil, ErrPageNotFound
+	}
+	page := &Page{
+		ID:          found.ID,
+		Path:        found.Path,
+		Locale:      found.Locale,
+		Title:       found.Title,
+		Description: found.Description,
+		Content:     found.Content,
+		IsPublished: found.IsPublished,
+	}
+	for _, tag := range found.Tags {
+		page.Tags = append(page.Tags, tag.Tag)
+	}
+	return page, nil
+}
+
+// CreatePage creates a markdown page and returns its ID
+func (c *Client) CreatePage(ctx context.Context, page Page) (int, error) {
+	var data struct {
+		Pages struct {
+			Create struct {
+				ResponseResult responseResult `json:"responseResult"`
+				Page           *struct {
+					ID int `json:"id"`
+				} `json:"page"`
+			} `json:"create"`
+		} `json:"pages"`
+	}
+	if err := c.do(ctx, createPageQuery, pageVariables(page), &data); err != nil {
+		return 0, err
+	}
+
+	created := data.Pages.Create
+	if err := created.ResponseResult.err(); err != nil {
+		return 0, fmt.Errorf("failed to create page %s: %w", page.Path, err)
+	}
+	if created.Page == nil {
+		return 0, nil
+	}
+	return created.Page.ID, nil
+}
+
+// UpdatePage replaces the content and properties of the page with page.ID
+func (c *Client) UpdatePage(ctx context.Context, page Page) error {
+	var data struct {
+		Pages struct {
+			Update struct {
+				ResponseResult responseResult `json:"responseResult"`
+			} `json:"update"`
+		} `json:"pages"`
+	}
+	variables := pageVariables(page)
+	variables["id"] = page.ID
+	if err := c.do(ctx, updatePageQuery, variables, &data); err != nil {
+		return err
+	}
+
+	if err := data.Pages.Update.ResponseResult.err(); err != nil {
+		return fmt.Errorf("failed to update page %s: %w", page.Path, err)
+	}
+	return nil
+}
+
+// pageVariables returns the mutation variables shared by page creation and update
+func pageVariables(page Page) map[string]any {
+	tags := page.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]any{
+		"content":     page.Content,
+		"description": page.Description,
+		"editor":      "markdown",
+		"isPublished": page.IsPublished,
+		"isPrivate":   false,
+		"locale":      page.Locale,
+		"path":        page.Path,
+		"tags":        tags,
+		"title":       page.Title,
+	}
+}
+
+// do sends a GraphQL request and decodes the data of the response into out
+func (c *Client) do(ctx context.Context, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode GraphQL request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GraphQL request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read GraphQL response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GraphQL request failed with HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("invalid GraphQL response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		for _, gqlErr := range result.Errors {
+			if gqlErr.Extensions.Exception.Code == pageNotFoundCode {
+				return ErrPageNotFound
+			}
+		}
+		return fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+	}
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		return fmt.Errorf("GraphQL response has no data")
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("invalid GraphQL response data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package wikijs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	gosync "sync"
+	"testing"
+)
+
+// fakeWiki is a Wiki.js GraphQL endpoint that keeps pages in memory and records every request
+type fakeWiki struct {
+	mu       gosync.Mutex
+	pages    map[string]Page // By path
+	nextID   int
+	requests []graphQLRequest
+	auth     []string
+}
+
+func newFakeWiki(t *testing.T) (*fakeWiki, *Client) {
+	t.Helper()
+	fake := &fakeWiki{pages: make(map[string]Page), nextID: 1}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+	return fake, NewClient(server.URL+"/", "secret-token")
+}
+
+func (f *fakeWiki) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	vars := req.Variables
+	switch {
+	case strings.Contains(req.Query, "singleByPath("):
+		page, ok := f.pages[vars["path"].(string)]
+		if !ok {
+			writeJSON(w, map[string]any{
+				"data":   map[string]any{"pages": map[string]any{"singleByPath": nil}},
+				"errors": []any{map[string]any{"message": "This page does not exist.", "extensions": map[string]any{"exception": map[string]any{"code": pageNotFoundCode}}}},
+			})
+			return
+		}
+		tags := []any{}
+		for _, tag := range page.Tags {
+			tags = append(tags, map[string]any{"tag": tag})
+		}
+		writeJSON(w, map[string]any{"data": map[string]any{"pages": map[string]any{"singleByPath": map[string]any{
+			"id": page.ID, "path": page.Path, "locale": page.Locale, "title": page.Title,
+			"description": page.Description, "content": page.Content, "isPublished": page.IsPublished, "tags": tags,
+		}}}})
+	case strings.Contains(req.Query, "create("):
+		page := pageFromVariables(vars)
+		page.ID = f.nextID
+		f.nextID++
+		f.pages[page.Path] = page
+		writeJSON(w, map[string]any{"data": map[string]any{"pages": map[string]any{"create": map[string]any{
+			"responseResult": map[string]any{"succeeded": true, "errorCode": 0, "message": "Page created."},
+			"page":           map[string]any{"id": page.ID},
+		}}}})
+	case strings.Contains(req.Query, "update("):
+		page := pageFromVariables(vars)
+		page.ID = int(vars["id"].(float64))
+		f.pages[page.Path] = page
+		writeJSON(w, map[string]any{"data": map[string]any{"pages": map[string]any{"update": map[string]any{
+			"responseResult": map[string]any{"succeeded": true, "errorCode": 0, "message": "Page updated."},
+		}}}})
+	default:
+		http.Error(w, "unknown query", http.StatusBadRequest)
+	}
+}
+
+func pageFromVariables(vars map[string]any) Page {
+	page := Page{
+		Path:        vars["path"].(string),
+		Locale:      vars["locale"].(string),
+		Title:       vars["title"].(string),
+		Description: vars["description"].(string),
+		Content:     vars["content"].(string),
+		IsPublished: vars["isPublished"].(bool),
+	}
+	for _, tag := range vars["tags"].([]any) {
+		page.Tags = append(page.Tags, tag.(string))
+	}
+	return page
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestCreatePageRequest(t *testing.T) {
+	fake, client := newFakeWiki(t)
+
+	id, err := client.CreatePage(context.Background(), Page{
+		Path: "guides/setup", Locale: "en", Title: "Setup", Description: "How to set up",
+		Content: "# Setup\n", Tags: []string{"kubernetes", "ops"}, IsPublished: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePage() error = %v", err)
+	}
+	if id != 1 {
+		t.Errorf("CreatePage() id = %d, want 1", id)
+	}
+
+	if len(fake.requests) != 1 {
+		t.Fatalf("Got %d requests, want 1", len(fake.requests))
+	}
+	req := fake.requests[0]
+	if !strings.HasPrefix(req.Query, "mutation") || !strings.Contains(req.Query, "pages {\n    create(") {
+		t.Errorf("Query is not a pages.create mutation:\n%s", req.Query)
+	}
+	want := map[string]any{
+		"content": "# Setup\n", "description": "How to set up", "editor": "markdown",
+		"isPublished": true, "isPrivate": false, "locale": "en", "path": "guides/setup",
+		"tags": []any{"kubernetes", "ops"}, "title": "Setup",
+	}
+	if !reflect.DeepEqual(req.Variables, want) {
+		t.Errorf("Variables = %v, want %v", req.Variables, want)
+	}
+	if fake.auth[0] != "Bearer secret-token" {
+		t.Errorf("Authorization = %q, want the bearer token", fake.auth[0])
+	}
+}
+
+func TestUpdatePageRequest(t *testing.T) {
+	fake, client := newFakeWiki(t)
+
+	err := client.UpdatePage(context.Background(), Page{ID: 7, Path: "guides/setup", Locale: "en", Title: "Setup", Content: "new"})
+	if err != nil {
+		t.Fatalf("UpdatePage() error = %v", err)
+	}
+
+	req := fake.requests[0]
+	if !strings.Contains(req.Query, "update(id: $id") {
+		t.Errorf("Query is not a pages.update mutation:\n%s", req.Query)
+	}
+	if req.Variables["id"] != float64(7) {
+		t.Errorf("id = %v, want 7", req.Variables["id"])
+	}
+	if tags, ok := req.Variables["tags"].([]any); !ok || len(tags) != 0 {
+		t.Errorf("tags = %v, want an empty list", req.Variables["tags"])
+	}
+}
+
+func TestGetPage(t *testing.T) {
+	fake, client := newFakeWiki(t)
+	fake.pages["guides/setup"] = Page{ID: 3, Path: "guides/setup", Locale: "en", Title: "Setup", Content: "body", Tags: []string{"ops"}, IsPublished: true}
+
+	page, err := client.GetPage(context.Background(), "guides/setup", "en")
+	if err != nil {
+		t.Fatalf("GetPage() error = %v", err)
+	}
+	if !reflect.DeepEqual(*page, fake.pages["guides/setup"]) {
+		t.Errorf("GetPage() = %+v, want %+v", *page, fake.pages["guides/setup"])
+	}
+	want := map[string]any{"path": "guides/setup", "locale": "en"}
+	if !reflect.DeepEqual(fake.requests[0].Variables, want) {
+		t.Errorf("Variables = %v, want %v", fake.requests[0].Variables, want)
+	}
+
+	if _, err := client.GetPage(context.Background(), "missing", "en"); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("GetPage(missing) error = %v, want ErrPageNotFound", err)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "http error", status: http.StatusForbidden, body: "forbidden", wantErr: "HTTP 403: forbidden"},
+		{name: "graphql error", status: http.StatusOK, body: `{"errors":[{"message":"Forbidden"}]}`, wantErr: "GraphQL error: Forbidden"},
+		{name: "failed mutation", status: http.StatusOK, body: `{"data":{"pages":{"create":{"responseResult":{"succeeded":false,"errorCode":6002,"message":"Page already exists"}}}}}`, wantErr: "wiki.js error 6002: Page already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := NewClient(server.URL, "").CreatePage(context.Background(), Page{Path: "a", Locale: "en"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpload(t *testing.T) {
+	fake, client := newFakeWiki(t)
+	fake.pages["Guides/setup"] = Page{ID: 9, Path: "Guides/setup", Locale: "en", Title: "Old"}
+
+	outDir := t.TempDir()
+	files := map[string]string{
+		"Guides/setup.md": "---\ntitle: Setup\ndescription: Setup\ntags: ops, kubernetes\npublished: true\n---\n\n# Setup\n",
+		"Guides/draft.md": "---\ntitle: Draft\npublished: false\n---\n\nDraft\n",
+		"notes.md":        "No frontmatter\n",
+		"broken.md":       "---\ntitle: Broken\n",
+		"image.png":       "png",
+	}
+	for name, content := range files {
+		path := filepath.Join(outDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := Upload(context.Background(), client, outDir, UploadOptions{})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.Created != 2 || result.Updated != 1 {
+		t.Errorf("Upload() created %d and updated %d, want 2 and 1", result.Created, result.Updated)
+	}
+	if len(result.Failed) != 1 || filepath.Base(result.Failed[0]) != "broken.md" {
+		t.Errorf("Failed = %v, want broken.md", result.Failed)
+	}
+
+	updated := fake.pages["Guides/setup"]
+	wantUpdated := Page{ID: 9, Path: "Guides/setup", Locale: "en", Title: "Setup", Description: "Setup", Content: "# Setup\n", Tags: []string{"ops", "kubernetes"}, IsPublished: true}
+	if !reflect.DeepEqual(updated, wantUpdated) {
+		t.Errorf("Updated page = %+v, want %+v", updated, wantUpdated)
+	}
+	if draft := fake.pages["Guides/draft"]; draft.Title != "Draft" || draft.IsPublished {
+		t.Errorf("Created page = %+v, want an unpublished Draft", draft)
+	}
+	if notes := fake.pages["notes"]; notes.Title != "notes" || notes.Content != "No frontmatter\n" || !notes.IsPublished {
+		t.Errorf("Page without frontmatter = %+v, want the whole file titled notes", notes)
+	}
+}
+
+func TestUploadDryRun(t *testing.T) {
+	fake, client := newFakeWiki(t)
+	outDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outDir, "page.md"), []byte("---\ntitle: Page\n---\nBody\n"), 0644); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+
+	result, err := Upload(context.Background(), client, outDir, UploadOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if result.Created != 1 {
+		t.Errorf("Created = %d, want 1", result.Created)
+	}
+	for _, req := range fake.requests {
+		if strings.HasPrefix(req.Query, "mutation") {
+			t.Errorf("Dry run sent a mutation:\n%s", req.Query)
+		}
+	}
+	if len(fake.pages) != 0 {
+		t.Errorf("Dry run changed the wiki: %v", fake.pages)
+	}
+}
+
+// operations returns the requests the fake received as "get <path>" and "create <path>"
+func (f *fakeWiki) operations() []string {
+	var ops []string
+	for _, req := range f.requests {
+		op := "get"
+		if strings.Contains(req.Query, "create(") {
+			op = "create"
+		}
+		ops = append(ops, op+" "+req.Variables["path"].(string))
+	}
+	return ops
+}
+
+func TestEnsurePageTree(t *testing.T) {
+	fake, client := newFakeWiki(t)
+	fake.pages["Guides"] = Page{ID: 9, Path: "Guides", Locale: "en", Title: "Guides"}
+
+	builder := NewTreeBuilder(client, TreeOptions{})
+	for _, fragments := range [][]string{
+		{"Guides", "Setup", "Linux"},
+		{"Guides", "Setup", "Windows"},
+		{"Guides", "", "Notes"},
+		{"Teams: Ops"},
+	} {
+		if err := builder.EnsurePageTree(context.Background(), fragments); err != nil {
+			t.Fatalf("EnsurePageTree(%v) error = %v", fragments, err)
+		}
+	}
+
+	want := []string{
+		"get Guides",
+		"get Guides/Setup", "create Guides/Setup",
+		"get Guides/Setup/Linux", "create Guides/Setup/Linux",
+		"get Guides/Setup/Windows", "create Guides/Setup/Windows",
+		"get Guides/Notes", "create Guides/Notes",
+		"get Teams_ Ops", "create Teams_ Ops",
+	}
+	if got := fake.operations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Requests = %v, want %v", got, want)
+	}
+	if builder.Result != (TreeResult{Created: 5, Existing: 1}) {
+		t.Errorf("Result = %+v, want 5 created and 1 existing", builder.Result)
+	}
+	if page := fake.pages["Guides/Setup/Linux"]; page.Title != "Linux" || page.Content != "# Linux\n" || !page.IsPublished {
+		t.Errorf("Created page = %+v, want a published page titled Linux", page)
+	}
+	if guides := fake.pages["Guides"]; guides.ID != 9 {
+		t.Errorf("Existing page = %+v, want it unchanged", guides)
+	}
+
+	// A second run only checks the pages
+	fake.requests = nil
+	again := NewTreeBuilder(client, TreeOptions{})
+	if err := again.EnsurePageTree(context.Background(), []string{"Guides", "Setup", "Linux"}); err != nil {
+		t.Fatalf("EnsurePageTree() error = %v", err)
+	}
+	if got, want := fake.operations(), []string{"get Guides", "get Guides/Setup", "get Guides/Setup/Linux"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Requests of second run = %v, want %v", got, want)
+	}
+}
+
+func TestEnsurePageTreeDryRun(t *testing.T) {
+	fake, client := newFakeWiki(t)
+
+	builder := NewTreeBuilder(client, TreeOptions{DryRun: true})
+	if err := builder.EnsurePageTree(context.Background(), []string{"Guides", "Setup"}); err != nil {
+		t.Fatalf("EnsurePageTree() error = %v", err)
+	}
+	if got, want := fake.operations(), []string{"get Guides", "get Guides/Setup"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Requests = %v, want %v", got, want)
+	}
+	if builder.Result.Created != 2 || len(fake.pages) != 0 {
+		t.Errorf("Dry run created %d pages and changed the wiki to %v, want 2 and no change", builder.Result.Created, fake.pages)
+	}
+}
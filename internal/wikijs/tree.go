@@ -0,0 +1,88 @@
+package wikijs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/utils"
+)
+
+// TreeOptions configures a TreeBuilder
+type TreeOptions struct {
+	Locale  string // Locale of the pages (default: DefaultLocale)
+	DryRun  bool   // Log the pages that would be created without changing the wiki
+	Verbose bool
+}
+
+// TreeResult counts the pages checked by a TreeBuilder
+type TreeResult struct {
+	Created  int
+	Existing int
+}
+
+// TreeBuilder creates a page for every directory of a fragment hierarchy, so the wiki's
+// navigation has a page at each level before the documents are uploaded below it
+type TreeBuilder struct {
+	client *Client
+	opts   TreeOptions
+	known  map[string]bool // Page paths already checked or created
+	Result TreeResult
+}
+
+// NewTreeBuilder returns a TreeBuilder creating pages with client
+func NewTreeBuilder(client *Client, opts TreeOptions) *TreeBuilder {
+	if opts.Locale == "" {
+		opts.Locale = DefaultLocale
+	}
+	return &TreeBuilder{client: client, opts: opts, known: make(map[string]bool)}
+}
+
+// EnsurePageTree makes sure a page exists for each directory the fragments map to, from the
+// top down: for Guides, Setup it checks Guides and then Guides/Setup. A missing page is created
+// titled after its fragment; existing pages are left unchanged and each path is checked once
+// per builder, so calling it again for the same or overlapping fragments is safe.
+func (b *TreeBuilder) EnsurePageTree(ctx context.Context, fragments []string) error {
+	for i, frag := range fragments {
+		if frag == "" {
+			continue
+		}
+		path := PagePath(utils.BuildFragmentDir(fragments[:i+1]))
+		if b.known[path] {
+			continue
+		}
+		if err := b.ensurePage(ctx, path, frag); err != nil {
+			return err
+		}
+		b.known[path] = true
+	}
+	return nil
+}
+
+// ensurePage creates a page titled title at path unless one exists
+func (b *TreeBuilder) ensurePage(ctx context.Context, path, title string) error {
+	_, err := b.client.GetPage(ctx, path, b.opts.Locale)
+	if err == nil {
+		b.Result.Existing++
+		return nil
+	}
+	if !errors.Is(err, ErrPageNotFound) {
+		return fmt.Errorf("failed to look up page %s: %w", path, err)
+	}
+
+	if b.opts.DryRun {
+		log.Printf("Would create page: %s", path)
+		b.Result.Created++
+		return nil
+	}
+	page := Page{Path: path, Locale: b.opts.Locale, Title: title, Content: "# " + title + "\n", IsPublished: true}
+	if _, err := b.client.CreatePage(ctx, page); err != nil {
+		return err
+	}
+	if b.opts.Verbose {
+		log.Printf("Created page %s", path)
+	}
+	b.Result.Created++
+	return nil
+}
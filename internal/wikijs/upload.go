@@ -0,0 +1,167 @@
+package wikijs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/webscrape-to-wikijs/internal/sync"
+)
+
+// UploadOptions configures Upload
+type UploadOptions struct {
+	Locale  string // Locale of the pages (default: DefaultLocale)
+	DryRun  bool   // Log the page each file would create or update without changing the wiki
+	Verbose bool
+}
+
+// UploadResult counts the pages changed by Upload
+type UploadResult struct {
+	Created int
+	Updated int
+	Failed  []string // Paths of the markdown files that could not be uploaded
+}
+
+// PagePath returns the wiki page path of a markdown file: its path relative to the output
+// directory, which follows the record's fragments, without .md
+func PagePath(relPath string) string {
+	return strings.TrimSuffix(filepath.ToSlash(relPath), ".md")
+}
+
+// Upload creates or updates a wiki page for every markdown file under outputDir, in path order.
+// A page is updated when one exists at the file's page path and created otherwise. The title,
+// description, tags and published fields come from the file's frontmatter. Files that fail are
+// logged and listed in the result; a cancelled ctx stops the upload.
+func Upload(ctx context.Context, client *Client, outputDir string, opts UploadOptions) (UploadResult, error) {
+	if opts.Locale == "" {
+		opts.Locale = DefaultLocale
+	}
+
+	var files []string
+	err := filepath.Walk(outputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".md") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return UploadResult{}, err
+	}
+	sort.Strings(files)
+
+	var result UploadResult
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("upload cancelled: %w", err)
+		}
+
+		rel, err := filepath.Rel(outputDir, file)
+		if err != nil {
+			return result, err
+		}
+		page, err := readPage(file, PagePath(rel), opts.Locale)
+		if err != nil {
+			log.Printf("Warning: skipping %s: %v", file, err)
+			result.Failed = append(result.Failed, file)
+			continue
+		}
+
+		created, err := uploadPage(ctx, client, page, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, fmt.Errorf("upload cancelled: %w", ctx.Err())
+			}
+			log.Printf("Warning: failed to upload %s: %v", file, err)
+			result.Failed = append(result.Failed, file)
+			continue
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// uploadPage creates or updates page and reports whether it was created
+func uploadPage(ctx context.Context, client *Client, page Page, opts UploadOptions) (bool, error) {
+	existing, err := client.GetPage(ctx, page.Path, page.Locale)
+	if errors.Is(err, ErrPageNotFound) {
+		if opts.DryRun {
+			log.Printf("Would create page: %s", page.Path)
+			return true, nil
+		}
+		if _, err := client.CreatePage(ctx, page); err != nil {
+			return false, err
+		}
+		if opts.Verbose {
+			log.Printf("Created page %s", page.Path)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up page %s: %w", page.Path, err)
+	}
+
+	page.ID = existing.ID
+	if opts.DryRun {
+		log.Printf("Would update page: %s", page.Path)
+		return false, nil
+	}
+	if err := client.UpdatePage(ctx, page); err != nil {
+		return false, err
+	}
+	if opts.Verbose {
+		log.Printf("Updated page %s", page.Path)
+	}
+	return false, nil
+}
+
+// readPage reads a converted markdown file as a page at pagePath
+func readPage(file, pagePath, locale string) (Page, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	// Files converted with -no-frontmatter are uploaded whole, titled after their filename
+	frontmatter, body := map[string]string{}, string(content)
+	var tags []string
+	if hasFrontmatter(body) {
+		frontmatter, tags, body, err = sync.ParseFrontmatterWithTags(body)
+		if err != nil {
+			return Page{}, err
+		}
+	}
+
+	title := frontmatter["title"]
+	if title == "" {
+		title = filepath.Base(pagePath)
+	}
+	page := Page{
+		Path:        pagePath,
+		Locale:      locale,
+		Title:       title,
+		Description: frontmatter["description"],
+		Content:     strings.TrimLeft(body, "\n"),
+		IsPublished: frontmatter["published"] != "false",
+	}
+	if len(tags) > 0 {
+		page.Tags = tags
+	}
+	return page, nil
+}
+
+// hasFrontmatter reports whether content opens with a YAML, TOML or JSON frontmatter delimiter
+func hasFrontmatter(content string) bool {
+	return strings.HasPrefix(content, "---\n") || strings.HasPrefix(content, "+++\n") || strings.HasPrefix(content, "{\n")
+}